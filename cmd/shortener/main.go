@@ -9,6 +9,7 @@ import (
 	"log"
 	"net"
 	"net/http"
+	"os"
 	"os/signal"
 	"syscall"
 	"time"
@@ -16,13 +17,22 @@ import (
 	"go.uber.org/zap"
 	"golang.org/x/crypto/acme/autocert"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
+	"github.com/MihailSergeenkov/shortener/internal/app/audit"
+	"github.com/MihailSergeenkov/shortener/internal/app/certs"
 	"github.com/MihailSergeenkov/shortener/internal/app/config"
 	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/health"
 	"github.com/MihailSergeenkov/shortener/internal/app/logger"
+	"github.com/MihailSergeenkov/shortener/internal/app/metrics"
+	"github.com/MihailSergeenkov/shortener/internal/app/outbox"
 	"github.com/MihailSergeenkov/shortener/internal/app/proto"
 	"github.com/MihailSergeenkov/shortener/internal/app/routes"
 	"github.com/MihailSergeenkov/shortener/internal/app/services"
+	"github.com/MihailSergeenkov/shortener/internal/app/tracing"
+	"github.com/MihailSergeenkov/shortener/internal/app/webhooks"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -31,6 +41,8 @@ const (
 	timeoutShutdown       = time.Second * 10
 )
 
+var errUnknownTLSMode = errors.New("unknown tls mode")
+
 var (
 	buildVersion = "N/A"
 	buildDate    = "N/A"
@@ -45,6 +57,18 @@ type WebServer interface {
 
 func main() {
 	ctx := context.Background()
+
+	if len(os.Args) > 1 && os.Args[1] == migrateDataCommand {
+		ctx, stop := signal.NotifyContext(ctx, syscall.SIGTERM, syscall.SIGINT)
+		err := runMigrateData(ctx, os.Args[2:], os.Stdout, os.Stderr)
+		stop()
+
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	if err := run(ctx, true); err != nil {
 		log.Fatal(err)
 	}
@@ -59,13 +83,39 @@ func run(baseCtx context.Context, withFlags bool) error {
 		return fmt.Errorf("config error: %w", err)
 	}
 
-	l, err := logger.NewLogger(config.Params.LogLevel)
+	l, closeLogger, err := logger.Setup(&config.Params)
 	if err != nil {
 		return fmt.Errorf("logger error: %w", err)
 	}
+	defer func() {
+		if err := closeLogger(); err != nil {
+			log.Printf("failed to close logger: %v", err)
+		}
+	}()
+
+	l.Info("Running server on",
+		zap.String("addr", config.Params.RunAddr),
+		zap.Bool("https", config.Params.EnableHTTPS),
+		zap.String("tls_mode", config.Params.TLSMode),
+	)
+	l.Info("Running grpc server on",
+		zap.String("addr", config.Params.RunGAddr),
+		zap.Bool("tls", config.Params.GRPCTLSEnabled()),
+		zap.Bool("mtls", config.Params.GRPCTLSEnabled() && config.Params.GRPCClientCA != ""),
+	)
+
+	shutdownTracing, err := tracing.Setup(baseCtx, &config.Params)
+	if err != nil {
+		return fmt.Errorf("tracing error: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeoutServerShutdown)
+		defer cancel()
 
-	l.Info("Running server on", zap.String("addr", config.Params.RunAddr))
-	l.Info("Running grpc server on", zap.String("addr", config.Params.RunGAddr))
+		if err := shutdownTracing(ctx); err != nil {
+			l.Error("failed to shutdown tracing", zap.Error(err))
+		}
+	}()
 
 	ctx, cancelCtx := signal.NotifyContext(baseCtx, syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT)
 	defer cancelCtx()
@@ -73,22 +123,52 @@ func run(baseCtx context.Context, withFlags bool) error {
 	g, ctx := errgroup.WithContext(ctx)
 
 	context.AfterFunc(ctx, func() {
-		ctx, cancelCtx := context.WithTimeout(context.Background(), timeoutShutdown)
+		ctx, cancelCtx := context.WithTimeout(context.Background(), config.Params.ShutdownDrainDelay+timeoutShutdown)
 		defer cancelCtx()
 
 		<-ctx.Done()
 		log.Fatal("failed to gracefully shutdown the service")
 	})
 
-	s, err := data.NewStorage(ctx, l, &config.Params)
+	storage, err := data.NewStorage(ctx, logger.Component(l, logger.ComponentDB), &config.Params)
 	if err != nil {
 		return fmt.Errorf("storage error: %w", err)
 	}
+	// Фоновые задачи и хранилище работают, пока серверы обслуживают запросы, в том числе во время задержки остановки.
+	workCtx, stopWork := context.WithCancel(context.WithoutCancel(ctx))
+	defer stopWork()
+
+	instrumented := metrics.InstrumentStorage(storage)
+	recorder := audit.NewRecorder(l, instrumented, config.Params.AuditRetention)
+	s := audit.AuditStorage(instrumented, recorder)
+
+	dispatcher := webhooks.NewDispatcher(l, s, config.Params.WebhookAttempts, config.Params.WebhookRetry)
+	defer services.OnLinkEvent(dispatcher.Enqueue)()
+	stopLinkFeed := services.StartLinkFeed(config.Params.EventsBuffer)
+	defer stopLinkFeed()
+
+	auditDone := make(chan struct{})
+	go func() {
+		defer close(auditDone)
+		recorder.Run(workCtx)
+	}()
+	webhooksDone := make(chan struct{})
+	go func() {
+		defer close(webhooksDone)
+		dispatcher.Run(workCtx)
+	}()
+	relayDone, err := runOutboxRelay(workCtx, l, storage)
+	if err != nil {
+		return fmt.Errorf("outbox error: %w", err)
+	}
 
 	g.Go(func() error {
 		defer log.Print("closed DB")
 
-		<-ctx.Done()
+		<-workCtx.Done()
+		<-auditDone
+		<-webhooksDone
+		<-relayDone
 
 		if err := s.Close(); err != nil {
 			l.Error("failed to close db connection", zap.Error(err))
@@ -96,12 +176,31 @@ func run(baseCtx context.Context, withFlags bool) error {
 		return nil
 	})
 
-	r := routes.NewRouter(l, s)
+	grpcState := &health.ServerState{}
+	health.RegisterReadiness("storage", data.StorageCheck(storage))
+	health.RegisterReadiness("grpc", grpcState.Check)
+	health.RegisterLiveness("background_job", services.BackgroundJobCheck(config.Params.DropURLsPeriod))
+
+	r, err := routes.NewRouter(logger.Component(l, logger.ComponentHTTP), s)
+	if err != nil {
+		return fmt.Errorf("router error: %w", err)
+	}
 
-	go services.BackgroundJob(ctx, l, s, config.Params.DropURLsPeriod)
+	go services.BackgroundJob(workCtx, l, s, config.Params.DropURLsPeriod)
 
-	srv := configureServer(r, config.Params.EnableHTTPS, config.Params.RunAddr)
-	gSrv := proto.NewGRPCServer(l, s)
+	srv, err := configureServer(ctx, l, r, config.Params.EnableHTTPS, config.Params.RunAddr)
+	if err != nil {
+		return fmt.Errorf("http server error: %w", err)
+	}
+	gOpts, err := grpcServerOptions(ctx, l)
+	if err != nil {
+		return fmt.Errorf("grpc server error: %w", err)
+	}
+	gl := logger.Component(l, logger.ComponentGRPC)
+	gSrv := proto.NewGRPCServer(gl, s, gOpts...)
+	healthChecker := proto.NewHealthChecker(gl, s)
+	healthChecker.Register(gSrv)
+	go healthChecker.Run(ctx, config.Params.HealthInterval)
 
 	g.Go(func() error {
 		defer func() {
@@ -119,11 +218,38 @@ func run(baseCtx context.Context, withFlags bool) error {
 		return nil
 	})
 
+	mSrv := metricsServer(l, s, config.Params.MetricsAddr)
+	if mSrv != nil {
+		l.Info("Running metrics server on", zap.String("addr", config.Params.MetricsAddr))
+
+		g.Go(func() error {
+			if err := mSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("metrics server has encoutenred an error: %w", err)
+			}
+			return nil
+		})
+	}
+
+	aSrv := adminServer(l, config.Params.AdminAddr)
+	if aSrv != nil {
+		l.Info("Running admin server on", zap.String("addr", config.Params.AdminAddr))
+
+		g.Go(func() error {
+			if err := aSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("admin server has encoutenred an error: %w", err)
+			}
+			return nil
+		})
+	}
+
 	g.Go(func() error {
 		listen, err := net.Listen("tcp", config.Params.RunGAddr)
 		if err != nil {
 			return fmt.Errorf("listen grpc has failed: %w", err)
 		}
+		grpcState.SetServing(true)
+		defer grpcState.SetServing(false)
+
 		if err := gSrv.Serve(listen); err != nil {
 			return fmt.Errorf("start grpc has failed: %w", err)
 		}
@@ -134,13 +260,8 @@ func run(baseCtx context.Context, withFlags bool) error {
 	g.Go(func() error {
 		defer log.Print("server has been shutdown")
 		<-ctx.Done()
-
-		shutdownTimeoutCtx, cancelShutdownTimeoutCtx := context.WithTimeout(context.Background(), timeoutServerShutdown)
-		defer cancelShutdownTimeoutCtx()
-		if err := srv.Shutdown(shutdownTimeoutCtx); err != nil {
-			log.Printf("an error occurred during server shutdown: %v", err)
-		}
-		gSrv.GracefulStop()
+		shutdownServers(l, config.Params.ShutdownDrainDelay, stopLinkFeed, srv, mSrv, aSrv, gSrv)
+		stopWork()
 
 		return nil
 	})
@@ -152,29 +273,193 @@ func run(baseCtx context.Context, withFlags bool) error {
 	return nil
 }
 
-func configureServer(r chi.Router, enableHTTPS bool, runAddr string) *http.Server {
-	if enableHTTPS {
-		certManager := autocert.Manager{
-			Prompt:     autocert.AcceptTOS,
-			Cache:      autocert.DirCache("/tmp/certs"),
-			HostPolicy: autocert.HostWhitelist("mynetwork.keenetic.link"),
+// shutdownServers переводит готовность в отказ и останавливает серверы через drainDelay:
+// за это время балансировщик замечает отказ /readyz и перестает направлять запросы.
+func shutdownServers(
+	l *zap.Logger,
+	drainDelay time.Duration,
+	stopLinkFeed func(),
+	srv, mSrv, aSrv *http.Server,
+	gSrv *grpc.Server,
+) {
+	health.Shutdown()
+	if drainDelay > 0 {
+		l.Info("draining before shutdown", zap.Duration("delay", drainDelay))
+		time.Sleep(drainDelay)
+	}
+
+	// Потоки событий открыты до отключения клиента, без их завершения серверы ждали бы таймаута остановки.
+	stopLinkFeed()
+
+	shutdownTimeoutCtx, cancelShutdownTimeoutCtx := context.WithTimeout(context.Background(), timeoutServerShutdown)
+	defer cancelShutdownTimeoutCtx()
+	if err := srv.Shutdown(shutdownTimeoutCtx); err != nil {
+		log.Printf("an error occurred during server shutdown: %v", err)
+	}
+	if mSrv != nil {
+		if err := mSrv.Shutdown(shutdownTimeoutCtx); err != nil {
+			log.Printf("an error occurred during metrics server shutdown: %v", err)
+		}
+	}
+	if aSrv != nil {
+		if err := aSrv.Shutdown(shutdownTimeoutCtx); err != nil {
+			log.Printf("an error occurred during admin server shutdown: %v", err)
 		}
-		server := &http.Server{
-			Addr:    runAddr,
-			Handler: r,
-			TLSConfig: &tls.Config{
-				GetCertificate: certManager.GetCertificate,
-				MinVersion:     tls.VersionTLS13,
-			},
+	}
+	gSrv.GracefulStop()
+}
+
+// runOutboxRelay запускает публикацию исходящей очереди хранилища, если задан адрес публикации.
+// Возвращаемый канал закрывается после остановки публикации.
+func runOutboxRelay(ctx context.Context, l *zap.Logger, storage data.Storager) (<-chan struct{}, error) {
+	done := make(chan struct{})
+
+	if config.Params.OutboxURL == "" {
+		close(done)
+		return done, nil
+	}
+
+	source, ok := storage.(data.Outbox)
+	if !ok {
+		l.Warn("outbox is supported only by postgres storage, events are not published")
+		close(done)
+		return done, nil
+	}
+
+	publisher, err := outbox.NewPublisher(l, config.Params.OutboxURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create outbox publisher: %w", err)
+	}
+
+	go func() {
+		defer close(done)
+
+		services.OutboxRelay(ctx, l, source, publisher, config.Params.OutboxPeriod)
+		if err := publisher.Close(); err != nil {
+			l.Error("failed to close outbox publisher", zap.Error(err))
 		}
+	}()
 
-		return server
+	return done, nil
+}
+
+// metricsServer возвращает сервер метрик на отдельном адресе addr или nil, если адрес не задан
+// и метрики отдаются основным сервером.
+func metricsServer(l *zap.Logger, s data.Storager, addr string) *http.Server {
+	if addr == "" {
+		return nil
 	}
 
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler(l, s))
+
 	return &http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
+}
+
+// adminServer возвращает административный сервер с профилировщиком на адресе addr или nil,
+// если адрес не задан и профилирование отключено.
+func adminServer(l *zap.Logger, addr string) *http.Server {
+	if addr == "" {
+		return nil
+	}
+
+	return &http.Server{
+		Addr:    addr,
+		Handler: routes.NewAdminRouter(logger.Component(l, logger.ComponentHTTP)),
+	}
+}
+
+func configureServer(ctx context.Context, l *zap.Logger, r chi.Router, enableHTTPS bool, runAddr string) (*http.Server, error) {
+	server := &http.Server{
 		Addr:    runAddr,
 		Handler: r,
 	}
+
+	if !enableHTTPS {
+		return server, nil
+	}
+
+	tlsConfig, err := httpsConfig(ctx, l)
+	if err != nil {
+		return nil, err
+	}
+	server.TLSConfig = tlsConfig
+
+	return server, nil
+}
+
+// httpsConfig возвращает TLS настройки HTTPS сервера в зависимости от режима получения сертификата.
+func httpsConfig(ctx context.Context, l *zap.Logger) (*tls.Config, error) {
+	switch config.Params.TLSMode {
+	case config.TLSModeAutocert:
+		certManager := autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			Cache:      autocert.DirCache(config.Params.AutocertDir),
+			HostPolicy: autocert.HostWhitelist(config.Params.TLSHosts...),
+		}
+
+		return &tls.Config{
+			GetCertificate: certManager.GetCertificate,
+			MinVersion:     tls.VersionTLS13,
+		}, nil
+	case config.TLSModeFiles:
+		reloader, err := certs.NewReloader(l, config.Params.TLSCert, config.Params.TLSKey, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to load https certificates: %w", err)
+		}
+
+		go reloader.Watch(ctx, config.Params.CertReload)
+		reloadOnSIGHUP(ctx, reloader)
+
+		return reloader.ServerConfig(), nil
+	case config.TLSModeSelfSigned:
+		hosts := append([]string{"localhost", "127.0.0.1", "::1", config.Params.BaseURL.Hostname()}, config.Params.TLSHosts...)
+
+		cert, err := certs.SelfSigned(hosts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate self-signed certificate: %w", err)
+		}
+
+		l.Warn("HTTPS server uses self-signed certificate", zap.Strings("hosts", hosts))
+
+		return &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS13,
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnknownTLSMode, config.Params.TLSMode)
+	}
+}
+
+// reloadOnSIGHUP перечитывает сертификаты reloader при получении сигнала SIGHUP.
+func reloadOnSIGHUP(ctx context.Context, reloader *certs.Reloader) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(sighup)
+		reloader.ReloadOn(ctx, sighup)
+	}()
+}
+
+// grpcServerOptions возвращает опции TLS для gRPC сервера, сертификаты перечитываются при изменении файлов и по SIGHUP.
+func grpcServerOptions(ctx context.Context, l *zap.Logger) ([]grpc.ServerOption, error) {
+	if !config.Params.GRPCTLSEnabled() {
+		return nil, nil
+	}
+
+	reloader, err := certs.NewReloader(l, config.Params.GRPCTLSCert, config.Params.GRPCTLSKey, config.Params.GRPCClientCA)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load grpc certificates: %w", err)
+	}
+
+	go reloader.Watch(ctx, config.Params.CertReload)
+	reloadOnSIGHUP(ctx, reloader)
+
+	return []grpc.ServerOption{grpc.Creds(credentials.NewTLS(reloader.ServerConfig()))}, nil
 }
 
 func runServer(srv WebServer, enableHTTPS bool) error {
@@ -2,19 +2,26 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"testing"
 	"time"
 
 	webmock "github.com/MihailSergeenkov/shortener/cmd/shortener/mock"
 
+	"github.com/MihailSergeenkov/shortener/internal/app/certs/certstest"
+	"github.com/MihailSergeenkov/shortener/internal/app/config"
 	"github.com/MihailSergeenkov/shortener/internal/app/data/mock"
 	"github.com/MihailSergeenkov/shortener/internal/app/routes"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 )
 
 func TestRun(t *testing.T) {
@@ -22,6 +29,7 @@ func TestRun(t *testing.T) {
 	defer cancel()
 
 	t.Run("run server", func(t *testing.T) {
+		t.Setenv("SHUTDOWN_DRAIN_DELAY", "0s")
 		err := run(ctx, false)
 		require.NoError(t, err)
 	})
@@ -31,27 +39,73 @@ func TestConfigureServer(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	logger := zap.NewNop()
 	storage := mock.NewMockStorager(mockCtrl)
-	router := routes.NewRouter(logger, storage)
+	router, err := routes.NewRouter(logger, storage)
+	require.NoError(t, err)
 	runAddr := "localhost:8080"
 
+	params := config.Params
+	defer func() { config.Params = params }()
+
+	ca, err := certstest.NewCA("test ca")
+	require.NoError(t, err)
+	pair, err := ca.IssueServer("localhost", "localhost")
+	require.NoError(t, err)
+	certFile, keyFile, err := pair.WriteFiles(t.TempDir(), "server")
+	require.NoError(t, err)
+
 	tests := []struct {
 		name        string
 		enableHTTPS bool
+		tlsMode     string
+		wantErr     error
 	}{
 		{
 			name:        "HTTP",
 			enableHTTPS: false,
+			tlsMode:     "unknown",
+		},
+		{
+			name:        "HTTPS autocert",
+			enableHTTPS: true,
+			tlsMode:     config.TLSModeAutocert,
+		},
+		{
+			name:        "HTTPS files",
+			enableHTTPS: true,
+			tlsMode:     config.TLSModeFiles,
+		},
+		{
+			name:        "HTTPS self-signed",
+			enableHTTPS: true,
+			tlsMode:     config.TLSModeSelfSigned,
 		},
 		{
-			name:        "HTTPS",
+			name:        "HTTPS unknown mode",
 			enableHTTPS: true,
+			tlsMode:     "unknown",
+			wantErr:     errUnknownTLSMode,
 		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			server := configureServer(router, test.enableHTTPS, runAddr)
+			config.Params.TLSMode = test.tlsMode
+			config.Params.TLSHosts = []string{"localhost"}
+			config.Params.AutocertDir = t.TempDir()
+			config.Params.TLSCert = certFile
+			config.Params.TLSKey = keyFile
+			config.Params.CertReload = 0
+
+			server, err := configureServer(ctx, logger, router, test.enableHTTPS, runAddr)
+			if test.wantErr != nil {
+				require.ErrorIs(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
 
 			assert.IsType(t, (*http.Server)(nil), server)
 			assert.Equal(t, runAddr, server.Addr)
@@ -64,6 +118,89 @@ func TestConfigureServer(t *testing.T) {
 			}
 		})
 	}
+
+	t.Run("HTTPS files missing", func(t *testing.T) {
+		config.Params.TLSMode = config.TLSModeFiles
+		config.Params.TLSCert = filepath.Join(t.TempDir(), "missing.crt")
+
+		_, err := configureServer(ctx, logger, router, true, runAddr)
+		require.ErrorContains(t, err, "failed to load https certificates")
+	})
+}
+
+func TestConfigureServer_SelfSignedHandshake(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	logger := zap.NewNop()
+	storage := mock.NewMockStorager(mockCtrl)
+
+	params := config.Params
+	defer func() { config.Params = params }()
+	config.Params.TLSMode = config.TLSModeSelfSigned
+	config.Params.TLSHosts = nil
+
+	router, err := routes.NewRouter(logger, storage)
+	require.NoError(t, err)
+
+	server, err := configureServer(context.Background(), logger, router, true, "")
+	require.NoError(t, err)
+
+	srv := httptest.NewUnstartedServer(server.Handler)
+	srv.TLS = server.TLSConfig
+	srv.StartTLS()
+	defer srv.Close()
+
+	conn, err := tls.Dial("tcp", srv.Listener.Addr().String(), &tls.Config{
+		InsecureSkipVerify: true, //nolint:gosec // самоподписанный сертификат проверяется ниже
+		MinVersion:         tls.VersionTLS13,
+	})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, conn.Close()) }()
+
+	cert := conn.ConnectionState().PeerCertificates[0]
+	assert.NoError(t, cert.VerifyHostname("localhost"))
+	assert.NoError(t, cert.VerifyHostname("127.0.0.1"))
+}
+
+func TestMetricsServer(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	storage := mock.NewMockStorager(mockCtrl)
+
+	t.Run("served by main server", func(t *testing.T) {
+		assert.Nil(t, metricsServer(zap.NewNop(), storage, ""))
+	})
+
+	t.Run("separate address", func(t *testing.T) {
+		storage.EXPECT().FetchStats(gomock.Any()).Times(1).Return(1, 1, nil)
+
+		srv := metricsServer(zap.NewNop(), storage, "localhost:9090")
+		require.NotNil(t, srv)
+		assert.Equal(t, "localhost:9090", srv.Addr)
+
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "shortener_urls 1")
+	})
+}
+
+func TestAdminServer(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		assert.Nil(t, adminServer(zap.NewNop(), ""))
+	})
+
+	t.Run("separate address", func(t *testing.T) {
+		srv := adminServer(zap.NewNop(), "localhost:6060")
+		require.NotNil(t, srv)
+		assert.Equal(t, "localhost:6060", srv.Addr)
+
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/pprof/", http.NoBody))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
 }
 
 func TestRunServer_OK(t *testing.T) {
@@ -113,3 +250,95 @@ func TestRunServer_Failed(t *testing.T) {
 		require.ErrorContains(t, err, "listen and server has failed")
 	})
 }
+
+func TestGRPCServerOptions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger := zap.NewNop()
+	params := config.Params
+	defer func() { config.Params = params }()
+
+	t.Run("plaintext", func(t *testing.T) {
+		config.Params.GRPCTLSCert = ""
+		config.Params.GRPCTLSKey = ""
+
+		opts, err := grpcServerOptions(ctx, logger)
+		require.NoError(t, err)
+		assert.Empty(t, opts)
+	})
+
+	t.Run("missing certificates", func(t *testing.T) {
+		config.Params.GRPCTLSCert = "/not_exist_dir/server.crt"
+		config.Params.GRPCTLSKey = "/not_exist_dir/server.key"
+
+		_, err := grpcServerOptions(ctx, logger)
+		require.ErrorContains(t, err, "failed to load grpc certificates")
+	})
+
+	t.Run("TLS", func(t *testing.T) {
+		ca, err := certstest.NewCA("test CA")
+		require.NoError(t, err)
+		pair, err := ca.IssueServer("server", "localhost")
+		require.NoError(t, err)
+
+		config.Params.GRPCTLSCert, config.Params.GRPCTLSKey, err = pair.WriteFiles(t.TempDir(), "server")
+		require.NoError(t, err)
+
+		opts, err := grpcServerOptions(ctx, logger)
+		require.NoError(t, err)
+		assert.Len(t, opts, 1)
+	})
+}
+
+func TestShutdownServers_Drain(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	logger := zap.NewNop()
+	storage := mock.NewMockStorager(mockCtrl)
+	router, err := routes.NewRouter(logger, storage)
+	require.NoError(t, err)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := &http.Server{Handler: router, ReadHeaderTimeout: time.Second}
+	go func() { _ = srv.Serve(ln) }()
+
+	readyzURL := "http://" + ln.Addr().String() + "/readyz"
+	client := &http.Client{Timeout: time.Second}
+	readyzStatus := func() (int, error) {
+		resp, err := client.Get(readyzURL)
+		if err != nil {
+			return 0, err
+		}
+		defer func() { _ = resp.Body.Close() }()
+		return resp.StatusCode, nil
+	}
+
+	const drainDelay = 300 * time.Millisecond
+	linkFeedStopped := make(chan struct{})
+	done := make(chan struct{})
+	start := time.Now()
+	go func() {
+		defer close(done)
+		shutdownServers(logger, drainDelay, func() { close(linkFeedStopped) }, srv, nil, nil, grpc.NewServer())
+	}()
+
+	// Пока идет задержка, сервер принимает запросы и отвечает отказом готовности.
+	assert.Eventually(t, func() bool {
+		code, err := readyzStatus()
+		return err == nil && code == http.StatusServiceUnavailable
+	}, drainDelay/2, 10*time.Millisecond)
+	select {
+	case <-linkFeedStopped:
+		t.Fatal("link feed stopped before drain delay")
+	default:
+	}
+
+	<-done
+	assert.GreaterOrEqual(t, time.Since(start), drainDelay)
+	<-linkFeedStopped
+	_, err = readyzStatus()
+	assert.Error(t, err)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/datamigration"
+	"github.com/MihailSergeenkov/shortener/internal/app/logger"
+)
+
+const migrateDataCommand = "migrate-data"
+
+var errMissingStorage = errors.New("both -from and -to are required")
+
+// runMigrateData выполняет команду migrate-data: перенос данных между хранилищами, заданными адресами
+// memory:, file:/path/to/db.json или postgres://..., итог выводится в stdout в формате JSON.
+func runMigrateData(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	fs := flag.NewFlagSet(migrateDataCommand, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	from := fs.String("from", "", "source storage: file:/path/to/db.json or postgres://...")
+	to := fs.String("to", "", "target storage: file:/path/to/db.json or postgres://...")
+	opts := datamigration.Options{}
+	fs.StringVar(&opts.StatePath, "state", "", "state file to resume an interrupted migration")
+	fs.IntVar(&opts.PageSize, "page-size", datamigration.DefaultPageSize, "number of urls copied at once")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "count what would be copied without writing to the target")
+	logLevel := zapcore.InfoLevel
+	fs.TextVar(&logLevel, "l", zapcore.InfoLevel, "log level")
+
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	if *from == "" || *to == "" {
+		return errMissingStorage
+	}
+	opts.Source, opts.Target = *from, *to
+
+	l, err := logger.NewLogger(logLevel)
+	if err != nil {
+		return fmt.Errorf("logger error: %w", err)
+	}
+
+	source, err := data.NewStorageFromURL(ctx, l, *from)
+	if err != nil {
+		return fmt.Errorf("source storage error: %w", err)
+	}
+	defer closeStorage(l, source)
+
+	target, err := data.NewStorageFromURL(ctx, l, *to)
+	if err != nil {
+		return fmt.Errorf("target storage error: %w", err)
+	}
+	defer closeStorage(l, target)
+
+	report, migrateErr := datamigration.Run(ctx, l, source, target, opts)
+
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	if migrateErr != nil {
+		return fmt.Errorf("migration error: %w", migrateErr)
+	}
+
+	return nil
+}
+
+func closeStorage(l *zap.Logger, s data.Storager) {
+	if err := s.Close(); err != nil {
+		l.Error("failed to close storage", zap.Error(err))
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/datamigration"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+)
+
+func TestRunMigrateData(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	fromPath := filepath.Join(dir, "from.json")
+	toPath := filepath.Join(dir, "to.json")
+
+	source, err := data.NewFileStorage(zap.NewNop(), fromPath)
+	require.NoError(t, err)
+	_, err = source.ImportURLs(ctx, []models.URL{
+		{ShortURL: "a", OriginalURL: "https://ya.ru/a", UserID: "user_1"},
+		{ShortURL: "b", OriginalURL: "https://ya.ru/b", UserID: "user_2", DeletedFlag: true},
+	})
+	require.NoError(t, err)
+
+	migrate := func(t *testing.T, args ...string) datamigration.Report {
+		t.Helper()
+
+		var stdout, stderr bytes.Buffer
+		require.NoError(t, runMigrateData(ctx, args, &stdout, &stderr))
+
+		var report datamigration.Report
+		require.NoError(t, json.Unmarshal(stdout.Bytes(), &report))
+
+		return report
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		report := migrate(t, "-l", "error", "-from", "file:"+fromPath, "-to", "file://"+toPath, "-dry-run")
+		assert.Equal(t, 2, report.Copied)
+		assert.True(t, report.DryRun)
+	})
+
+	t.Run("migrate", func(t *testing.T) {
+		report := migrate(t, "-l", "error", "-from", "file:"+fromPath, "-to", "file://"+toPath,
+			"-state", filepath.Join(dir, "state.json"), "-page-size", "1")
+		assert.Equal(t, 2, report.Copied)
+		assert.Equal(t, 2, report.TargetAfter)
+
+		target, err := data.NewFileStorage(zap.NewNop(), toPath)
+		require.NoError(t, err)
+
+		u, err := target.GetURL(ctx, "b")
+		require.NoError(t, err)
+		assert.Equal(t, "user_2", u.UserID)
+		assert.True(t, u.DeletedFlag)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+
+		err := runMigrateData(ctx, []string{"-from", "file:" + fromPath}, &stdout, &stderr)
+		require.ErrorIs(t, err, errMissingStorage)
+
+		err = runMigrateData(ctx, []string{"-l", "error", "-from", "redis://localhost", "-to", "memory:"}, &stdout, &stderr)
+		require.ErrorIs(t, err, data.ErrUnknownStorage)
+	})
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/MihailSergeenkov/shortener/pkg/client"
+)
+
+const originalURLColumn = "original_url"
+
+var (
+	errMissingArgs       = errors.New("missing arguments")
+	errMissingURLColumn  = errors.New("csv file has no original_url column")
+	errNothingToShorten  = errors.New("no urls to shorten")
+	errTooManyArgs       = errors.New("too many arguments")
+	errShortenConflicted = errors.New("url is already shortened")
+)
+
+// app состояние утилиты на время выполнения команды.
+type app struct {
+	stdout io.Writer
+	stderr io.Writer
+	client *client.Client
+	http   *client.HTTPTransport
+	cfg    *ctlConfig
+	format string
+	dirty  bool
+}
+
+type command func(ctx context.Context, args []string) error
+
+func (a *app) commands() map[string]command {
+	return map[string]command{
+		"shorten": a.shorten,
+		"import":  a.importURLs,
+		"export":  a.export,
+		"list":    a.list,
+		"delete":  a.delete,
+		"resolve": a.resolve,
+		"stats":   a.stats,
+		"logout":  a.logout,
+	}
+}
+
+// saveConfig сохраняет настройки, если сервис выдал новый токен или изменились настройки.
+func (a *app) saveConfig(path string) error {
+	if a.http != nil {
+		if token := a.http.Token(); token != "" && token != a.cfg.Token {
+			a.cfg.Token = token
+			a.dirty = true
+		}
+	}
+
+	if !a.dirty {
+		return nil
+	}
+
+	return a.cfg.save(path)
+}
+
+func (a *app) shorten(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("shorten", flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	file := fs.String("f", "", "file with URLs: one per line, CSV or JSON with original_url")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	if *file != "" {
+		return a.shortenFile(ctx, *file)
+	}
+
+	switch fs.NArg() {
+	case 0:
+		return fmt.Errorf("%w: shorten URL... or shorten -f FILE", errMissingArgs)
+	case 1:
+		return a.shortenOne(ctx, fs.Arg(0))
+	default:
+		items := make([]client.BatchItem, 0, fs.NArg())
+		for i, u := range fs.Args() {
+			items = append(items, client.BatchItem{CorrelationID: strconv.Itoa(i + 1), OriginalURL: u})
+		}
+		return a.shortenBatch(ctx, items)
+	}
+}
+
+func (a *app) shortenOne(ctx context.Context, originalURL string) error {
+	shortURL, err := a.client.Shorten(ctx, originalURL)
+	if err != nil && !errors.Is(err, client.ErrConflict) {
+		return fmt.Errorf("failed to shorten url: %w", err)
+	}
+	if err != nil {
+		fmt.Fprintf(a.stderr, "%s: %s\n", errShortenConflicted, originalURL)
+	}
+
+	result := client.URL{ShortURL: shortURL, OriginalURL: originalURL}
+
+	return writeOutput(a.stdout, a.format, result, urlsTable([]client.URL{result}))
+}
+
+func (a *app) importURLs(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w: import FILE", errMissingArgs)
+	}
+
+	return a.shortenFile(ctx, args[0])
+}
+
+func (a *app) shortenFile(ctx context.Context, path string) error {
+	items, err := readURLs(path)
+	if err != nil {
+		return err
+	}
+
+	return a.shortenBatch(ctx, items)
+}
+
+func (a *app) shortenBatch(ctx context.Context, items []client.BatchItem) error {
+	if len(items) == 0 {
+		return errNothingToShorten
+	}
+
+	results, err := a.client.ShortenBatch(ctx, items)
+	if err != nil {
+		return fmt.Errorf("failed to shorten urls: %w", err)
+	}
+
+	originals := make(map[string]string, len(items))
+	for _, item := range items {
+		originals[item.CorrelationID] = item.OriginalURL
+	}
+
+	type row struct {
+		CorrelationID string `json:"correlation_id"`
+		ShortURL      string `json:"short_url"`
+		OriginalURL   string `json:"original_url"`
+	}
+
+	rows := make([]row, 0, len(results))
+	t := table{header: []string{"correlation_id", "short_url", originalURLColumn}}
+	for _, r := range results {
+		rows = append(rows, row{CorrelationID: r.CorrelationID, ShortURL: r.ShortURL, OriginalURL: originals[r.CorrelationID]})
+		t.rows = append(t.rows, []string{r.CorrelationID, r.ShortURL, originals[r.CorrelationID]})
+	}
+
+	return writeOutput(a.stdout, a.format, rows, t)
+}
+
+func (a *app) export(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	file := fs.String("f", "", "file to write links to, format is chosen by extension")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	urls, err := a.client.ListMyURLs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch urls: %w", err)
+	}
+
+	if *file == "" {
+		return writeOutput(a.stdout, a.format, urls, urlsTable(urls))
+	}
+
+	format := a.format
+	switch strings.ToLower(filepath.Ext(*file)) {
+	case ".json":
+		format = formatJSON
+	case ".csv":
+		format = formatCSV
+	}
+
+	f, err := os.Create(*file)
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+
+	if err := writeOutput(f, format, urls, urlsTable(urls)); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close export file: %w", err)
+	}
+
+	fmt.Fprintf(a.stderr, "exported %d urls to %s\n", len(urls), *file)
+
+	return nil
+}
+
+func (a *app) list(ctx context.Context, _ []string) error {
+	urls, err := a.client.ListMyURLs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch urls: %w", err)
+	}
+
+	return writeOutput(a.stdout, a.format, urls, urlsTable(urls))
+}
+
+func (a *app) delete(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: delete URL|KEY...", errMissingArgs)
+	}
+
+	if err := a.client.Delete(ctx, args...); err != nil {
+		return fmt.Errorf("failed to delete urls: %w", err)
+	}
+
+	keys := make([]string, 0, len(args))
+	t := table{header: []string{"deleted"}}
+	for _, arg := range args {
+		keys = append(keys, client.Key(arg))
+		t.rows = append(t.rows, []string{client.Key(arg)})
+	}
+
+	return writeOutput(a.stdout, a.format, map[string][]string{"deleted": keys}, t)
+}
+
+func (a *app) resolve(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w: resolve URL|KEY", errMissingArgs)
+	}
+
+	originalURL, err := a.client.Resolve(ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("failed to resolve url: %w", err)
+	}
+
+	result := client.URL{ShortURL: args[0], OriginalURL: originalURL}
+
+	return writeOutput(a.stdout, a.format, result, urlsTable([]client.URL{result}))
+}
+
+func (a *app) stats(ctx context.Context, _ []string) error {
+	stats, err := a.client.Stats(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch stats: %w", err)
+	}
+
+	t := table{
+		header: []string{"urls", "users"},
+		rows:   [][]string{{strconv.Itoa(stats.URLs), strconv.Itoa(stats.Users)}},
+	}
+
+	return writeOutput(a.stdout, a.format, stats, t)
+}
+
+func (a *app) logout(_ context.Context, args []string) error {
+	if len(args) != 0 {
+		return errTooManyArgs
+	}
+
+	a.cfg.Token = ""
+	a.cfg.UserID = ""
+	a.http = nil
+	a.dirty = true
+
+	return nil
+}
+
+func urlsTable(urls []client.URL) table {
+	t := table{header: []string{"short_url", originalURLColumn}}
+	for _, u := range urls {
+		t.rows = append(t.rows, []string{u.ShortURL, u.OriginalURL})
+	}
+
+	return t
+}
+
+// readURLs читает ссылки из файла: JSON массив объектов с original_url, CSV с колонкой original_url
+// (например, результат export) или текст по одной ссылке в строке.
+func readURLs(path string) ([]client.BatchItem, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var items []client.BatchItem
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		if err := json.NewDecoder(f).Decode(&items); err != nil {
+			return nil, fmt.Errorf("failed to parse json file: %w", err)
+		}
+	case ".csv":
+		items, err = readCSVURLs(f)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			items = append(items, client.BatchItem{OriginalURL: line})
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+	}
+
+	for i := range items {
+		if items[i].CorrelationID == "" {
+			items[i].CorrelationID = strconv.Itoa(i + 1)
+		}
+	}
+
+	return items, nil
+}
+
+func readCSVURLs(r io.Reader) ([]client.BatchItem, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse csv file: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	column := slices.Index(records[0], originalURLColumn)
+	if column < 0 {
+		return nil, errMissingURLColumn
+	}
+
+	items := make([]client.BatchItem, 0, len(records)-1)
+	for _, record := range records[1:] {
+		if column < len(record) && record[column] != "" {
+			items = append(items, client.BatchItem{OriginalURL: record[column]})
+		}
+	}
+
+	return items, nil
+}
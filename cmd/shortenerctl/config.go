@@ -0,0 +1,110 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	configDirName  = "shortenerctl"
+	configFileName = "config.json"
+	configFileMode = 0o600
+	configDirMode  = 0o700
+	userIDBytes    = 8
+)
+
+// ctlConfig локальные настройки утилиты: выданный сервисом JWT и идентификатор пользователя для gRPC.
+type ctlConfig struct {
+	Token  string `json:"token,omitempty"`
+	UserID string `json:"user_id,omitempty"`
+}
+
+// defaultConfigPath возвращает путь к файлу настроек в пользовательском каталоге конфигурации.
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return configFileName
+	}
+
+	return filepath.Join(dir, configDirName, configFileName)
+}
+
+// loadConfig читает настройки, отсутствующий файл означает пустые настройки.
+func loadConfig(path string) (*ctlConfig, error) {
+	cfg := &ctlConfig{}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// save записывает настройки, файл доступен только владельцу, так как содержит токен.
+func (c *ctlConfig) save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), configDirMode); err != nil {
+		return fmt.Errorf("failed to create config dir: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, configFileMode); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	return nil
+}
+
+// grpcUserID возвращает идентификатор пользователя для gRPC: из сохраненного JWT, чтобы HTTP и gRPC
+// работали с одними ссылками, иначе ранее выданный утилитой. Новый идентификатор генерируется при первом вызове.
+func (c *ctlConfig) grpcUserID() (string, bool, error) {
+	if userID := tokenUserID(c.Token); userID != "" {
+		return userID, false, nil
+	}
+
+	if c.UserID != "" {
+		return c.UserID, false, nil
+	}
+
+	bytes := make([]byte, userIDBytes)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", false, fmt.Errorf("failed to generate user id: %w", err)
+	}
+	c.UserID = hex.EncodeToString(bytes)
+
+	return c.UserID, true, nil
+}
+
+// tokenUserID читает идентификатор пользователя из JWT без проверки подписи, ее проверяет сервис.
+func tokenUserID(token string) string {
+	if token == "" {
+		return ""
+	}
+
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+		return ""
+	}
+
+	userID, _ := claims["UserID"].(string)
+
+	return userID
+}
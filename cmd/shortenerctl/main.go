@@ -0,0 +1,237 @@
+// Пакет main утилиты shortenerctl - клиента командной строки сервиса сокращения ссылок.
+// Утилита работает по HTTP или gRPC (флаг -grpc) и сохраняет выданный сервисом JWT в локальном файле настроек.
+// Использование: shortenerctl [-flag] command [args]. Для подсказки выполните shortenerctl -h.
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/MihailSergeenkov/shortener/pkg/client"
+)
+
+const (
+	defaultAddr    = "http://localhost:8080"
+	defaultTimeout = 10 * time.Second
+	realIPHeader   = "X-Real-IP"
+)
+
+var (
+	errNoCommand      = errors.New("command is required")
+	errUnknownCommand = errors.New("unknown command")
+	errInvalidCA      = errors.New("no certificates found in grpc ca file")
+)
+
+const usage = `Usage: shortenerctl [-flag] command [args]
+
+Commands:
+  shorten URL...          shorten URLs (several URLs are shortened in one batch)
+  shorten -f FILE         shorten URLs from a file: one per line, CSV or JSON with original_url
+  import FILE             shorten URLs from an exported CSV or JSON file
+  export [-f FILE]        export own links (format by file extension or -o)
+  list                    list own links
+  delete URL|KEY...       delete own links
+  resolve URL|KEY         show original URL
+  stats                   show service stats (trusted subnet only, see -real-ip)
+  logout                  forget the saved token
+
+Flags:
+`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		log.Fatal(err)
+	}
+}
+
+// options глобальные флаги утилиты.
+type options struct {
+	addr       string
+	grpcAddr   string
+	grpcCA     string
+	grpcCert   string
+	grpcKey    string
+	configPath string
+	format     string
+	org        string
+	realIP     string
+	timeout    time.Duration
+	gzip       bool
+}
+
+func parseOptions(args []string, stderr io.Writer) (*options, []string, error) {
+	o := &options{}
+
+	fs := flag.NewFlagSet("shortenerctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+
+	fs.StringVar(&o.addr, "addr", envOr("SHORTENER_ADDR", defaultAddr), "HTTP base URL of the service")
+	fs.StringVar(&o.grpcAddr, "grpc", os.Getenv("SHORTENER_GRPC_ADDR"), "gRPC address of the service, enables gRPC transport")
+	fs.StringVar(&o.grpcCA, "grpc-ca", "", "CA certificate file for gRPC over TLS")
+	fs.StringVar(&o.grpcCert, "grpc-cert", "", "client certificate file for gRPC over mutual TLS, requires -grpc-key")
+	fs.StringVar(&o.grpcKey, "grpc-key", "", "client key file for gRPC over mutual TLS")
+	fs.StringVar(&o.configPath, "config", defaultConfigPath(), "path to the local config file with the token")
+	fs.StringVar(&o.format, "o", formatTable, "output format: table, json or csv")
+	fs.StringVar(&o.org, "org", "", "organization to act on behalf of")
+	fs.StringVar(&o.realIP, "real-ip", "", "X-Real-IP header value for stats behind a trusted proxy")
+	fs.DurationVar(&o.timeout, "timeout", defaultTimeout, "request timeout including retries")
+	fs.BoolVar(&o.gzip, "gzip", false, "compress request bodies")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	if err := checkFormat(o.format); err != nil {
+		return nil, nil, err
+	}
+
+	return o, fs.Args(), nil
+}
+
+func run(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	o, args, err := parseOptions(args, stderr)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return errNoCommand
+	}
+
+	cfg, err := loadConfig(o.configPath)
+	if err != nil {
+		return err
+	}
+
+	a := &app{stdout: stdout, stderr: stderr, format: o.format, cfg: cfg}
+
+	command, ok := a.commands()[args[0]]
+	if !ok {
+		return fmt.Errorf("%w: %s", errUnknownCommand, args[0])
+	}
+
+	closeConn, err := a.connect(o)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+
+	if err := command(ctx, args[1:]); err != nil {
+		return err
+	}
+
+	return a.saveConfig(o.configPath)
+}
+
+// connect создает клиент с выбранным транспортом.
+func (a *app) connect(o *options) (func(), error) {
+	opts := []client.Option{client.WithTimeout(o.timeout)}
+	if o.org != "" {
+		opts = append(opts, client.WithOrg(o.org))
+	}
+
+	if o.grpcAddr == "" {
+		opts = append(opts, client.WithToken(a.cfg.Token))
+		if o.realIP != "" {
+			opts = append(opts, client.WithHeader(realIPHeader, o.realIP))
+		}
+		if o.gzip {
+			opts = append(opts, client.WithGzip())
+		}
+
+		transport, err := client.NewHTTPTransport(o.addr, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to init http transport: %w", err)
+		}
+
+		a.http = transport
+		a.client = client.New(transport, opts...)
+
+		return func() {}, nil
+	}
+
+	userID, generated, err := a.cfg.grpcUserID()
+	if err != nil {
+		return nil, err
+	}
+	a.dirty = generated
+	opts = append(opts, client.WithUserID(userID))
+
+	creds, err := grpcCredentials(o)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := grpc.NewClient(o.grpcAddr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to init grpc client: %w", err)
+	}
+
+	a.client = client.New(client.NewGRPCTransport(conn, opts...), opts...)
+
+	return func() { _ = conn.Close() }, nil
+}
+
+// grpcCredentials возвращает TLS, если задан CA или клиентский сертификат, иначе соединение без шифрования.
+// Без -grpc-ca сертификат сервера проверяется по системным CA.
+func grpcCredentials(o *options) (credentials.TransportCredentials, error) {
+	if o.grpcCA == "" && o.grpcCert == "" && o.grpcKey == "" {
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if o.grpcCA != "" {
+		caPEM, err := os.ReadFile(o.grpcCA)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load grpc ca: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("%w: %s", errInvalidCA, o.grpcCA)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if o.grpcCert != "" || o.grpcKey != "" {
+		cert, err := tls.LoadX509KeyPair(o.grpcCert, o.grpcKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load grpc client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+func envOr(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+
+	return fallback
+}
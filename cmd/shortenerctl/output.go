@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Форматы вывода.
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+const tabPadding = 2
+
+var errUnknownFormat = errors.New("unknown output format")
+
+// table табличное представление результата команды для форматов table и csv.
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+// checkFormat проверяет, что формат вывода поддерживается.
+func checkFormat(format string) error {
+	switch format {
+	case formatTable, formatJSON, formatCSV:
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", errUnknownFormat, format)
+	}
+}
+
+// writeOutput выводит результат: value в формате JSON, t - в виде таблицы или CSV.
+func writeOutput(w io.Writer, format string, value any, t table) error {
+	switch format {
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(value); err != nil {
+			return fmt.Errorf("failed to write json: %w", err)
+		}
+	case formatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(t.header); err != nil {
+			return fmt.Errorf("failed to write csv: %w", err)
+		}
+		if err := cw.WriteAll(t.rows); err != nil {
+			return fmt.Errorf("failed to write csv: %w", err)
+		}
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, tabPadding, ' ', 0)
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(t.header, "\t")))
+		for _, row := range t.rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		if err := tw.Flush(); err != nil {
+			return fmt.Errorf("failed to write table: %w", err)
+		}
+	}
+
+	return nil
+}
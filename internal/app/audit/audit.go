@@ -0,0 +1,169 @@
+// Пакет audit предназначен для журнала аудита: кто, когда и откуда создал, удалил или запросил ссылки.
+package audit
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/clientip"
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+	"github.com/MihailSergeenkov/shortener/internal/app/requestid"
+)
+
+// Транспорты, через которые выполнено действие.
+const (
+	TransportHTTP   = "http"   // HTTP сервер, в том числе REST шлюз gRPC методов
+	TransportGRPC   = "grpc"   // gRPC сервер
+	TransportSystem = "system" // фоновые задачи сервиса
+)
+
+const (
+	bufferSize     = 1024
+	batchSize      = 100
+	flushPeriod    = time.Second
+	retentionCheck = time.Hour
+	storeTimeout   = 5 * time.Second
+)
+
+// NewTransportContext возвращает контекст с транспортом запроса.
+func NewTransportContext(ctx context.Context, transport string) context.Context {
+	return context.WithValue(ctx, common.KeyTransport, transport)
+}
+
+// TransportFromContext получает транспорт запроса из контекста.
+func TransportFromContext(ctx context.Context) string {
+	transport, _ := ctx.Value(common.KeyTransport).(string)
+	return transport
+}
+
+// Recorder записывает события аудита в хранилище пачками, не задерживая обработку запросов.
+type Recorder struct {
+	logger    *zap.Logger
+	store     data.Storager
+	events    chan models.AuditEvent
+	retention time.Duration
+}
+
+// NewRecorder создает журнал аудита поверх хранилища s.
+// События старше retention удаляются, при нулевом retention события хранятся бессрочно.
+func NewRecorder(l *zap.Logger, s data.Storager, retention time.Duration) *Recorder {
+	return &Recorder{
+		logger:    l,
+		store:     s,
+		events:    make(chan models.AuditEvent, bufferSize),
+		retention: retention,
+	}
+}
+
+// Record дополняет события данными запроса из ctx и ставит их в очередь на запись.
+// Если очередь переполнена, событие отбрасывается с предупреждением в журнале.
+func (r *Recorder) Record(ctx context.Context, events ...models.AuditEvent) {
+	now := time.Now().UTC()
+	userID, _ := ctx.Value(common.KeyUserID).(string)
+	transport := TransportFromContext(ctx)
+	if transport == "" {
+		transport = TransportSystem
+	}
+
+	for _, event := range events {
+		event.Time = now
+		event.UserID = userID
+		event.Transport = transport
+		event.ClientIP = clientip.String(ctx)
+		event.RequestID = requestid.FromContext(ctx)
+
+		select {
+		case r.events <- event:
+		default:
+			r.logger.Warn("audit queue is full, event dropped",
+				zap.String("action", event.Action),
+				zap.String("short_url", event.ShortURL),
+				zap.String("user_id", event.UserID),
+			)
+		}
+	}
+}
+
+// Run записывает события из очереди до отмены ctx и удаляет устаревшие события.
+// После отмены ctx оставшиеся в очереди события записываются перед выходом.
+func (r *Recorder) Run(ctx context.Context) {
+	flushTicker := time.NewTicker(flushPeriod)
+	defer flushTicker.Stop()
+
+	retentionTicker := time.NewTicker(retentionCheck)
+	defer retentionTicker.Stop()
+
+	r.dropExpired()
+
+	batch := make([]models.AuditEvent, 0, batchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			batch = r.drain(batch)
+			r.flush(batch)
+			r.logger.Info("audit recorder stopped", zap.Error(ctx.Err()))
+			return
+		case event := <-r.events:
+			batch = append(batch, event)
+			if len(batch) >= batchSize {
+				batch = r.flush(batch)
+			}
+		case <-flushTicker.C:
+			batch = r.flush(batch)
+		case <-retentionTicker.C:
+			r.dropExpired()
+		}
+	}
+}
+
+// drain забирает из очереди все накопленные события.
+func (r *Recorder) drain(batch []models.AuditEvent) []models.AuditEvent {
+	for {
+		select {
+		case event := <-r.events:
+			batch = append(batch, event)
+		default:
+			return batch
+		}
+	}
+}
+
+// flush записывает пачку событий и возвращает пустую пачку для переиспользования.
+// Запись выполняется с отдельным таймаутом, чтобы события сохранялись и при остановке сервиса.
+func (r *Recorder) flush(batch []models.AuditEvent) []models.AuditEvent {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	if err := r.store.StoreAuditEvents(ctx, batch); err != nil {
+		r.logger.Error("failed to store audit events", zap.Int("count", len(batch)), zap.Error(err))
+	}
+
+	return batch[:0]
+}
+
+// dropExpired удаляет события старше срока хранения.
+func (r *Recorder) dropExpired() {
+	if r.retention <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	count, err := r.store.DropAuditEvents(ctx, time.Now().Add(-r.retention))
+	if err != nil {
+		r.logger.Error("failed to drop expired audit events", zap.Error(err))
+		return
+	}
+	if count > 0 {
+		r.logger.Info("expired audit events dropped", zap.Int("count", count))
+	}
+}
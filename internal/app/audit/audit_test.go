@@ -0,0 +1,131 @@
+package audit
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/clientip"
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+	"github.com/MihailSergeenkov/shortener/internal/app/requestid"
+)
+
+func requestContext() context.Context {
+	ctx := context.WithValue(context.Background(), common.KeyUserID, "user_1")
+	ctx = clientip.NewContext(ctx, net.ParseIP("10.0.0.1"))
+	ctx = requestid.NewContext(ctx, "req-1")
+
+	return NewTransportContext(ctx, TransportGRPC)
+}
+
+// runRecorder запускает Run и возвращает функцию остановки, которая ждет записи оставшихся событий.
+func runRecorder(t *testing.T, r *Recorder) func() {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.Run(ctx)
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	t.Run("events enriched from context and flushed on stop", func(t *testing.T) {
+		store := data.NewBaseStorage()
+		r := NewRecorder(zap.NewNop(), store, 0)
+		stop := runRecorder(t, r)
+
+		r.Record(requestContext(), models.AuditEvent{Action: models.AuditCreate, ShortURL: "a"})
+		r.Record(context.Background(), models.AuditEvent{Action: models.AuditPurge})
+		stop()
+
+		events, err := store.FetchAuditEvents(context.Background(), models.AuditFilter{})
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+
+		assert.Equal(t, models.AuditPurge, events[0].Action)
+		assert.Equal(t, TransportSystem, events[0].Transport)
+		assert.Empty(t, events[0].UserID)
+
+		assert.Equal(t, "user_1", events[1].UserID)
+		assert.Equal(t, TransportGRPC, events[1].Transport)
+		assert.Equal(t, "10.0.0.1", events[1].ClientIP)
+		assert.Equal(t, "req-1", events[1].RequestID)
+		assert.Equal(t, "a", events[1].ShortURL)
+		assert.False(t, events[1].Time.IsZero())
+	})
+
+	t.Run("full queue drops events", func(t *testing.T) {
+		store := data.NewBaseStorage()
+		r := NewRecorder(zap.NewNop(), store, 0)
+
+		for range bufferSize + 1 {
+			r.Record(context.Background(), models.AuditEvent{Action: models.AuditStats})
+		}
+		runRecorder(t, r)()
+
+		events, err := store.FetchAuditEvents(context.Background(), models.AuditFilter{})
+		require.NoError(t, err)
+		assert.Len(t, events, bufferSize)
+	})
+
+	t.Run("expired events dropped on start", func(t *testing.T) {
+		store := data.NewBaseStorage()
+		old := models.AuditEvent{Time: time.Now().Add(-48 * time.Hour), Action: models.AuditCreate}
+		fresh := models.AuditEvent{Time: time.Now(), Action: models.AuditDelete}
+		require.NoError(t, store.StoreAuditEvents(context.Background(), []models.AuditEvent{old, fresh}))
+
+		runRecorder(t, NewRecorder(zap.NewNop(), store, 24*time.Hour))()
+
+		events, err := store.FetchAuditEvents(context.Background(), models.AuditFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, []models.AuditEvent{fresh}, events)
+	})
+}
+
+func TestAuditStorage(t *testing.T) {
+	ctx := requestContext()
+	store := data.NewBaseStorage()
+	r := NewRecorder(zap.NewNop(), store, 0)
+	s := AuditStorage(store, r)
+
+	require.NoError(t, s.StoreShortURL(ctx, "a", "https://ya.ru/a"))
+	require.Error(t, s.StoreShortURL(ctx, "a", "https://ya.ru/a"))
+	require.NoError(t, s.StoreShortURLs(ctx, []models.URL{
+		{ShortURL: "b", OriginalURL: "https://ya.ru/b", UserID: "user_1"},
+		{ShortURL: "c", OriginalURL: "https://ya.ru/c", UserID: "user_1"},
+	}))
+	require.NoError(t, s.DeleteShortURLs(ctx, []string{"b"}))
+	require.NoError(t, s.DropDeletedURLs(context.Background()))
+	_, _, err := s.FetchStats(ctx)
+	require.NoError(t, err)
+	_, _, err = s.FetchStats(context.Background())
+	require.NoError(t, err)
+
+	runRecorder(t, r)()
+
+	events, err := store.FetchAuditEvents(context.Background(), models.AuditFilter{})
+	require.NoError(t, err)
+
+	actions := make([]string, 0, len(events))
+	for _, e := range events {
+		actions = append(actions, e.Action+":"+e.ShortURL)
+	}
+	assert.Equal(t, []string{
+		"stats:", "purge:", "delete:b", "batch_create:c", "batch_create:b", "create:a",
+	}, actions)
+	assert.Equal(t, "https://ya.ru/a", events[len(events)-1].OriginalURL)
+}
@@ -0,0 +1,95 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+)
+
+// auditedStorage декоратор хранилища, записывающий в журнал аудита успешные изменения ссылок и запросы статистики.
+// Остальные методы Storager передаются обернутому хранилищу без изменений.
+type auditedStorage struct {
+	data.Storager
+	recorder *Recorder
+}
+
+// AuditStorage оборачивает хранилище s записью событий в журнал аудита r.
+func AuditStorage(s data.Storager, r *Recorder) data.Storager {
+	return &auditedStorage{Storager: s, recorder: r}
+}
+
+// StoreShortURL переопределение оригинального метода.
+func (s *auditedStorage) StoreShortURL(ctx context.Context, shortURL string, originalURL string) error {
+	if err := s.Storager.StoreShortURL(ctx, shortURL, originalURL); err != nil {
+		return err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+	}
+
+	s.recorder.Record(ctx, models.AuditEvent{
+		Action:      models.AuditCreate,
+		ShortURL:    shortURL,
+		OriginalURL: originalURL,
+	})
+
+	return nil
+}
+
+// StoreShortURLs переопределение оригинального метода, каждая ссылка пачки записывается отдельным событием.
+func (s *auditedStorage) StoreShortURLs(ctx context.Context, urls []models.URL) error {
+	if err := s.Storager.StoreShortURLs(ctx, urls); err != nil {
+		return err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+	}
+
+	events := make([]models.AuditEvent, 0, len(urls))
+	for _, u := range urls {
+		events = append(events, models.AuditEvent{
+			Action:      models.AuditBatchCreate,
+			ShortURL:    u.ShortURL,
+			OriginalURL: u.OriginalURL,
+		})
+	}
+	s.recorder.Record(ctx, events...)
+
+	return nil
+}
+
+// DeleteShortURLs переопределение оригинального метода, каждая ссылка записывается отдельным событием.
+func (s *auditedStorage) DeleteShortURLs(ctx context.Context, urls []string) error {
+	if err := s.Storager.DeleteShortURLs(ctx, urls); err != nil {
+		return err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+	}
+
+	events := make([]models.AuditEvent, 0, len(urls))
+	for _, u := range urls {
+		events = append(events, models.AuditEvent{Action: models.AuditDelete, ShortURL: u})
+	}
+	s.recorder.Record(ctx, events...)
+
+	return nil
+}
+
+// DropDeletedURLs переопределение оригинального метода.
+func (s *auditedStorage) DropDeletedURLs(ctx context.Context) error {
+	if err := s.Storager.DropDeletedURLs(ctx); err != nil {
+		return err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+	}
+
+	s.recorder.Record(ctx, models.AuditEvent{Action: models.AuditPurge})
+
+	return nil
+}
+
+// FetchStats переопределение оригинального метода. Записываются только запросы клиентов,
+// сбор метрик и другие внутренние вызовы без транспорта в контексте в журнал не попадают.
+func (s *auditedStorage) FetchStats(ctx context.Context) (int, int, error) {
+	urls, users, err := s.Storager.FetchStats(ctx)
+	if err != nil {
+		return 0, 0, err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+	}
+
+	if TransportFromContext(ctx) != "" {
+		s.recorder.Record(ctx, models.AuditEvent{Action: models.AuditStats})
+	}
+
+	return urls, users, nil
+}
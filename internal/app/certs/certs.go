@@ -0,0 +1,261 @@
+// Пакет certs предназначен для загрузки TLS сертификатов с перезагрузкой без перезапуска сервиса.
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	serialBits    = 62
+	selfSignedTTL = 365 * 24 * time.Hour
+)
+
+// ErrNoCACerts ошибка, когда в файле CA не найдено ни одного сертификата.
+var ErrNoCACerts = errors.New("no certificates found in CA bundle")
+
+// Reloader структура для хранения сертификата сервера и CA клиентских сертификатов.
+// Файлы перечитываются при изменении, при ошибке чтения продолжает использоваться предыдущий сертификат.
+type Reloader struct {
+	logger    *zap.Logger
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+	certFile  string
+	keyFile   string
+	caFile    string
+	mu        sync.RWMutex
+}
+
+// NewReloader инициализирует Reloader и загружает сертификаты.
+// Если caFile не пустой, сервер требует и проверяет клиентские сертификаты (mTLS).
+func NewReloader(l *zap.Logger, certFile string, keyFile string, caFile string) (*Reloader, error) {
+	r := &Reloader{
+		logger:   l,
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+		modTimes: make(map[string]time.Time),
+	}
+
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Reload перечитывает сертификаты с диска.
+func (r *Reloader) Reload() error {
+	modTimes := r.fileModTimes()
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load key pair: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.caFile != "" {
+		clientCAs, err = loadCertPool(r.caFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+
+	return nil
+}
+
+// GetCertificate возвращает текущий сертификат сервера.
+func (r *Reloader) GetCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert, nil
+}
+
+// ClientCAs возвращает текущий набор CA для проверки клиентских сертификатов.
+func (r *Reloader) ClientCAs() *x509.CertPool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.clientCAs
+}
+
+// ServerConfig возвращает конфигурацию TLS сервера, которая всегда использует актуальные сертификаты.
+func (r *Reloader) ServerConfig() *tls.Config {
+	cfg := &tls.Config{
+		GetCertificate: r.GetCertificate,
+		MinVersion:     tls.VersionTLS13,
+	}
+
+	if r.caFile == "" {
+		return cfg
+	}
+
+	cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	cfg.GetConfigForClient = func(_ *tls.ClientHelloInfo) (*tls.Config, error) {
+		clientCfg := cfg.Clone()
+		clientCfg.GetConfigForClient = nil
+		clientCfg.ClientCAs = r.ClientCAs()
+
+		return clientCfg, nil
+	}
+
+	return cfg
+}
+
+// Watch проверяет изменение файлов сертификатов с заданным интервалом и перечитывает их.
+// Нулевой интервал отключает проверку.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+
+			if err := r.Reload(); err != nil {
+				r.logger.Error("failed to reload certificates", zap.Error(err))
+				continue
+			}
+
+			r.logger.Info("certificates reloaded", zap.String("cert", r.certFile))
+		}
+	}
+}
+
+// ReloadOn перечитывает сертификаты при получении сигнала из trigger, например SIGHUP.
+func (r *Reloader) ReloadOn(ctx context.Context, trigger <-chan os.Signal) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-trigger:
+			if err := r.Reload(); err != nil {
+				r.logger.Error("failed to reload certificates", zap.Error(err))
+				continue
+			}
+
+			r.logger.Info("certificates reloaded", zap.String("cert", r.certFile))
+		}
+	}
+}
+
+// SelfSigned выпускает самоподписанный сертификат для указанных хостов и IP адресов (режим разработки).
+func SelfSigned(hosts ...string) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), serialBits))
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{Organization: []string{"shortener self-signed"}},
+		NotBefore:    now.Add(-time.Minute),
+		NotAfter:     now.Add(selfSignedTTL),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else if host != "" {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to create certificate: %w", err)
+	}
+
+	return tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+	}, nil
+}
+
+func (r *Reloader) changed() bool {
+	modTimes := r.fileModTimes()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for file, modTime := range modTimes {
+		if !modTime.Equal(r.modTimes[file]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Reloader) fileModTimes() map[string]time.Time {
+	modTimes := make(map[string]time.Time, 3)
+
+	for _, file := range []string{r.certFile, r.keyFile, r.caFile} {
+		if file == "" {
+			continue
+		}
+
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+
+		modTimes[file] = info.ModTime()
+	}
+
+	return modTimes
+}
+
+func loadCertPool(caFile string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, ErrNoCACerts
+	}
+
+	return pool, nil
+}
@@ -0,0 +1,248 @@
+package certs
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/certs/certstest"
+)
+
+type testPKI struct {
+	ca       *certstest.CA
+	dir      string
+	certFile string
+	keyFile  string
+	caFile   string
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+
+	ca, err := certstest.NewCA("test CA")
+	require.NoError(t, err)
+
+	p := &testPKI{ca: ca, dir: t.TempDir()}
+	p.caFile = filepath.Join(p.dir, "ca.crt")
+	require.NoError(t, os.WriteFile(p.caFile, ca.PEM, 0o600))
+
+	p.rotate(t)
+
+	return p
+}
+
+// rotate выпускает новый сертификат сервера поверх старых файлов.
+func (p *testPKI) rotate(t *testing.T) []byte {
+	t.Helper()
+
+	pair, err := p.ca.IssueServer("server", "127.0.0.1", "localhost")
+	require.NoError(t, err)
+
+	p.certFile, p.keyFile, err = pair.WriteFiles(p.dir, "server")
+	require.NoError(t, err)
+
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(p.certFile, future, future))
+
+	return pair.CertPEM
+}
+
+func leaf(t *testing.T, r *Reloader) []byte {
+	t.Helper()
+
+	cert, err := r.GetCertificate(nil)
+	require.NoError(t, err)
+
+	return cert.Certificate[0]
+}
+
+func TestNewReloader(t *testing.T) {
+	p := newTestPKI(t)
+	logger := zap.NewNop()
+
+	t.Run("success load", func(t *testing.T) {
+		r, err := NewReloader(logger, p.certFile, p.keyFile, p.caFile)
+		require.NoError(t, err)
+		assert.NotNil(t, r.ClientCAs())
+		assert.NotEmpty(t, leaf(t, r))
+	})
+
+	t.Run("missing key pair", func(t *testing.T) {
+		_, err := NewReloader(logger, filepath.Join(p.dir, "missing.crt"), p.keyFile, "")
+		require.ErrorContains(t, err, "failed to load key pair")
+	})
+
+	t.Run("invalid CA bundle", func(t *testing.T) {
+		_, err := NewReloader(logger, p.certFile, p.keyFile, p.keyFile)
+		require.ErrorIs(t, err, ErrNoCACerts)
+	})
+}
+
+func TestWatch(t *testing.T) {
+	p := newTestPKI(t)
+
+	r, err := NewReloader(zap.NewNop(), p.certFile, p.keyFile, "")
+	require.NoError(t, err)
+	before := leaf(t, r)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Watch(ctx, 10*time.Millisecond)
+
+	t.Run("broken files keep previous certificate", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(p.keyFile, []byte("broken"), 0o600))
+		future := time.Now().Add(2 * time.Minute)
+		require.NoError(t, os.Chtimes(p.keyFile, future, future))
+
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, before, leaf(t, r))
+	})
+
+	t.Run("rotated files are reloaded", func(t *testing.T) {
+		p.rotate(t)
+		future := time.Now().Add(3 * time.Minute)
+		require.NoError(t, os.Chtimes(p.certFile, future, future))
+
+		assert.Eventually(t, func() bool {
+			return !bytes.Equal(before, leaf(t, r))
+		}, time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestReloadOn(t *testing.T) {
+	p := newTestPKI(t)
+
+	r, err := NewReloader(zap.NewNop(), p.certFile, p.keyFile, "")
+	require.NoError(t, err)
+	before := leaf(t, r)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	trigger := make(chan os.Signal)
+	go r.ReloadOn(ctx, trigger)
+
+	t.Run("files are not reloaded without signal", func(t *testing.T) {
+		p.rotate(t)
+		assert.Equal(t, before, leaf(t, r))
+	})
+
+	t.Run("files are reloaded on signal", func(t *testing.T) {
+		trigger <- syscall.SIGHUP
+		trigger <- syscall.SIGHUP // дожидаемся обработки первого сигнала
+
+		assert.NotEqual(t, before, leaf(t, r))
+	})
+}
+
+func TestSelfSigned(t *testing.T) {
+	cert, err := SelfSigned("localhost", "127.0.0.1", "")
+	require.NoError(t, err)
+
+	parsed, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"localhost"}, parsed.DNSNames)
+	assert.Len(t, parsed.IPAddresses, 1)
+	assert.NoError(t, parsed.CheckSignature(parsed.SignatureAlgorithm, parsed.RawTBSCertificate, parsed.Signature))
+	assert.NoError(t, parsed.VerifyHostname("127.0.0.1"))
+	assert.True(t, parsed.NotAfter.After(time.Now().Add(24*time.Hour)))
+}
+
+func TestServerConfig(t *testing.T) {
+	p := newTestPKI(t)
+
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(p.ca.PEM))
+
+	clientPair, err := p.ca.IssueClient("client")
+	require.NoError(t, err)
+	clientCert, err := tls.X509KeyPair(clientPair.CertPEM, clientPair.KeyPEM)
+	require.NoError(t, err)
+
+	handshake := func(t *testing.T, serverCfg *tls.Config, clientCfg *tls.Config) error {
+		t.Helper()
+
+		serverConn, clientConn := net.Pipe()
+		defer func() { _ = serverConn.Close() }()
+		defer func() { _ = clientConn.Close() }()
+
+		errCh := make(chan error, 1)
+		go func() {
+			server := tls.Server(serverConn, serverCfg)
+			errCh <- server.Handshake()
+			_ = server.Close()
+		}()
+
+		client := tls.Client(clientConn, clientCfg)
+		if err := client.Handshake(); err != nil {
+			return err //nolint:wrapcheck // Тестовый хелпер
+		}
+
+		// В TLS 1.3 сервер проверяет клиентский сертификат после завершения рукопожатия на стороне клиента.
+		if _, err := client.Read(make([]byte, 1)); err != nil && !isEOF(err) {
+			return err //nolint:wrapcheck // Тестовый хелпер
+		}
+
+		return <-errCh
+	}
+
+	t.Run("TLS without client certificate", func(t *testing.T) {
+		r, err := NewReloader(zap.NewNop(), p.certFile, p.keyFile, "")
+		require.NoError(t, err)
+
+		err = handshake(t, r.ServerConfig(), &tls.Config{RootCAs: roots, ServerName: "localhost", MinVersion: tls.VersionTLS13})
+		require.NoError(t, err)
+	})
+
+	r, err := NewReloader(zap.NewNop(), p.certFile, p.keyFile, p.caFile)
+	require.NoError(t, err)
+
+	t.Run("mTLS with client certificate", func(t *testing.T) {
+		err := handshake(t, r.ServerConfig(), &tls.Config{
+			RootCAs:      roots,
+			ServerName:   "localhost",
+			Certificates: []tls.Certificate{clientCert},
+			MinVersion:   tls.VersionTLS13,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("mTLS without client certificate", func(t *testing.T) {
+		err := handshake(t, r.ServerConfig(), &tls.Config{RootCAs: roots, ServerName: "localhost", MinVersion: tls.VersionTLS13})
+		require.Error(t, err)
+	})
+
+	t.Run("mTLS with foreign client certificate", func(t *testing.T) {
+		foreignCA, err := certstest.NewCA("foreign CA")
+		require.NoError(t, err)
+		foreignPair, err := foreignCA.IssueClient("client")
+		require.NoError(t, err)
+		foreignCert, err := tls.X509KeyPair(foreignPair.CertPEM, foreignPair.KeyPEM)
+		require.NoError(t, err)
+
+		err = handshake(t, r.ServerConfig(), &tls.Config{
+			RootCAs:      roots,
+			ServerName:   "localhost",
+			Certificates: []tls.Certificate{foreignCert},
+			MinVersion:   tls.VersionTLS13,
+		})
+		require.Error(t, err)
+	})
+}
+
+func isEOF(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrClosedPipe)
+}
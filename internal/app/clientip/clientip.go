@@ -0,0 +1,177 @@
+// Пакет clientip предназначен для определения IP адреса клиента с учетом доверенных прокси.
+package clientip
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+)
+
+// Заголовки, в которых прокси передают адрес клиента.
+const (
+	HeaderXForwardedFor = "X-Forwarded-For"
+	HeaderXRealIP       = "X-Real-IP"
+	HeaderForwarded     = "Forwarded"
+)
+
+// Resolver структура для определения IP адреса клиента.
+type Resolver struct {
+	trustedProxies []*net.IPNet
+}
+
+// NewResolver инициализирует Resolver со списком доверенных прокси.
+func NewResolver(trustedProxies []*net.IPNet) *Resolver {
+	return &Resolver{
+		trustedProxies: trustedProxies,
+	}
+}
+
+// FromRequest определяет IP адрес клиента для HTTP запроса.
+// Заголовки учитываются только если запрос пришел от доверенного прокси.
+func (r *Resolver) FromRequest(req *http.Request) net.IP {
+	return r.resolve(parseAddr(req.RemoteAddr), req.Header.Values)
+}
+
+// FromPeer определяет IP адрес клиента для gRPC запроса.
+// Метаданные учитываются только если запрос пришел от доверенного прокси.
+func (r *Resolver) FromPeer(ctx context.Context) net.IP {
+	var peerIP net.IP
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		peerIP = parseAddr(p.Addr.String())
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	return r.resolve(peerIP, func(key string) []string {
+		return md.Get(key)
+	})
+}
+
+// IsTrusted проверяет, входит ли адрес в список доверенных прокси.
+func (r *Resolver) IsTrusted(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+
+	for _, subnet := range r.trustedProxies {
+		if subnet != nil && subnet.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Resolver) resolve(peerIP net.IP, values func(key string) []string) net.IP {
+	if !r.IsTrusted(peerIP) {
+		return peerIP
+	}
+
+	if ip := r.fromChain(parseList(values(HeaderXForwardedFor))); ip != nil {
+		return ip
+	}
+
+	if realIP := values(HeaderXRealIP); len(realIP) > 0 {
+		if ip := net.ParseIP(strings.TrimSpace(realIP[0])); ip != nil {
+			return ip
+		}
+	}
+
+	if ip := r.fromChain(parseForwarded(values(HeaderForwarded))); ip != nil {
+		return ip
+	}
+
+	return peerIP
+}
+
+// fromChain обходит цепочку адресов справа налево и возвращает первый недоверенный адрес.
+func (r *Resolver) fromChain(chain []net.IP) net.IP {
+	if len(chain) == 0 {
+		return nil
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		if !r.IsTrusted(chain[i]) {
+			return chain[i]
+		}
+	}
+
+	return chain[0]
+}
+
+// NewContext возвращает контекст с IP адресом клиента.
+func NewContext(ctx context.Context, ip net.IP) context.Context {
+	return context.WithValue(ctx, common.KeyClientIP, ip)
+}
+
+// FromContext получает IP адрес клиента из контекста.
+func FromContext(ctx context.Context) net.IP {
+	ip, _ := ctx.Value(common.KeyClientIP).(net.IP)
+	return ip
+}
+
+// String возвращает строковое представление IP адреса клиента из контекста.
+func String(ctx context.Context) string {
+	ip := FromContext(ctx)
+	if ip == nil {
+		return ""
+	}
+
+	return ip.String()
+}
+
+func parseList(values []string) []net.IP {
+	chain := make([]net.IP, 0, len(values))
+
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			ip := parseAddr(part)
+			if ip == nil {
+				return nil
+			}
+			chain = append(chain, ip)
+		}
+	}
+
+	return chain
+}
+
+func parseForwarded(values []string) []net.IP {
+	chain := make([]net.IP, 0, len(values))
+
+	for _, v := range values {
+		for _, element := range strings.Split(v, ",") {
+			for _, pair := range strings.Split(element, ";") {
+				key, value, found := strings.Cut(strings.TrimSpace(pair), "=")
+				if !found || !strings.EqualFold(key, "for") {
+					continue
+				}
+
+				ip := parseAddr(strings.Trim(value, `"`))
+				if ip == nil {
+					return nil
+				}
+				chain = append(chain, ip)
+			}
+		}
+	}
+
+	return chain
+}
+
+// parseAddr разбирает адрес вида "ip", "ip:port", "[ipv6]" или "[ipv6]:port".
+func parseAddr(addr string) net.IP {
+	addr = strings.TrimSpace(addr)
+
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+
+	return net.ParseIP(strings.Trim(addr, "[]"))
+}
@@ -0,0 +1,143 @@
+package clientip
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func mustParseCIDR(t *testing.T, v string) *net.IPNet {
+	t.Helper()
+
+	_, subnet, err := net.ParseCIDR(v)
+	require.NoError(t, err)
+
+	return subnet
+}
+
+func TestFromRequest(t *testing.T) {
+	resolver := NewResolver([]*net.IPNet{
+		mustParseCIDR(t, "10.0.0.0/8"),
+		mustParseCIDR(t, "192.0.2.0/24"),
+	})
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{
+			name:       "untrusted peer ignores headers",
+			remoteAddr: "203.0.113.1:1234",
+			headers:    map[string]string{HeaderXRealIP: "192.168.1.33"},
+			want:       "203.0.113.1",
+		},
+		{
+			name:       "trusted peer without headers",
+			remoteAddr: "192.0.2.1:1234",
+			headers:    map[string]string{},
+			want:       "192.0.2.1",
+		},
+		{
+			name:       "x-forwarded-for chain skips trusted proxies",
+			remoteAddr: "192.0.2.1:1234",
+			headers:    map[string]string{HeaderXForwardedFor: "1.1.1.1, 198.51.100.5, 10.0.0.3"},
+			want:       "198.51.100.5",
+		},
+		{
+			name:       "x-forwarded-for with only trusted proxies",
+			remoteAddr: "192.0.2.1:1234",
+			headers:    map[string]string{HeaderXForwardedFor: "10.0.0.2, 10.0.0.3"},
+			want:       "10.0.0.2",
+		},
+		{
+			name:       "bad x-forwarded-for falls back to x-real-ip",
+			remoteAddr: "192.0.2.1:1234",
+			headers: map[string]string{
+				HeaderXForwardedFor: "some string",
+				HeaderXRealIP:       "198.51.100.6",
+			},
+			want: "198.51.100.6",
+		},
+		{
+			name:       "forwarded header",
+			remoteAddr: "192.0.2.1:1234",
+			headers:    map[string]string{HeaderForwarded: `for="[2001:db8::1]:4711";proto=https, for=10.0.0.4`},
+			want:       "2001:db8::1",
+		},
+		{
+			name:       "bad headers fall back to peer",
+			remoteAddr: "192.0.2.1:1234",
+			headers:    map[string]string{HeaderXRealIP: "some string"},
+			want:       "192.0.2.1",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			request.RemoteAddr = test.remoteAddr
+			for k, v := range test.headers {
+				request.Header.Add(k, v)
+			}
+
+			ip := resolver.FromRequest(request)
+			require.NotNil(t, ip)
+			assert.Equal(t, test.want, ip.String())
+		})
+	}
+}
+
+func TestFromPeer(t *testing.T) {
+	resolver := NewResolver([]*net.IPNet{mustParseCIDR(t, "127.0.0.0/8")})
+
+	tests := []struct {
+		name     string
+		peerAddr net.Addr
+		metadata map[string]string
+		want     string
+	}{
+		{
+			name:     "trusted peer with metadata",
+			peerAddr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 3200},
+			metadata: map[string]string{"x-forwarded-for": "198.51.100.5"},
+			want:     "198.51.100.5",
+		},
+		{
+			name:     "untrusted peer with metadata",
+			peerAddr: &net.TCPAddr{IP: net.ParseIP("203.0.113.1"), Port: 3200},
+			metadata: map[string]string{"x-real-ip": "198.51.100.5"},
+			want:     "203.0.113.1",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: test.peerAddr})
+			ctx = metadata.NewIncomingContext(ctx, metadata.New(test.metadata))
+
+			ip := resolver.FromPeer(ctx)
+			require.NotNil(t, ip)
+			assert.Equal(t, test.want, ip.String())
+		})
+	}
+
+	t.Run("without peer", func(t *testing.T) {
+		assert.Nil(t, resolver.FromPeer(context.Background()))
+	})
+}
+
+func TestContext(t *testing.T) {
+	t.Run("store and fetch ip", func(t *testing.T) {
+		ctx := NewContext(context.Background(), net.ParseIP("198.51.100.5"))
+
+		assert.Equal(t, "198.51.100.5", String(ctx))
+		assert.Equal(t, "", String(context.Background()))
+	})
+}
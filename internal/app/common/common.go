@@ -6,8 +6,15 @@ import "errors"
 // ContextValueKey тип ключа контекста.
 type ContextValueKey int
 
-// KeyUserID ключ ID пользователя для контекста.
-const KeyUserID ContextValueKey = iota
+// Ключи контекста.
+const (
+	KeyUserID     ContextValueKey = iota // ID пользователя
+	KeyClientIP                          // IP адрес клиента
+	KeyOrgID                             // ID выбранной организации
+	KeyRequestID                         // ID запроса
+	KeyTransport                         // транспорт запроса для журнала аудита
+	KeyUnverified                        // ID пользователя не подтвержден ранее выданным токеном
+)
 
 // ErrFetchUserIDFromContext ошибка получеения ID пользователя из контекста.
 var ErrFetchUserIDFromContext = errors.New("failed to fetch user id from context")
@@ -26,3 +33,9 @@ var ContentTypeHeader = "Content-Type"
 
 // JSONContentType тип контента JSON.
 var JSONContentType = "application/json"
+
+// CSVContentType тип контента CSV.
+var CSVContentType = "text/csv"
+
+// NDJSONContentType тип контента JSON с объектом в каждой строке.
+var NDJSONContentType = "application/x-ndjson"
@@ -6,27 +6,149 @@ import (
 	"flag"
 	"fmt"
 	"net"
+	"net/http"
 	"net/url"
 	"os"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/caarlos0/env/v11"
 	"go.uber.org/zap/zapcore"
 )
 
+// Режимы получения сертификата HTTPS сервера.
+const (
+	TLSModeAutocert   = "autocert"    // сертификат Let's Encrypt для хостов из TLSHosts
+	TLSModeFiles      = "files"       // сертификат и ключ из файлов TLSCert и TLSKey
+	TLSModeSelfSigned = "self-signed" // самоподписанный сертификат, выпускается при старте (режим разработки)
+)
+
+// Экспортеры трассировки OpenTelemetry.
+const (
+	TraceExporterNone   = "none"   // трассировка отключена, контекст трассировки только передается дальше
+	TraceExporterOTLP   = "otlp"   // отправка спанов в OTLP коллектор по gRPC на OTLPEndpoint
+	TraceExporterStdout = "stdout" // вывод спанов в stdout в формате JSON
+	TraceExporterFile   = "file"   // запись спанов в файл TraceFile в формате JSON
+)
+
+// Форматы записей журнала.
+const (
+	LogFormatJSON    = "json"    // JSON, по одной записи в строке
+	LogFormatConsole = "console" // текст для чтения человеком
+)
+
 // Settings структура для конфигурирования сервиса.
 type Settings struct {
-	TrustedSubnet   *net.IPNet    `json:"trusted_subnet" env:"TRUSTED_SUBNET" envDefault:""`
-	BaseURL         url.URL       `json:"base_url" env:"BASE_URL" envDefault:"http://localhost:8080"`
-	RunAddr         string        `json:"server_address" env:"SERVER_ADDRESS" envDefault:"localhost:8080"`
-	RunGAddr        string        `json:"gserver_address" env:"GSERVER_ADDRESS" envDefault:"localhost:3200"`
+	TrustedSubnet  *net.IPNet   `json:"trusted_subnet" env:"TRUSTED_SUBNET" envDefault:""`
+	TrustedProxies []*net.IPNet `json:"trusted_proxies" env:"TRUSTED_PROXIES" envDefault:""`
+	BaseURL        url.URL      `json:"base_url" env:"BASE_URL" envDefault:"http://localhost:8080"`
+	RunAddr        string       `json:"server_address" env:"SERVER_ADDRESS" envDefault:"localhost:8080"`
+	RunGAddr       string       `json:"gserver_address" env:"GSERVER_ADDRESS" envDefault:"localhost:3200"`
+
+	// Адрес метрик, без него метрики отдаются по /metrics основного сервера из доверенной подсети.
+	MetricsAddr string `json:"metrics_address" env:"METRICS_ADDRESS" envDefault:""`
+
+	// Адрес административного сервера, только на нем доступен профилировщик pprof,
+	// снятые профили сохраняются в ProfilesDir.
+	AdminAddr   string `json:"admin_address" env:"ADMIN_ADDRESS" envDefault:""`
+	ProfilesDir string `json:"profiles_dir" env:"PROFILES_DIR" envDefault:"profiles"`
+
+	// Экспортер спанов трассировки и доля трассируемых запросов TraceRatio.
+	TraceExporter string  `json:"trace_exporter" env:"TRACE_EXPORTER" envDefault:"none"`
+	OTLPEndpoint  string  `json:"otlp_endpoint" env:"OTLP_ENDPOINT" envDefault:"localhost:4317"`
+	TraceFile     string  `json:"trace_file" env:"TRACE_FILE" envDefault:"/tmp/traces.json"`
+	TraceRatio    float64 `json:"trace_sample_ratio" env:"TRACE_SAMPLE_RATIO" envDefault:"1"`
+
 	FileStoragePath string        `json:"file_storage_path" env:"FILE_STORAGE_PATH" envDefault:"/tmp/url-db.json"`
 	DatabaseDSN     string        `json:"database_dsn" env:"DATABASE_DSN" envDefault:""`
 	SecretKey       string        `json:"secret_key" env:"SECRET_KEY" envDefault:"1234567890"`
 	DropURLsPeriod  time.Duration `json:"drop_urls_period" env:"DROP_URLS_PERIOD" envDefault:"1m"`
-	LogLevel        zapcore.Level `json:"log_level" env:"LOG_LEVEL" envDefault:"ERROR"`
-	EnableHTTPS     bool          `json:"enable_https" env:"ENABLE_HTTPS" envDefault:"false"`
+
+	// Срок хранения событий журнала аудита, нулевое значение отключает их удаление.
+	AuditRetention time.Duration `json:"audit_retention" env:"AUDIT_RETENTION" envDefault:"2160h"`
+
+	// Неудачная доставка вебхука повторяется через WebhookRetry с удвоением задержки,
+	// после WebhookAttempts попыток событие переносится в список недоставленных.
+	WebhookRetry    time.Duration `json:"webhook_retry_delay" env:"WEBHOOK_RETRY_DELAY" envDefault:"30s"`
+	WebhookAttempts int           `json:"webhook_max_attempts" env:"WEBHOOK_MAX_ATTEMPTS" envDefault:"8"`
+
+	// Адрес публикации изменений ссылок в postgres (nats://, file: или memory:) и период публикации,
+	// пустой адрес отключает публикацию.
+	OutboxURL    string        `json:"outbox_url" env:"OUTBOX_URL" envDefault:""`
+	OutboxPeriod time.Duration `json:"outbox_period" env:"OUTBOX_PERIOD" envDefault:"1s"`
+
+	// Поток событий пользователя возобновляется из буфера последних EventsBuffer событий,
+	// пока событий нет, каждые EventsHeartbeat в поток отправляется проверка соединения.
+	EventsBuffer    int           `json:"events_replay_buffer" env:"EVENTS_REPLAY_BUFFER" envDefault:"1024"`
+	EventsHeartbeat time.Duration `json:"events_heartbeat" env:"EVENTS_HEARTBEAT" envDefault:"15s"`
+
+	// Уровень журналирования, уровни компонентов LogLevels в виде http=debug,db=warn и формат записей.
+	LogLevel  zapcore.Level `json:"log_level" env:"LOG_LEVEL" envDefault:"ERROR"`
+	LogLevels []string      `json:"log_levels" env:"LOG_LEVELS" envDefault:""`
+	LogFormat string        `json:"log_format" env:"LOG_FORMAT" envDefault:"json"`
+
+	// Файл журнала с ротацией по размеру LogMaxSize (МБ) и возрасту LogMaxAge, без него журнал пишется в stderr.
+	LogFile       string        `json:"log_file" env:"LOG_FILE" envDefault:""`
+	LogMaxSize    int           `json:"log_max_size" env:"LOG_MAX_SIZE" envDefault:"100"`
+	LogMaxAge     time.Duration `json:"log_max_age" env:"LOG_MAX_AGE" envDefault:"168h"`
+	LogMaxBackups int           `json:"log_max_backups" env:"LOG_MAX_BACKUPS" envDefault:"5"`
+
+	// Выборка записей о переходах по ссылкам: в секунду пишутся первые LogSampleFirst одинаковых записей,
+	// далее каждая LogSampleEvery.
+	LogSampleFirst int `json:"log_sample_initial" env:"LOG_SAMPLE_INITIAL" envDefault:"100"`
+	LogSampleEvery int `json:"log_sample_thereafter" env:"LOG_SAMPLE_THEREAFTER" envDefault:"100"`
+
+	// Лимиты частоты запросов и дневная квота, нулевые значения отключают ограничения.
+	CreateRPS      float64 `json:"create_rate_limit" env:"CREATE_RATE_LIMIT" envDefault:"10"`
+	CreateBurst    int     `json:"create_rate_burst" env:"CREATE_RATE_BURST" envDefault:"50"`
+	RedirectRPS    float64 `json:"redirect_rate_limit" env:"REDIRECT_RATE_LIMIT" envDefault:"100"`
+	RedirectBurst  int     `json:"redirect_rate_burst" env:"REDIRECT_RATE_BURST" envDefault:"200"`
+	DailyURLsQuota int     `json:"daily_urls_quota" env:"DAILY_URLS_QUOTA" envDefault:"0"`
+
+	CookieDomain    string        `json:"cookie_domain" env:"COOKIE_DOMAIN" envDefault:""`
+	CookiePath      string        `json:"cookie_path" env:"COOKIE_PATH" envDefault:"/"`
+	CookieMaxAge    time.Duration `json:"cookie_max_age" env:"COOKIE_MAX_AGE" envDefault:"720h"`
+	CookieSameSite  http.SameSite `json:"cookie_same_site" env:"COOKIE_SAME_SITE" envDefault:"lax"`
+	OIDCIssuer      string        `json:"oidc_issuer" env:"OIDC_ISSUER" envDefault:""`
+	OIDCClientID    string        `json:"oidc_client_id" env:"OIDC_CLIENT_ID" envDefault:""`
+	OIDCSecret      string        `json:"oidc_client_secret" env:"OIDC_CLIENT_SECRET" envDefault:""`
+	OIDCRedirectURL string        `json:"oidc_redirect_url" env:"OIDC_REDIRECT_URL" envDefault:""`
+
+	// gRPC сервер работает по TLS, если заданы сертификат и ключ, и требует клиентский сертификат, если задан CA.
+	GRPCTLSCert  string `json:"grpc_tls_cert" env:"GRPC_TLS_CERT" envDefault:""`
+	GRPCTLSKey   string `json:"grpc_tls_key" env:"GRPC_TLS_KEY" envDefault:""`
+	GRPCClientCA string `json:"grpc_client_ca" env:"GRPC_CLIENT_CA" envDefault:""`
+
+	// Сертификат HTTPS выбирается по TLSMode, файлы сертификата перечитываются при изменении, раз в CertReload
+	// и по SIGHUP.
+	CertReload  time.Duration `json:"cert_reload_interval" env:"CERT_RELOAD_INTERVAL" envDefault:"1m"`
+	TLSMode     string        `json:"tls_mode" env:"TLS_MODE" envDefault:"autocert"`
+	TLSHosts    []string      `json:"tls_hosts" env:"TLS_HOSTS" envDefault:"mynetwork.keenetic.link"`
+	AutocertDir string        `json:"autocert_cache_dir" env:"AUTOCERT_CACHE_DIR" envDefault:"/tmp/certs"`
+	TLSCert     string        `json:"tls_cert" env:"TLS_CERT" envDefault:""`
+	TLSKey      string        `json:"tls_key" env:"TLS_KEY" envDefault:""`
+
+	// Задержка между переводом готовности в отказ и остановкой серверов при плавной остановке.
+	ShutdownDrainDelay time.Duration `json:"shutdown_drain_delay" env:"SHUTDOWN_DRAIN_DELAY" envDefault:"5s"`
+
+	HealthInterval time.Duration `json:"health_check_interval" env:"HEALTH_CHECK_INTERVAL" envDefault:"10s"`
+	GRPCReflection bool          `json:"grpc_reflection" env:"GRPC_REFLECTION" envDefault:"true"`
+	EnableHTTPS    bool          `json:"enable_https" env:"ENABLE_HTTPS" envDefault:"false"`
+	CookieSecure   bool          `json:"cookie_secure" env:"COOKIE_SECURE" envDefault:"false"`
+	CSRFProtection bool          `json:"csrf_protection" env:"CSRF_PROTECTION" envDefault:"false"`
+	OTLPInsecure   bool          `json:"otlp_insecure" env:"OTLP_INSECURE" envDefault:"false"`
+	LogQueryArgs   bool          `json:"log_query_args" env:"LOG_QUERY_ARGS" envDefault:"false"`
+}
+
+// SecureCookies проверяет, нужно ли выставлять cookie атрибут Secure (всегда включен в режиме HTTPS).
+func (s *Settings) SecureCookies() bool {
+	return s.CookieSecure || s.EnableHTTPS
+}
+
+// GRPCTLSEnabled проверяет, нужно ли запускать gRPC сервер по TLS.
+func (s *Settings) GRPCTLSEnabled() bool {
+	return s.GRPCTLSCert != "" && s.GRPCTLSKey != ""
 }
 
 // Params глобальная переменная типа Settings, инициализируется в момент старта сервиса.
@@ -34,7 +156,8 @@ var Params Settings
 
 func init() {
 	Params = Settings{
-		LogLevel: zapcore.ErrorLevel,
+		LogLevel:  zapcore.ErrorLevel,
+		LogFormat: LogFormatJSON,
 	}
 }
 
@@ -95,6 +218,58 @@ func parseConfigData(data []byte) error {
 		LogLevel        string `json:"log_level" env:"LOG_LEVEL"`
 		EnableHTTPS     string `json:"enable_https" env:"ENABLE_HTTPS"`
 		TrustedSubnet   string `json:"trusted_subnet" env:"TRUSTED_SUBNET"`
+		TrustedProxies  string `json:"trusted_proxies" env:"TRUSTED_PROXIES"`
+		CreateRPS       string `json:"create_rate_limit" env:"CREATE_RATE_LIMIT"`
+		CreateBurst     string `json:"create_rate_burst" env:"CREATE_RATE_BURST"`
+		RedirectRPS     string `json:"redirect_rate_limit" env:"REDIRECT_RATE_LIMIT"`
+		RedirectBurst   string `json:"redirect_rate_burst" env:"REDIRECT_RATE_BURST"`
+		DailyURLsQuota  string `json:"daily_urls_quota" env:"DAILY_URLS_QUOTA"`
+		CookieDomain    string `json:"cookie_domain" env:"COOKIE_DOMAIN"`
+		CookiePath      string `json:"cookie_path" env:"COOKIE_PATH"`
+		CookieMaxAge    string `json:"cookie_max_age" env:"COOKIE_MAX_AGE"`
+		CookieSameSite  string `json:"cookie_same_site" env:"COOKIE_SAME_SITE"`
+		CookieSecure    string `json:"cookie_secure" env:"COOKIE_SECURE"`
+		CSRFProtection  string `json:"csrf_protection" env:"CSRF_PROTECTION"`
+		OIDCIssuer      string `json:"oidc_issuer" env:"OIDC_ISSUER"`
+		OIDCClientID    string `json:"oidc_client_id" env:"OIDC_CLIENT_ID"`
+		OIDCSecret      string `json:"oidc_client_secret" env:"OIDC_CLIENT_SECRET"`
+		OIDCRedirectURL string `json:"oidc_redirect_url" env:"OIDC_REDIRECT_URL"`
+		GRPCTLSCert     string `json:"grpc_tls_cert" env:"GRPC_TLS_CERT"`
+		GRPCTLSKey      string `json:"grpc_tls_key" env:"GRPC_TLS_KEY"`
+		GRPCClientCA    string `json:"grpc_client_ca" env:"GRPC_CLIENT_CA"`
+		CertReload      string `json:"cert_reload_interval" env:"CERT_RELOAD_INTERVAL"`
+		TLSMode         string `json:"tls_mode" env:"TLS_MODE"`
+		TLSHosts        string `json:"tls_hosts" env:"TLS_HOSTS"`
+		AutocertDir     string `json:"autocert_cache_dir" env:"AUTOCERT_CACHE_DIR"`
+		TLSCert         string `json:"tls_cert" env:"TLS_CERT"`
+		TLSKey          string `json:"tls_key" env:"TLS_KEY"`
+		HealthInterval  string `json:"health_check_interval" env:"HEALTH_CHECK_INTERVAL"`
+		ShutdownDrain   string `json:"shutdown_drain_delay" env:"SHUTDOWN_DRAIN_DELAY"`
+		MetricsAddr     string `json:"metrics_address" env:"METRICS_ADDRESS"`
+		AdminAddr       string `json:"admin_address" env:"ADMIN_ADDRESS"`
+		ProfilesDir     string `json:"profiles_dir" env:"PROFILES_DIR"`
+		TraceExporter   string `json:"trace_exporter" env:"TRACE_EXPORTER"`
+		OTLPEndpoint    string `json:"otlp_endpoint" env:"OTLP_ENDPOINT"`
+		OTLPInsecure    string `json:"otlp_insecure" env:"OTLP_INSECURE"`
+		TraceFile       string `json:"trace_file" env:"TRACE_FILE"`
+		TraceRatio      string `json:"trace_sample_ratio" env:"TRACE_SAMPLE_RATIO"`
+		GRPCReflection  string `json:"grpc_reflection" env:"GRPC_REFLECTION"`
+		LogLevels       string `json:"log_levels" env:"LOG_LEVELS"`
+		LogFormat       string `json:"log_format" env:"LOG_FORMAT"`
+		LogFile         string `json:"log_file" env:"LOG_FILE"`
+		LogMaxSize      string `json:"log_max_size" env:"LOG_MAX_SIZE"`
+		LogMaxAge       string `json:"log_max_age" env:"LOG_MAX_AGE"`
+		LogMaxBackups   string `json:"log_max_backups" env:"LOG_MAX_BACKUPS"`
+		LogSampleFirst  string `json:"log_sample_initial" env:"LOG_SAMPLE_INITIAL"`
+		LogSampleEvery  string `json:"log_sample_thereafter" env:"LOG_SAMPLE_THEREAFTER"`
+		LogQueryArgs    string `json:"log_query_args" env:"LOG_QUERY_ARGS"`
+		AuditRetention  string `json:"audit_retention" env:"AUDIT_RETENTION"`
+		WebhookRetry    string `json:"webhook_retry_delay" env:"WEBHOOK_RETRY_DELAY"`
+		WebhookAttempts string `json:"webhook_max_attempts" env:"WEBHOOK_MAX_ATTEMPTS"`
+		OutboxURL       string `json:"outbox_url" env:"OUTBOX_URL"`
+		OutboxPeriod    string `json:"outbox_period" env:"OUTBOX_PERIOD"`
+		EventsBuffer    string `json:"events_replay_buffer" env:"EVENTS_REPLAY_BUFFER"`
+		EventsHeartbeat string `json:"events_heartbeat" env:"EVENTS_HEARTBEAT"`
 	}{}
 
 	err := json.Unmarshal(data, &config)
@@ -127,12 +302,15 @@ func (s *Settings) parseEnv() error {
 				if v == "" {
 					return nil, nil //nolint:nilnil // Контроллируемое поведение
 				}
-				_, net, err := net.ParseCIDR(v)
+				_, net, err := net.ParseCIDR(strings.TrimSpace(v))
 				if err != nil {
 					return nil, fmt.Errorf("parse trusted subnet env error: %w", err)
 				}
 				return *net, nil
 			},
+			reflect.TypeOf(http.SameSite(0)): func(v string) (interface{}, error) {
+				return parseSameSite(v)
+			},
 		},
 	})
 	if err != nil {
@@ -145,6 +323,8 @@ func (s *Settings) parseEnv() error {
 func (s *Settings) parseFlags() {
 	flag.StringVar(&s.RunAddr, "a", s.RunAddr, "address and port to run server")
 	flag.StringVar(&s.RunGAddr, "g", s.RunGAddr, "address and port to run grpc server")
+	flag.StringVar(&s.AdminAddr, "aa", s.AdminAddr, "address and port to run admin server with pprof, disabled when empty")
+	flag.StringVar(&s.MetricsAddr, "ma", s.MetricsAddr, "address and port to serve metrics separately from the trusted subnet guarded /metrics")
 	flag.Func("b", `address and port to urls (default "http://localhost:8080")`, func(v string) error {
 		parsedBaseURL, err := url.Parse(v)
 		if err != nil {
@@ -177,15 +357,64 @@ func (s *Settings) parseFlags() {
 		s.TrustedSubnet = subnet
 		return nil
 	})
+	flag.Func("tp", `comma-separated trusted proxy subnets for client IP resolution (default "")`, func(v string) error {
+		subnets, err := parseSubnets(v)
+		if err != nil {
+			return fmt.Errorf("parse trusted proxies error: %w", err)
+		}
+
+		s.TrustedProxies = subnets
+		return nil
+	})
 
 	flag.StringVar(&s.FileStoragePath, "f", s.FileStoragePath, "file storage path")
 	flag.StringVar(&s.DatabaseDSN, "d", s.DatabaseDSN, "database DSN")
 	flag.StringVar(&s.SecretKey, "sk", s.SecretKey, "secret key for generate cookie token")
 	flag.DurationVar(&s.DropURLsPeriod, "dp", s.DropURLsPeriod, "drop urls period")
+	flag.DurationVar(&s.ShutdownDrainDelay, "sd", s.ShutdownDrainDelay, "shutdown drain delay")
 	flag.BoolVar(&s.EnableHTTPS, "s", s.EnableHTTPS, "enable HTTPS")
+	flag.StringVar(&s.TLSMode, "tm", s.TLSMode, "HTTPS certificate mode: autocert, files or self-signed")
+	flag.StringVar(&s.LogFormat, "lf", s.LogFormat, "log format: json or console")
+	flag.StringVar(&s.LogFile, "lo", s.LogFile, "log file with size and age based rotation instead of stderr")
+	flag.StringVar(&s.TraceExporter, "te", s.TraceExporter, "trace exporter: none, otlp, stdout or file")
 
 	flag.String("c", "", "config file path (shorthand)")
 	flag.String("config", "", "config file path")
 
 	flag.Parse()
 }
+
+func parseSameSite(v string) (http.SameSite, error) {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "default":
+		return http.SameSiteDefaultMode, nil
+	case "lax":
+		return http.SameSiteLaxMode, nil
+	case "strict":
+		return http.SameSiteStrictMode, nil
+	case "none":
+		return http.SameSiteNoneMode, nil
+	default:
+		return 0, fmt.Errorf("unknown cookie same site mode %s", v)
+	}
+}
+
+func parseSubnets(v string) ([]*net.IPNet, error) {
+	subnets := []*net.IPNet{}
+
+	for _, part := range strings.Split(v, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		_, subnet, err := net.ParseCIDR(part)
+		if err != nil {
+			return nil, fmt.Errorf("parse subnet %s error: %w", part, err)
+		}
+
+		subnets = append(subnets, subnet)
+	}
+
+	return subnets, nil
+}
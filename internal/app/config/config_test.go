@@ -1,6 +1,7 @@
 package config
 
 import (
+	"net/http"
 	"os"
 	"testing"
 
@@ -21,6 +22,7 @@ func TestSetup(t *testing.T) {
 				require.NoError(t, os.Setenv("SERVER_ADDRESS", "localhost:8081"))
 				require.NoError(t, os.Setenv("BASE_URL", "http://localhost:8081"))
 				require.NoError(t, os.Setenv("TRUSTED_SUBNET", "192.168.0.0/24"))
+				require.NoError(t, os.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 127.0.0.1/32"))
 			},
 			wantErr: false,
 			errText: "",
@@ -200,3 +202,83 @@ func TestParseEnv(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSubnets(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		count   int
+		wantErr bool
+	}{
+		{
+			name:    "several subnets",
+			value:   "10.0.0.0/8, 127.0.0.1/32",
+			count:   2,
+			wantErr: false,
+		},
+		{
+			name:    "empty value",
+			value:   "",
+			count:   0,
+			wantErr: false,
+		},
+		{
+			name:    "bad subnet",
+			value:   "10.0.0.0/8,some string",
+			count:   0,
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			subnets, err := parseSubnets(test.value)
+
+			if test.wantErr {
+				require.Error(t, err)
+				require.ErrorContains(t, err, "parse subnet")
+			} else {
+				require.NoError(t, err)
+				assert.Len(t, subnets, test.count)
+			}
+		})
+	}
+}
+
+func TestParseSameSite(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    http.SameSite
+		wantErr bool
+	}{
+		{value: "lax", want: http.SameSiteLaxMode, wantErr: false},
+		{value: "Strict", want: http.SameSiteStrictMode, wantErr: false},
+		{value: "none", want: http.SameSiteNoneMode, wantErr: false},
+		{value: "default", want: http.SameSiteDefaultMode, wantErr: false},
+		{value: "some string", want: 0, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			mode, err := parseSameSite(test.value)
+
+			if test.wantErr {
+				require.Error(t, err)
+				require.ErrorContains(t, err, "unknown cookie same site mode")
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.want, mode)
+			}
+		})
+	}
+}
+
+func TestSecureCookies(t *testing.T) {
+	assert.False(t, (&Settings{}).SecureCookies())
+	assert.True(t, (&Settings{CookieSecure: true}).SecureCookies())
+	assert.True(t, (&Settings{EnableHTTPS: true}).SecureCookies())
+}
+
+func TestGRPCTLSEnabled(t *testing.T) {
+	assert.False(t, (&Settings{}).GRPCTLSEnabled())
+	assert.False(t, (&Settings{GRPCTLSCert: "server.crt"}).GRPCTLSEnabled())
+	assert.True(t, (&Settings{GRPCTLSCert: "server.crt", GRPCTLSKey: "server.key"}).GRPCTLSEnabled())
+}
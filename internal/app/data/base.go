@@ -3,6 +3,11 @@ package data
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/MihailSergeenkov/shortener/internal/app/common"
 	"github.com/MihailSergeenkov/shortener/internal/app/models"
@@ -12,13 +17,47 @@ const initSize int = 100
 
 // BaseStorage структура in-memory БД.
 type BaseStorage struct {
-	urls map[string]models.URL
+	urls    map[string]models.URL
+	quotas  *quotaStore
+	orgs    map[string]models.Org
+	members map[string]map[string]string
+	keys    []string // отсортированные короткие ссылки для постраничного обхода, сбрасываются при добавлении
+	audit   *auditLog
+	hooks   *webhookStore
+}
+
+// auditLog события журнала аудита в порядке записи, пишутся из фоновой горутины и поэтому под мьютексом.
+type auditLog struct {
+	events []models.AuditEvent
+	mu     sync.RWMutex
+}
+
+// quotaStore списанные за текущий день квоты по ключу, списываются из параллельных запросов и поэтому под мьютексом.
+type quotaStore struct {
+	used map[string]int
+	day  time.Time // день, за который хранятся квоты, при смене дня квоты прошлого дня удаляются
+	mu   sync.Mutex
+}
+
+// webhookStore вебхуки и очередь их доставок, доставки обрабатываются фоновой горутиной и поэтому под мьютексом.
+type webhookStore struct {
+	webhooks   map[string]models.Webhook
+	deliveries map[string]models.WebhookDelivery
+	mu         sync.RWMutex
 }
 
 // NewBaseStorage инициализирует in-memory БД.
 func NewBaseStorage() *BaseStorage {
 	return &BaseStorage{
-		urls: make(map[string]models.URL, initSize),
+		urls:    make(map[string]models.URL, initSize),
+		quotas:  &quotaStore{used: make(map[string]int)},
+		orgs:    make(map[string]models.Org),
+		members: make(map[string]map[string]string),
+		audit:   &auditLog{},
+		hooks: &webhookStore{
+			webhooks:   make(map[string]models.Webhook),
+			deliveries: make(map[string]models.WebhookDelivery),
+		},
 	}
 }
 
@@ -34,15 +73,20 @@ func (s *BaseStorage) StoreShortURL(ctx context.Context, shortURL string, origin
 		return common.ErrFetchUserIDFromContext
 	}
 
+	orgID, _ := ctx.Value(common.KeyOrgID).(string)
+
 	url := models.URL{
 		ID:          uint(len(s.urls) + 1),
 		ShortURL:    shortURL,
 		OriginalURL: originalURL,
 		UserID:      userID,
+		OrgID:       orgID,
 		DeletedFlag: false,
+		CreatedAt:   time.Now().UTC(),
 	}
 
 	s.urls[shortURL] = url
+	s.keys = nil
 
 	return nil
 }
@@ -56,11 +100,14 @@ func (s *BaseStorage) StoreShortURLs(_ context.Context, urls []models.URL) error
 	}
 
 	lastID := len(s.urls)
+	now := time.Now().UTC()
 
 	for i, url := range urls {
 		url.ID = uint(lastID + i)
+		url.CreatedAt = now
 		s.urls[url.ShortURL] = url
 	}
+	s.keys = nil
 
 	return nil
 }
@@ -76,13 +123,13 @@ func (s *BaseStorage) GetURL(_ context.Context, shortURL string) (models.URL, er
 	return u, nil
 }
 
-// FetchUserURLs получает все пользовательские ссылки.
+// FetchUserURLs получает все пользовательские ссылки (ссылки организаций не входят).
 func (s *BaseStorage) FetchUserURLs(ctx context.Context) ([]models.URL, error) {
 	urls := []models.URL{}
 	userID := ctx.Value(common.KeyUserID)
 
 	for _, u := range s.urls {
-		if u.UserID == userID {
+		if u.UserID == userID && u.OrgID == "" {
 			urls = append(urls, u)
 		}
 	}
@@ -121,11 +168,522 @@ func (s *BaseStorage) FetchStats(ctx context.Context) (int, int, error) {
 	return len(s.urls), len(users), nil
 }
 
+// ConsumeDailyQuota списывает дневную квоту ключа (пользователя или IP адреса) на создание ссылок.
+func (s *BaseStorage) ConsumeDailyQuota(_ context.Context, key string, count int, limit int) error {
+	_, _, err := s.quotas.update(key, count, limit)
+	return err
+}
+
+// RefundDailyQuota возвращает списанную сегодня квоту ключа, если ссылки не были сохранены.
+func (s *BaseStorage) RefundDailyQuota(_ context.Context, key string, count int) error {
+	_, _, err := s.quotas.update(key, -count, 0)
+	return err
+}
+
+// update изменяет квоту ключа за текущий день на delta: списание не может превысить limit,
+// а возврат не опускает квоту ниже нуля. Возвращает новое значение квоты и признак смены дня.
+func (q *quotaStore) update(key string, delta int, limit int) (quotaRecord, bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	day := today()
+	dayChanged := !q.day.Equal(day)
+	if dayChanged {
+		q.used = make(map[string]int)
+		q.day = day
+	}
+
+	dayKey := quotaKey(key, day)
+	used := q.used[dayKey] + delta
+	if delta > 0 && used > limit {
+		return quotaRecord{}, dayChanged, ErrQuotaExceeded
+	}
+
+	used = max(used, 0)
+	q.used[dayKey] = used
+
+	return quotaRecord{Key: dayKey, Used: used}, dayChanged, nil
+}
+
+// restore восстанавливает квоты текущего дня, квоты прошлых дней пропускаются.
+func (q *quotaStore) restore(records []quotaRecord) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.day = today()
+	q.used = make(map[string]int, len(records))
+	suffix := quotaKey("", q.day)
+	for _, record := range records {
+		if strings.HasSuffix(record.Key, suffix) {
+			q.used[record.Key] = record.Used
+		}
+	}
+}
+
+// records возвращает квоты текущего дня.
+func (q *quotaStore) records() []quotaRecord {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	records := make([]quotaRecord, 0, len(q.used))
+	for key, used := range q.used {
+		records = append(records, quotaRecord{Key: key, Used: used})
+	}
+
+	return records
+}
+
+// StoreOrg создает организацию, пользователь из контекста становится ее владельцем.
+func (s *BaseStorage) StoreOrg(ctx context.Context, org models.Org) error {
+	userID, ok := ctx.Value(common.KeyUserID).(string)
+	if !ok {
+		return common.ErrFetchUserIDFromContext
+	}
+
+	if _, ok := s.orgs[org.ID]; ok {
+		return ErrOrgAlreadyExist
+	}
+
+	s.orgs[org.ID] = org
+	s.members[org.ID] = map[string]string{userID: models.RoleOwner}
+
+	return nil
+}
+
+// FetchUserOrgs получает организации, в которых состоит пользователь.
+func (s *BaseStorage) FetchUserOrgs(ctx context.Context) ([]models.UserOrg, error) {
+	orgs := []models.UserOrg{}
+	userID := ctx.Value(common.KeyUserID)
+
+	for orgID, members := range s.members {
+		for memberID, role := range members {
+			if memberID == userID {
+				orgs = append(orgs, models.UserOrg{
+					ID:   orgID,
+					Name: s.orgs[orgID].Name,
+					Role: role,
+				})
+			}
+		}
+	}
+
+	sort.Slice(orgs, func(i, j int) bool { return orgs[i].ID < orgs[j].ID })
+
+	return orgs, nil
+}
+
+// GetOrgRole получает роль пользователя из контекста в организации.
+func (s *BaseStorage) GetOrgRole(ctx context.Context, orgID string) (string, error) {
+	userID, ok := ctx.Value(common.KeyUserID).(string)
+	if !ok {
+		return "", common.ErrFetchUserIDFromContext
+	}
+
+	role, ok := s.members[orgID][userID]
+	if !ok {
+		return "", fmt.Errorf("%w for org %s", ErrMemberNotFound, orgID)
+	}
+
+	return role, nil
+}
+
+// FetchOrgMembers получает участников организации.
+func (s *BaseStorage) FetchOrgMembers(_ context.Context, orgID string) ([]models.Member, error) {
+	members := []models.Member{}
+
+	for userID, role := range s.members[orgID] {
+		members = append(members, models.Member{
+			OrgID:  orgID,
+			UserID: userID,
+			Role:   role,
+		})
+	}
+
+	sort.Slice(members, func(i, j int) bool { return members[i].UserID < members[j].UserID })
+
+	return members, nil
+}
+
+// StoreOrgMember добавляет участника организации или меняет его роль.
+func (s *BaseStorage) StoreOrgMember(_ context.Context, member models.Member) error {
+	members, ok := s.members[member.OrgID]
+	if !ok {
+		members = make(map[string]string)
+		s.members[member.OrgID] = members
+	}
+
+	members[member.UserID] = member.Role
+
+	return nil
+}
+
+// DeleteOrgMember исключает участника из организации.
+func (s *BaseStorage) DeleteOrgMember(_ context.Context, orgID string, userID string) error {
+	if _, ok := s.members[orgID][userID]; !ok {
+		return fmt.Errorf("%w for org %s", ErrMemberNotFound, orgID)
+	}
+
+	delete(s.members[orgID], userID)
+
+	return nil
+}
+
+// FetchOrgURLs получает все ссылки организации.
+func (s *BaseStorage) FetchOrgURLs(_ context.Context, orgID string) ([]models.URL, error) {
+	urls := []models.URL{}
+
+	for _, u := range s.urls {
+		if u.OrgID == orgID {
+			urls = append(urls, u)
+		}
+	}
+
+	return urls, nil
+}
+
+// FetchURLsPage получает страницу ссылок пользователя (или организации, если задан orgID),
+// упорядоченных по короткой ссылке и следующих за after.
+func (s *BaseStorage) FetchURLsPage(ctx context.Context, orgID string, after string, limit int) ([]models.URL, error) {
+	var urls []models.URL
+	if orgID != "" {
+		urls, _ = s.FetchOrgURLs(ctx, orgID)
+	} else {
+		urls, _ = s.FetchUserURLs(ctx)
+	}
+
+	sort.Slice(urls, func(i, j int) bool { return urls[i].ShortURL < urls[j].ShortURL })
+
+	start := sort.Search(len(urls), func(i int) bool { return urls[i].ShortURL > after })
+	end := min(start+limit, len(urls))
+
+	return urls[start:end], nil
+}
+
+// CountURLs получает число всех ссылок, включая удаленные.
+func (s *BaseStorage) CountURLs(_ context.Context) (int, error) {
+	return len(s.urls), nil
+}
+
+// FetchAllURLsPage получает страницу всех ссылок, упорядоченных по короткой ссылке и следующих за after.
+func (s *BaseStorage) FetchAllURLsPage(_ context.Context, after string, limit int) ([]models.URL, error) {
+	if s.keys == nil {
+		s.keys = make([]string, 0, len(s.urls))
+		for key := range s.urls {
+			s.keys = append(s.keys, key)
+		}
+		sort.Strings(s.keys)
+	}
+
+	start := sort.Search(len(s.keys), func(i int) bool { return s.keys[i] > after })
+	end := min(start+limit, len(s.keys))
+
+	urls := make([]models.URL, 0, end-start)
+	for _, key := range s.keys[start:end] {
+		urls = append(urls, s.urls[key])
+	}
+
+	return urls, nil
+}
+
+// ImportURLs сохраняет ссылки как есть, уже существующие короткие ссылки пропускаются.
+func (s *BaseStorage) ImportURLs(_ context.Context, urls []models.URL) (int, error) {
+	imported := 0
+
+	for _, url := range urls {
+		if _, ok := s.urls[url.ShortURL]; ok {
+			continue
+		}
+
+		url.ID = uint(len(s.urls) + 1)
+		s.urls[url.ShortURL] = url
+		imported++
+	}
+
+	if imported > 0 {
+		s.keys = nil
+	}
+
+	return imported, nil
+}
+
+// FetchAllOrgs получает все организации и их участников.
+func (s *BaseStorage) FetchAllOrgs(ctx context.Context) ([]models.Org, []models.Member, error) {
+	orgs := make([]models.Org, 0, len(s.orgs))
+	for _, org := range s.orgs {
+		orgs = append(orgs, org)
+	}
+
+	sort.Slice(orgs, func(i, j int) bool { return orgs[i].ID < orgs[j].ID })
+
+	members := []models.Member{}
+	for _, org := range orgs {
+		orgMembers, _ := s.FetchOrgMembers(ctx, org.ID)
+		members = append(members, orgMembers...)
+	}
+
+	return orgs, members, nil
+}
+
+// ImportOrgs сохраняет организации и участников как есть, уже существующие пропускаются.
+func (s *BaseStorage) ImportOrgs(ctx context.Context, orgs []models.Org, members []models.Member) error {
+	for _, org := range orgs {
+		if _, ok := s.orgs[org.ID]; !ok {
+			s.orgs[org.ID] = org
+		}
+	}
+
+	for _, member := range members {
+		if _, ok := s.members[member.OrgID][member.UserID]; !ok {
+			_ = s.StoreOrgMember(ctx, member)
+		}
+	}
+
+	return nil
+}
+
 // Ping проверяет работоспособность БД (не используется для in-memory БД).
 func (s *BaseStorage) Ping(_ context.Context) error {
 	return nil
 }
 
+// HealthDetails возвращает состояние БД для проверки готовности (in-memory БД всегда доступна).
+func (s *BaseStorage) HealthDetails(_ context.Context) (map[string]any, error) {
+	return map[string]any{"type": "memory"}, nil
+}
+
+// StoreAuditEvents записывает события в журнал аудита.
+func (s *BaseStorage) StoreAuditEvents(_ context.Context, events []models.AuditEvent) error {
+	s.audit.mu.Lock()
+	defer s.audit.mu.Unlock()
+
+	s.audit.events = append(s.audit.events, events...)
+	return nil
+}
+
+// FetchAuditEvents получает события журнала аудита по фильтру, новые первыми.
+func (s *BaseStorage) FetchAuditEvents(_ context.Context, filter models.AuditFilter) ([]models.AuditEvent, error) {
+	s.audit.mu.RLock()
+	defer s.audit.mu.RUnlock()
+
+	events := make([]models.AuditEvent, 0)
+	for i := len(s.audit.events) - 1; i >= 0; i-- {
+		if filter.Limit > 0 && len(events) == filter.Limit {
+			break
+		}
+		if filter.Match(s.audit.events[i]) {
+			events = append(events, s.audit.events[i])
+		}
+	}
+
+	return events, nil
+}
+
+// DropAuditEvents удаляет события журнала аудита раньше before.
+func (s *BaseStorage) DropAuditEvents(_ context.Context, before time.Time) (int, error) {
+	s.audit.mu.Lock()
+	defer s.audit.mu.Unlock()
+
+	kept := s.audit.events[:0]
+	for _, e := range s.audit.events {
+		if !e.Time.Before(before) {
+			kept = append(kept, e)
+		}
+	}
+
+	dropped := len(s.audit.events) - len(kept)
+	s.audit.events = kept
+
+	return dropped, nil
+}
+
+// StoreWebhook сохраняет вебхук пользователя.
+func (s *BaseStorage) StoreWebhook(_ context.Context, webhook models.Webhook) error {
+	s.hooks.mu.Lock()
+	defer s.hooks.mu.Unlock()
+
+	s.hooks.webhooks[webhook.ID] = webhook
+	return nil
+}
+
+// FetchUserWebhooks получает вебхуки пользователя из контекста.
+func (s *BaseStorage) FetchUserWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	userID, ok := ctx.Value(common.KeyUserID).(string)
+	if !ok {
+		return nil, common.ErrFetchUserIDFromContext
+	}
+
+	s.hooks.mu.RLock()
+	defer s.hooks.mu.RUnlock()
+
+	webhooks := []models.Webhook{}
+	for _, w := range s.hooks.webhooks {
+		if w.UserID == userID {
+			webhooks = append(webhooks, w)
+		}
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID < webhooks[j].ID })
+
+	return webhooks, nil
+}
+
+// UpdateWebhook меняет адрес, ключ и события вебхука пользователя из контекста.
+func (s *BaseStorage) UpdateWebhook(ctx context.Context, webhook models.Webhook) error {
+	userID, ok := ctx.Value(common.KeyUserID).(string)
+	if !ok {
+		return common.ErrFetchUserIDFromContext
+	}
+
+	s.hooks.mu.Lock()
+	defer s.hooks.mu.Unlock()
+
+	current, ok := s.hooks.webhooks[webhook.ID]
+	if !ok || current.UserID != userID {
+		return ErrWebhookNotFound
+	}
+
+	current.URL = webhook.URL
+	current.Secret = webhook.Secret
+	current.Events = webhook.Events
+	s.hooks.webhooks[webhook.ID] = current
+
+	return nil
+}
+
+// DeleteWebhook удаляет вебхук пользователя из контекста вместе с его доставками.
+func (s *BaseStorage) DeleteWebhook(ctx context.Context, id string) error {
+	userID, ok := ctx.Value(common.KeyUserID).(string)
+	if !ok {
+		return common.ErrFetchUserIDFromContext
+	}
+
+	s.hooks.mu.Lock()
+	defer s.hooks.mu.Unlock()
+
+	if w, ok := s.hooks.webhooks[id]; !ok || w.UserID != userID {
+		return ErrWebhookNotFound
+	}
+
+	s.removeWebhook(id)
+
+	return nil
+}
+
+// removeWebhook удаляет вебхук и его доставки, вызывается под мьютексом.
+func (s *BaseStorage) removeWebhook(id string) {
+	delete(s.hooks.webhooks, id)
+
+	for deliveryID, d := range s.hooks.deliveries {
+		if d.WebhookID == id {
+			delete(s.hooks.deliveries, deliveryID)
+		}
+	}
+}
+
+// FetchEventWebhooks получает вебхуки пользователя userID, подписанные на событие event.
+func (s *BaseStorage) FetchEventWebhooks(_ context.Context, userID string, event string) ([]models.Webhook, error) {
+	s.hooks.mu.RLock()
+	defer s.hooks.mu.RUnlock()
+
+	webhooks := []models.Webhook{}
+	for _, w := range s.hooks.webhooks {
+		if w.UserID == userID && slices.Contains(w.Events, event) {
+			webhooks = append(webhooks, w)
+		}
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID < webhooks[j].ID })
+
+	return webhooks, nil
+}
+
+// StoreWebhookDeliveries добавляет доставки в исходящую очередь вебхуков.
+func (s *BaseStorage) StoreWebhookDeliveries(_ context.Context, deliveries []models.WebhookDelivery) error {
+	s.hooks.mu.Lock()
+	defer s.hooks.mu.Unlock()
+
+	for _, d := range deliveries {
+		d.URL, d.Secret = "", ""
+		s.hooks.deliveries[d.ID] = d
+	}
+
+	return nil
+}
+
+// FetchDueWebhookDeliveries получает до limit доставок, время попытки которых наступило к now, старые первыми.
+func (s *BaseStorage) FetchDueWebhookDeliveries(
+	_ context.Context,
+	now time.Time,
+	limit int,
+) ([]models.WebhookDelivery, error) {
+	s.hooks.mu.RLock()
+	defer s.hooks.mu.RUnlock()
+
+	deliveries := []models.WebhookDelivery{}
+	for _, d := range s.hooks.deliveries {
+		if d.Dead || d.NextAttempt.After(now) {
+			continue
+		}
+
+		w := s.hooks.webhooks[d.WebhookID]
+		d.URL, d.Secret = w.URL, w.Secret
+		deliveries = append(deliveries, d)
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].NextAttempt.Before(deliveries[j].NextAttempt) })
+	if limit > 0 && len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	return deliveries, nil
+}
+
+// UpdateWebhookDelivery сохраняет результат попытки доставки, удаленные из очереди доставки пропускаются.
+func (s *BaseStorage) UpdateWebhookDelivery(_ context.Context, delivery models.WebhookDelivery) error {
+	s.hooks.mu.Lock()
+	defer s.hooks.mu.Unlock()
+
+	if _, ok := s.hooks.deliveries[delivery.ID]; !ok {
+		return nil
+	}
+
+	delivery.URL, delivery.Secret = "", ""
+	s.hooks.deliveries[delivery.ID] = delivery
+
+	return nil
+}
+
+// DeleteWebhookDelivery удаляет выполненную доставку из очереди.
+func (s *BaseStorage) DeleteWebhookDelivery(_ context.Context, id string) error {
+	s.hooks.mu.Lock()
+	defer s.hooks.mu.Unlock()
+
+	delete(s.hooks.deliveries, id)
+	return nil
+}
+
+// FetchDeadWebhookDeliveries получает недоставленные события вебхуков пользователя из контекста, новые первыми.
+func (s *BaseStorage) FetchDeadWebhookDeliveries(ctx context.Context) ([]models.WebhookDelivery, error) {
+	userID, ok := ctx.Value(common.KeyUserID).(string)
+	if !ok {
+		return nil, common.ErrFetchUserIDFromContext
+	}
+
+	s.hooks.mu.RLock()
+	defer s.hooks.mu.RUnlock()
+
+	deliveries := []models.WebhookDelivery{}
+	for _, d := range s.hooks.deliveries {
+		if d.Dead && s.hooks.webhooks[d.WebhookID].UserID == userID {
+			deliveries = append(deliveries, d)
+		}
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt) })
+
+	return deliveries, nil
+}
+
 // Close закрывает соединение с БД (не используется для in-memory БД).
 func (s *BaseStorage) Close() error {
 	return nil
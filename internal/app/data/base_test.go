@@ -2,7 +2,10 @@ package data
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/MihailSergeenkov/shortener/internal/app/common"
 	"github.com/MihailSergeenkov/shortener/internal/app/models"
@@ -205,6 +208,49 @@ func TestFetchUserURLs(t *testing.T) {
 	}
 }
 
+func TestFetchURLsPage(t *testing.T) {
+	currentUserID := "some_id"
+	ctx := context.WithValue(context.Background(), common.KeyUserID, currentUserID)
+	storage := &BaseStorage{
+		urls: map[string]models.URL{
+			"c": {ShortURL: "c", UserID: currentUserID},
+			"a": {ShortURL: "a", UserID: currentUserID},
+			"b": {ShortURL: "b", UserID: currentUserID},
+			"d": {ShortURL: "d", UserID: "other_id"},
+			"e": {ShortURL: "e", UserID: currentUserID, OrgID: "org"},
+		},
+	}
+
+	shortURLs := func(urls []models.URL) []string {
+		res := make([]string, 0, len(urls))
+		for _, u := range urls {
+			res = append(res, u.ShortURL)
+		}
+		return res
+	}
+
+	tests := []struct {
+		name  string
+		orgID string
+		after string
+		limit int
+		want  []string
+	}{
+		{name: "first page", limit: 2, want: []string{"a", "b"}},
+		{name: "next page", after: "b", limit: 2, want: []string{"c"}},
+		{name: "after last", after: "c", limit: 2, want: []string{}},
+		{name: "org page", orgID: "org", limit: 2, want: []string{"e"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			urls, err := storage.FetchURLsPage(ctx, test.orgID, test.after, test.limit)
+
+			require.NoError(t, err)
+			assert.Equal(t, test.want, shortURLs(urls))
+		})
+	}
+}
+
 func TestDeleteShortURLs(t *testing.T) {
 	ctx := context.Background()
 	shortURL := "short_url"
@@ -308,6 +354,56 @@ func TestFetchStats(t *testing.T) {
 	}
 }
 
+func TestConsumeDailyQuota(t *testing.T) {
+	ctx := context.Background()
+	key := "user:some_id"
+	storage := NewBaseStorage()
+	limit := 3
+
+	t.Run("consume within limit", func(t *testing.T) {
+		require.NoError(t, storage.ConsumeDailyQuota(ctx, key, 2, limit))
+		require.NoError(t, storage.ConsumeDailyQuota(ctx, key, 1, limit))
+	})
+
+	t.Run("quota exceeded", func(t *testing.T) {
+		err := storage.ConsumeDailyQuota(ctx, key, 1, limit)
+
+		require.ErrorIs(t, err, ErrQuotaExceeded)
+	})
+
+	t.Run("separate quota per key", func(t *testing.T) {
+		require.NoError(t, storage.ConsumeDailyQuota(ctx, "ip:192.0.2.1", 1, limit))
+	})
+
+	t.Run("refund", func(t *testing.T) {
+		require.NoError(t, storage.RefundDailyQuota(ctx, key, 1))
+		require.NoError(t, storage.ConsumeDailyQuota(ctx, key, 1, limit))
+		require.ErrorIs(t, storage.ConsumeDailyQuota(ctx, key, 1, limit), ErrQuotaExceeded)
+	})
+
+	t.Run("refund does not go below zero", func(t *testing.T) {
+		require.NoError(t, storage.RefundDailyQuota(ctx, key, 10))
+		require.ErrorIs(t, storage.ConsumeDailyQuota(ctx, key, limit+1, limit), ErrQuotaExceeded)
+	})
+
+	t.Run("concurrent consume", func(t *testing.T) {
+		var consumed atomic.Int32
+		var wg sync.WaitGroup
+		for range 50 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if storage.ConsumeDailyQuota(ctx, "ip:192.0.2.2", 1, 20) == nil {
+					consumed.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(20), consumed.Load())
+	})
+}
+
 func TestPing(t *testing.T) {
 	storage := NewBaseStorage()
 	ctx := context.Background()
@@ -322,3 +418,215 @@ func TestClose(t *testing.T) {
 
 	require.NoError(t, err)
 }
+
+func TestOrgs(t *testing.T) {
+	storage := NewBaseStorage()
+	ownerCtx := context.WithValue(context.Background(), common.KeyUserID, "owner_id")
+	viewerCtx := context.WithValue(context.Background(), common.KeyUserID, "viewer_id")
+	org := models.Org{ID: "org_id", Name: "Team"}
+
+	t.Run("store org", func(t *testing.T) {
+		require.NoError(t, storage.StoreOrg(ownerCtx, org))
+		require.ErrorIs(t, storage.StoreOrg(ownerCtx, org), ErrOrgAlreadyExist)
+
+		role, err := storage.GetOrgRole(ownerCtx, org.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.RoleOwner, role)
+	})
+
+	t.Run("store and fetch members", func(t *testing.T) {
+		member := models.Member{OrgID: org.ID, UserID: "viewer_id", Role: models.RoleViewer}
+		require.NoError(t, storage.StoreOrgMember(ownerCtx, member))
+
+		members, err := storage.FetchOrgMembers(ownerCtx, org.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []models.Member{
+			{OrgID: org.ID, UserID: "owner_id", Role: models.RoleOwner},
+			member,
+		}, members)
+
+		orgs, err := storage.FetchUserOrgs(viewerCtx)
+		require.NoError(t, err)
+		assert.Equal(t, []models.UserOrg{{ID: org.ID, Name: org.Name, Role: models.RoleViewer}}, orgs)
+	})
+
+	t.Run("org urls are not personal", func(t *testing.T) {
+		orgCtx := context.WithValue(ownerCtx, common.KeyOrgID, org.ID)
+		require.NoError(t, storage.StoreShortURL(orgCtx, "org_url", "https://ya.ru"))
+		require.NoError(t, storage.StoreShortURL(ownerCtx, "own_url", "https://ya.ru/own"))
+
+		urls, err := storage.FetchOrgURLs(ownerCtx, org.ID)
+		require.NoError(t, err)
+		require.Len(t, urls, 1)
+		assert.Equal(t, "org_url", urls[0].ShortURL)
+		assert.Equal(t, org.ID, urls[0].OrgID)
+
+		urls, err = storage.FetchUserURLs(ownerCtx)
+		require.NoError(t, err)
+		require.Len(t, urls, 1)
+		assert.Equal(t, "own_url", urls[0].ShortURL)
+	})
+
+	t.Run("delete member", func(t *testing.T) {
+		require.NoError(t, storage.DeleteOrgMember(ownerCtx, org.ID, "viewer_id"))
+		require.ErrorIs(t, storage.DeleteOrgMember(ownerCtx, org.ID, "viewer_id"), ErrMemberNotFound)
+
+		_, err := storage.GetOrgRole(viewerCtx, org.ID)
+		require.ErrorIs(t, err, ErrMemberNotFound)
+	})
+
+	t.Run("failed context", func(t *testing.T) {
+		require.ErrorIs(t, storage.StoreOrg(context.Background(), org), common.ErrFetchUserIDFromContext)
+
+		_, err := storage.GetOrgRole(context.Background(), org.ID)
+		require.ErrorIs(t, err, common.ErrFetchUserIDFromContext)
+	})
+}
+
+func TestMigratorMethods(t *testing.T) {
+	storage := NewBaseStorage()
+	ctx := context.WithValue(context.Background(), common.KeyUserID, "owner_id")
+	urls := []models.URL{
+		{ShortURL: "c", OriginalURL: "https://ya.ru/c", UserID: "user_1", DeletedFlag: true},
+		{ShortURL: "a", OriginalURL: "https://ya.ru/a", UserID: "user_2"},
+		{ShortURL: "b", OriginalURL: "https://ya.ru/b", UserID: "user_1", OrgID: "org_id"},
+	}
+
+	t.Run("import urls", func(t *testing.T) {
+		imported, err := storage.ImportURLs(ctx, urls)
+		require.NoError(t, err)
+		assert.Equal(t, 3, imported)
+
+		imported, err = storage.ImportURLs(ctx, urls[:1])
+		require.NoError(t, err)
+		assert.Equal(t, 0, imported)
+
+		count, err := storage.CountURLs(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+	})
+
+	t.Run("fetch all pages", func(t *testing.T) {
+		page, err := storage.FetchAllURLsPage(ctx, "", 2)
+		require.NoError(t, err)
+		require.Len(t, page, 2)
+		assert.Equal(t, "a", page[0].ShortURL)
+		assert.Equal(t, "user_2", page[0].UserID)
+
+		page, err = storage.FetchAllURLsPage(ctx, "b", 2)
+		require.NoError(t, err)
+		require.Len(t, page, 1)
+		assert.Equal(t, "c", page[0].ShortURL)
+		assert.True(t, page[0].DeletedFlag)
+
+		require.NoError(t, storage.StoreShortURL(ctx, "d", "https://ya.ru/d"))
+		page, err = storage.FetchAllURLsPage(ctx, "c", 2)
+		require.NoError(t, err)
+		require.Len(t, page, 1)
+		assert.Equal(t, "d", page[0].ShortURL)
+	})
+
+	t.Run("import and fetch orgs", func(t *testing.T) {
+		orgs := []models.Org{{ID: "org_id", Name: "Team"}}
+		members := []models.Member{
+			{OrgID: "org_id", UserID: "user_1", Role: models.RoleOwner},
+			{OrgID: "org_id", UserID: "user_2", Role: models.RoleViewer},
+		}
+
+		require.NoError(t, storage.ImportOrgs(ctx, orgs, members))
+		require.NoError(t, storage.ImportOrgs(ctx, []models.Org{{ID: "org_id", Name: "Renamed"}}, nil))
+
+		gotOrgs, gotMembers, err := storage.FetchAllOrgs(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, orgs, gotOrgs)
+		assert.Equal(t, members, gotMembers)
+	})
+}
+
+func TestAuditEvents(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	events := []models.AuditEvent{
+		{Time: now.Add(-2 * time.Hour), Action: models.AuditCreate, UserID: "user_1", ShortURL: "a"},
+		{Time: now.Add(-time.Hour), Action: models.AuditDelete, UserID: "user_1", ShortURL: "a"},
+		{Time: now, Action: models.AuditCreate, UserID: "user_2", ShortURL: "b"},
+	}
+
+	storage := NewBaseStorage()
+	require.NoError(t, storage.StoreAuditEvents(ctx, events))
+
+	t.Run("newest first", func(t *testing.T) {
+		got, err := storage.FetchAuditEvents(ctx, models.AuditFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, []models.AuditEvent{events[2], events[1], events[0]}, got)
+	})
+
+	t.Run("filter and limit", func(t *testing.T) {
+		got, err := storage.FetchAuditEvents(ctx, models.AuditFilter{UserID: "user_1", Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []models.AuditEvent{events[1]}, got)
+
+		got, err = storage.FetchAuditEvents(ctx, models.AuditFilter{Action: models.AuditCreate, To: now})
+		require.NoError(t, err)
+		assert.Equal(t, []models.AuditEvent{events[0]}, got)
+	})
+
+	t.Run("drop expired", func(t *testing.T) {
+		dropped, err := storage.DropAuditEvents(ctx, now.Add(-30*time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, 2, dropped)
+
+		got, err := storage.FetchAuditEvents(ctx, models.AuditFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, []models.AuditEvent{events[2]}, got)
+	})
+}
+
+func TestWebhooks(t *testing.T) {
+	ctx := context.WithValue(context.Background(), common.KeyUserID, "user_1")
+	otherCtx := context.WithValue(context.Background(), common.KeyUserID, "user_2")
+	now := time.Now().UTC()
+
+	storage := NewBaseStorage()
+	require.NoError(t, storage.StoreWebhook(ctx, models.Webhook{
+		ID: "a", UserID: "user_1", URL: "https://example.com", Secret: "s", Events: []string{models.LinkCreated},
+	}))
+
+	t.Run("event subscription", func(t *testing.T) {
+		got, err := storage.FetchEventWebhooks(ctx, "user_1", models.LinkCreated)
+		require.NoError(t, err)
+		assert.Len(t, got, 1)
+
+		got, err = storage.FetchEventWebhooks(ctx, "user_1", models.LinkClicked)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("foreign webhook", func(t *testing.T) {
+		require.ErrorIs(t, storage.UpdateWebhook(otherCtx, models.Webhook{ID: "a"}), ErrWebhookNotFound)
+		require.ErrorIs(t, storage.DeleteWebhook(otherCtx, "a"), ErrWebhookNotFound)
+	})
+
+	t.Run("due deliveries", func(t *testing.T) {
+		require.NoError(t, storage.StoreWebhookDeliveries(ctx, []models.WebhookDelivery{
+			{ID: "later", WebhookID: "a", NextAttempt: now.Add(time.Minute)},
+			{ID: "first", WebhookID: "a", NextAttempt: now.Add(-time.Minute)},
+			{ID: "second", WebhookID: "a", NextAttempt: now},
+		}))
+
+		got, err := storage.FetchDueWebhookDeliveries(ctx, now, 1)
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, "first", got[0].ID)
+		assert.Equal(t, "https://example.com", got[0].URL)
+		assert.Equal(t, "s", got[0].Secret)
+	})
+
+	t.Run("delete removes deliveries", func(t *testing.T) {
+		require.NoError(t, storage.DeleteWebhook(ctx, "a"))
+
+		got, err := storage.FetchDueWebhookDeliveries(ctx, now.Add(time.Hour), 0)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+}
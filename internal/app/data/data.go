@@ -4,10 +4,14 @@ package data
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/url"
+	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/MihailSergeenkov/shortener/internal/app/config"
+	"github.com/MihailSergeenkov/shortener/internal/app/health"
 	"github.com/MihailSergeenkov/shortener/internal/app/models"
 )
 
@@ -15,6 +19,11 @@ import (
 var (
 	ErrURLNotFound          = errors.New("url not found")           // короткая ссылка не найдена
 	ErrShortURLAlreadyExist = errors.New("short url already exist") // короткая ссылка уже существует в сервисе
+	ErrQuotaExceeded        = errors.New("daily quota exceeded")    // дневная квота пользователя исчерпана
+	ErrOrgAlreadyExist      = errors.New("org already exist")       // организация уже существует
+	ErrMemberNotFound       = errors.New("org member not found")    // пользователь не состоит в организации
+	ErrUnknownStorage       = errors.New("unknown storage")         // адрес хранилища не поддерживается
+	ErrWebhookNotFound      = errors.New("webhook not found")       // вебхук не найден у пользователя
 )
 
 // OriginalURLAlreadyExistError структура ошибки, когда оригинальная ссылка уже существует в сервисе.
@@ -35,15 +44,78 @@ func newOriginalURLAlreadyExistError(url string) error {
 
 // Storager интерфейс к БД.
 type Storager interface {
-	StoreShortURL(ctx context.Context, shortURL string, originalURL string) error // сохранение короткой ссылки
-	StoreShortURLs(ctx context.Context, urls []models.URL) error                  // сохранение нескольких коротких ссылок
-	GetURL(ctx context.Context, shortURL string) (models.URL, error)              // получение оригинальной ссылки
-	FetchUserURLs(ctx context.Context) ([]models.URL, error)                      // получить все ссылки пользователя
-	DeleteShortURLs(ctx context.Context, urls []string) error                     // мягко удалить ссылки
-	DropDeletedURLs(ctx context.Context) error                                    // очистить из БД удаленные ссылки
-	FetchStats(ctx context.Context) (int, int, error)                             // получение статистических данных
-	Ping(ctx context.Context) error                                               // проверка работоспособности БД
-	Close() error                                                                 // закрыть соединение с БД
+	StoreShortURL(ctx context.Context, shortURL string, originalURL string) error  // сохранение короткой ссылки
+	StoreShortURLs(ctx context.Context, urls []models.URL) error                   // сохранение нескольких коротких ссылок
+	GetURL(ctx context.Context, shortURL string) (models.URL, error)               // получение оригинальной ссылки
+	FetchUserURLs(ctx context.Context) ([]models.URL, error)                       // получить все ссылки пользователя
+	DeleteShortURLs(ctx context.Context, urls []string) error                      // мягко удалить ссылки
+	DropDeletedURLs(ctx context.Context) error                                     // очистить из БД удаленные ссылки
+	FetchStats(ctx context.Context) (int, int, error)                              // получение статистических данных
+	ConsumeDailyQuota(ctx context.Context, key string, count int, limit int) error // списать дневную квоту ключа
+	RefundDailyQuota(ctx context.Context, key string, count int) error             // вернуть квоту несохраненных ссылок
+	StoreOrg(ctx context.Context, org models.Org) error                            // создать организацию с владельцем
+	FetchUserOrgs(ctx context.Context) ([]models.UserOrg, error)                   // получить организации пользователя
+	GetOrgRole(ctx context.Context, orgID string) (string, error)                  // получить роль пользователя
+	FetchOrgMembers(ctx context.Context, orgID string) ([]models.Member, error)    // получить участников организации
+	StoreOrgMember(ctx context.Context, member models.Member) error                // добавить участника или сменить роль
+	DeleteOrgMember(ctx context.Context, orgID string, userID string) error        // исключить участника
+	FetchOrgURLs(ctx context.Context, orgID string) ([]models.URL, error)          // получить все ссылки организации
+	Ping(ctx context.Context) error                                                // проверка работоспособности БД
+	Close() error                                                                  // закрыть соединение с БД
+
+	// FetchURLsPage получить страницу ссылок пользователя или организации orgID после короткой ссылки after.
+	FetchURLsPage(ctx context.Context, orgID string, after string, limit int) ([]models.URL, error)
+
+	// StoreAuditEvents записать события в журнал аудита.
+	StoreAuditEvents(ctx context.Context, events []models.AuditEvent) error
+	// FetchAuditEvents получить события журнала аудита по фильтру, новые первыми.
+	FetchAuditEvents(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error)
+	// DropAuditEvents удалить события журнала аудита раньше before, возвращает число удаленных событий.
+	DropAuditEvents(ctx context.Context, before time.Time) (int, error)
+
+	// StoreWebhook сохранить вебхук пользователя.
+	StoreWebhook(ctx context.Context, webhook models.Webhook) error
+	// FetchUserWebhooks получить вебхуки пользователя из контекста.
+	FetchUserWebhooks(ctx context.Context) ([]models.Webhook, error)
+	// UpdateWebhook изменить адрес, ключ и события вебхука пользователя из контекста.
+	UpdateWebhook(ctx context.Context, webhook models.Webhook) error
+	// DeleteWebhook удалить вебхук пользователя из контекста вместе с его доставками.
+	DeleteWebhook(ctx context.Context, id string) error
+	// FetchEventWebhooks получить вебхуки пользователя userID, подписанные на событие event.
+	FetchEventWebhooks(ctx context.Context, userID string, event string) ([]models.Webhook, error)
+	// StoreWebhookDeliveries добавить доставки в исходящую очередь вебхуков.
+	StoreWebhookDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error
+	// FetchDueWebhookDeliveries получить до limit доставок, время попытки которых наступило к now, с адресом и ключом.
+	FetchDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]models.WebhookDelivery, error)
+	// UpdateWebhookDelivery сохранить результат попытки доставки.
+	UpdateWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) error
+	// DeleteWebhookDelivery удалить выполненную доставку из очереди.
+	DeleteWebhookDelivery(ctx context.Context, id string) error
+	// FetchDeadWebhookDeliveries получить недоставленные события вебхуков пользователя из контекста.
+	FetchDeadWebhookDeliveries(ctx context.Context) ([]models.WebhookDelivery, error)
+}
+
+// HealthReporter интерфейс к БД, которая сообщает подробности своего состояния для проверки готовности.
+type HealthReporter interface {
+	HealthDetails(ctx context.Context) (map[string]any, error)
+}
+
+// Outbox интерфейс к БД с транзакционной исходящей очередью изменений ссылок: события создания и удаления
+// записываются в одной транзакции с изменением ссылок и удаляются после публикации.
+type Outbox interface {
+	FetchOutboxEvents(ctx context.Context, limit int) ([]models.OutboxEvent, error) // первые limit событий очереди
+	DeleteOutboxEvents(ctx context.Context, ids []int64) error                      // удалить опубликованные события
+}
+
+// Migrator интерфейс к БД для переноса данных между хранилищами.
+type Migrator interface {
+	Storager
+
+	CountURLs(ctx context.Context) (int, error)                                          // число всех ссылок
+	FetchAllURLsPage(ctx context.Context, after string, limit int) ([]models.URL, error) // страница всех ссылок после after
+	ImportURLs(ctx context.Context, urls []models.URL) (int, error)                      // сохранить ссылки как есть
+	FetchAllOrgs(ctx context.Context) ([]models.Org, []models.Member, error)             // получить все организации
+	ImportOrgs(ctx context.Context, orgs []models.Org, members []models.Member) error    // сохранить организации как есть
 }
 
 // NewStorage инициализирует БД.
@@ -52,7 +124,15 @@ func NewStorage(ctx context.Context, logger *zap.Logger, params *config.Settings
 	fsp := params.FileStoragePath
 
 	if dbDSN != "" {
-		return NewDBStorage(ctx, logger, dbDSN)
+		s, err := NewDBStorage(ctx, logger, dbDSN)
+		if err != nil {
+			return nil, err
+		}
+		if params.OutboxURL != "" {
+			s.EnableOutbox()
+		}
+
+		return s, nil
 	}
 
 	if fsp == "" {
@@ -61,3 +141,58 @@ func NewStorage(ctx context.Context, logger *zap.Logger, params *config.Settings
 
 	return NewFileStorage(logger, fsp)
 }
+
+// StorageCheck возвращает проверку готовности БД: подробности состояния для HealthReporter или Ping.
+func StorageCheck(s Storager) health.CheckFunc {
+	return func(ctx context.Context) health.Result {
+		reporter, ok := s.(HealthReporter)
+		if !ok {
+			if err := s.Ping(ctx); err != nil {
+				return health.Failing(err, nil)
+			}
+			return health.OK(nil)
+		}
+
+		details, err := reporter.HealthDetails(ctx)
+		if err != nil {
+			return health.Failing(err, details)
+		}
+		return health.OK(details)
+	}
+}
+
+// NewStorageFromURL инициализирует БД по адресу: memory:, file:/path/to/db.json или postgres://...
+func NewStorageFromURL(ctx context.Context, logger *zap.Logger, rawURL string) (Migrator, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse storage url: %w", err)
+	}
+
+	switch u.Scheme {
+	case "memory":
+		return NewBaseStorage(), nil
+	case "file":
+		path := u.Path
+		if path == "" {
+			path = u.Opaque
+		}
+		if path == "" {
+			return nil, fmt.Errorf("%w: file path is empty", ErrUnknownStorage)
+		}
+
+		return NewFileStorage(logger, path)
+	case "postgres", "postgresql":
+		return NewDBStorage(ctx, logger, rawURL)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownStorage, rawURL)
+	}
+}
+
+func quotaKey(userID string, day time.Time) string {
+	return userID + "/" + day.Format(time.DateOnly)
+}
+
+func today() time.Time {
+	now := time.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -2,9 +2,15 @@ package data
 
 import (
 	"context"
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/MihailSergeenkov/shortener/internal/app/config"
+	"github.com/MihailSergeenkov/shortener/internal/app/data/mock"
+	"github.com/MihailSergeenkov/shortener/internal/app/health"
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -55,3 +61,78 @@ func TestNewOriginalURLAlreadyExistError(t *testing.T) {
 		assert.IsType(t, (*OriginalURLAlreadyExistError)(nil), err)
 	})
 }
+
+func TestNewStorageFromURL(t *testing.T) {
+	ctx := context.Background()
+	logger := zap.NewNop()
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		rawURL  string
+		wantErr error
+	}{
+		{name: "memory", rawURL: "memory:"},
+		{name: "file path", rawURL: "file:" + dir + "/a.json"},
+		{name: "file url", rawURL: "file://" + dir + "/b.json"},
+		{name: "empty file path", rawURL: "file:", wantErr: ErrUnknownStorage},
+		{name: "unknown scheme", rawURL: "redis://localhost", wantErr: ErrUnknownStorage},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			storage, err := NewStorageFromURL(ctx, logger, test.rawURL)
+
+			if test.wantErr != nil {
+				require.ErrorIs(t, err, test.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Implements(t, (*Migrator)(nil), storage)
+		})
+	}
+}
+
+func TestStorageCheck(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ctx := context.Background()
+	errSome := errors.New("some error")
+
+	fileStorage, err := NewFileStorage(zap.NewNop(), filepath.Join(t.TempDir(), "db.json"))
+	require.NoError(t, err)
+
+	removedStorage, err := NewFileStorage(zap.NewNop(), filepath.Join(t.TempDir(), "removed.json"))
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(removedStorage.fileStoragePath))
+
+	pool := mock.NewMockDBPooler(mockCtrl)
+	pool.EXPECT().Ping(ctx).Times(1).Return(errSome)
+
+	storager := mock.NewMockStorager(mockCtrl)
+	storager.EXPECT().Ping(ctx).Times(1).Return(nil)
+
+	tests := []struct {
+		name       string
+		storage    Storager
+		wantStatus string
+		wantType   any
+	}{
+		{name: "memory storage", storage: NewBaseStorage(), wantStatus: health.StatusOK, wantType: "memory"},
+		{name: "writable file storage", storage: fileStorage, wantStatus: health.StatusOK, wantType: "file"},
+		{name: "missing file storage", storage: removedStorage, wantStatus: health.StatusFailing, wantType: "file"},
+		{name: "unavailable DB", storage: &DBStorage{pool: pool}, wantStatus: health.StatusFailing, wantType: "postgres"},
+		{name: "storage without details", storage: storager, wantStatus: health.StatusOK, wantType: nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := StorageCheck(test.storage)(ctx)
+
+			assert.Equal(t, test.wantStatus, result.Status)
+			assert.Equal(t, test.wantType, result.Details["type"])
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"embed"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
@@ -16,13 +17,20 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/config"
+	"github.com/MihailSergeenkov/shortener/internal/app/logger"
 	"github.com/MihailSergeenkov/shortener/internal/app/models"
 )
 
+const (
+	uniqueViolationCode = "23505"           // код ошибки postgres о нарушении уникальности
+	shortURLIndex       = "short_url_index" // уникальный индекс коротких ссылок
+)
+
 const stmt = `
 	WITH new_url AS (
-		INSERT INTO urls (short_url, original_url, user_id) 
-		VALUES ($1, $2, $3)
+		INSERT INTO urls (short_url, original_url, user_id, org_id) 
+		VALUES ($1, $2, $3, NULLIF($4, ''))
 		ON CONFLICT (original_url) WHERE is_deleted = false DO NOTHING
 		RETURNING short_url
 	)
@@ -31,6 +39,23 @@ const stmt = `
 	SELECT short_url, false as is_new FROM urls WHERE original_url = $2 AND is_deleted = false
 `
 
+// outboxStmt сохраняет ссылку как stmt и в том же запросе, а значит и в той же транзакции,
+// ставит событие создания в исходящую очередь.
+const outboxStmt = `
+	WITH new_url AS (
+		INSERT INTO urls (short_url, original_url, user_id, org_id)
+		VALUES ($1, $2, $3, NULLIF($4, ''))
+		ON CONFLICT (original_url) WHERE is_deleted = false DO NOTHING
+		RETURNING short_url, original_url, user_id, org_id
+	), new_event AS (
+		INSERT INTO outbox (event, short_url, original_url, user_id, org_id)
+		SELECT 'create', short_url, original_url, user_id, org_id FROM new_url
+	)
+	SELECT short_url, true as is_new FROM new_url
+	UNION
+	SELECT short_url, false as is_new FROM urls WHERE original_url = $2 AND is_deleted = false
+`
+
 // DBPooler интерфейс к пулу БД.
 type DBPooler interface {
 	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
@@ -45,6 +70,7 @@ type DBPooler interface {
 type DBStorage struct {
 	pool   DBPooler
 	logger *zap.Logger
+	outbox bool // изменения ссылок записываются в исходящую очередь, см. EnableOutbox
 }
 
 // NewDBStorage инициализирует postgresql БД.
@@ -66,6 +92,12 @@ func NewDBStorage(ctx context.Context, logger *zap.Logger, dbDSN string) (*DBSto
 	return s, nil
 }
 
+// EnableOutbox включает запись событий создания и удаления ссылок в исходящую очередь.
+// Без публикации очередь не разбирается, поэтому запись включается, только если задан адрес публикации.
+func (s *DBStorage) EnableOutbox() {
+	s.outbox = true
+}
+
 //go:embed migrations/*.sql
 var migrationsDir embed.FS
 
@@ -89,7 +121,8 @@ func runMigrations(dsn string) error {
 
 // StoreShortURL сохраняет короткую ссылку.
 func (s *DBStorage) StoreShortURL(ctx context.Context, shortURL string, originalURL string) error {
-	row := s.pool.QueryRow(ctx, stmt, shortURL, originalURL, ctx.Value(common.KeyUserID))
+	orgID, _ := ctx.Value(common.KeyOrgID).(string)
+	row := s.pool.QueryRow(ctx, s.storeStmt(), shortURL, originalURL, ctx.Value(common.KeyUserID), orgID)
 
 	var url string
 	var isNewURL bool
@@ -106,43 +139,68 @@ func (s *DBStorage) StoreShortURL(ctx context.Context, shortURL string, original
 	return nil
 }
 
-// StoreShortURLs сохраняет несколько коротких ссылок.
+// StoreShortURLs сохраняет несколько коротких ссылок в одной транзакции.
+// Если одна из коротких ссылок уже занята, не сохраняется ни одна и возвращается ErrShortURLAlreadyExist.
 func (s *DBStorage) StoreShortURLs(ctx context.Context, urls []models.URL) error {
 	batch := &pgx.Batch{}
 
 	for _, url := range urls {
-		batch.Queue(stmt, url.ShortURL, url.OriginalURL, url.UserID)
+		batch.Queue(s.storeStmt(), url.ShortURL, url.OriginalURL, url.UserID, url.OrgID)
 	}
 
 	result := s.pool.SendBatch(ctx, batch)
-	defer func() {
-		if err := result.Close(); err != nil {
-			s.logger.Error("failed to close batch result", zap.Error(err))
+
+	var err error
+	for range urls {
+		if _, err = result.Exec(); err != nil {
+			break
 		}
-	}()
+	}
+
+	if closeErr := result.Close(); err == nil {
+		err = closeErr
+	}
 
-	_, err := result.Exec()
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode && pgErr.ConstraintName == shortURLIndex {
+			return fmt.Errorf("unable to insert batch: %w: %w", ErrShortURLAlreadyExist, err)
+		}
+
 		return fmt.Errorf("unable to insert batch: %w", err)
 	}
 
 	return nil
 }
 
-// DeleteShortURLs мягко удаляет ссылки.
+// DeleteShortURLs мягко удаляет ссылки. Если включена исходящая очередь, события удаления
+// записываются тем же запросом, что и удаление ссылки.
 func (s *DBStorage) DeleteShortURLs(ctx context.Context, urls []string) error {
 	const stmt = `UPDATE urls SET is_deleted = true WHERE short_url = $1`
+	const outboxStmt = `
+		WITH deleted AS (
+			UPDATE urls SET is_deleted = true WHERE short_url = $1 AND is_deleted = false
+			RETURNING short_url, original_url, user_id, org_id
+		)
+		INSERT INTO outbox (event, short_url, original_url, user_id, org_id)
+		SELECT 'delete', short_url, original_url, user_id, org_id FROM deleted
+	`
+
+	deleteStmt := stmt
+	if s.outbox {
+		deleteStmt = outboxStmt
+	}
 
 	batch := &pgx.Batch{}
 
 	for _, url := range urls {
-		batch.Queue(stmt, url)
+		batch.Queue(deleteStmt, url)
 	}
 
 	result := s.pool.SendBatch(ctx, batch)
 	defer func() {
 		if err := result.Close(); err != nil {
-			s.logger.Error("failed to close batch result", zap.Error(err))
+			logger.FromContext(ctx, s.logger).Error("failed to close batch result", zap.Error(err))
 		}
 	}()
 
@@ -154,9 +212,18 @@ func (s *DBStorage) DeleteShortURLs(ctx context.Context, urls []string) error {
 	return nil
 }
 
+// storeStmt возвращает запрос сохранения ссылки с записью в исходящую очередь, если она включена.
+func (s *DBStorage) storeStmt() string {
+	if s.outbox {
+		return outboxStmt
+	}
+
+	return stmt
+}
+
 // GetURL получает оригинальную ссылку по короткой.
 func (s *DBStorage) GetURL(ctx context.Context, shortURL string) (models.URL, error) {
-	const queryStmt = `SELECT id, short_url, original_url, is_deleted, user_id
+	const queryStmt = `SELECT id, short_url, original_url, is_deleted, user_id, COALESCE(org_id, '')
 		FROM urls
 		WHERE short_url = $1
 		LIMIT 1`
@@ -164,7 +231,7 @@ func (s *DBStorage) GetURL(ctx context.Context, shortURL string) (models.URL, er
 	row := s.pool.QueryRow(ctx, queryStmt, shortURL)
 
 	var u models.URL
-	err := row.Scan(&u.ID, &u.ShortURL, &u.OriginalURL, &u.DeletedFlag, &u.UserID)
+	err := row.Scan(&u.ID, &u.ShortURL, &u.OriginalURL, &u.DeletedFlag, &u.UserID, &u.OrgID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return models.URL{}, fmt.Errorf("%w for short URL %s", ErrURLNotFound, shortURL)
@@ -176,11 +243,11 @@ func (s *DBStorage) GetURL(ctx context.Context, shortURL string) (models.URL, er
 	return u, nil
 }
 
-// FetchUserURLs получает все пользовательские ссылки.
+// FetchUserURLs получает все пользовательские ссылки (ссылки организаций не входят).
 func (s *DBStorage) FetchUserURLs(ctx context.Context) ([]models.URL, error) {
 	const queryStmt = `SELECT id, short_url, original_url, user_id
 		FROM urls
-		WHERE user_id = $1`
+		WHERE user_id = $1 AND org_id IS NULL`
 
 	urls := []models.URL{}
 
@@ -237,6 +304,421 @@ func (s *DBStorage) FetchStats(ctx context.Context) (int, int, error) {
 	return urlsCount, usersCount, nil
 }
 
+// ConsumeDailyQuota списывает дневную квоту ключа (пользователя или IP адреса) на создание ссылок.
+func (s *DBStorage) ConsumeDailyQuota(ctx context.Context, key string, count int, limit int) error {
+	const stmt = `INSERT INTO user_quotas (user_id, day, used)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, day) DO UPDATE SET used = user_quotas.used + EXCLUDED.used
+		WHERE user_quotas.used + EXCLUDED.used <= $4
+		RETURNING used`
+
+	if count > limit {
+		return ErrQuotaExceeded
+	}
+
+	row := s.pool.QueryRow(ctx, stmt, key, today(), count, limit)
+
+	var used int
+	if err := row.Scan(&used); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrQuotaExceeded
+		}
+
+		return fmt.Errorf("failed to scan a response row: %w", err)
+	}
+
+	return nil
+}
+
+// RefundDailyQuota возвращает списанную сегодня квоту ключа, если ссылки не были сохранены.
+func (s *DBStorage) RefundDailyQuota(ctx context.Context, key string, count int) error {
+	const stmt = `UPDATE user_quotas SET used = GREATEST(used - $3, 0) WHERE user_id = $1 AND day = $2`
+
+	if _, err := s.pool.Exec(ctx, stmt, key, today(), count); err != nil {
+		return fmt.Errorf("failed to refund quota: %w", err)
+	}
+
+	return nil
+}
+
+// StoreOrg создает организацию, пользователь из контекста становится ее владельцем.
+func (s *DBStorage) StoreOrg(ctx context.Context, org models.Org) error {
+	const stmt = `WITH new_org AS (
+			INSERT INTO orgs (id, name)
+			VALUES ($1, $2)
+			ON CONFLICT (id) DO NOTHING
+			RETURNING id
+		)
+		INSERT INTO org_members (org_id, user_id, role)
+		SELECT id, $3, $4 FROM new_org`
+
+	tag, err := s.pool.Exec(ctx, stmt, org.ID, org.Name, ctx.Value(common.KeyUserID), models.RoleOwner)
+	if err != nil {
+		return fmt.Errorf("failed to execute insert query: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrOrgAlreadyExist
+	}
+
+	return nil
+}
+
+// FetchUserOrgs получает организации, в которых состоит пользователь.
+func (s *DBStorage) FetchUserOrgs(ctx context.Context) ([]models.UserOrg, error) {
+	const queryStmt = `SELECT o.id, o.name, m.role
+		FROM org_members m
+		JOIN orgs o ON o.id = m.org_id
+		WHERE m.user_id = $1
+		ORDER BY o.id`
+
+	orgs := []models.UserOrg{}
+
+	rows, err := s.pool.Query(ctx, queryStmt, ctx.Value(common.KeyUserID))
+	if err != nil {
+		return []models.UserOrg{}, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var o models.UserOrg
+		if err := rows.Scan(&o.ID, &o.Name, &o.Role); err != nil {
+			return []models.UserOrg{}, fmt.Errorf("failed to scan query: %w", err)
+		}
+
+		orgs = append(orgs, o)
+	}
+
+	if err := rows.Err(); err != nil {
+		return []models.UserOrg{}, fmt.Errorf("failed to read query: %w", err)
+	}
+
+	return orgs, nil
+}
+
+// GetOrgRole получает роль пользователя из контекста в организации.
+func (s *DBStorage) GetOrgRole(ctx context.Context, orgID string) (string, error) {
+	const queryStmt = `SELECT role FROM org_members WHERE org_id = $1 AND user_id = $2`
+
+	row := s.pool.QueryRow(ctx, queryStmt, orgID, ctx.Value(common.KeyUserID))
+
+	var role string
+	if err := row.Scan(&role); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", fmt.Errorf("%w for org %s", ErrMemberNotFound, orgID)
+		}
+
+		return "", fmt.Errorf("failed to scan a response row: %w", err)
+	}
+
+	return role, nil
+}
+
+// FetchOrgMembers получает участников организации.
+func (s *DBStorage) FetchOrgMembers(ctx context.Context, orgID string) ([]models.Member, error) {
+	const queryStmt = `SELECT org_id, user_id, role
+		FROM org_members
+		WHERE org_id = $1
+		ORDER BY user_id`
+
+	members := []models.Member{}
+
+	rows, err := s.pool.Query(ctx, queryStmt, orgID)
+	if err != nil {
+		return []models.Member{}, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m models.Member
+		if err := rows.Scan(&m.OrgID, &m.UserID, &m.Role); err != nil {
+			return []models.Member{}, fmt.Errorf("failed to scan query: %w", err)
+		}
+
+		members = append(members, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return []models.Member{}, fmt.Errorf("failed to read query: %w", err)
+	}
+
+	return members, nil
+}
+
+// StoreOrgMember добавляет участника организации или меняет его роль.
+func (s *DBStorage) StoreOrgMember(ctx context.Context, member models.Member) error {
+	const stmt = `INSERT INTO org_members (org_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (org_id, user_id) DO UPDATE SET role = EXCLUDED.role`
+
+	if _, err := s.pool.Exec(ctx, stmt, member.OrgID, member.UserID, member.Role); err != nil {
+		return fmt.Errorf("failed to execute insert query: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteOrgMember исключает участника из организации.
+func (s *DBStorage) DeleteOrgMember(ctx context.Context, orgID string, userID string) error {
+	const stmt = `DELETE FROM org_members WHERE org_id = $1 AND user_id = $2`
+
+	tag, err := s.pool.Exec(ctx, stmt, orgID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to execute delete query: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w for org %s", ErrMemberNotFound, orgID)
+	}
+
+	return nil
+}
+
+// FetchOrgURLs получает все ссылки организации.
+func (s *DBStorage) FetchOrgURLs(ctx context.Context, orgID string) ([]models.URL, error) {
+	const queryStmt = `SELECT id, short_url, original_url, user_id, org_id
+		FROM urls
+		WHERE org_id = $1`
+
+	urls := []models.URL{}
+
+	rows, err := s.pool.Query(ctx, queryStmt, orgID)
+	if err != nil {
+		return []models.URL{}, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var u models.URL
+		if err := rows.Scan(&u.ID, &u.ShortURL, &u.OriginalURL, &u.UserID, &u.OrgID); err != nil {
+			return []models.URL{}, fmt.Errorf("failed to scan query: %w", err)
+		}
+
+		urls = append(urls, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return []models.URL{}, fmt.Errorf("failed to read query: %w", err)
+	}
+
+	return urls, nil
+}
+
+// FetchURLsPage получает страницу ссылок пользователя (или организации, если задан orgID),
+// упорядоченных по короткой ссылке и следующих за after.
+func (s *DBStorage) FetchURLsPage(ctx context.Context, orgID string, after string, limit int) ([]models.URL, error) {
+	const userStmt = `SELECT id, short_url, original_url, user_id, COALESCE(org_id, ''), is_deleted, created_at
+		FROM urls
+		WHERE user_id = $1 AND org_id IS NULL AND short_url > $2
+		ORDER BY short_url
+		LIMIT $3`
+	const orgStmt = `SELECT id, short_url, original_url, user_id, COALESCE(org_id, ''), is_deleted, created_at
+		FROM urls
+		WHERE org_id = $1 AND short_url > $2
+		ORDER BY short_url
+		LIMIT $3`
+
+	queryStmt, owner := userStmt, ctx.Value(common.KeyUserID)
+	if orgID != "" {
+		queryStmt, owner = orgStmt, orgID
+	}
+
+	urls := []models.URL{}
+
+	rows, err := s.pool.Query(ctx, queryStmt, owner, after, limit)
+	if err != nil {
+		return []models.URL{}, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var u models.URL
+		var createdAt *time.Time
+		if err := rows.Scan(&u.ID, &u.ShortURL, &u.OriginalURL, &u.UserID, &u.OrgID, &u.DeletedFlag, &createdAt); err != nil {
+			return []models.URL{}, fmt.Errorf("failed to scan query: %w", err)
+		}
+		if createdAt != nil {
+			u.CreatedAt = createdAt.UTC()
+		}
+
+		urls = append(urls, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return []models.URL{}, fmt.Errorf("failed to read query: %w", err)
+	}
+
+	return urls, nil
+}
+
+// CountURLs получает число всех ссылок, включая удаленные.
+func (s *DBStorage) CountURLs(ctx context.Context) (int, error) {
+	const queryStmt = `SELECT count(*) FROM urls`
+
+	var count int
+
+	if err := s.pool.QueryRow(ctx, queryStmt).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to scan a response row: %w", err)
+	}
+
+	return count, nil
+}
+
+// FetchAllURLsPage получает страницу всех ссылок, упорядоченных по короткой ссылке и следующих за after.
+func (s *DBStorage) FetchAllURLsPage(ctx context.Context, after string, limit int) ([]models.URL, error) {
+	const queryStmt = `SELECT id, short_url, original_url, COALESCE(user_id, ''), COALESCE(org_id, ''), is_deleted, created_at
+		FROM urls
+		WHERE short_url > $1
+		ORDER BY short_url
+		LIMIT $2`
+
+	urls := []models.URL{}
+
+	rows, err := s.pool.Query(ctx, queryStmt, after, limit)
+	if err != nil {
+		return []models.URL{}, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var u models.URL
+		var createdAt *time.Time
+		if err := rows.Scan(&u.ID, &u.ShortURL, &u.OriginalURL, &u.UserID, &u.OrgID, &u.DeletedFlag, &createdAt); err != nil {
+			return []models.URL{}, fmt.Errorf("failed to scan query: %w", err)
+		}
+		if createdAt != nil {
+			u.CreatedAt = createdAt.UTC()
+		}
+
+		urls = append(urls, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return []models.URL{}, fmt.Errorf("failed to read query: %w", err)
+	}
+
+	return urls, nil
+}
+
+// ImportURLs сохраняет ссылки как есть, уже существующие короткие ссылки пропускаются.
+func (s *DBStorage) ImportURLs(ctx context.Context, urls []models.URL) (int, error) {
+	const stmt = `INSERT INTO urls (short_url, original_url, user_id, org_id, is_deleted, created_at)
+		SELECT u.short_url, u.original_url, NULLIF(u.user_id, ''), NULLIF(u.org_id, ''), u.is_deleted, u.created_at
+		FROM unnest($1::text[], $2::text[], $3::text[], $4::text[], $5::bool[], $6::timestamptz[])
+			AS u(short_url, original_url, user_id, org_id, is_deleted, created_at)
+		WHERE NOT EXISTS (SELECT 1 FROM urls WHERE urls.short_url = u.short_url)
+		ON CONFLICT DO NOTHING`
+
+	shortURLs := make([]string, 0, len(urls))
+	originalURLs := make([]string, 0, len(urls))
+	userIDs := make([]string, 0, len(urls))
+	orgIDs := make([]string, 0, len(urls))
+	deleted := make([]bool, 0, len(urls))
+	createdAt := make([]*time.Time, 0, len(urls))
+
+	for _, u := range urls {
+		shortURLs = append(shortURLs, u.ShortURL)
+		originalURLs = append(originalURLs, u.OriginalURL)
+		userIDs = append(userIDs, u.UserID)
+		orgIDs = append(orgIDs, u.OrgID)
+		deleted = append(deleted, u.DeletedFlag)
+		createdAt = append(createdAt, nullTime(u.CreatedAt))
+	}
+
+	tag, err := s.pool.Exec(ctx, stmt, shortURLs, originalURLs, userIDs, orgIDs, deleted, createdAt)
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute insert query: %w", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// FetchAllOrgs получает все организации и их участников.
+func (s *DBStorage) FetchAllOrgs(ctx context.Context) ([]models.Org, []models.Member, error) {
+	const orgsStmt = `SELECT id, name FROM orgs ORDER BY id`
+	const membersStmt = `SELECT org_id, user_id, role FROM org_members ORDER BY org_id, user_id`
+
+	orgs := []models.Org{}
+
+	rows, err := s.pool.Query(ctx, orgsStmt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var o models.Org
+		if err := rows.Scan(&o.ID, &o.Name); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan query: %w", err)
+		}
+
+		orgs = append(orgs, o)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read query: %w", err)
+	}
+
+	members := []models.Member{}
+
+	memberRows, err := s.pool.Query(ctx, membersStmt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer memberRows.Close()
+
+	for memberRows.Next() {
+		var m models.Member
+		if err := memberRows.Scan(&m.OrgID, &m.UserID, &m.Role); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan query: %w", err)
+		}
+
+		members = append(members, m)
+	}
+
+	if err := memberRows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read query: %w", err)
+	}
+
+	return orgs, members, nil
+}
+
+// ImportOrgs сохраняет организации и участников как есть, уже существующие пропускаются.
+func (s *DBStorage) ImportOrgs(ctx context.Context, orgs []models.Org, members []models.Member) error {
+	const orgsStmt = `INSERT INTO orgs (id, name)
+		SELECT * FROM unnest($1::text[], $2::text[])
+		ON CONFLICT (id) DO NOTHING`
+	const membersStmt = `INSERT INTO org_members (org_id, user_id, role)
+		SELECT * FROM unnest($1::text[], $2::text[], $3::text[])
+		ON CONFLICT (org_id, user_id) DO NOTHING`
+
+	ids := make([]string, 0, len(orgs))
+	names := make([]string, 0, len(orgs))
+	for _, o := range orgs {
+		ids = append(ids, o.ID)
+		names = append(names, o.Name)
+	}
+
+	if _, err := s.pool.Exec(ctx, orgsStmt, ids, names); err != nil {
+		return fmt.Errorf("failed to execute insert query: %w", err)
+	}
+
+	orgIDs := make([]string, 0, len(members))
+	userIDs := make([]string, 0, len(members))
+	roles := make([]string, 0, len(members))
+	for _, m := range members {
+		orgIDs = append(orgIDs, m.OrgID)
+		userIDs = append(userIDs, m.UserID)
+		roles = append(roles, m.Role)
+	}
+
+	if _, err := s.pool.Exec(ctx, membersStmt, orgIDs, userIDs, roles); err != nil {
+		return fmt.Errorf("failed to execute insert query: %w", err)
+	}
+
+	return nil
+}
+
 // Ping проверяет работоспособность БД.
 func (s *DBStorage) Ping(ctx context.Context) error {
 	if err := s.pool.Ping(ctx); err != nil {
@@ -246,19 +728,320 @@ func (s *DBStorage) Ping(ctx context.Context) error {
 	return nil
 }
 
+// StoreAuditEvents записывает события в журнал аудита.
+func (s *DBStorage) StoreAuditEvents(ctx context.Context, events []models.AuditEvent) error {
+	const stmt = `INSERT INTO audit_events
+		(time, action, user_id, transport, client_ip, request_id, short_url, original_url)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	batch := &pgx.Batch{}
+
+	for _, e := range events {
+		batch.Queue(stmt, e.Time, e.Action, e.UserID, e.Transport, e.ClientIP, e.RequestID, e.ShortURL, e.OriginalURL)
+	}
+
+	result := s.pool.SendBatch(ctx, batch)
+	defer func() {
+		if err := result.Close(); err != nil {
+			logger.FromContext(ctx, s.logger).Error("failed to close batch result", zap.Error(err))
+		}
+	}()
+
+	_, err := result.Exec()
+	if err != nil {
+		return fmt.Errorf("unable to insert audit batch: %w", err)
+	}
+
+	return nil
+}
+
+// FetchAuditEvents получает события журнала аудита по фильтру, новые первыми.
+func (s *DBStorage) FetchAuditEvents(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error) {
+	const queryStmt = `SELECT time, action, user_id, transport, client_ip, request_id, short_url, original_url
+		FROM audit_events
+		WHERE ($1::timestamptz IS NULL OR time >= $1) AND ($2::timestamptz IS NULL OR time < $2)
+			AND ($3 = '' OR action = $3) AND ($4 = '' OR user_id = $4)
+			AND ($5 = '' OR short_url = $5) AND ($6 = '' OR transport = $6)
+		ORDER BY time DESC, id DESC
+		LIMIT NULLIF($7, 0)`
+
+	rows, err := s.pool.Query(ctx, queryStmt, nullTime(filter.From), nullTime(filter.To),
+		filter.Action, filter.UserID, filter.ShortURL, filter.Transport, filter.Limit)
+	if err != nil {
+		return []models.AuditEvent{}, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.AuditEvent{}
+	for rows.Next() {
+		var e models.AuditEvent
+		err := rows.Scan(&e.Time, &e.Action, &e.UserID, &e.Transport, &e.ClientIP, &e.RequestID, &e.ShortURL, &e.OriginalURL)
+		if err != nil {
+			return []models.AuditEvent{}, fmt.Errorf("failed to scan query: %w", err)
+		}
+		e.Time = e.Time.UTC()
+
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return []models.AuditEvent{}, fmt.Errorf("failed to read query: %w", err)
+	}
+
+	return events, nil
+}
+
+// DropAuditEvents удаляет события журнала аудита раньше before.
+func (s *DBStorage) DropAuditEvents(ctx context.Context, before time.Time) (int, error) {
+	const stmt = `DELETE FROM audit_events WHERE time < $1`
+
+	tag, err := s.pool.Exec(ctx, stmt, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute drop query: %w", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// StoreWebhook сохраняет вебхук пользователя.
+func (s *DBStorage) StoreWebhook(ctx context.Context, webhook models.Webhook) error {
+	const stmt = `INSERT INTO webhooks (id, user_id, url, secret, events, created_at) VALUES ($1, $2, $3, $4, $5, $6)`
+
+	_, err := s.pool.Exec(ctx, stmt,
+		webhook.ID, webhook.UserID, webhook.URL, webhook.Secret, webhook.Events, webhook.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to execute insert query: %w", err)
+	}
+
+	return nil
+}
+
+// FetchUserWebhooks получает вебхуки пользователя из контекста.
+func (s *DBStorage) FetchUserWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	const queryStmt = `SELECT id, user_id, url, secret, events, created_at
+		FROM webhooks WHERE user_id = $1 ORDER BY id`
+
+	return s.queryWebhooks(ctx, queryStmt, ctx.Value(common.KeyUserID))
+}
+
+// UpdateWebhook меняет адрес, ключ и события вебхука пользователя из контекста.
+func (s *DBStorage) UpdateWebhook(ctx context.Context, webhook models.Webhook) error {
+	const stmt = `UPDATE webhooks SET url = $1, secret = $2, events = $3 WHERE id = $4 AND user_id = $5`
+
+	tag, err := s.pool.Exec(ctx, stmt,
+		webhook.URL, webhook.Secret, webhook.Events, webhook.ID, ctx.Value(common.KeyUserID))
+	if err != nil {
+		return fmt.Errorf("failed to execute update query: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// DeleteWebhook удаляет вебхук пользователя из контекста, доставки удаляются каскадно.
+func (s *DBStorage) DeleteWebhook(ctx context.Context, id string) error {
+	const stmt = `DELETE FROM webhooks WHERE id = $1 AND user_id = $2`
+
+	tag, err := s.pool.Exec(ctx, stmt, id, ctx.Value(common.KeyUserID))
+	if err != nil {
+		return fmt.Errorf("failed to execute delete query: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// FetchEventWebhooks получает вебхуки пользователя userID, подписанные на событие event.
+func (s *DBStorage) FetchEventWebhooks(ctx context.Context, userID string, event string) ([]models.Webhook, error) {
+	const queryStmt = `SELECT id, user_id, url, secret, events, created_at
+		FROM webhooks WHERE user_id = $1 AND $2 = ANY(events) ORDER BY id`
+
+	return s.queryWebhooks(ctx, queryStmt, userID, event)
+}
+
+func (s *DBStorage) queryWebhooks(ctx context.Context, queryStmt string, args ...any) ([]models.Webhook, error) {
+	rows, err := s.pool.Query(ctx, queryStmt, args...)
+	if err != nil {
+		return []models.Webhook{}, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := []models.Webhook{}
+	for rows.Next() {
+		var w models.Webhook
+		if err := rows.Scan(&w.ID, &w.UserID, &w.URL, &w.Secret, &w.Events, &w.CreatedAt); err != nil {
+			return []models.Webhook{}, fmt.Errorf("failed to scan query: %w", err)
+		}
+
+		webhooks = append(webhooks, w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return []models.Webhook{}, fmt.Errorf("failed to read query: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+// StoreWebhookDeliveries добавляет доставки в исходящую очередь вебхуков.
+func (s *DBStorage) StoreWebhookDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	const stmt = `INSERT INTO webhook_deliveries
+		(id, webhook_id, event, payload, attempts, next_attempt, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	batch := &pgx.Batch{}
+
+	for _, d := range deliveries {
+		batch.Queue(stmt, d.ID, d.WebhookID, d.Event, d.Payload, d.Attempts, d.NextAttempt, d.CreatedAt)
+	}
+
+	result := s.pool.SendBatch(ctx, batch)
+	defer func() {
+		if err := result.Close(); err != nil {
+			logger.FromContext(ctx, s.logger).Error("failed to close batch result", zap.Error(err))
+		}
+	}()
+
+	_, err := result.Exec()
+	if err != nil {
+		return fmt.Errorf("unable to insert deliveries batch: %w", err)
+	}
+
+	return nil
+}
+
+// FetchDueWebhookDeliveries получает до limit доставок, время попытки которых наступило к now, старые первыми.
+func (s *DBStorage) FetchDueWebhookDeliveries(
+	ctx context.Context,
+	now time.Time,
+	limit int,
+) ([]models.WebhookDelivery, error) {
+	const queryStmt = `SELECT d.id, d.webhook_id, d.event, d.payload, d.attempts, d.next_attempt,
+			d.last_error, d.dead, d.created_at, w.url, w.secret
+		FROM webhook_deliveries d
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE NOT d.dead AND d.next_attempt <= $1
+		ORDER BY d.next_attempt
+		LIMIT $2`
+
+	return s.queryDeliveries(ctx, queryStmt, now, limit)
+}
+
+// UpdateWebhookDelivery сохраняет результат попытки доставки.
+func (s *DBStorage) UpdateWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) error {
+	const stmt = `UPDATE webhook_deliveries
+		SET attempts = $1, next_attempt = $2, last_error = $3, dead = $4
+		WHERE id = $5`
+
+	_, err := s.pool.Exec(ctx, stmt,
+		delivery.Attempts, delivery.NextAttempt, delivery.LastError, delivery.Dead, delivery.ID)
+	if err != nil {
+		return fmt.Errorf("failed to execute update query: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteWebhookDelivery удаляет выполненную доставку из очереди.
+func (s *DBStorage) DeleteWebhookDelivery(ctx context.Context, id string) error {
+	const stmt = `DELETE FROM webhook_deliveries WHERE id = $1`
+
+	if _, err := s.pool.Exec(ctx, stmt, id); err != nil {
+		return fmt.Errorf("failed to execute delete query: %w", err)
+	}
+
+	return nil
+}
+
+// FetchDeadWebhookDeliveries получает недоставленные события вебхуков пользователя из контекста, новые первыми.
+func (s *DBStorage) FetchDeadWebhookDeliveries(ctx context.Context) ([]models.WebhookDelivery, error) {
+	const queryStmt = `SELECT d.id, d.webhook_id, d.event, d.payload, d.attempts, d.next_attempt,
+			d.last_error, d.dead, d.created_at, w.url, w.secret
+		FROM webhook_deliveries d
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.dead AND w.user_id = $1
+		ORDER BY d.created_at DESC`
+
+	return s.queryDeliveries(ctx, queryStmt, ctx.Value(common.KeyUserID))
+}
+
+func (s *DBStorage) queryDeliveries(
+	ctx context.Context,
+	queryStmt string,
+	args ...any,
+) ([]models.WebhookDelivery, error) {
+	rows, err := s.pool.Query(ctx, queryStmt, args...)
+	if err != nil {
+		return []models.WebhookDelivery{}, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var d models.WebhookDelivery
+		err := rows.Scan(&d.ID, &d.WebhookID, &d.Event, &d.Payload, &d.Attempts, &d.NextAttempt,
+			&d.LastError, &d.Dead, &d.CreatedAt, &d.URL, &d.Secret)
+		if err != nil {
+			return []models.WebhookDelivery{}, fmt.Errorf("failed to scan query: %w", err)
+		}
+
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return []models.WebhookDelivery{}, fmt.Errorf("failed to read query: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// HealthDetails проверяет подключение к БД и возвращает статистику пула соединений.
+func (s *DBStorage) HealthDetails(ctx context.Context) (map[string]any, error) {
+	details := map[string]any{"type": "postgres"}
+
+	if pool, ok := s.pool.(*pgxpool.Pool); ok {
+		stat := pool.Stat()
+		details["total_conns"] = stat.TotalConns()
+		details["idle_conns"] = stat.IdleConns()
+		details["acquired_conns"] = stat.AcquiredConns()
+		details["max_conns"] = stat.MaxConns()
+	}
+
+	if err := s.Ping(ctx); err != nil {
+		return details, err
+	}
+
+	return details, nil
+}
+
 // Close закрывает соединение с БД.
 func (s *DBStorage) Close() error {
 	s.pool.Close()
 	return nil
 }
 
+// nullTime возвращает nil для нулевого времени, такое время сохраняется в БД как NULL.
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
 func initPool(ctx context.Context, logger *zap.Logger, dbDSN string) (*pgxpool.Pool, error) {
 	poolCfg, err := pgxpool.ParseConfig(dbDSN)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse the DSN: %w", err)
 	}
 
-	poolCfg.ConnConfig.Tracer = &queryTracer{logger: logger}
+	poolCfg.ConnConfig.Tracer = tracers{&queryTracer{logger: logger, logArgs: config.Params.LogQueryArgs}, spanTracer{}}
 	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize a connection pool: %w", err)
@@ -270,3 +1053,43 @@ func initPool(ctx context.Context, logger *zap.Logger, dbDSN string) (*pgxpool.P
 
 	return pool, nil
 }
+
+// FetchOutboxEvents получает до limit первых событий исходящей очереди изменений ссылок.
+func (s *DBStorage) FetchOutboxEvents(ctx context.Context, limit int) ([]models.OutboxEvent, error) {
+	const queryStmt = `SELECT id, event, short_url, original_url, COALESCE(user_id, ''), COALESCE(org_id, ''), created_at
+		FROM outbox ORDER BY id LIMIT $1`
+
+	rows, err := s.pool.Query(ctx, queryStmt, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.OutboxEvent{}
+	for rows.Next() {
+		var e models.OutboxEvent
+		err = rows.Scan(&e.ID, &e.Event, &e.ShortURL, &e.OriginalURL, &e.UserID, &e.OrgID, &e.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan query: %w", err)
+		}
+
+		events = append(events, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read query: %w", err)
+	}
+
+	return events, nil
+}
+
+// DeleteOutboxEvents удаляет опубликованные события из исходящей очереди.
+func (s *DBStorage) DeleteOutboxEvents(ctx context.Context, ids []int64) error {
+	const stmt = `DELETE FROM outbox WHERE id = ANY($1)`
+
+	if _, err := s.pool.Exec(ctx, stmt, ids); err != nil {
+		return fmt.Errorf("failed to execute delete query: %w", err)
+	}
+
+	return nil
+}
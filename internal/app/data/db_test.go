@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/MihailSergeenkov/shortener/internal/app/common"
 	"github.com/MihailSergeenkov/shortener/internal/app/data/mock"
@@ -11,6 +12,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
@@ -49,7 +51,7 @@ func TestDBStoreShortURL(t *testing.T) {
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			pool.EXPECT().QueryRow(ctx, stmt, shortURL, originalURL, currentUserID).Times(1).Return(row)
+			pool.EXPECT().QueryRow(ctx, stmt, shortURL, originalURL, currentUserID, "").Times(1).Return(row)
 
 			row.EXPECT().Scan(gomock.Any()).Times(1).Return(test.rowErr)
 
@@ -79,35 +81,67 @@ func TestDBStoreShortURLs(t *testing.T) {
 			OriginalURL: "some_url",
 			UserID:      "some_id",
 		},
+		{
+			ShortURL:    "other_short_url",
+			OriginalURL: "other_url",
+			UserID:      "some_id",
+		},
 	}
+	conflictErr := &pgconn.PgError{Code: uniqueViolationCode, ConstraintName: shortURLIndex}
 	tests := []struct {
-		name      string
-		wantErr   bool
-		resultErr error
+		resultErr  error
+		closeErr   error
+		wantErrIs  error
+		name       string
+		execCalls  int
+		wantErr    bool
+		secondFail bool
 	}{
 		{
 			name:      "success store",
-			wantErr:   false,
-			resultErr: nil,
+			execCalls: 2,
 		},
 		{
 			name:      "failed exec batch",
 			wantErr:   true,
 			resultErr: errors.New("some error"),
+			execCalls: 1,
+		},
+		{
+			name:       "short url conflict in second row",
+			wantErr:    true,
+			resultErr:  conflictErr,
+			wantErrIs:  ErrShortURLAlreadyExist,
+			execCalls:  2,
+			secondFail: true,
+		},
+		{
+			name:      "failed close batch",
+			wantErr:   true,
+			closeErr:  errors.New("some error"),
+			execCalls: 2,
 		},
 	}
-	for _, test := range tests { //nolint:dupl // Если устранить дублирование, код будет очень запутанным
+	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			pool.EXPECT().SendBatch(ctx, gomock.Any()).Times(1).Return(batchResults)
 
-			batchResults.EXPECT().Exec().Times(1).Return(pgconn.CommandTag{}, test.resultErr)
-			batchResults.EXPECT().Close().Times(1)
+			if test.secondFail {
+				first := batchResults.EXPECT().Exec().Times(1).Return(pgconn.CommandTag{}, nil)
+				batchResults.EXPECT().Exec().Times(1).After(first).Return(pgconn.CommandTag{}, test.resultErr)
+			} else {
+				batchResults.EXPECT().Exec().Times(test.execCalls).Return(pgconn.CommandTag{}, test.resultErr)
+			}
+			batchResults.EXPECT().Close().Times(1).Return(test.closeErr)
 
 			err := storage.StoreShortURLs(ctx, urls)
 
 			if test.wantErr {
 				require.Error(t, err)
 				require.ErrorContains(t, err, "unable to insert batch")
+				if test.wantErrIs != nil {
+					require.ErrorIs(t, err, test.wantErrIs)
+				}
 			} else {
 				require.NoError(t, err)
 			}
@@ -175,7 +209,7 @@ func TestDBGetURL(t *testing.T) {
 		logger: logger,
 	}
 	ctx := context.Background()
-	stmt := `SELECT id, short_url, original_url, is_deleted, user_id
+	stmt := `SELECT id, short_url, original_url, is_deleted, user_id, COALESCE(org_id, '')
 		FROM urls
 		WHERE short_url = $1
 		LIMIT 1`
@@ -240,7 +274,7 @@ func TestDBFetchUserURLs(t *testing.T) {
 	ctx := context.WithValue(context.Background(), common.KeyUserID, currentUserID)
 	stmt := `SELECT id, short_url, original_url, user_id
 		FROM urls
-		WHERE user_id = $1`
+		WHERE user_id = $1 AND org_id IS NULL`
 
 	rows := mock.NewMockRows(mockCtrl)
 
@@ -297,7 +331,7 @@ func TestDBFetchUserURLs_Failed(t *testing.T) {
 	ctx := context.WithValue(context.Background(), common.KeyUserID, currentUserID)
 	stmt := `SELECT id, short_url, original_url, user_id
 		FROM urls
-		WHERE user_id = $1`
+		WHERE user_id = $1 AND org_id IS NULL`
 
 	rows := mock.NewMockRows(mockCtrl)
 	someErr := errors.New("some error")
@@ -312,6 +346,87 @@ func TestDBFetchUserURLs_Failed(t *testing.T) {
 	})
 }
 
+func TestDBFetchURLsPage(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	pool := mock.NewMockDBPooler(mockCtrl)
+	storage := DBStorage{
+		pool:   pool,
+		logger: zap.NewNop(),
+	}
+	currentUserID := "some_id"
+	ctx := context.WithValue(context.Background(), common.KeyUserID, currentUserID)
+	userStmt := `SELECT id, short_url, original_url, user_id, COALESCE(org_id, ''), is_deleted, created_at
+		FROM urls
+		WHERE user_id = $1 AND org_id IS NULL AND short_url > $2
+		ORDER BY short_url
+		LIMIT $3`
+	orgStmt := `SELECT id, short_url, original_url, user_id, COALESCE(org_id, ''), is_deleted, created_at
+		FROM urls
+		WHERE org_id = $1 AND short_url > $2
+		ORDER BY short_url
+		LIMIT $3`
+
+	rows := mock.NewMockRows(mockCtrl)
+	someErr := errors.New("some error")
+
+	tests := []struct {
+		name     string
+		orgID    string
+		stmt     string
+		owner    any
+		queryErr error
+		rowsErr  error
+		errText  string
+	}{
+		{
+			name:  "success user page",
+			stmt:  userStmt,
+			owner: currentUserID,
+		},
+		{
+			name:  "success org page",
+			orgID: "org",
+			stmt:  orgStmt,
+			owner: "org",
+		},
+		{
+			name:     "failed query",
+			stmt:     userStmt,
+			owner:    currentUserID,
+			queryErr: someErr,
+			errText:  "failed to execute query",
+		},
+		{
+			name:    "failed read rows",
+			stmt:    userStmt,
+			owner:   currentUserID,
+			rowsErr: someErr,
+			errText: "failed to read query",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pool.EXPECT().Query(ctx, test.stmt, test.owner, "after", 10).Times(1).Return(rows, test.queryErr)
+
+			if test.queryErr == nil {
+				rows.EXPECT().Close().Times(1)
+				rows.EXPECT().Next().Times(1).Return(false)
+				rows.EXPECT().Err().Times(1).Return(test.rowsErr)
+			}
+
+			_, err := storage.FetchURLsPage(ctx, test.orgID, "after", 10)
+
+			if test.errText != "" {
+				require.ErrorContains(t, err, test.errText)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestDBDropDeletedURLs(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -409,6 +524,97 @@ func TestDBFetchStats(t *testing.T) {
 	}
 }
 
+func TestDBConsumeDailyQuota(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	pool := mock.NewMockDBPooler(mockCtrl)
+	logger := zap.NewNop()
+	storage := DBStorage{
+		pool:   pool,
+		logger: logger,
+	}
+	ctx := context.Background()
+	row := mock.NewMockRow(mockCtrl)
+	limit := 10
+
+	tests := []struct {
+		name    string
+		rowErr  error
+		wantErr error
+		errText string
+	}{
+		{
+			name:    "success consume",
+			rowErr:  nil,
+			wantErr: nil,
+			errText: "",
+		},
+		{
+			name:    "quota exceeded",
+			rowErr:  pgx.ErrNoRows,
+			wantErr: ErrQuotaExceeded,
+			errText: "daily quota exceeded",
+		},
+		{
+			name:    "failed read row",
+			rowErr:  errors.New("some error"),
+			wantErr: nil,
+			errText: "failed to scan a response row",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pool.EXPECT().QueryRow(ctx, gomock.Any(), "user:some_id", gomock.Any(), 1, limit).Times(1).Return(row)
+
+			row.EXPECT().Scan(gomock.Any()).Times(1).Return(test.rowErr)
+
+			err := storage.ConsumeDailyQuota(ctx, "user:some_id", 1, limit)
+
+			if test.errText == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorContains(t, err, test.errText)
+			if test.wantErr != nil {
+				require.ErrorIs(t, err, test.wantErr)
+			}
+		})
+	}
+
+	t.Run("count over limit", func(t *testing.T) {
+		err := storage.ConsumeDailyQuota(ctx, "user:some_id", limit+1, limit)
+
+		require.ErrorIs(t, err, ErrQuotaExceeded)
+	})
+}
+
+func TestDBRefundDailyQuota(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	pool := mock.NewMockDBPooler(mockCtrl)
+	storage := DBStorage{
+		pool:   pool,
+		logger: zap.NewNop(),
+	}
+	ctx := context.Background()
+
+	t.Run("success refund", func(t *testing.T) {
+		pool.EXPECT().Exec(ctx, gomock.Any(), "user:some_id", today(), 2).Times(1).Return(pgconn.CommandTag{}, nil)
+
+		require.NoError(t, storage.RefundDailyQuota(ctx, "user:some_id", 2))
+	})
+
+	t.Run("failed refund", func(t *testing.T) {
+		pool.EXPECT().Exec(ctx, gomock.Any(), "user:some_id", today(), 2).Times(1).
+			Return(pgconn.CommandTag{}, errors.New("some error"))
+
+		require.ErrorContains(t, storage.RefundDailyQuota(ctx, "user:some_id", 2), "failed to refund quota")
+	})
+}
+
 func TestDBPing(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -471,3 +677,418 @@ func TestDBClose(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestDBStoreOrg(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	pool := mock.NewMockDBPooler(mockCtrl)
+	storage := DBStorage{
+		pool:   pool,
+		logger: zap.NewNop(),
+	}
+	currentUserID := "some_id"
+	ctx := context.WithValue(context.Background(), common.KeyUserID, currentUserID)
+	org := models.Org{ID: "org_id", Name: "Team"}
+
+	tests := []struct {
+		name    string
+		tag     pgconn.CommandTag
+		execErr error
+		wantErr error
+		errText string
+	}{
+		{
+			name:    "success store",
+			tag:     pgconn.NewCommandTag("INSERT 0 1"),
+			execErr: nil,
+			wantErr: nil,
+			errText: "",
+		},
+		{
+			name:    "org already exist",
+			tag:     pgconn.NewCommandTag("INSERT 0 0"),
+			execErr: nil,
+			wantErr: ErrOrgAlreadyExist,
+			errText: "org already exist",
+		},
+		{
+			name:    "failed exec",
+			tag:     pgconn.CommandTag{},
+			execErr: errors.New("some error"),
+			wantErr: nil,
+			errText: "failed to execute insert query",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pool.EXPECT().
+				Exec(ctx, gomock.Any(), org.ID, org.Name, currentUserID, models.RoleOwner).
+				Times(1).
+				Return(test.tag, test.execErr)
+
+			err := storage.StoreOrg(ctx, org)
+
+			if test.errText == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorContains(t, err, test.errText)
+			if test.wantErr != nil {
+				require.ErrorIs(t, err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestDBGetOrgRole(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	pool := mock.NewMockDBPooler(mockCtrl)
+	storage := DBStorage{
+		pool:   pool,
+		logger: zap.NewNop(),
+	}
+	currentUserID := "some_id"
+	ctx := context.WithValue(context.Background(), common.KeyUserID, currentUserID)
+	row := mock.NewMockRow(mockCtrl)
+
+	tests := []struct {
+		name    string
+		rowErr  error
+		wantErr error
+		errText string
+	}{
+		{
+			name:    "success get",
+			rowErr:  nil,
+			wantErr: nil,
+			errText: "",
+		},
+		{
+			name:    "member not found",
+			rowErr:  pgx.ErrNoRows,
+			wantErr: ErrMemberNotFound,
+			errText: "org member not found",
+		},
+		{
+			name:    "failed read row",
+			rowErr:  errors.New("some error"),
+			wantErr: nil,
+			errText: "failed to scan a response row",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pool.EXPECT().QueryRow(ctx, gomock.Any(), "org_id", currentUserID).Times(1).Return(row)
+
+			row.EXPECT().Scan(gomock.Any()).Times(1).Return(test.rowErr)
+
+			_, err := storage.GetOrgRole(ctx, "org_id")
+
+			if test.errText == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorContains(t, err, test.errText)
+			if test.wantErr != nil {
+				require.ErrorIs(t, err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestDBOrgMembers(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	pool := mock.NewMockDBPooler(mockCtrl)
+	storage := DBStorage{
+		pool:   pool,
+		logger: zap.NewNop(),
+	}
+	ctx := context.Background()
+	rows := mock.NewMockRows(mockCtrl)
+	member := models.Member{OrgID: "org_id", UserID: "some_id", Role: models.RoleEditor}
+
+	t.Run("fetch members", func(t *testing.T) {
+		pool.EXPECT().Query(ctx, gomock.Any(), member.OrgID).Times(1).Return(rows, nil)
+
+		rows.EXPECT().Close().Times(1)
+		rows.EXPECT().Next().Times(1).Return(false)
+		rows.EXPECT().Err().Times(1).Return(nil)
+
+		members, err := storage.FetchOrgMembers(ctx, member.OrgID)
+		require.NoError(t, err)
+		require.Empty(t, members)
+	})
+
+	t.Run("store member", func(t *testing.T) {
+		pool.EXPECT().
+			Exec(ctx, gomock.Any(), member.OrgID, member.UserID, member.Role).
+			Times(1).
+			Return(pgconn.NewCommandTag("INSERT 0 1"), nil)
+
+		require.NoError(t, storage.StoreOrgMember(ctx, member))
+	})
+
+	t.Run("delete member", func(t *testing.T) {
+		pool.EXPECT().
+			Exec(ctx, gomock.Any(), member.OrgID, member.UserID).
+			Times(1).
+			Return(pgconn.NewCommandTag("DELETE 1"), nil)
+
+		require.NoError(t, storage.DeleteOrgMember(ctx, member.OrgID, member.UserID))
+	})
+
+	t.Run("delete missing member", func(t *testing.T) {
+		pool.EXPECT().
+			Exec(ctx, gomock.Any(), member.OrgID, member.UserID).
+			Times(1).
+			Return(pgconn.NewCommandTag("DELETE 0"), nil)
+
+		err := storage.DeleteOrgMember(ctx, member.OrgID, member.UserID)
+		require.ErrorIs(t, err, ErrMemberNotFound)
+	})
+}
+
+func TestDBMigratorMethods(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	pool := mock.NewMockDBPooler(mockCtrl)
+	storage := DBStorage{
+		pool:   pool,
+		logger: zap.NewNop(),
+	}
+	ctx := context.Background()
+	someErr := errors.New("some error")
+
+	t.Run("count urls", func(t *testing.T) {
+		row := mock.NewMockRow(mockCtrl)
+		pool.EXPECT().QueryRow(ctx, `SELECT count(*) FROM urls`).Times(2).Return(row)
+		row.EXPECT().Scan(gomock.Any()).Times(1).Return(nil)
+		row.EXPECT().Scan(gomock.Any()).Times(1).Return(someErr)
+
+		_, err := storage.CountURLs(ctx)
+		require.NoError(t, err)
+
+		_, err = storage.CountURLs(ctx)
+		require.ErrorContains(t, err, "failed to scan a response row")
+	})
+
+	t.Run("fetch all urls page", func(t *testing.T) {
+		rows := mock.NewMockRows(mockCtrl)
+		pool.EXPECT().Query(ctx, gomock.Any(), "after", 10).Times(1).Return(rows, nil)
+		pool.EXPECT().Query(ctx, gomock.Any(), "after", 10).Times(1).Return(nil, someErr)
+		rows.EXPECT().Close().Times(1)
+		rows.EXPECT().Next().Times(1).Return(false)
+		rows.EXPECT().Err().Times(1).Return(nil)
+
+		urls, err := storage.FetchAllURLsPage(ctx, "after", 10)
+		require.NoError(t, err)
+		assert.Empty(t, urls)
+
+		_, err = storage.FetchAllURLsPage(ctx, "after", 10)
+		require.ErrorContains(t, err, "failed to execute query")
+	})
+
+	t.Run("import urls", func(t *testing.T) {
+		createdAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+		urls := []models.URL{
+			{ShortURL: "a", OriginalURL: "https://ya.ru/a", UserID: "user_1", DeletedFlag: true, CreatedAt: createdAt},
+			{ShortURL: "b", OriginalURL: "https://ya.ru/b", UserID: "user_2", OrgID: "org_id"},
+		}
+		pool.EXPECT().
+			Exec(ctx, gomock.Any(),
+				[]string{"a", "b"},
+				[]string{"https://ya.ru/a", "https://ya.ru/b"},
+				[]string{"user_1", "user_2"},
+				[]string{"", "org_id"},
+				[]bool{true, false},
+				[]*time.Time{&createdAt, nil}).
+			Times(1).
+			Return(pgconn.NewCommandTag("INSERT 0 1"), nil)
+		pool.EXPECT().Exec(ctx, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(pgconn.CommandTag{}, someErr)
+
+		imported, err := storage.ImportURLs(ctx, urls)
+		require.NoError(t, err)
+		assert.Equal(t, 1, imported)
+
+		_, err = storage.ImportURLs(ctx, urls)
+		require.ErrorContains(t, err, "failed to execute insert query")
+	})
+
+	t.Run("fetch all orgs", func(t *testing.T) {
+		orgRows := mock.NewMockRows(mockCtrl)
+		memberRows := mock.NewMockRows(mockCtrl)
+		pool.EXPECT().Query(ctx, `SELECT id, name FROM orgs ORDER BY id`).Times(1).Return(orgRows, nil)
+		pool.EXPECT().
+			Query(ctx, `SELECT org_id, user_id, role FROM org_members ORDER BY org_id, user_id`).
+			Times(1).
+			Return(memberRows, nil)
+		for _, rows := range []*mock.MockRows{orgRows, memberRows} {
+			rows.EXPECT().Close().Times(1)
+			rows.EXPECT().Next().Times(1).Return(false)
+			rows.EXPECT().Err().Times(1).Return(nil)
+		}
+
+		orgs, members, err := storage.FetchAllOrgs(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, orgs)
+		assert.Empty(t, members)
+
+		pool.EXPECT().Query(ctx, gomock.Any()).Times(1).Return(nil, someErr)
+		_, _, err = storage.FetchAllOrgs(ctx)
+		require.ErrorContains(t, err, "failed to execute query")
+	})
+
+	t.Run("import orgs", func(t *testing.T) {
+		orgs := []models.Org{{ID: "org_id", Name: "Team"}}
+		members := []models.Member{{OrgID: "org_id", UserID: "user_1", Role: models.RoleOwner}}
+		pool.EXPECT().Exec(ctx, gomock.Any(), []string{"org_id"}, []string{"Team"}).
+			Times(1).
+			Return(pgconn.NewCommandTag("INSERT 0 1"), nil)
+		pool.EXPECT().Exec(ctx, gomock.Any(), []string{"org_id"}, []string{"user_1"}, []string{models.RoleOwner}).
+			Times(1).
+			Return(pgconn.NewCommandTag("INSERT 0 1"), nil)
+
+		require.NoError(t, storage.ImportOrgs(ctx, orgs, members))
+
+		pool.EXPECT().Exec(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(pgconn.CommandTag{}, someErr)
+		require.ErrorContains(t, storage.ImportOrgs(ctx, orgs, members), "failed to execute insert query")
+	})
+}
+
+func TestDBDropAuditEvents(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	pool := mock.NewMockDBPooler(mockCtrl)
+	storage := DBStorage{
+		pool:   pool,
+		logger: zap.NewNop(),
+	}
+	ctx := context.Background()
+	before := time.Now()
+	stmt := `DELETE FROM audit_events WHERE time < $1`
+
+	t.Run("success drop", func(t *testing.T) {
+		pool.EXPECT().Exec(ctx, stmt, before).Times(1).Return(pgconn.NewCommandTag("DELETE 3"), nil)
+
+		dropped, err := storage.DropAuditEvents(ctx, before)
+		require.NoError(t, err)
+		assert.Equal(t, 3, dropped)
+	})
+
+	t.Run("failed drop", func(t *testing.T) {
+		pool.EXPECT().Exec(ctx, stmt, before).Times(1).Return(pgconn.CommandTag{}, errors.New("some error"))
+
+		_, err := storage.DropAuditEvents(ctx, before)
+		require.ErrorContains(t, err, "failed to execute drop query")
+	})
+}
+
+func TestDBDeleteWebhook(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	pool := mock.NewMockDBPooler(mockCtrl)
+	storage := DBStorage{
+		pool:   pool,
+		logger: zap.NewNop(),
+	}
+	ctx := context.WithValue(context.Background(), common.KeyUserID, "user_id")
+	stmt := `DELETE FROM webhooks WHERE id = $1 AND user_id = $2`
+
+	t.Run("success delete", func(t *testing.T) {
+		pool.EXPECT().Exec(ctx, stmt, "hook_id", "user_id").Times(1).Return(pgconn.NewCommandTag("DELETE 1"), nil)
+
+		require.NoError(t, storage.DeleteWebhook(ctx, "hook_id"))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		pool.EXPECT().Exec(ctx, stmt, "hook_id", "user_id").Times(1).Return(pgconn.NewCommandTag("DELETE 0"), nil)
+
+		require.ErrorIs(t, storage.DeleteWebhook(ctx, "hook_id"), ErrWebhookNotFound)
+	})
+
+	t.Run("failed delete", func(t *testing.T) {
+		pool.EXPECT().Exec(ctx, stmt, "hook_id", "user_id").Times(1).Return(pgconn.CommandTag{}, errors.New("some error"))
+
+		require.ErrorContains(t, storage.DeleteWebhook(ctx, "hook_id"), "failed to execute delete query")
+	})
+}
+
+func TestDBOutbox(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	pool := mock.NewMockDBPooler(mockCtrl)
+	storage := DBStorage{
+		pool:   pool,
+		logger: zap.NewNop(),
+	}
+	ctx := context.WithValue(context.Background(), common.KeyUserID, "user_id")
+
+	t.Run("store writes outbox only when enabled", func(t *testing.T) {
+		row := mock.NewMockRow(mockCtrl)
+		row.EXPECT().Scan(gomock.Any()).Times(2).Return(errors.New("some error"))
+
+		pool.EXPECT().QueryRow(ctx, stmt, "short", "original", "user_id", "").Times(1).Return(row)
+		require.Error(t, storage.StoreShortURL(ctx, "short", "original"))
+
+		enabled := storage
+		enabled.EnableOutbox()
+		pool.EXPECT().QueryRow(ctx, outboxStmt, "short", "original", "user_id", "").Times(1).Return(row)
+		require.Error(t, enabled.StoreShortURL(ctx, "short", "original"))
+	})
+
+	t.Run("fetch events", func(t *testing.T) {
+		queryStmt := `SELECT id, event, short_url, original_url, COALESCE(user_id, ''), COALESCE(org_id, ''), created_at
+		FROM outbox ORDER BY id LIMIT $1`
+		rows := mock.NewMockRows(mockCtrl)
+
+		pool.EXPECT().Query(ctx, queryStmt, 100).Times(1).Return(rows, nil)
+		rows.EXPECT().Close().Times(1)
+		rows.EXPECT().Next().Times(1).Return(true)
+		rows.EXPECT().Scan(gomock.Any()).Times(1).DoAndReturn(func(dest ...any) error {
+			*dest[0].(*int64) = 7
+			*dest[1].(*string) = models.LinkDeleted
+			*dest[2].(*string) = "short"
+			return nil
+		})
+		rows.EXPECT().Next().Times(1).Return(false)
+		rows.EXPECT().Err().Times(1).Return(nil)
+
+		events, err := storage.FetchOutboxEvents(ctx, 100)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, int64(7), events[0].ID)
+		assert.Equal(t, models.LinkDeleted, events[0].Event)
+		assert.Equal(t, "short", events[0].ShortURL)
+	})
+
+	t.Run("failed fetch", func(t *testing.T) {
+		pool.EXPECT().Query(ctx, gomock.Any(), 100).Times(1).Return(nil, errors.New("some error"))
+
+		_, err := storage.FetchOutboxEvents(ctx, 100)
+		require.ErrorContains(t, err, "failed to execute query")
+	})
+
+	t.Run("delete events", func(t *testing.T) {
+		stmt := `DELETE FROM outbox WHERE id = ANY($1)`
+		ids := []int64{1, 2}
+
+		pool.EXPECT().Exec(ctx, stmt, ids).Times(1).Return(pgconn.NewCommandTag("DELETE 2"), nil)
+		require.NoError(t, storage.DeleteOutboxEvents(ctx, ids))
+
+		pool.EXPECT().Exec(ctx, stmt, ids).Times(1).Return(pgconn.CommandTag{}, errors.New("some error"))
+		require.ErrorContains(t, storage.DeleteOutboxEvents(ctx, ids), "failed to execute delete query")
+	})
+}
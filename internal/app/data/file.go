@@ -7,22 +7,51 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
+	"sync"
+	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
 	"github.com/MihailSergeenkov/shortener/internal/app/models"
 )
 
 const (
 	filePerm       fs.FileMode = 0o600
 	openFileErrStr             = "failed to open file storage: %w"
+	quotaFileExt               = ".quotas"
+	orgFileExt                 = ".orgs"
+	auditFileExt               = ".audit"
+	webhookFileExt             = ".webhooks"
 )
 
+type quotaRecord struct {
+	Key  string `json:"key"`
+	Used int    `json:"used"`
+}
+
+// orgRecord запись журнала изменений организаций: создание организации, изменение или удаление участника.
+type orgRecord struct {
+	Org     *models.Org    `json:"org,omitempty"`
+	Member  *models.Member `json:"member,omitempty"`
+	Removed bool           `json:"removed,omitempty"`
+}
+
+// webhookRecord запись журнала изменений вебхуков: сохранение или удаление вебхука или доставки.
+type webhookRecord struct {
+	Webhook  *models.Webhook         `json:"webhook,omitempty"`
+	Delivery *models.WebhookDelivery `json:"delivery,omitempty"`
+	Removed  bool                    `json:"removed,omitempty"`
+}
+
 // FileStorage структура файловой БД.
 type FileStorage struct {
 	logger          *zap.Logger
 	baseStorage     BaseStorage
 	fileStoragePath string
+	quotaMu         sync.Mutex // запись и перезапись файла квот
+	auditMu         sync.Mutex // запись и перезапись файла журнала аудита
+	hooksMu         sync.Mutex // запись журнала вебхуков из обработчиков и фоновой доставки
 }
 
 // NewFileStorage инициализирует файловую БД.
@@ -54,9 +83,102 @@ func NewFileStorage(logger *zap.Logger, fsp string) (*FileStorage, error) {
 		storage.baseStorage.urls[url.ShortURL] = url
 	}
 
+	if err := storage.loadQuotas(); err != nil {
+		return &FileStorage{}, err
+	}
+
+	if err := storage.loadOrgs(); err != nil {
+		return &FileStorage{}, err
+	}
+
+	if err := storage.loadAuditEvents(); err != nil {
+		return &FileStorage{}, err
+	}
+
+	if err := storage.loadWebhooks(); err != nil {
+		return &FileStorage{}, err
+	}
+
 	return &storage, nil
 }
 
+// loadQuotas восстанавливает квоты текущего дня и перезаписывает файл без квот прошлых дней.
+func (s *FileStorage) loadQuotas() error {
+	file, err := os.OpenFile(s.fileStoragePath+quotaFileExt, os.O_RDONLY|os.O_CREATE, filePerm)
+	if err != nil {
+		return fmt.Errorf("failed to open quota storage: %w", err)
+	}
+	defer closeFile(s, file)
+
+	scanner := bufio.NewScanner(file)
+
+	records := []quotaRecord{}
+	for scanner.Scan() {
+		record := quotaRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("failed to parse quota storage: %w", err)
+		}
+
+		records = append(records, record)
+	}
+
+	s.baseStorage.quotas.restore(records)
+
+	return s.writeQuotas()
+}
+
+// writeQuotas перезаписывает файл квот квотами текущего дня.
+func (s *FileStorage) writeQuotas() error {
+	path := s.fileStoragePath + quotaFileExt
+	file, err := os.OpenFile(path+".tmp", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, filePerm)
+	if err != nil {
+		return fmt.Errorf("failed to open quota storage: %w", err)
+	}
+
+	encoder := json.NewEncoder(file)
+	for _, record := range s.baseStorage.quotas.records() {
+		if err := encoder.Encode(&record); err != nil {
+			closeFile(s, file)
+			return fmt.Errorf("failed to dump quota: %w", err)
+		}
+	}
+	closeFile(s, file)
+
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("failed to replace quota storage: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileStorage) loadOrgs() error {
+	file, err := os.OpenFile(s.fileStoragePath+orgFileExt, os.O_RDONLY|os.O_CREATE, filePerm)
+	if err != nil {
+		return fmt.Errorf("failed to open org storage: %w", err)
+	}
+	defer closeFile(s, file)
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		record := orgRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("failed to parse org storage: %w", err)
+		}
+
+		switch {
+		case record.Org != nil:
+			s.baseStorage.orgs[record.Org.ID] = *record.Org
+		case record.Member != nil && record.Removed:
+			delete(s.baseStorage.members[record.Member.OrgID], record.Member.UserID)
+		case record.Member != nil:
+			_ = s.baseStorage.StoreOrgMember(context.Background(), *record.Member)
+		}
+	}
+
+	return nil
+}
+
 // StoreShortURL сохраняет короткую ссылку.
 func (s *FileStorage) StoreShortURL(ctx context.Context, shortURL string, originalURL string) error {
 	file, err := os.OpenFile(s.fileStoragePath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, filePerm)
@@ -164,11 +286,465 @@ func (s *FileStorage) FetchStats(ctx context.Context) (int, int, error) {
 	return len(s.baseStorage.urls), len(users), nil
 }
 
+// ConsumeDailyQuota списывает дневную квоту ключа (пользователя или IP адреса) на создание ссылок.
+func (s *FileStorage) ConsumeDailyQuota(_ context.Context, key string, count int, limit int) error {
+	if err := s.updateQuota(key, count, limit); err != nil {
+		return fmt.Errorf("failed to consume quota: %w", err)
+	}
+
+	return nil
+}
+
+// RefundDailyQuota возвращает списанную сегодня квоту ключа, если ссылки не были сохранены.
+func (s *FileStorage) RefundDailyQuota(_ context.Context, key string, count int) error {
+	if err := s.updateQuota(key, -count, 0); err != nil {
+		return fmt.Errorf("failed to refund quota: %w", err)
+	}
+
+	return nil
+}
+
+// updateQuota изменяет квоту и дописывает ее новое значение в файл.
+// После смены дня файл перезаписывается, чтобы в нем не копились квоты прошлых дней.
+func (s *FileStorage) updateQuota(key string, delta int, limit int) error {
+	s.quotaMu.Lock()
+	defer s.quotaMu.Unlock()
+
+	record, dayChanged, err := s.baseStorage.quotas.update(key, delta, limit)
+	if err != nil {
+		return err
+	}
+
+	if dayChanged {
+		return s.writeQuotas()
+	}
+
+	file, err := os.OpenFile(s.fileStoragePath+quotaFileExt, os.O_WRONLY|os.O_CREATE|os.O_APPEND, filePerm)
+	if err != nil {
+		return fmt.Errorf("failed to open quota storage: %w", err)
+	}
+	defer closeFile(s, file)
+
+	if err := json.NewEncoder(file).Encode(&record); err != nil {
+		return fmt.Errorf("failed to dump quota: %w", err)
+	}
+
+	return nil
+}
+
+// StoreOrg создает организацию, пользователь из контекста становится ее владельцем.
+func (s *FileStorage) StoreOrg(ctx context.Context, org models.Org) error {
+	userID, _ := ctx.Value(common.KeyUserID).(string)
+	owner := models.Member{
+		OrgID:  org.ID,
+		UserID: userID,
+		Role:   models.RoleOwner,
+	}
+
+	return s.storeOrgRecords(func() error {
+		if err := s.baseStorage.StoreOrg(ctx, org); err != nil {
+			return fmt.Errorf("failed to add org: %w", err)
+		}
+
+		return nil
+	}, orgRecord{Org: &org}, orgRecord{Member: &owner})
+}
+
+// FetchUserOrgs получает организации, в которых состоит пользователь.
+func (s *FileStorage) FetchUserOrgs(ctx context.Context) ([]models.UserOrg, error) {
+	return s.baseStorage.FetchUserOrgs(ctx)
+}
+
+// GetOrgRole получает роль пользователя из контекста в организации.
+func (s *FileStorage) GetOrgRole(ctx context.Context, orgID string) (string, error) {
+	return s.baseStorage.GetOrgRole(ctx, orgID)
+}
+
+// FetchOrgMembers получает участников организации.
+func (s *FileStorage) FetchOrgMembers(ctx context.Context, orgID string) ([]models.Member, error) {
+	return s.baseStorage.FetchOrgMembers(ctx, orgID)
+}
+
+// StoreOrgMember добавляет участника организации или меняет его роль.
+func (s *FileStorage) StoreOrgMember(ctx context.Context, member models.Member) error {
+	return s.storeOrgRecords(func() error {
+		if err := s.baseStorage.StoreOrgMember(ctx, member); err != nil {
+			return fmt.Errorf("failed to add org member: %w", err)
+		}
+
+		return nil
+	}, orgRecord{Member: &member})
+}
+
+// DeleteOrgMember исключает участника из организации.
+func (s *FileStorage) DeleteOrgMember(ctx context.Context, orgID string, userID string) error {
+	member := models.Member{
+		OrgID:  orgID,
+		UserID: userID,
+	}
+
+	return s.storeOrgRecords(func() error {
+		if err := s.baseStorage.DeleteOrgMember(ctx, orgID, userID); err != nil {
+			return fmt.Errorf("failed to delete org member: %w", err)
+		}
+
+		return nil
+	}, orgRecord{Member: &member, Removed: true})
+}
+
+// FetchOrgURLs получает все ссылки организации.
+func (s *FileStorage) FetchOrgURLs(ctx context.Context, orgID string) ([]models.URL, error) {
+	return s.baseStorage.FetchOrgURLs(ctx, orgID)
+}
+
+// FetchURLsPage получает страницу ссылок пользователя или организации.
+func (s *FileStorage) FetchURLsPage(ctx context.Context, orgID string, after string, limit int) ([]models.URL, error) {
+	return s.baseStorage.FetchURLsPage(ctx, orgID, after, limit)
+}
+
+// CountURLs получает число всех ссылок, включая удаленные.
+func (s *FileStorage) CountURLs(ctx context.Context) (int, error) {
+	return s.baseStorage.CountURLs(ctx)
+}
+
+// FetchAllURLsPage получает страницу всех ссылок, упорядоченных по короткой ссылке и следующих за after.
+func (s *FileStorage) FetchAllURLsPage(ctx context.Context, after string, limit int) ([]models.URL, error) {
+	return s.baseStorage.FetchAllURLsPage(ctx, after, limit)
+}
+
+// ImportURLs сохраняет ссылки как есть, уже существующие короткие ссылки пропускаются.
+func (s *FileStorage) ImportURLs(ctx context.Context, urls []models.URL) (int, error) {
+	file, err := os.OpenFile(s.fileStoragePath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, filePerm)
+	if err != nil {
+		return 0, fmt.Errorf(openFileErrStr, err)
+	}
+
+	defer closeFile(s, file)
+
+	encoder := json.NewEncoder(file)
+	imported := 0
+
+	for _, v := range urls {
+		n, _ := s.baseStorage.ImportURLs(ctx, []models.URL{v})
+		if n == 0 {
+			continue
+		}
+
+		url := s.baseStorage.urls[v.ShortURL]
+		if err := encoder.Encode(&url); err != nil {
+			return imported, fmt.Errorf("failed to dump URL: %w", err)
+		}
+		imported++
+	}
+
+	return imported, nil
+}
+
+// FetchAllOrgs получает все организации и их участников.
+func (s *FileStorage) FetchAllOrgs(ctx context.Context) ([]models.Org, []models.Member, error) {
+	return s.baseStorage.FetchAllOrgs(ctx)
+}
+
+// ImportOrgs сохраняет организации и участников как есть, уже существующие пропускаются.
+func (s *FileStorage) ImportOrgs(ctx context.Context, orgs []models.Org, members []models.Member) error {
+	records := make([]orgRecord, 0, len(orgs)+len(members))
+
+	for _, org := range orgs {
+		if _, ok := s.baseStorage.orgs[org.ID]; !ok {
+			records = append(records, orgRecord{Org: &org})
+		}
+	}
+
+	for _, member := range members {
+		if _, ok := s.baseStorage.members[member.OrgID][member.UserID]; !ok {
+			records = append(records, orgRecord{Member: &member})
+		}
+	}
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	return s.storeOrgRecords(func() error {
+		return s.baseStorage.ImportOrgs(ctx, orgs, members)
+	}, records...)
+}
+
+// storeOrgRecords применяет изменение к in-memory БД и дописывает его в журнал организаций.
+func (s *FileStorage) storeOrgRecords(apply func() error, records ...orgRecord) error {
+	file, err := os.OpenFile(s.fileStoragePath+orgFileExt, os.O_WRONLY|os.O_CREATE|os.O_APPEND, filePerm)
+	if err != nil {
+		return fmt.Errorf("failed to open org storage: %w", err)
+	}
+
+	defer closeFile(s, file)
+
+	if err := apply(); err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(file)
+
+	for _, record := range records {
+		if err := encoder.Encode(&record); err != nil {
+			return fmt.Errorf("failed to dump org: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *FileStorage) loadAuditEvents() error {
+	file, err := os.OpenFile(s.fileStoragePath+auditFileExt, os.O_RDONLY|os.O_CREATE, filePerm)
+	if err != nil {
+		return fmt.Errorf("failed to open audit storage: %w", err)
+	}
+	defer closeFile(s, file)
+
+	scanner := bufio.NewScanner(file)
+
+	var events []models.AuditEvent
+	for scanner.Scan() {
+		event := models.AuditEvent{}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return fmt.Errorf("failed to parse audit storage: %w", err)
+		}
+
+		events = append(events, event)
+	}
+
+	return s.baseStorage.StoreAuditEvents(context.Background(), events)
+}
+
+// StoreAuditEvents дописывает события в файл журнала аудита.
+func (s *FileStorage) StoreAuditEvents(ctx context.Context, events []models.AuditEvent) error {
+	s.auditMu.Lock()
+	defer s.auditMu.Unlock()
+
+	file, err := os.OpenFile(s.fileStoragePath+auditFileExt, os.O_WRONLY|os.O_CREATE|os.O_APPEND, filePerm)
+	if err != nil {
+		return fmt.Errorf("failed to open audit storage: %w", err)
+	}
+	defer closeFile(s, file)
+
+	encoder := json.NewEncoder(file)
+	for i := range events {
+		if err := encoder.Encode(&events[i]); err != nil {
+			return fmt.Errorf("failed to dump audit event: %w", err)
+		}
+	}
+
+	return s.baseStorage.StoreAuditEvents(ctx, events)
+}
+
+// FetchAuditEvents получает события журнала аудита по фильтру, новые первыми.
+func (s *FileStorage) FetchAuditEvents(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error) {
+	return s.baseStorage.FetchAuditEvents(ctx, filter)
+}
+
+// DropAuditEvents удаляет события журнала аудита раньше before и перезаписывает файл журнала.
+func (s *FileStorage) DropAuditEvents(ctx context.Context, before time.Time) (int, error) {
+	s.auditMu.Lock()
+	defer s.auditMu.Unlock()
+
+	dropped, err := s.baseStorage.DropAuditEvents(ctx, before)
+	if err != nil || dropped == 0 {
+		return dropped, err
+	}
+
+	events, err := s.baseStorage.FetchAuditEvents(ctx, models.AuditFilter{})
+	if err != nil {
+		return 0, err
+	}
+
+	path := s.fileStoragePath + auditFileExt
+	file, err := os.OpenFile(path+".tmp", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, filePerm)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open audit storage: %w", err)
+	}
+
+	encoder := json.NewEncoder(file)
+	for i := len(events) - 1; i >= 0; i-- {
+		if err := encoder.Encode(&events[i]); err != nil {
+			closeFile(s, file)
+			return 0, fmt.Errorf("failed to dump audit event: %w", err)
+		}
+	}
+	closeFile(s, file)
+
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return 0, fmt.Errorf("failed to replace audit storage: %w", err)
+	}
+
+	return dropped, nil
+}
+
+func (s *FileStorage) loadWebhooks() error {
+	file, err := os.OpenFile(s.fileStoragePath+webhookFileExt, os.O_RDONLY|os.O_CREATE, filePerm)
+	if err != nil {
+		return fmt.Errorf("failed to open webhook storage: %w", err)
+	}
+	defer closeFile(s, file)
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		record := webhookRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("failed to parse webhook storage: %w", err)
+		}
+
+		hooks := s.baseStorage.hooks
+		switch {
+		case record.Webhook != nil && record.Removed:
+			s.baseStorage.removeWebhook(record.Webhook.ID)
+		case record.Webhook != nil:
+			hooks.webhooks[record.Webhook.ID] = *record.Webhook
+		case record.Delivery != nil && record.Removed:
+			delete(hooks.deliveries, record.Delivery.ID)
+		case record.Delivery != nil:
+			// Доставка могла обновиться после удаления вебхука, такие записи пропускаются.
+			if _, ok := hooks.webhooks[record.Delivery.WebhookID]; ok {
+				hooks.deliveries[record.Delivery.ID] = *record.Delivery
+			}
+		}
+	}
+
+	return nil
+}
+
+// StoreWebhook сохраняет вебхук пользователя.
+func (s *FileStorage) StoreWebhook(ctx context.Context, webhook models.Webhook) error {
+	return s.storeWebhookRecords(func() error {
+		return s.baseStorage.StoreWebhook(ctx, webhook)
+	}, webhookRecord{Webhook: &webhook})
+}
+
+// FetchUserWebhooks получает вебхуки пользователя из контекста.
+func (s *FileStorage) FetchUserWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	return s.baseStorage.FetchUserWebhooks(ctx)
+}
+
+// UpdateWebhook меняет адрес, ключ и события вебхука пользователя из контекста.
+func (s *FileStorage) UpdateWebhook(ctx context.Context, webhook models.Webhook) error {
+	var updated models.Webhook
+
+	return s.storeWebhookRecords(func() error {
+		if err := s.baseStorage.UpdateWebhook(ctx, webhook); err != nil {
+			return err
+		}
+
+		s.baseStorage.hooks.mu.RLock()
+		defer s.baseStorage.hooks.mu.RUnlock()
+
+		updated = s.baseStorage.hooks.webhooks[webhook.ID]
+		return nil
+	}, webhookRecord{Webhook: &updated})
+}
+
+// DeleteWebhook удаляет вебхук пользователя из контекста вместе с его доставками.
+func (s *FileStorage) DeleteWebhook(ctx context.Context, id string) error {
+	return s.storeWebhookRecords(func() error {
+		return s.baseStorage.DeleteWebhook(ctx, id)
+	}, webhookRecord{Webhook: &models.Webhook{ID: id}, Removed: true})
+}
+
+// FetchEventWebhooks получает вебхуки пользователя userID, подписанные на событие event.
+func (s *FileStorage) FetchEventWebhooks(ctx context.Context, userID string, event string) ([]models.Webhook, error) {
+	return s.baseStorage.FetchEventWebhooks(ctx, userID, event)
+}
+
+// StoreWebhookDeliveries добавляет доставки в исходящую очередь вебхуков.
+func (s *FileStorage) StoreWebhookDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	records := make([]webhookRecord, 0, len(deliveries))
+	for i := range deliveries {
+		records = append(records, webhookRecord{Delivery: &deliveries[i]})
+	}
+
+	return s.storeWebhookRecords(func() error {
+		return s.baseStorage.StoreWebhookDeliveries(ctx, deliveries)
+	}, records...)
+}
+
+// FetchDueWebhookDeliveries получает до limit доставок, время попытки которых наступило к now, старые первыми.
+func (s *FileStorage) FetchDueWebhookDeliveries(
+	ctx context.Context,
+	now time.Time,
+	limit int,
+) ([]models.WebhookDelivery, error) {
+	return s.baseStorage.FetchDueWebhookDeliveries(ctx, now, limit)
+}
+
+// UpdateWebhookDelivery сохраняет результат попытки доставки.
+func (s *FileStorage) UpdateWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) error {
+	return s.storeWebhookRecords(func() error {
+		return s.baseStorage.UpdateWebhookDelivery(ctx, delivery)
+	}, webhookRecord{Delivery: &delivery})
+}
+
+// DeleteWebhookDelivery удаляет выполненную доставку из очереди.
+func (s *FileStorage) DeleteWebhookDelivery(ctx context.Context, id string) error {
+	return s.storeWebhookRecords(func() error {
+		return s.baseStorage.DeleteWebhookDelivery(ctx, id)
+	}, webhookRecord{Delivery: &models.WebhookDelivery{ID: id}, Removed: true})
+}
+
+// FetchDeadWebhookDeliveries получает недоставленные события вебхуков пользователя из контекста.
+func (s *FileStorage) FetchDeadWebhookDeliveries(ctx context.Context) ([]models.WebhookDelivery, error) {
+	return s.baseStorage.FetchDeadWebhookDeliveries(ctx)
+}
+
+// storeWebhookRecords применяет изменение к in-memory БД и дописывает его в журнал вебхуков.
+func (s *FileStorage) storeWebhookRecords(apply func() error, records ...webhookRecord) error {
+	s.hooksMu.Lock()
+	defer s.hooksMu.Unlock()
+
+	file, err := os.OpenFile(s.fileStoragePath+webhookFileExt, os.O_WRONLY|os.O_CREATE|os.O_APPEND, filePerm)
+	if err != nil {
+		return fmt.Errorf("failed to open webhook storage: %w", err)
+	}
+
+	defer closeFile(s, file)
+
+	if err := apply(); err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(file)
+
+	for _, record := range records {
+		if err := encoder.Encode(&record); err != nil {
+			return fmt.Errorf("failed to dump webhook: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // Ping проверяет работоспособность БД (не используется для файловой БД).
 func (s *FileStorage) Ping(_ context.Context) error {
 	return nil
 }
 
+// HealthDetails проверяет, что файл БД доступен для записи, и возвращает его размер.
+func (s *FileStorage) HealthDetails(_ context.Context) (map[string]any, error) {
+	details := map[string]any{"type": "file", "path": s.fileStoragePath}
+
+	file, err := os.OpenFile(s.fileStoragePath, os.O_WRONLY|os.O_APPEND, filePerm)
+	if err != nil {
+		return details, fmt.Errorf("file storage is not writable: %w", err)
+	}
+	defer closeFile(s, file)
+
+	info, err := file.Stat()
+	if err != nil {
+		return details, fmt.Errorf("failed to stat file storage: %w", err)
+	}
+	details["size"] = info.Size()
+
+	return details, nil
+}
+
 // Close закрывает соединение с БД (не используется для файловой БД).
 func (s *FileStorage) Close() error {
 	return nil
@@ -2,7 +2,9 @@ package data
 
 import (
 	"context"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/MihailSergeenkov/shortener/internal/app/common"
 	"github.com/MihailSergeenkov/shortener/internal/app/models"
@@ -216,6 +218,25 @@ func TestFileFetchUserURLs(t *testing.T) {
 	}
 }
 
+func TestFileFetchURLsPage(t *testing.T) {
+	currentUserID := "some_id"
+	ctx := context.WithValue(context.Background(), common.KeyUserID, currentUserID)
+	url := models.URL{
+		ShortURL:    "short_url",
+		OriginalURL: "some_url",
+		UserID:      currentUserID,
+	}
+	storage := &FileStorage{
+		baseStorage: BaseStorage{
+			urls: map[string]models.URL{url.ShortURL: url},
+		},
+	}
+
+	urls, err := storage.FetchURLsPage(ctx, "", "", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []models.URL{url}, urls)
+}
+
 func TestFileGetURL(t *testing.T) {
 	ctx := context.Background()
 	shortURL := "short_url"
@@ -390,6 +411,68 @@ func TestFileFetchStats(t *testing.T) {
 	}
 }
 
+func TestFileConsumeDailyQuota(t *testing.T) {
+	logger := zap.NewNop()
+	fileStoragePath := t.TempDir() + "/short-url-db.json"
+	ctx := context.Background()
+	key := "user:some_id"
+	limit := 2
+
+	storage, err := NewFileStorage(logger, fileStoragePath)
+	require.NoError(t, err)
+
+	t.Run("consume within limit", func(t *testing.T) {
+		require.NoError(t, storage.ConsumeDailyQuota(ctx, key, 2, limit))
+	})
+
+	t.Run("quota restored after restart", func(t *testing.T) {
+		restored, err := NewFileStorage(logger, fileStoragePath)
+		require.NoError(t, err)
+
+		err = restored.ConsumeDailyQuota(ctx, key, 1, limit)
+		require.ErrorIs(t, err, ErrQuotaExceeded)
+		require.ErrorContains(t, err, "failed to consume quota")
+	})
+
+	t.Run("failed open file", func(t *testing.T) {
+		broken := &FileStorage{
+			baseStorage:     *NewBaseStorage(),
+			fileStoragePath: "/not_exist_dir/short-url-db.json",
+			logger:          logger,
+		}
+
+		err := broken.ConsumeDailyQuota(ctx, key, 1, limit)
+		require.ErrorContains(t, err, "failed to open quota storage")
+	})
+
+	t.Run("refund restored after restart", func(t *testing.T) {
+		require.NoError(t, storage.RefundDailyQuota(ctx, key, 1))
+
+		restored, err := NewFileStorage(logger, fileStoragePath)
+		require.NoError(t, err)
+
+		require.NoError(t, restored.ConsumeDailyQuota(ctx, key, 1, limit))
+		require.ErrorIs(t, restored.ConsumeDailyQuota(ctx, key, 1, limit), ErrQuotaExceeded)
+	})
+
+	t.Run("quotas of past days are compacted", func(t *testing.T) {
+		path := t.TempDir() + "/short-url-db.json"
+		yesterday := quotaKey(key, today().AddDate(0, 0, -1))
+		content := `{"key":"` + yesterday + `","used":2}` + "\n" +
+			`{"key":"` + quotaKey(key, today()) + `","used":1}` + "\n" +
+			`{"key":"` + quotaKey(key, today()) + `","used":2}` + "\n"
+		require.NoError(t, os.WriteFile(path+quotaFileExt, []byte(content), filePerm))
+
+		restored, err := NewFileStorage(logger, path)
+		require.NoError(t, err)
+		require.ErrorIs(t, restored.ConsumeDailyQuota(ctx, key, 1, limit), ErrQuotaExceeded)
+
+		compacted, err := os.ReadFile(path + quotaFileExt)
+		require.NoError(t, err)
+		assert.Equal(t, `{"key":"`+quotaKey(key, today())+`","used":2}`+"\n", string(compacted))
+	})
+}
+
 func TestFilePing(t *testing.T) {
 	storage := FileStorage{}
 	ctx := context.Background()
@@ -404,3 +487,201 @@ func TestFileClose(t *testing.T) {
 
 	require.NoError(t, err)
 }
+
+func TestFileOrgs(t *testing.T) {
+	logger := zap.NewNop()
+	fileStoragePath := t.TempDir() + "/short-url-db.json"
+	ctx := context.WithValue(context.Background(), common.KeyUserID, "owner_id")
+	org := models.Org{ID: "org_id", Name: "Team"}
+
+	storage, err := NewFileStorage(logger, fileStoragePath)
+	require.NoError(t, err)
+
+	t.Run("store org and members", func(t *testing.T) {
+		require.NoError(t, storage.StoreOrg(ctx, org))
+		require.NoError(t, storage.StoreOrgMember(ctx, models.Member{OrgID: org.ID, UserID: "editor_id", Role: models.RoleEditor}))
+		require.NoError(t, storage.StoreOrgMember(ctx, models.Member{OrgID: org.ID, UserID: "viewer_id", Role: models.RoleViewer}))
+		require.NoError(t, storage.DeleteOrgMember(ctx, org.ID, "viewer_id"))
+
+		orgCtx := context.WithValue(ctx, common.KeyOrgID, org.ID)
+		require.NoError(t, storage.StoreShortURL(orgCtx, "org_url", "https://ya.ru"))
+	})
+
+	t.Run("orgs restored after restart", func(t *testing.T) {
+		restored, err := NewFileStorage(logger, fileStoragePath)
+		require.NoError(t, err)
+
+		orgs, err := restored.FetchUserOrgs(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []models.UserOrg{{ID: org.ID, Name: org.Name, Role: models.RoleOwner}}, orgs)
+
+		members, err := restored.FetchOrgMembers(ctx, org.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []models.Member{
+			{OrgID: org.ID, UserID: "editor_id", Role: models.RoleEditor},
+			{OrgID: org.ID, UserID: "owner_id", Role: models.RoleOwner},
+		}, members)
+
+		urls, err := restored.FetchOrgURLs(ctx, org.ID)
+		require.NoError(t, err)
+		require.Len(t, urls, 1)
+
+		role, err := restored.GetOrgRole(ctx, org.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.RoleOwner, role)
+	})
+
+	t.Run("failed open file", func(t *testing.T) {
+		broken := &FileStorage{
+			baseStorage:     *NewBaseStorage(),
+			fileStoragePath: "/not_exist_dir/short-url-db.json",
+			logger:          logger,
+		}
+
+		err := broken.StoreOrg(ctx, org)
+		require.ErrorContains(t, err, "failed to open org storage")
+	})
+}
+
+func TestFileMigratorMethods(t *testing.T) {
+	logger := zap.NewNop()
+	fileStoragePath := t.TempDir() + "/short-url-db.json"
+	ctx := context.Background()
+	urls := []models.URL{
+		{ShortURL: "a", OriginalURL: "https://ya.ru/a", UserID: "user_1", OrgID: "org_id"},
+		{ShortURL: "b", OriginalURL: "https://ya.ru/b", UserID: "user_2", DeletedFlag: true},
+	}
+	orgs := []models.Org{{ID: "org_id", Name: "Team"}}
+	members := []models.Member{{OrgID: "org_id", UserID: "user_1", Role: models.RoleOwner}}
+
+	storage, err := NewFileStorage(logger, fileStoragePath)
+	require.NoError(t, err)
+
+	t.Run("import", func(t *testing.T) {
+		require.NoError(t, storage.ImportOrgs(ctx, orgs, members))
+
+		imported, err := storage.ImportURLs(ctx, urls)
+		require.NoError(t, err)
+		assert.Equal(t, 2, imported)
+
+		imported, err = storage.ImportURLs(ctx, urls)
+		require.NoError(t, err)
+		assert.Equal(t, 0, imported)
+	})
+
+	t.Run("restored after restart", func(t *testing.T) {
+		restored, err := NewFileStorage(logger, fileStoragePath)
+		require.NoError(t, err)
+
+		count, err := restored.CountURLs(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		page, err := restored.FetchAllURLsPage(ctx, "", 10)
+		require.NoError(t, err)
+		require.Len(t, page, 2)
+		assert.Equal(t, "org_id", page[0].OrgID)
+		assert.True(t, page[1].DeletedFlag)
+
+		gotOrgs, gotMembers, err := restored.FetchAllOrgs(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, orgs, gotOrgs)
+		assert.Equal(t, members, gotMembers)
+	})
+
+	t.Run("failed open file", func(t *testing.T) {
+		broken := &FileStorage{
+			baseStorage:     *NewBaseStorage(),
+			fileStoragePath: "/not_exist_dir/short-url-db.json",
+			logger:          logger,
+		}
+
+		_, err := broken.ImportURLs(ctx, urls)
+		require.ErrorContains(t, err, "failed to open file storage")
+
+		err = broken.ImportOrgs(ctx, orgs, members)
+		require.ErrorContains(t, err, "failed to open org storage")
+	})
+}
+
+func TestFileAuditEvents(t *testing.T) {
+	logger := zap.NewNop()
+	fileStoragePath := t.TempDir() + "/short-url-db.json"
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	events := []models.AuditEvent{
+		{Time: now.Add(-time.Hour), Action: models.AuditCreate, Transport: "http", ShortURL: "a"},
+		{Time: now, Action: models.AuditDelete, Transport: "grpc", ShortURL: "a"},
+	}
+
+	storage, err := NewFileStorage(logger, fileStoragePath)
+	require.NoError(t, err)
+	require.NoError(t, storage.StoreAuditEvents(ctx, events))
+
+	t.Run("restored after restart", func(t *testing.T) {
+		restored, err := NewFileStorage(logger, fileStoragePath)
+		require.NoError(t, err)
+
+		got, err := restored.FetchAuditEvents(ctx, models.AuditFilter{Transport: "grpc"})
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.True(t, events[1].Time.Equal(got[0].Time))
+		assert.Equal(t, models.AuditDelete, got[0].Action)
+	})
+
+	t.Run("drop rewrites file", func(t *testing.T) {
+		dropped, err := storage.DropAuditEvents(ctx, now.Add(-time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, 1, dropped)
+
+		restored, err := NewFileStorage(logger, fileStoragePath)
+		require.NoError(t, err)
+
+		got, err := restored.FetchAuditEvents(ctx, models.AuditFilter{})
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, models.AuditDelete, got[0].Action)
+	})
+}
+
+func TestFileWebhooks(t *testing.T) {
+	logger := zap.NewNop()
+	fileStoragePath := t.TempDir() + "/short-url-db.json"
+	ctx := context.WithValue(context.Background(), common.KeyUserID, "user_id")
+	now := time.Now().UTC().Truncate(time.Second)
+	webhooks := []models.Webhook{
+		{ID: "a", UserID: "user_id", URL: "https://example.com/a", Secret: "s", Events: []string{models.LinkCreated}},
+		{ID: "b", UserID: "user_id", URL: "https://example.com/b", Secret: "s", Events: []string{models.LinkCreated}},
+	}
+
+	storage, err := NewFileStorage(logger, fileStoragePath)
+	require.NoError(t, err)
+	for _, w := range webhooks {
+		require.NoError(t, storage.StoreWebhook(ctx, w))
+	}
+	require.NoError(t, storage.StoreWebhookDeliveries(ctx, []models.WebhookDelivery{
+		{ID: "d1", WebhookID: "a", Event: models.LinkCreated, NextAttempt: now, CreatedAt: now},
+		{ID: "d2", WebhookID: "b", Event: models.LinkCreated, NextAttempt: now, CreatedAt: now},
+	}))
+	require.NoError(t, storage.UpdateWebhookDelivery(ctx, models.WebhookDelivery{
+		ID: "d1", WebhookID: "a", Event: models.LinkCreated, Attempts: 3, Dead: true, CreatedAt: now,
+	}))
+	require.NoError(t, storage.DeleteWebhook(ctx, "b"))
+
+	restored, err := NewFileStorage(logger, fileStoragePath)
+	require.NoError(t, err)
+
+	got, err := restored.FetchUserWebhooks(ctx)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "a", got[0].ID)
+
+	due, err := restored.FetchDueWebhookDeliveries(ctx, now.Add(time.Hour), 0)
+	require.NoError(t, err)
+	assert.Empty(t, due, "dead and removed deliveries are not due")
+
+	dead, err := restored.FetchDeadWebhookDeliveries(ctx)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, 3, dead[0].Attempts)
+}
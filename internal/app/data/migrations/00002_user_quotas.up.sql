@@ -0,0 +1,10 @@
+BEGIN TRANSACTION;
+
+CREATE TABLE user_quotas(
+	user_id VARCHAR(200) NOT NULL,
+	day DATE NOT NULL,
+	used INT NOT NULL DEFAULT 0,
+	PRIMARY KEY (user_id, day)
+);
+
+COMMIT;
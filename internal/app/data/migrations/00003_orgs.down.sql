@@ -0,0 +1,8 @@
+BEGIN TRANSACTION;
+
+DROP INDEX urls_org_id_index;
+ALTER TABLE urls DROP COLUMN org_id;
+DROP TABLE org_members;
+DROP TABLE orgs;
+
+COMMIT;
@@ -0,0 +1,19 @@
+BEGIN TRANSACTION;
+
+CREATE TABLE orgs(
+	id VARCHAR(200) PRIMARY KEY,
+	name VARCHAR(200) NOT NULL
+);
+
+CREATE TABLE org_members(
+	org_id VARCHAR(200) NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+	user_id VARCHAR(200) NOT NULL,
+	role VARCHAR(20) NOT NULL,
+	PRIMARY KEY (org_id, user_id)
+);
+CREATE INDEX org_members_user_id_index ON org_members(user_id);
+
+ALTER TABLE urls ADD COLUMN org_id VARCHAR(200) REFERENCES orgs(id);
+CREATE INDEX urls_org_id_index ON urls(org_id);
+
+COMMIT;
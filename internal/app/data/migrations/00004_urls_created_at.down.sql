@@ -0,0 +1,5 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE urls DROP COLUMN created_at;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE urls ADD COLUMN created_at TIMESTAMPTZ;
+ALTER TABLE urls ALTER COLUMN created_at SET DEFAULT now();
+
+COMMIT;
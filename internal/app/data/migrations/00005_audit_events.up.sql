@@ -0,0 +1,18 @@
+BEGIN TRANSACTION;
+
+CREATE TABLE audit_events(
+	id BIGSERIAL PRIMARY KEY,
+	time TIMESTAMPTZ NOT NULL,
+	action VARCHAR(20) NOT NULL,
+	user_id VARCHAR(200) NOT NULL DEFAULT '',
+	transport VARCHAR(20) NOT NULL,
+	client_ip VARCHAR(64) NOT NULL DEFAULT '',
+	request_id VARCHAR(128) NOT NULL DEFAULT '',
+	short_url VARCHAR(200) NOT NULL DEFAULT '',
+	original_url TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX audit_events_time_index ON audit_events(time);
+CREATE INDEX audit_events_user_id_index ON audit_events(user_id);
+CREATE INDEX audit_events_short_url_index ON audit_events(short_url);
+
+COMMIT;
@@ -0,0 +1,27 @@
+BEGIN TRANSACTION;
+
+CREATE TABLE webhooks(
+	id VARCHAR(64) PRIMARY KEY,
+	user_id VARCHAR(200) NOT NULL,
+	url TEXT NOT NULL,
+	secret VARCHAR(200) NOT NULL,
+	events TEXT[] NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX webhooks_user_id_index ON webhooks(user_id);
+
+CREATE TABLE webhook_deliveries(
+	id VARCHAR(64) PRIMARY KEY,
+	webhook_id VARCHAR(64) NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+	event VARCHAR(20) NOT NULL,
+	payload JSONB NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	next_attempt TIMESTAMPTZ NOT NULL,
+	last_error TEXT NOT NULL DEFAULT '',
+	dead BOOLEAN NOT NULL DEFAULT false,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX webhook_deliveries_due_index ON webhook_deliveries(next_attempt) WHERE NOT dead;
+CREATE INDEX webhook_deliveries_webhook_id_index ON webhook_deliveries(webhook_id);
+
+COMMIT;
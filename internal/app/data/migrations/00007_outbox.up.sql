@@ -0,0 +1,13 @@
+BEGIN TRANSACTION;
+
+CREATE TABLE outbox(
+	id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+	event VARCHAR(20) NOT NULL,
+	short_url VARCHAR(200) NOT NULL,
+	original_url VARCHAR(300) NOT NULL,
+	user_id VARCHAR(200),
+	org_id VARCHAR(200),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+COMMIT;
@@ -7,6 +7,7 @@ package mock
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	models "github.com/MihailSergeenkov/shortener/internal/app/models"
 	gomock "github.com/golang/mock/gomock"
@@ -49,6 +50,34 @@ func (mr *MockStoragerMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockStorager)(nil).Close))
 }
 
+// ConsumeDailyQuota mocks base method.
+func (m *MockStorager) ConsumeDailyQuota(ctx context.Context, key string, count, limit int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeDailyQuota", ctx, key, count, limit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConsumeDailyQuota indicates an expected call of ConsumeDailyQuota.
+func (mr *MockStoragerMockRecorder) ConsumeDailyQuota(ctx, key, count, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeDailyQuota", reflect.TypeOf((*MockStorager)(nil).ConsumeDailyQuota), ctx, key, count, limit)
+}
+
+// DeleteOrgMember mocks base method.
+func (m *MockStorager) DeleteOrgMember(ctx context.Context, orgID, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOrgMember", ctx, orgID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOrgMember indicates an expected call of DeleteOrgMember.
+func (mr *MockStoragerMockRecorder) DeleteOrgMember(ctx, orgID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrgMember", reflect.TypeOf((*MockStorager)(nil).DeleteOrgMember), ctx, orgID, userID)
+}
+
 // DeleteShortURLs mocks base method.
 func (m *MockStorager) DeleteShortURLs(ctx context.Context, urls []string) error {
 	m.ctrl.T.Helper()
@@ -63,6 +92,49 @@ func (mr *MockStoragerMockRecorder) DeleteShortURLs(ctx, urls interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteShortURLs", reflect.TypeOf((*MockStorager)(nil).DeleteShortURLs), ctx, urls)
 }
 
+// DeleteWebhook mocks base method.
+func (m *MockStorager) DeleteWebhook(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockStoragerMockRecorder) DeleteWebhook(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockStorager)(nil).DeleteWebhook), ctx, id)
+}
+
+// DeleteWebhookDelivery mocks base method.
+func (m *MockStorager) DeleteWebhookDelivery(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhookDelivery", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhookDelivery indicates an expected call of DeleteWebhookDelivery.
+func (mr *MockStoragerMockRecorder) DeleteWebhookDelivery(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhookDelivery", reflect.TypeOf((*MockStorager)(nil).DeleteWebhookDelivery), ctx, id)
+}
+
+// DropAuditEvents mocks base method.
+func (m *MockStorager) DropAuditEvents(ctx context.Context, before time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DropAuditEvents", ctx, before)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DropAuditEvents indicates an expected call of DropAuditEvents.
+func (mr *MockStoragerMockRecorder) DropAuditEvents(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropAuditEvents", reflect.TypeOf((*MockStorager)(nil).DropAuditEvents), ctx, before)
+}
+
 // DropDeletedURLs mocks base method.
 func (m *MockStorager) DropDeletedURLs(ctx context.Context) error {
 	m.ctrl.T.Helper()
@@ -77,6 +149,96 @@ func (mr *MockStoragerMockRecorder) DropDeletedURLs(ctx interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropDeletedURLs", reflect.TypeOf((*MockStorager)(nil).DropDeletedURLs), ctx)
 }
 
+// FetchAuditEvents mocks base method.
+func (m *MockStorager) FetchAuditEvents(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchAuditEvents", ctx, filter)
+	ret0, _ := ret[0].([]models.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchAuditEvents indicates an expected call of FetchAuditEvents.
+func (mr *MockStoragerMockRecorder) FetchAuditEvents(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchAuditEvents", reflect.TypeOf((*MockStorager)(nil).FetchAuditEvents), ctx, filter)
+}
+
+// FetchDeadWebhookDeliveries mocks base method.
+func (m *MockStorager) FetchDeadWebhookDeliveries(ctx context.Context) ([]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchDeadWebhookDeliveries", ctx)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchDeadWebhookDeliveries indicates an expected call of FetchDeadWebhookDeliveries.
+func (mr *MockStoragerMockRecorder) FetchDeadWebhookDeliveries(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchDeadWebhookDeliveries", reflect.TypeOf((*MockStorager)(nil).FetchDeadWebhookDeliveries), ctx)
+}
+
+// FetchDueWebhookDeliveries mocks base method.
+func (m *MockStorager) FetchDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchDueWebhookDeliveries", ctx, now, limit)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchDueWebhookDeliveries indicates an expected call of FetchDueWebhookDeliveries.
+func (mr *MockStoragerMockRecorder) FetchDueWebhookDeliveries(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchDueWebhookDeliveries", reflect.TypeOf((*MockStorager)(nil).FetchDueWebhookDeliveries), ctx, now, limit)
+}
+
+// FetchEventWebhooks mocks base method.
+func (m *MockStorager) FetchEventWebhooks(ctx context.Context, userID, event string) ([]models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchEventWebhooks", ctx, userID, event)
+	ret0, _ := ret[0].([]models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchEventWebhooks indicates an expected call of FetchEventWebhooks.
+func (mr *MockStoragerMockRecorder) FetchEventWebhooks(ctx, userID, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchEventWebhooks", reflect.TypeOf((*MockStorager)(nil).FetchEventWebhooks), ctx, userID, event)
+}
+
+// FetchOrgMembers mocks base method.
+func (m *MockStorager) FetchOrgMembers(ctx context.Context, orgID string) ([]models.Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchOrgMembers", ctx, orgID)
+	ret0, _ := ret[0].([]models.Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchOrgMembers indicates an expected call of FetchOrgMembers.
+func (mr *MockStoragerMockRecorder) FetchOrgMembers(ctx, orgID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchOrgMembers", reflect.TypeOf((*MockStorager)(nil).FetchOrgMembers), ctx, orgID)
+}
+
+// FetchOrgURLs mocks base method.
+func (m *MockStorager) FetchOrgURLs(ctx context.Context, orgID string) ([]models.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchOrgURLs", ctx, orgID)
+	ret0, _ := ret[0].([]models.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchOrgURLs indicates an expected call of FetchOrgURLs.
+func (mr *MockStoragerMockRecorder) FetchOrgURLs(ctx, orgID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchOrgURLs", reflect.TypeOf((*MockStorager)(nil).FetchOrgURLs), ctx, orgID)
+}
+
 // FetchStats mocks base method.
 func (m *MockStorager) FetchStats(ctx context.Context) (int, int, error) {
 	m.ctrl.T.Helper()
@@ -93,6 +255,36 @@ func (mr *MockStoragerMockRecorder) FetchStats(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchStats", reflect.TypeOf((*MockStorager)(nil).FetchStats), ctx)
 }
 
+// FetchURLsPage mocks base method.
+func (m *MockStorager) FetchURLsPage(ctx context.Context, orgID, after string, limit int) ([]models.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchURLsPage", ctx, orgID, after, limit)
+	ret0, _ := ret[0].([]models.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchURLsPage indicates an expected call of FetchURLsPage.
+func (mr *MockStoragerMockRecorder) FetchURLsPage(ctx, orgID, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchURLsPage", reflect.TypeOf((*MockStorager)(nil).FetchURLsPage), ctx, orgID, after, limit)
+}
+
+// FetchUserOrgs mocks base method.
+func (m *MockStorager) FetchUserOrgs(ctx context.Context) ([]models.UserOrg, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchUserOrgs", ctx)
+	ret0, _ := ret[0].([]models.UserOrg)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchUserOrgs indicates an expected call of FetchUserOrgs.
+func (mr *MockStoragerMockRecorder) FetchUserOrgs(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchUserOrgs", reflect.TypeOf((*MockStorager)(nil).FetchUserOrgs), ctx)
+}
+
 // FetchUserURLs mocks base method.
 func (m *MockStorager) FetchUserURLs(ctx context.Context) ([]models.URL, error) {
 	m.ctrl.T.Helper()
@@ -108,6 +300,36 @@ func (mr *MockStoragerMockRecorder) FetchUserURLs(ctx interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchUserURLs", reflect.TypeOf((*MockStorager)(nil).FetchUserURLs), ctx)
 }
 
+// FetchUserWebhooks mocks base method.
+func (m *MockStorager) FetchUserWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchUserWebhooks", ctx)
+	ret0, _ := ret[0].([]models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchUserWebhooks indicates an expected call of FetchUserWebhooks.
+func (mr *MockStoragerMockRecorder) FetchUserWebhooks(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchUserWebhooks", reflect.TypeOf((*MockStorager)(nil).FetchUserWebhooks), ctx)
+}
+
+// GetOrgRole mocks base method.
+func (m *MockStorager) GetOrgRole(ctx context.Context, orgID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrgRole", ctx, orgID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrgRole indicates an expected call of GetOrgRole.
+func (mr *MockStoragerMockRecorder) GetOrgRole(ctx, orgID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrgRole", reflect.TypeOf((*MockStorager)(nil).GetOrgRole), ctx, orgID)
+}
+
 // GetURL mocks base method.
 func (m *MockStorager) GetURL(ctx context.Context, shortURL string) (models.URL, error) {
 	m.ctrl.T.Helper()
@@ -137,6 +359,62 @@ func (mr *MockStoragerMockRecorder) Ping(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockStorager)(nil).Ping), ctx)
 }
 
+// RefundDailyQuota mocks base method.
+func (m *MockStorager) RefundDailyQuota(ctx context.Context, key string, count int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefundDailyQuota", ctx, key, count)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RefundDailyQuota indicates an expected call of RefundDailyQuota.
+func (mr *MockStoragerMockRecorder) RefundDailyQuota(ctx, key, count interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefundDailyQuota", reflect.TypeOf((*MockStorager)(nil).RefundDailyQuota), ctx, key, count)
+}
+
+// StoreAuditEvents mocks base method.
+func (m *MockStorager) StoreAuditEvents(ctx context.Context, events []models.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreAuditEvents", ctx, events)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreAuditEvents indicates an expected call of StoreAuditEvents.
+func (mr *MockStoragerMockRecorder) StoreAuditEvents(ctx, events interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreAuditEvents", reflect.TypeOf((*MockStorager)(nil).StoreAuditEvents), ctx, events)
+}
+
+// StoreOrg mocks base method.
+func (m *MockStorager) StoreOrg(ctx context.Context, org models.Org) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreOrg", ctx, org)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreOrg indicates an expected call of StoreOrg.
+func (mr *MockStoragerMockRecorder) StoreOrg(ctx, org interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreOrg", reflect.TypeOf((*MockStorager)(nil).StoreOrg), ctx, org)
+}
+
+// StoreOrgMember mocks base method.
+func (m *MockStorager) StoreOrgMember(ctx context.Context, member models.Member) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreOrgMember", ctx, member)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreOrgMember indicates an expected call of StoreOrgMember.
+func (mr *MockStoragerMockRecorder) StoreOrgMember(ctx, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreOrgMember", reflect.TypeOf((*MockStorager)(nil).StoreOrgMember), ctx, member)
+}
+
 // StoreShortURL mocks base method.
 func (m *MockStorager) StoreShortURL(ctx context.Context, shortURL, originalURL string) error {
 	m.ctrl.T.Helper()
@@ -164,3 +442,710 @@ func (mr *MockStoragerMockRecorder) StoreShortURLs(ctx, urls interface{}) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreShortURLs", reflect.TypeOf((*MockStorager)(nil).StoreShortURLs), ctx, urls)
 }
+
+// StoreWebhook mocks base method.
+func (m *MockStorager) StoreWebhook(ctx context.Context, webhook models.Webhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreWebhook", ctx, webhook)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreWebhook indicates an expected call of StoreWebhook.
+func (mr *MockStoragerMockRecorder) StoreWebhook(ctx, webhook interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreWebhook", reflect.TypeOf((*MockStorager)(nil).StoreWebhook), ctx, webhook)
+}
+
+// StoreWebhookDeliveries mocks base method.
+func (m *MockStorager) StoreWebhookDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreWebhookDeliveries", ctx, deliveries)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreWebhookDeliveries indicates an expected call of StoreWebhookDeliveries.
+func (mr *MockStoragerMockRecorder) StoreWebhookDeliveries(ctx, deliveries interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreWebhookDeliveries", reflect.TypeOf((*MockStorager)(nil).StoreWebhookDeliveries), ctx, deliveries)
+}
+
+// UpdateWebhook mocks base method.
+func (m *MockStorager) UpdateWebhook(ctx context.Context, webhook models.Webhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhook", ctx, webhook)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebhook indicates an expected call of UpdateWebhook.
+func (mr *MockStoragerMockRecorder) UpdateWebhook(ctx, webhook interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhook", reflect.TypeOf((*MockStorager)(nil).UpdateWebhook), ctx, webhook)
+}
+
+// UpdateWebhookDelivery mocks base method.
+func (m *MockStorager) UpdateWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhookDelivery", ctx, delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebhookDelivery indicates an expected call of UpdateWebhookDelivery.
+func (mr *MockStoragerMockRecorder) UpdateWebhookDelivery(ctx, delivery interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhookDelivery", reflect.TypeOf((*MockStorager)(nil).UpdateWebhookDelivery), ctx, delivery)
+}
+
+// MockHealthReporter is a mock of HealthReporter interface.
+type MockHealthReporter struct {
+	ctrl     *gomock.Controller
+	recorder *MockHealthReporterMockRecorder
+}
+
+// MockHealthReporterMockRecorder is the mock recorder for MockHealthReporter.
+type MockHealthReporterMockRecorder struct {
+	mock *MockHealthReporter
+}
+
+// NewMockHealthReporter creates a new mock instance.
+func NewMockHealthReporter(ctrl *gomock.Controller) *MockHealthReporter {
+	mock := &MockHealthReporter{ctrl: ctrl}
+	mock.recorder = &MockHealthReporterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHealthReporter) EXPECT() *MockHealthReporterMockRecorder {
+	return m.recorder
+}
+
+// HealthDetails mocks base method.
+func (m *MockHealthReporter) HealthDetails(ctx context.Context) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HealthDetails", ctx)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HealthDetails indicates an expected call of HealthDetails.
+func (mr *MockHealthReporterMockRecorder) HealthDetails(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HealthDetails", reflect.TypeOf((*MockHealthReporter)(nil).HealthDetails), ctx)
+}
+
+// MockOutbox is a mock of Outbox interface.
+type MockOutbox struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxMockRecorder
+}
+
+// MockOutboxMockRecorder is the mock recorder for MockOutbox.
+type MockOutboxMockRecorder struct {
+	mock *MockOutbox
+}
+
+// NewMockOutbox creates a new mock instance.
+func NewMockOutbox(ctrl *gomock.Controller) *MockOutbox {
+	mock := &MockOutbox{ctrl: ctrl}
+	mock.recorder = &MockOutboxMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutbox) EXPECT() *MockOutboxMockRecorder {
+	return m.recorder
+}
+
+// DeleteOutboxEvents mocks base method.
+func (m *MockOutbox) DeleteOutboxEvents(ctx context.Context, ids []int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOutboxEvents", ctx, ids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOutboxEvents indicates an expected call of DeleteOutboxEvents.
+func (mr *MockOutboxMockRecorder) DeleteOutboxEvents(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOutboxEvents", reflect.TypeOf((*MockOutbox)(nil).DeleteOutboxEvents), ctx, ids)
+}
+
+// FetchOutboxEvents mocks base method.
+func (m *MockOutbox) FetchOutboxEvents(ctx context.Context, limit int) ([]models.OutboxEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchOutboxEvents", ctx, limit)
+	ret0, _ := ret[0].([]models.OutboxEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchOutboxEvents indicates an expected call of FetchOutboxEvents.
+func (mr *MockOutboxMockRecorder) FetchOutboxEvents(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchOutboxEvents", reflect.TypeOf((*MockOutbox)(nil).FetchOutboxEvents), ctx, limit)
+}
+
+// MockMigrator is a mock of Migrator interface.
+type MockMigrator struct {
+	ctrl     *gomock.Controller
+	recorder *MockMigratorMockRecorder
+}
+
+// MockMigratorMockRecorder is the mock recorder for MockMigrator.
+type MockMigratorMockRecorder struct {
+	mock *MockMigrator
+}
+
+// NewMockMigrator creates a new mock instance.
+func NewMockMigrator(ctrl *gomock.Controller) *MockMigrator {
+	mock := &MockMigrator{ctrl: ctrl}
+	mock.recorder = &MockMigratorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMigrator) EXPECT() *MockMigratorMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockMigrator) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockMigratorMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockMigrator)(nil).Close))
+}
+
+// ConsumeDailyQuota mocks base method.
+func (m *MockMigrator) ConsumeDailyQuota(ctx context.Context, key string, count, limit int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeDailyQuota", ctx, key, count, limit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConsumeDailyQuota indicates an expected call of ConsumeDailyQuota.
+func (mr *MockMigratorMockRecorder) ConsumeDailyQuota(ctx, key, count, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeDailyQuota", reflect.TypeOf((*MockMigrator)(nil).ConsumeDailyQuota), ctx, key, count, limit)
+}
+
+// CountURLs mocks base method.
+func (m *MockMigrator) CountURLs(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountURLs", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountURLs indicates an expected call of CountURLs.
+func (mr *MockMigratorMockRecorder) CountURLs(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountURLs", reflect.TypeOf((*MockMigrator)(nil).CountURLs), ctx)
+}
+
+// DeleteOrgMember mocks base method.
+func (m *MockMigrator) DeleteOrgMember(ctx context.Context, orgID, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOrgMember", ctx, orgID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOrgMember indicates an expected call of DeleteOrgMember.
+func (mr *MockMigratorMockRecorder) DeleteOrgMember(ctx, orgID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrgMember", reflect.TypeOf((*MockMigrator)(nil).DeleteOrgMember), ctx, orgID, userID)
+}
+
+// DeleteShortURLs mocks base method.
+func (m *MockMigrator) DeleteShortURLs(ctx context.Context, urls []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteShortURLs", ctx, urls)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteShortURLs indicates an expected call of DeleteShortURLs.
+func (mr *MockMigratorMockRecorder) DeleteShortURLs(ctx, urls interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteShortURLs", reflect.TypeOf((*MockMigrator)(nil).DeleteShortURLs), ctx, urls)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockMigrator) DeleteWebhook(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockMigratorMockRecorder) DeleteWebhook(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockMigrator)(nil).DeleteWebhook), ctx, id)
+}
+
+// DeleteWebhookDelivery mocks base method.
+func (m *MockMigrator) DeleteWebhookDelivery(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhookDelivery", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhookDelivery indicates an expected call of DeleteWebhookDelivery.
+func (mr *MockMigratorMockRecorder) DeleteWebhookDelivery(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhookDelivery", reflect.TypeOf((*MockMigrator)(nil).DeleteWebhookDelivery), ctx, id)
+}
+
+// DropAuditEvents mocks base method.
+func (m *MockMigrator) DropAuditEvents(ctx context.Context, before time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DropAuditEvents", ctx, before)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DropAuditEvents indicates an expected call of DropAuditEvents.
+func (mr *MockMigratorMockRecorder) DropAuditEvents(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropAuditEvents", reflect.TypeOf((*MockMigrator)(nil).DropAuditEvents), ctx, before)
+}
+
+// DropDeletedURLs mocks base method.
+func (m *MockMigrator) DropDeletedURLs(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DropDeletedURLs", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DropDeletedURLs indicates an expected call of DropDeletedURLs.
+func (mr *MockMigratorMockRecorder) DropDeletedURLs(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropDeletedURLs", reflect.TypeOf((*MockMigrator)(nil).DropDeletedURLs), ctx)
+}
+
+// FetchAllOrgs mocks base method.
+func (m *MockMigrator) FetchAllOrgs(ctx context.Context) ([]models.Org, []models.Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchAllOrgs", ctx)
+	ret0, _ := ret[0].([]models.Org)
+	ret1, _ := ret[1].([]models.Member)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FetchAllOrgs indicates an expected call of FetchAllOrgs.
+func (mr *MockMigratorMockRecorder) FetchAllOrgs(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchAllOrgs", reflect.TypeOf((*MockMigrator)(nil).FetchAllOrgs), ctx)
+}
+
+// FetchAllURLsPage mocks base method.
+func (m *MockMigrator) FetchAllURLsPage(ctx context.Context, after string, limit int) ([]models.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchAllURLsPage", ctx, after, limit)
+	ret0, _ := ret[0].([]models.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchAllURLsPage indicates an expected call of FetchAllURLsPage.
+func (mr *MockMigratorMockRecorder) FetchAllURLsPage(ctx, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchAllURLsPage", reflect.TypeOf((*MockMigrator)(nil).FetchAllURLsPage), ctx, after, limit)
+}
+
+// FetchAuditEvents mocks base method.
+func (m *MockMigrator) FetchAuditEvents(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchAuditEvents", ctx, filter)
+	ret0, _ := ret[0].([]models.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchAuditEvents indicates an expected call of FetchAuditEvents.
+func (mr *MockMigratorMockRecorder) FetchAuditEvents(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchAuditEvents", reflect.TypeOf((*MockMigrator)(nil).FetchAuditEvents), ctx, filter)
+}
+
+// FetchDeadWebhookDeliveries mocks base method.
+func (m *MockMigrator) FetchDeadWebhookDeliveries(ctx context.Context) ([]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchDeadWebhookDeliveries", ctx)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchDeadWebhookDeliveries indicates an expected call of FetchDeadWebhookDeliveries.
+func (mr *MockMigratorMockRecorder) FetchDeadWebhookDeliveries(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchDeadWebhookDeliveries", reflect.TypeOf((*MockMigrator)(nil).FetchDeadWebhookDeliveries), ctx)
+}
+
+// FetchDueWebhookDeliveries mocks base method.
+func (m *MockMigrator) FetchDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchDueWebhookDeliveries", ctx, now, limit)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchDueWebhookDeliveries indicates an expected call of FetchDueWebhookDeliveries.
+func (mr *MockMigratorMockRecorder) FetchDueWebhookDeliveries(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchDueWebhookDeliveries", reflect.TypeOf((*MockMigrator)(nil).FetchDueWebhookDeliveries), ctx, now, limit)
+}
+
+// FetchEventWebhooks mocks base method.
+func (m *MockMigrator) FetchEventWebhooks(ctx context.Context, userID, event string) ([]models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchEventWebhooks", ctx, userID, event)
+	ret0, _ := ret[0].([]models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchEventWebhooks indicates an expected call of FetchEventWebhooks.
+func (mr *MockMigratorMockRecorder) FetchEventWebhooks(ctx, userID, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchEventWebhooks", reflect.TypeOf((*MockMigrator)(nil).FetchEventWebhooks), ctx, userID, event)
+}
+
+// FetchOrgMembers mocks base method.
+func (m *MockMigrator) FetchOrgMembers(ctx context.Context, orgID string) ([]models.Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchOrgMembers", ctx, orgID)
+	ret0, _ := ret[0].([]models.Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchOrgMembers indicates an expected call of FetchOrgMembers.
+func (mr *MockMigratorMockRecorder) FetchOrgMembers(ctx, orgID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchOrgMembers", reflect.TypeOf((*MockMigrator)(nil).FetchOrgMembers), ctx, orgID)
+}
+
+// FetchOrgURLs mocks base method.
+func (m *MockMigrator) FetchOrgURLs(ctx context.Context, orgID string) ([]models.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchOrgURLs", ctx, orgID)
+	ret0, _ := ret[0].([]models.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchOrgURLs indicates an expected call of FetchOrgURLs.
+func (mr *MockMigratorMockRecorder) FetchOrgURLs(ctx, orgID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchOrgURLs", reflect.TypeOf((*MockMigrator)(nil).FetchOrgURLs), ctx, orgID)
+}
+
+// FetchStats mocks base method.
+func (m *MockMigrator) FetchStats(ctx context.Context) (int, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchStats", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FetchStats indicates an expected call of FetchStats.
+func (mr *MockMigratorMockRecorder) FetchStats(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchStats", reflect.TypeOf((*MockMigrator)(nil).FetchStats), ctx)
+}
+
+// FetchURLsPage mocks base method.
+func (m *MockMigrator) FetchURLsPage(ctx context.Context, orgID, after string, limit int) ([]models.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchURLsPage", ctx, orgID, after, limit)
+	ret0, _ := ret[0].([]models.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchURLsPage indicates an expected call of FetchURLsPage.
+func (mr *MockMigratorMockRecorder) FetchURLsPage(ctx, orgID, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchURLsPage", reflect.TypeOf((*MockMigrator)(nil).FetchURLsPage), ctx, orgID, after, limit)
+}
+
+// FetchUserOrgs mocks base method.
+func (m *MockMigrator) FetchUserOrgs(ctx context.Context) ([]models.UserOrg, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchUserOrgs", ctx)
+	ret0, _ := ret[0].([]models.UserOrg)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchUserOrgs indicates an expected call of FetchUserOrgs.
+func (mr *MockMigratorMockRecorder) FetchUserOrgs(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchUserOrgs", reflect.TypeOf((*MockMigrator)(nil).FetchUserOrgs), ctx)
+}
+
+// FetchUserURLs mocks base method.
+func (m *MockMigrator) FetchUserURLs(ctx context.Context) ([]models.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchUserURLs", ctx)
+	ret0, _ := ret[0].([]models.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchUserURLs indicates an expected call of FetchUserURLs.
+func (mr *MockMigratorMockRecorder) FetchUserURLs(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchUserURLs", reflect.TypeOf((*MockMigrator)(nil).FetchUserURLs), ctx)
+}
+
+// FetchUserWebhooks mocks base method.
+func (m *MockMigrator) FetchUserWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchUserWebhooks", ctx)
+	ret0, _ := ret[0].([]models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchUserWebhooks indicates an expected call of FetchUserWebhooks.
+func (mr *MockMigratorMockRecorder) FetchUserWebhooks(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchUserWebhooks", reflect.TypeOf((*MockMigrator)(nil).FetchUserWebhooks), ctx)
+}
+
+// GetOrgRole mocks base method.
+func (m *MockMigrator) GetOrgRole(ctx context.Context, orgID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrgRole", ctx, orgID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrgRole indicates an expected call of GetOrgRole.
+func (mr *MockMigratorMockRecorder) GetOrgRole(ctx, orgID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrgRole", reflect.TypeOf((*MockMigrator)(nil).GetOrgRole), ctx, orgID)
+}
+
+// GetURL mocks base method.
+func (m *MockMigrator) GetURL(ctx context.Context, shortURL string) (models.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetURL", ctx, shortURL)
+	ret0, _ := ret[0].(models.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetURL indicates an expected call of GetURL.
+func (mr *MockMigratorMockRecorder) GetURL(ctx, shortURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetURL", reflect.TypeOf((*MockMigrator)(nil).GetURL), ctx, shortURL)
+}
+
+// ImportOrgs mocks base method.
+func (m *MockMigrator) ImportOrgs(ctx context.Context, orgs []models.Org, members []models.Member) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportOrgs", ctx, orgs, members)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ImportOrgs indicates an expected call of ImportOrgs.
+func (mr *MockMigratorMockRecorder) ImportOrgs(ctx, orgs, members interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportOrgs", reflect.TypeOf((*MockMigrator)(nil).ImportOrgs), ctx, orgs, members)
+}
+
+// ImportURLs mocks base method.
+func (m *MockMigrator) ImportURLs(ctx context.Context, urls []models.URL) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportURLs", ctx, urls)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportURLs indicates an expected call of ImportURLs.
+func (mr *MockMigratorMockRecorder) ImportURLs(ctx, urls interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportURLs", reflect.TypeOf((*MockMigrator)(nil).ImportURLs), ctx, urls)
+}
+
+// Ping mocks base method.
+func (m *MockMigrator) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockMigratorMockRecorder) Ping(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockMigrator)(nil).Ping), ctx)
+}
+
+// RefundDailyQuota mocks base method.
+func (m *MockMigrator) RefundDailyQuota(ctx context.Context, key string, count int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefundDailyQuota", ctx, key, count)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RefundDailyQuota indicates an expected call of RefundDailyQuota.
+func (mr *MockMigratorMockRecorder) RefundDailyQuota(ctx, key, count interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefundDailyQuota", reflect.TypeOf((*MockMigrator)(nil).RefundDailyQuota), ctx, key, count)
+}
+
+// StoreAuditEvents mocks base method.
+func (m *MockMigrator) StoreAuditEvents(ctx context.Context, events []models.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreAuditEvents", ctx, events)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreAuditEvents indicates an expected call of StoreAuditEvents.
+func (mr *MockMigratorMockRecorder) StoreAuditEvents(ctx, events interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreAuditEvents", reflect.TypeOf((*MockMigrator)(nil).StoreAuditEvents), ctx, events)
+}
+
+// StoreOrg mocks base method.
+func (m *MockMigrator) StoreOrg(ctx context.Context, org models.Org) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreOrg", ctx, org)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreOrg indicates an expected call of StoreOrg.
+func (mr *MockMigratorMockRecorder) StoreOrg(ctx, org interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreOrg", reflect.TypeOf((*MockMigrator)(nil).StoreOrg), ctx, org)
+}
+
+// StoreOrgMember mocks base method.
+func (m *MockMigrator) StoreOrgMember(ctx context.Context, member models.Member) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreOrgMember", ctx, member)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreOrgMember indicates an expected call of StoreOrgMember.
+func (mr *MockMigratorMockRecorder) StoreOrgMember(ctx, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreOrgMember", reflect.TypeOf((*MockMigrator)(nil).StoreOrgMember), ctx, member)
+}
+
+// StoreShortURL mocks base method.
+func (m *MockMigrator) StoreShortURL(ctx context.Context, shortURL, originalURL string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreShortURL", ctx, shortURL, originalURL)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreShortURL indicates an expected call of StoreShortURL.
+func (mr *MockMigratorMockRecorder) StoreShortURL(ctx, shortURL, originalURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreShortURL", reflect.TypeOf((*MockMigrator)(nil).StoreShortURL), ctx, shortURL, originalURL)
+}
+
+// StoreShortURLs mocks base method.
+func (m *MockMigrator) StoreShortURLs(ctx context.Context, urls []models.URL) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreShortURLs", ctx, urls)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreShortURLs indicates an expected call of StoreShortURLs.
+func (mr *MockMigratorMockRecorder) StoreShortURLs(ctx, urls interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreShortURLs", reflect.TypeOf((*MockMigrator)(nil).StoreShortURLs), ctx, urls)
+}
+
+// StoreWebhook mocks base method.
+func (m *MockMigrator) StoreWebhook(ctx context.Context, webhook models.Webhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreWebhook", ctx, webhook)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreWebhook indicates an expected call of StoreWebhook.
+func (mr *MockMigratorMockRecorder) StoreWebhook(ctx, webhook interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreWebhook", reflect.TypeOf((*MockMigrator)(nil).StoreWebhook), ctx, webhook)
+}
+
+// StoreWebhookDeliveries mocks base method.
+func (m *MockMigrator) StoreWebhookDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreWebhookDeliveries", ctx, deliveries)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreWebhookDeliveries indicates an expected call of StoreWebhookDeliveries.
+func (mr *MockMigratorMockRecorder) StoreWebhookDeliveries(ctx, deliveries interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreWebhookDeliveries", reflect.TypeOf((*MockMigrator)(nil).StoreWebhookDeliveries), ctx, deliveries)
+}
+
+// UpdateWebhook mocks base method.
+func (m *MockMigrator) UpdateWebhook(ctx context.Context, webhook models.Webhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhook", ctx, webhook)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebhook indicates an expected call of UpdateWebhook.
+func (mr *MockMigratorMockRecorder) UpdateWebhook(ctx, webhook interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhook", reflect.TypeOf((*MockMigrator)(nil).UpdateWebhook), ctx, webhook)
+}
+
+// UpdateWebhookDelivery mocks base method.
+func (m *MockMigrator) UpdateWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhookDelivery", ctx, delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebhookDelivery indicates an expected call of UpdateWebhookDelivery.
+func (mr *MockMigratorMockRecorder) UpdateWebhookDelivery(ctx, delivery interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhookDelivery", reflect.TypeOf((*MockMigrator)(nil).UpdateWebhookDelivery), ctx, delivery)
+}
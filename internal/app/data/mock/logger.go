@@ -34,19 +34,19 @@ func (m *MockLogger) EXPECT() *MockLoggerMockRecorder {
 	return m.recorder
 }
 
-// Info mocks base method.
-func (m *MockLogger) Info(msg string, fields ...zapcore.Field) {
+// Debug mocks base method.
+func (m *MockLogger) Debug(msg string, fields ...zapcore.Field) {
 	m.ctrl.T.Helper()
 	varargs := []interface{}{msg}
 	for _, a := range fields {
 		varargs = append(varargs, a)
 	}
-	m.ctrl.Call(m, "Info", varargs...)
+	m.ctrl.Call(m, "Debug", varargs...)
 }
 
-// Info indicates an expected call of Info.
-func (mr *MockLoggerMockRecorder) Info(msg interface{}, fields ...interface{}) *gomock.Call {
+// Debug indicates an expected call of Debug.
+func (mr *MockLoggerMockRecorder) Debug(msg interface{}, fields ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	varargs := append([]interface{}{msg}, fields...)
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Info", reflect.TypeOf((*MockLogger)(nil).Info), varargs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Debug", reflect.TypeOf((*MockLogger)(nil).Debug), varargs...)
 }
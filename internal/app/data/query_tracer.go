@@ -6,24 +6,56 @@ import (
 	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/logger"
 )
 
+// redactedArg значение, которым в журнале заменяются строковые аргументы запросов.
+const redactedArg = "[REDACTED]"
+
 // Logger интерфейс к логгеру.
 type Logger interface {
-	Info(msg string, fields ...zapcore.Field)
+	Debug(msg string, fields ...zapcore.Field)
 }
 
 type queryTracer struct {
-	logger Logger
+	logger  Logger
+	logArgs bool // писать аргументы запросов как есть, без скрытия
 }
 
-// TraceQueryStart метод начала трасировки запроса.
+// TraceQueryStart метод начала трасировки запроса, в журнал добавляются поля запроса из контекста.
+// Строковые аргументы (ссылки, идентификаторы пользователей) скрываются, если не включен logArgs.
 func (t *queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
-	t.logger.Info("Running query", zap.String("query", data.SQL), zap.Any("args", data.Args))
+	args := data.Args
+	if !t.logArgs {
+		args = redactArgs(args)
+	}
+
+	fields := append([]zapcore.Field{zap.String("query", data.SQL), zap.Any("args", args)}, logger.Fields(ctx)...)
+	t.logger.Debug("Running query", fields...)
 	return ctx
 }
 
 // TraceQueryEnd метод окончания трасировки запроса.
-func (t *queryTracer) TraceQueryEnd(_ context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
-	t.logger.Info("End query", zap.Any("tag", data.CommandTag))
+func (t *queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	t.logger.Debug("End query", append([]zapcore.Field{zap.Any("tag", data.CommandTag)}, logger.Fields(ctx)...)...)
+}
+
+// redactArgs возвращает копию аргументов, в которой строки и байты заменены на redactedArg.
+func redactArgs(args []any) []any {
+	if args == nil {
+		return nil
+	}
+
+	redacted := make([]any, len(args))
+	for i, arg := range args {
+		switch arg.(type) {
+		case string, []byte, []string, *string:
+			redacted[i] = redactedArg
+		default:
+			redacted[i] = arg
+		}
+	}
+
+	return redacted
 }
@@ -20,7 +20,7 @@ func TestTraceQueryStart(t *testing.T) {
 	ctx := context.Background()
 	data := pgx.TraceQueryStartData{}
 
-	logger.EXPECT().Info("Running query", zap.String("query", data.SQL), zap.Any("args", data.Args)).Times(1)
+	logger.EXPECT().Debug("Running query", zap.String("query", data.SQL), zap.Any("args", data.Args)).Times(1)
 
 	t.Run("log start", func(t *testing.T) {
 		returnCtx := tracer.TraceQueryStart(ctx, nil, data)
@@ -37,9 +37,36 @@ func TestTraceQueryEnd(t *testing.T) {
 	ctx := context.Background()
 	data := pgx.TraceQueryEndData{}
 
-	logger.EXPECT().Info("End query", zap.Any("tag", data.CommandTag)).Times(1)
+	logger.EXPECT().Debug("End query", zap.Any("tag", data.CommandTag)).Times(1)
 
 	t.Run("log end", func(t *testing.T) {
 		tracer.TraceQueryEnd(ctx, nil, data)
 	})
 }
+
+func TestTraceQueryStart_RedactArgs(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	data := pgx.TraceQueryStartData{SQL: "SELECT 1", Args: []any{"https://practicum.yandex.ru", 10, []byte("secret")}}
+
+	t.Run("redact string args", func(t *testing.T) {
+		logger := mock.NewMockLogger(mockCtrl)
+		tracer := queryTracer{logger: logger}
+
+		logger.EXPECT().Debug("Running query",
+			zap.String("query", data.SQL), zap.Any("args", []any{redactedArg, 10, redactedArg}),
+		).Times(1)
+
+		tracer.TraceQueryStart(context.Background(), nil, data)
+	})
+
+	t.Run("log args as is", func(t *testing.T) {
+		logger := mock.NewMockLogger(mockCtrl)
+		tracer := queryTracer{logger: logger, logArgs: true}
+
+		logger.EXPECT().Debug("Running query", zap.String("query", data.SQL), zap.Any("args", data.Args)).Times(1)
+
+		tracer.TraceQueryStart(context.Background(), nil, data)
+	})
+}
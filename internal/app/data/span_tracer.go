@@ -0,0 +1,111 @@
+package data
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/tracing"
+)
+
+const batchSpanName = "BATCH"
+
+// spanTracer создает дочерние спаны OpenTelemetry для запросов к БД.
+// В спан попадает только текст запроса, аргументы с данными пользователей не записываются.
+type spanTracer struct{}
+
+// TraceQueryStart метод начала спана запроса.
+func (spanTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctx, _ = tracing.Start(ctx, queryOperation(data.SQL),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBStatement(data.SQL)),
+	)
+
+	return ctx
+}
+
+// TraceQueryEnd метод окончания спана запроса.
+func (spanTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	tracing.End(trace.SpanFromContext(ctx), &data.Err)
+}
+
+// TraceBatchStart метод начала спана пакета запросов.
+func (spanTracer) TraceBatchStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	ctx, _ = tracing.Start(ctx, batchSpanName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBOperation(batchSpanName)),
+	)
+
+	return ctx
+}
+
+// TraceBatchQuery метод учета запроса пакета событием спана.
+func (spanTracer) TraceBatchQuery(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchQueryData) {
+	trace.SpanFromContext(ctx).AddEvent(queryOperation(data.SQL), trace.WithAttributes(semconv.DBStatement(data.SQL)))
+}
+
+// TraceBatchEnd метод окончания спана пакета запросов.
+func (spanTracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	tracing.End(trace.SpanFromContext(ctx), &data.Err)
+}
+
+// queryOperation возвращает первое слово запроса как имя операции.
+func queryOperation(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "QUERY"
+	}
+
+	return strings.ToUpper(fields[0])
+}
+
+// tracers объединяет трассировщики запросов pgx, пакеты передаются тем, кто их поддерживает.
+type tracers []pgx.QueryTracer
+
+// TraceQueryStart метод начала трасировки запроса.
+func (ts tracers) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	for _, t := range ts {
+		ctx = t.TraceQueryStart(ctx, conn, data)
+	}
+
+	return ctx
+}
+
+// TraceQueryEnd метод окончания трасировки запроса.
+func (ts tracers) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	for _, t := range ts {
+		t.TraceQueryEnd(ctx, conn, data)
+	}
+}
+
+// TraceBatchStart метод начала трасировки пакета запросов.
+func (ts tracers) TraceBatchStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	for _, t := range ts {
+		if bt, ok := t.(pgx.BatchTracer); ok {
+			ctx = bt.TraceBatchStart(ctx, conn, data)
+		}
+	}
+
+	return ctx
+}
+
+// TraceBatchQuery метод трасировки запроса пакета.
+func (ts tracers) TraceBatchQuery(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchQueryData) {
+	for _, t := range ts {
+		if bt, ok := t.(pgx.BatchTracer); ok {
+			bt.TraceBatchQuery(ctx, conn, data)
+		}
+	}
+}
+
+// TraceBatchEnd метод окончания трасировки пакета запросов.
+func (ts tracers) TraceBatchEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchEndData) {
+	for _, t := range ts {
+		if bt, ok := t.(pgx.BatchTracer); ok {
+			bt.TraceBatchEnd(ctx, conn, data)
+		}
+	}
+}
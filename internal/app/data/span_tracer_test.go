@@ -0,0 +1,70 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/data/mock"
+)
+
+func TestTracers(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	logger := mock.NewMockLogger(mockCtrl)
+	tracer := tracers{&queryTracer{logger: logger}, spanTracer{}}
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "services.GetURL")
+	defer parent.End()
+
+	t.Run("query span", func(t *testing.T) {
+		sql := "\n\t\tSELECT original_url FROM urls WHERE short_url = $1"
+		args := []any{"secret"}
+		queryErr := errors.New("some error")
+
+		logger.EXPECT().Debug("Running query", zap.String("query", sql), zap.Any("args", []any{redactedArg})).Times(1)
+		logger.EXPECT().Debug("End query", gomock.Any()).Times(1)
+
+		queryCtx := tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: sql, Args: args})
+		tracer.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{Err: queryErr})
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "SELECT", spans[0].Name())
+		assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+		assert.Contains(t, spans[0].Attributes(), semconv.DBStatement(sql))
+		assert.Equal(t, "some error", spans[0].Status().Description)
+		for _, attr := range spans[0].Attributes() {
+			assert.NotContains(t, attr.Value.Emit(), "secret")
+		}
+	})
+
+	t.Run("batch span", func(t *testing.T) {
+		batchCtx := tracer.TraceBatchStart(ctx, nil, pgx.TraceBatchStartData{})
+		tracer.TraceBatchQuery(batchCtx, nil, pgx.TraceBatchQueryData{SQL: "INSERT INTO urls VALUES ($1)"})
+		tracer.TraceBatchEnd(batchCtx, nil, pgx.TraceBatchEndData{})
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 2)
+		assert.Equal(t, batchSpanName, spans[1].Name())
+		require.Len(t, spans[1].Events(), 1)
+		assert.Equal(t, "INSERT", spans[1].Events()[0].Name)
+	})
+}
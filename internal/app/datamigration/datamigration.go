@@ -0,0 +1,298 @@
+// Пакет datamigration предназначен для переноса данных сервиса между хранилищами.
+// Ссылки копируются как есть, вместе с пользователем, организацией и признаком удаления,
+// уже существующие в приемнике короткие ссылки пропускаются. Дневные квоты не переносятся.
+package datamigration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+)
+
+// DefaultPageSize размер страницы ссылок по умолчанию.
+const DefaultPageSize = 500
+
+const stateFilePerm os.FileMode = 0o600
+
+var (
+	// ErrCountMismatch ошибка сверки числа ссылок после переноса.
+	ErrCountMismatch = errors.New("url count mismatch")
+	// ErrStateMismatch ошибка продолжения переноса по файлу состояния другого источника или приемника.
+	ErrStateMismatch = errors.New("state file belongs to another migration")
+)
+
+// Options настройки переноса.
+type Options struct {
+	Source    string // адрес источника, сохраняется в файле состояния для проверки при продолжении
+	Target    string // адрес приемника, сохраняется в файле состояния для проверки при продолжении
+	StatePath string // файл состояния для продолжения прерванного переноса, пустой - без продолжения
+	PageSize  int    // число ссылок, читаемых и сохраняемых за один раз
+	DryRun    bool   // только посчитать, что будет перенесено, не изменяя приемник
+}
+
+// Report итог переноса.
+type Report struct {
+	SourceURLs   int  `json:"source_urls"`   // число ссылок в источнике
+	TargetBefore int  `json:"target_before"` // число ссылок в приемнике до начала переноса
+	TargetAfter  int  `json:"target_after"`  // число ссылок в приемнике после переноса
+	Copied       int  `json:"copied"`        // перенесено ссылок
+	Skipped      int  `json:"skipped"`       // пропущено ссылок, уже существующих в приемнике
+	Orgs         int  `json:"orgs"`          // перенесено организаций
+	Members      int  `json:"members"`       // перенесено участников организаций
+	Resumed      bool `json:"resumed"`       // перенос продолжен по файлу состояния
+	DryRun       bool `json:"dry_run"`       // пробный запуск
+}
+
+// state состояние переноса, сохраняемое после каждой страницы.
+type state struct {
+	Source       string `json:"source"`
+	Target       string `json:"target"`
+	After        string `json:"after"`
+	Copied       int    `json:"copied"`
+	Skipped      int    `json:"skipped"`
+	TargetBefore int    `json:"target_before"`
+	OrgsDone     bool   `json:"orgs_done"`
+	Orgs         int    `json:"orgs"`
+	Members      int    `json:"members"`
+}
+
+// Run переносит организации и ссылки из from в to постранично и сверяет число ссылок.
+// При заданном Options.StatePath прогресс сохраняется после каждой страницы,
+// повторный запуск продолжает перенос с места остановки, файл удаляется после успешной сверки.
+func Run(ctx context.Context, logger *zap.Logger, from data.Migrator, to data.Migrator, opts Options) (Report, error) {
+	if opts.PageSize <= 0 {
+		opts.PageSize = DefaultPageSize
+	}
+
+	report := Report{DryRun: opts.DryRun}
+
+	st, resumed, err := loadState(opts.StatePath)
+	if err != nil {
+		return report, err
+	}
+	report.Resumed = resumed && !opts.DryRun
+
+	source, target := redactURL(opts.Source), redactURL(opts.Target)
+	if report.Resumed && (st.Source != source || st.Target != target) {
+		return report, fmt.Errorf("%w: state is for %s -> %s, not %s -> %s",
+			ErrStateMismatch, st.Source, st.Target, source, target)
+	}
+
+	if report.SourceURLs, err = from.CountURLs(ctx); err != nil {
+		return report, fmt.Errorf("failed to count source urls: %w", err)
+	}
+
+	targetCount, err := to.CountURLs(ctx)
+	if err != nil {
+		return report, fmt.Errorf("failed to count target urls: %w", err)
+	}
+
+	if !report.Resumed {
+		st = state{Source: source, Target: target, TargetBefore: targetCount}
+	}
+	report.TargetBefore = st.TargetBefore
+
+	logger.Info("migrating data",
+		zap.Int("source_urls", report.SourceURLs),
+		zap.Int("target_urls", targetCount),
+		zap.Bool("resumed", report.Resumed),
+		zap.Bool("dry_run", opts.DryRun),
+	)
+
+	if err := migrateOrgs(ctx, from, to, &st, opts); err != nil {
+		return report, err
+	}
+
+	if err := migrateURLs(ctx, logger, from, to, &st, opts); err != nil {
+		return report, err
+	}
+
+	report.Copied, report.Skipped = st.Copied, st.Skipped
+	report.Orgs, report.Members = st.Orgs, st.Members
+
+	if report.TargetAfter, err = to.CountURLs(ctx); err != nil {
+		return report, fmt.Errorf("failed to count target urls: %w", err)
+	}
+
+	if err := verify(report); err != nil {
+		return report, err
+	}
+
+	if opts.StatePath != "" && !opts.DryRun {
+		if err := os.Remove(opts.StatePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return report, fmt.Errorf("failed to remove state file: %w", err)
+		}
+	}
+
+	return report, nil
+}
+
+// migrateOrgs переносит организации до ссылок, так как ссылки могут на них ссылаться.
+func migrateOrgs(ctx context.Context, from data.Migrator, to data.Migrator, st *state, opts Options) error {
+	if st.OrgsDone {
+		return nil
+	}
+
+	orgs, members, err := from.FetchAllOrgs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch source orgs: %w", err)
+	}
+
+	st.Orgs, st.Members = len(orgs), len(members)
+
+	if opts.DryRun {
+		return nil
+	}
+
+	if err := to.ImportOrgs(ctx, orgs, members); err != nil {
+		return fmt.Errorf("failed to import orgs: %w", err)
+	}
+
+	st.OrgsDone = true
+
+	return saveState(opts.StatePath, st)
+}
+
+func migrateURLs(ctx context.Context, logger *zap.Logger, from data.Migrator, to data.Migrator, st *state, opts Options) error {
+	for {
+		page, err := from.FetchAllURLsPage(ctx, st.After, opts.PageSize)
+		if err != nil {
+			return fmt.Errorf("failed to fetch source urls after %q: %w", st.After, err)
+		}
+		if len(page) == 0 {
+			return nil
+		}
+
+		copied, err := copyPage(ctx, to, page, opts.DryRun)
+		if err != nil {
+			return err
+		}
+
+		st.Copied += copied
+		st.Skipped += len(page) - copied
+		st.After = page[len(page)-1].ShortURL
+
+		if !opts.DryRun {
+			if err := saveState(opts.StatePath, st); err != nil {
+				return err
+			}
+		}
+
+		logger.Info("migrated urls page",
+			zap.String("after", st.After),
+			zap.Int("copied", st.Copied),
+			zap.Int("skipped", st.Skipped),
+		)
+	}
+}
+
+// copyPage сохраняет страницу в приемнике, при пробном запуске только проверяет наличие ссылок в нем.
+func copyPage(ctx context.Context, to data.Migrator, page []models.URL, dryRun bool) (int, error) {
+	if !dryRun {
+		copied, err := to.ImportURLs(ctx, page)
+		if err != nil {
+			return 0, fmt.Errorf("failed to import urls: %w", err)
+		}
+
+		return copied, nil
+	}
+
+	copied := 0
+
+	for _, u := range page {
+		_, err := to.GetURL(ctx, u.ShortURL)
+		switch {
+		case errors.Is(err, data.ErrURLNotFound):
+			copied++
+		case err != nil:
+			return 0, fmt.Errorf("failed to check target url %s: %w", u.ShortURL, err)
+		}
+	}
+
+	return copied, nil
+}
+
+// verify сверяет число ссылок: каждая ссылка источника перенесена или пропущена,
+// а число ссылок приемника выросло ровно на число перенесенных.
+// При продолжении переноса страница, сохраненная в приемнике перед прерыванием, но не отмеченная
+// в файле состояния, повторно считается пропущенной, поэтому приемник может вырасти больше,
+// но не больше чем на число перенесенных и пропущенных ссылок.
+func verify(r Report) error {
+	if r.Copied+r.Skipped != r.SourceURLs {
+		return fmt.Errorf("%w: source has %d urls, copied %d and skipped %d",
+			ErrCountMismatch, r.SourceURLs, r.Copied, r.Skipped)
+	}
+
+	if r.DryRun {
+		return nil
+	}
+
+	grown := r.TargetAfter - r.TargetBefore
+	if grown == r.Copied || (r.Resumed && grown > r.Copied && grown <= r.Copied+r.Skipped) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: target grew from %d to %d urls, copied %d",
+		ErrCountMismatch, r.TargetBefore, r.TargetAfter, r.Copied)
+}
+
+// redactURL скрывает пароль в адресе хранилища, чтобы он не попадал в файл состояния.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+
+	return u.Redacted()
+}
+
+func loadState(path string) (state, bool, error) {
+	if path == "" {
+		return state{}, false, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return state{}, false, nil
+		}
+		return state{}, false, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	var st state
+	if err := json.Unmarshal(content, &st); err != nil {
+		return state{}, false, fmt.Errorf("failed to parse state file %s: %w", path, err)
+	}
+
+	return st, true, nil
+}
+
+func saveState(path string, st *state) error {
+	if path == "" {
+		return nil
+	}
+
+	content, err := json.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, stateFilePerm); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,194 @@
+// Пакет feed предназначен для рассылки событий ссылок в открытые потоки событий пользователей.
+package feed
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+)
+
+// EventReset событие, с которого начинается возобновленный поток, если пропущенные события уже вытеснены
+// из буфера или получены до перезапуска сервиса: клиенту нужно заново загрузить ссылки.
+// Номер события равен номеру последнего события рассылки, с него поток возобновляется в следующий раз.
+const EventReset = "reset"
+
+// subscriberBuffer число событий, которые подписчик может не забрать, прежде чем будет отключен.
+const subscriberBuffer = 64
+
+// Ошибки завершения подписки.
+var (
+	ErrClosed         = errors.New("link feed is closed")          // рассылка остановлена
+	ErrSlowSubscriber = errors.New("link feed subscriber is slow") // подписчик не успевал забирать события
+)
+
+// Event событие ссылки с номером в рассылке, по номеру клиент возобновляет поток.
+type Event struct {
+	ID   uint64
+	Link models.LinkEvent
+}
+
+// Hub рассылка событий ссылок подписчикам-владельцам ссылок. Последние события хранятся в буфере
+// для возобновления потока после переподключения. Публикация не блокируется медленными подписчиками:
+// подписчик с переполненной очередью отключается и может возобновить поток из буфера.
+type Hub struct {
+	subs   map[*Subscription]struct{}
+	replay []Event
+	size   int
+	seq    uint64
+	closed bool
+	mu     sync.Mutex
+}
+
+// NewHub создает рассылку с буфером последних size событий.
+func NewHub(size int) *Hub {
+	return &Hub{
+		subs: map[*Subscription]struct{}{},
+		size: max(size, 0),
+	}
+}
+
+// Publish рассылает событие подписчикам владельца ссылки.
+// Подходит как обработчик services.OnLinkEvent.
+func (h *Hub) Publish(_ context.Context, link models.LinkEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed || link.UserID == "" {
+		return
+	}
+
+	h.seq++
+	event := Event{ID: h.seq, Link: link}
+
+	if h.size > 0 {
+		if len(h.replay) == h.size {
+			h.replay = append(h.replay[:0], h.replay[1:]...)
+		}
+		h.replay = append(h.replay, event)
+	}
+
+	for sub := range h.subs {
+		if sub.userID != link.UserID {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+			h.drop(sub, ErrSlowSubscriber)
+		}
+	}
+}
+
+// Subscribe подписывает пользователя userID на события его ссылок. Если lastEventID больше нуля,
+// поток возобновляется: сначала передаются события после lastEventID из буфера, а если часть из них
+// уже недоступна - только событие EventReset.
+func (h *Hub) Subscribe(userID string, lastEventID uint64) (*Subscription, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, ErrClosed
+	}
+
+	missed := h.missed(userID, lastEventID)
+	sub := &Subscription{
+		hub:    h,
+		userID: userID,
+		events: make(chan Event, len(missed)+subscriberBuffer),
+		done:   make(chan struct{}),
+	}
+	for _, event := range missed {
+		sub.events <- event
+	}
+	h.subs[sub] = struct{}{}
+
+	return sub, nil
+}
+
+// missed возвращает события пользователя после lastEventID, которые нужно передать при возобновлении потока.
+func (h *Hub) missed(userID string, lastEventID uint64) []Event {
+	if lastEventID == 0 {
+		return nil
+	}
+
+	oldest := h.seq + 1
+	if len(h.replay) > 0 {
+		oldest = h.replay[0].ID
+	}
+
+	if lastEventID > h.seq || lastEventID+1 < oldest {
+		return []Event{{ID: h.seq, Link: models.LinkEvent{
+			Time:   time.Now().UTC(),
+			Type:   EventReset,
+			UserID: userID,
+		}}}
+	}
+
+	var events []Event
+	for _, event := range h.replay {
+		if event.ID > lastEventID && event.Link.UserID == userID {
+			events = append(events, event)
+		}
+	}
+
+	return events
+}
+
+// Close останавливает рассылку и завершает все подписки с ошибкой ErrClosed.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for sub := range h.subs {
+		h.drop(sub, ErrClosed)
+	}
+}
+
+// drop завершает подписку с ошибкой err, вызывается под блокировкой.
+func (h *Hub) drop(sub *Subscription, err error) {
+	delete(h.subs, sub)
+	sub.err = err
+	close(sub.done)
+}
+
+// Subscription подписка на события ссылок пользователя.
+type Subscription struct {
+	hub    *Hub
+	userID string
+	events chan Event
+	done   chan struct{}
+	err    error
+}
+
+// Events возвращает канал событий подписки. Канал не закрывается, завершение подписки сообщает Done.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Done возвращает канал, закрываемый при завершении подписки рассылкой.
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err возвращает причину завершения подписки после закрытия Done: ErrClosed или ErrSlowSubscriber.
+func (s *Subscription) Err() error {
+	select {
+	case <-s.done:
+		return s.err
+	default:
+		return nil
+	}
+}
+
+// Close отписывает подписчика от рассылки.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	delete(s.hub.subs, s)
+}
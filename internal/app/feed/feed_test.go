@@ -0,0 +1,151 @@
+package feed
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+)
+
+func publish(h *Hub, userID string, shortURLs ...string) {
+	for _, shortURL := range shortURLs {
+		h.Publish(context.Background(), models.LinkEvent{Type: models.LinkCreated, ShortURL: shortURL, UserID: userID})
+	}
+}
+
+// received забирает из подписки уже доставленные события.
+func received(sub *Subscription) []Event {
+	var events []Event
+	for {
+		select {
+		case event := <-sub.Events():
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func shortURLs(events []Event) []string {
+	urls := make([]string, 0, len(events))
+	for _, event := range events {
+		urls = append(urls, event.Link.ShortURL)
+	}
+
+	return urls
+}
+
+func TestHub(t *testing.T) {
+	t.Run("delivers events of subscriber links", func(t *testing.T) {
+		h := NewHub(10)
+		sub, err := h.Subscribe("user_1", 0)
+		require.NoError(t, err)
+		defer sub.Close()
+
+		publish(h, "user_1", "a")
+		publish(h, "user_2", "b")
+		publish(h, "user_1", "c")
+
+		events := received(sub)
+		assert.Equal(t, []string{"a", "c"}, shortURLs(events))
+		assert.Equal(t, []uint64{1, 3}, []uint64{events[0].ID, events[1].ID})
+	})
+
+	t.Run("resume from replay buffer", func(t *testing.T) {
+		h := NewHub(10)
+		publish(h, "user_1", "a", "b")
+		publish(h, "user_2", "x")
+		publish(h, "user_1", "c")
+
+		sub, err := h.Subscribe("user_1", 1)
+		require.NoError(t, err)
+		defer sub.Close()
+
+		publish(h, "user_1", "d")
+
+		assert.Equal(t, []string{"b", "c", "d"}, shortURLs(received(sub)))
+	})
+
+	t.Run("reset when events are evicted", func(t *testing.T) {
+		h := NewHub(2)
+		publish(h, "user_1", "a", "b", "c", "d")
+
+		sub, err := h.Subscribe("user_1", 1)
+		require.NoError(t, err)
+		defer sub.Close()
+
+		events := received(sub)
+		require.Len(t, events, 1)
+		assert.Equal(t, EventReset, events[0].Link.Type)
+		assert.Equal(t, uint64(4), events[0].ID)
+
+		resumed, err := h.Subscribe("user_1", 2)
+		require.NoError(t, err)
+		defer resumed.Close()
+		assert.Equal(t, []string{"c", "d"}, shortURLs(received(resumed)), "oldest buffered event follows")
+	})
+
+	t.Run("reset after restart", func(t *testing.T) {
+		h := NewHub(10)
+		publish(h, "user_1", "a")
+
+		sub, err := h.Subscribe("user_1", 100)
+		require.NoError(t, err)
+		defer sub.Close()
+
+		events := received(sub)
+		require.Len(t, events, 1)
+		assert.Equal(t, EventReset, events[0].Link.Type)
+		assert.Equal(t, uint64(1), events[0].ID)
+	})
+
+	t.Run("slow subscriber is dropped", func(t *testing.T) {
+		h := NewHub(0)
+		slow, err := h.Subscribe("user_1", 0)
+		require.NoError(t, err)
+
+		for range subscriberBuffer + 1 {
+			publish(h, "user_1", "a")
+		}
+
+		<-slow.Done()
+		require.ErrorIs(t, slow.Err(), ErrSlowSubscriber)
+
+		other, err := h.Subscribe("user_1", 0)
+		require.NoError(t, err)
+		defer other.Close()
+		publish(h, "user_1", "b")
+		assert.Len(t, received(other), 1)
+	})
+
+	t.Run("close ends subscriptions", func(t *testing.T) {
+		h := NewHub(10)
+		sub, err := h.Subscribe("user_1", 0)
+		require.NoError(t, err)
+		assert.NoError(t, sub.Err())
+
+		h.Close()
+
+		<-sub.Done()
+		require.ErrorIs(t, sub.Err(), ErrClosed)
+		sub.Close()
+
+		_, err = h.Subscribe("user_1", 0)
+		require.ErrorIs(t, err, ErrClosed)
+	})
+
+	t.Run("closed subscription gets no events", func(t *testing.T) {
+		h := NewHub(10)
+		sub, err := h.Subscribe("user_1", 0)
+		require.NoError(t, err)
+
+		sub.Close()
+		publish(h, "user_1", "a")
+
+		assert.Empty(t, received(sub))
+		assert.NoError(t, sub.Err())
+	})
+}
@@ -2,16 +2,14 @@ package handlers
 
 import (
 	"encoding/json"
-	"errors"
 	"io"
 	"net/http"
-	"path"
 
 	"go.uber.org/zap"
 
 	"github.com/MihailSergeenkov/shortener/internal/app/common"
-	"github.com/MihailSergeenkov/shortener/internal/app/config"
 	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/logger"
 	"github.com/MihailSergeenkov/shortener/internal/app/models"
 	"github.com/MihailSergeenkov/shortener/internal/app/services"
 )
@@ -23,42 +21,34 @@ func AddHandler(l *zap.Logger, s data.Storager) http.HandlerFunc {
 
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
-			l.Error(common.ReadReqErrStr, zap.Error(err))
+			logger.FromContext(r.Context(), l).Error(common.ReadReqErrStr, zap.Error(err))
 			return
 		}
 
-		baseURL := config.Params.BaseURL
 		shortURL, err := services.AddShortURL(r.Context(), s, string(body))
 		if err != nil {
-			var origErr *data.OriginalURLAlreadyExistError
-			if errors.As(err, &origErr) {
+			if existing, ok := conflictResource(err); ok {
 				w.WriteHeader(http.StatusConflict)
-
-				newPath := path.Join(baseURL.Path, origErr.ShortURL)
-				baseURL.Path = newPath
-				_, err = w.Write([]byte(baseURL.String()))
+				_, err = w.Write([]byte(existing))
 
 				if err != nil {
 					w.WriteHeader(http.StatusInternalServerError)
-					l.Error("failed to write response body", zap.Error(err))
+					logger.FromContext(r.Context(), l).Error("failed to write response body", zap.Error(err))
 					return
 				}
 				return
 			}
 
-			w.WriteHeader(http.StatusInternalServerError)
-			l.Error("failed to add URL to storage", zap.Error(err))
+			writeError(l, w, r, err, "failed to add URL to storage")
 			return
 		}
 
-		newPath := path.Join(baseURL.Path, shortURL)
-		baseURL.Path = newPath
 		w.WriteHeader(http.StatusCreated)
-		_, err = w.Write([]byte(baseURL.String()))
+		_, err = w.Write([]byte(services.ShortLink(shortURL)))
 
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
-			l.Error("failed to write response body", zap.Error(err))
+			logger.FromContext(r.Context(), l).Error("failed to write response body", zap.Error(err))
 			return
 		}
 	}
@@ -71,40 +61,32 @@ func APIAddHandler(l *zap.Logger, s data.Storager) http.HandlerFunc {
 		dec := json.NewDecoder(r.Body)
 		if err := dec.Decode(&req); err != nil {
 			w.WriteHeader(http.StatusBadRequest)
-			l.Error(common.ReadReqErrStr, zap.Error(err))
+			logger.FromContext(r.Context(), l).Error(common.ReadReqErrStr, zap.Error(err))
 			return
 		}
 
 		shortURL, err := services.AddShortURL(r.Context(), s, req.URL)
-		baseURL := config.Params.BaseURL
-
 		if err != nil {
-			var origErr *data.OriginalURLAlreadyExistError
-			if errors.As(err, &origErr) {
+			if existing, ok := conflictResource(err); ok {
 				w.Header().Set(common.ContentTypeHeader, common.JSONContentType)
 				w.WriteHeader(http.StatusConflict)
 
-				newPath := path.Join(baseURL.Path, origErr.ShortURL)
-				baseURL.Path = newPath
-				resp := models.Response{Result: baseURL.String()}
+				resp := models.Response{Result: existing}
 
 				enc := json.NewEncoder(w)
 				if errEnc := enc.Encode(resp); errEnc != nil {
 					w.WriteHeader(http.StatusInternalServerError)
-					l.Error(common.EncRespErrStr, zap.Error(errEnc))
+					logger.FromContext(r.Context(), l).Error(common.EncRespErrStr, zap.Error(errEnc))
 					return
 				}
 				return
 			}
 
-			w.WriteHeader(http.StatusInternalServerError)
-			l.Error("failed to add URL to storage", zap.Error(err))
+			writeError(l, w, r, err, "failed to add URL to storage")
 			return
 		}
 
-		newPath := path.Join(baseURL.Path, shortURL)
-		baseURL.Path = newPath
-		resp := models.Response{Result: baseURL.String()}
+		resp := models.Response{Result: services.ShortLink(shortURL)}
 
 		w.Header().Set(common.ContentTypeHeader, common.JSONContentType)
 		w.WriteHeader(http.StatusCreated)
@@ -112,7 +94,7 @@ func APIAddHandler(l *zap.Logger, s data.Storager) http.HandlerFunc {
 		enc := json.NewEncoder(w)
 		if err := enc.Encode(resp); err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
-			l.Error(common.EncRespErrStr, zap.Error(err))
+			logger.FromContext(r.Context(), l).Error(common.EncRespErrStr, zap.Error(err))
 			return
 		}
 	}
@@ -126,15 +108,14 @@ func APIAddBatchHandler(l *zap.Logger, s data.Storager) http.HandlerFunc {
 		dec := json.NewDecoder(r.Body)
 		if err := dec.Decode(&req); err != nil {
 			w.WriteHeader(http.StatusBadRequest)
-			l.Error(common.ReadReqErrStr, zap.Error(err))
+			logger.FromContext(r.Context(), l).Error(common.ReadReqErrStr, zap.Error(err))
 			return
 		}
 
 		resp, err := services.AddBatchShortURL(r.Context(), s, req)
 
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			l.Error("failed to add URLs to storage", zap.Error(err))
+			writeError(l, w, r, err, "failed to add URLs to storage")
 			return
 		}
 
@@ -144,7 +125,7 @@ func APIAddBatchHandler(l *zap.Logger, s data.Storager) http.HandlerFunc {
 		enc := json.NewEncoder(w)
 		if err := enc.Encode(resp); err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
-			l.Error(common.EncRespErrStr, zap.Error(err))
+			logger.FromContext(r.Context(), l).Error(common.EncRespErrStr, zap.Error(err))
 			return
 		}
 	}
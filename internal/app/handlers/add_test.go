@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/config"
 	"github.com/MihailSergeenkov/shortener/internal/app/data"
 	"github.com/MihailSergeenkov/shortener/internal/app/data/mock"
 	"github.com/golang/mock/gomock"
@@ -126,6 +127,33 @@ func TestAddHandler_Failed(t *testing.T) {
 	}
 }
 
+func TestAddHandler_QuotaExceeded(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	logger := zap.NewNop()
+	storage := mock.NewMockStorager(mockCtrl)
+	currentUserID := "some_id"
+
+	config.Params.DailyURLsQuota = 1
+	defer func() { config.Params.DailyURLsQuota = 0 }()
+
+	storage.EXPECT().ConsumeDailyQuota(gomock.Any(), "user:some_id", 1, 1).Times(1).Return(data.ErrQuotaExceeded)
+
+	t.Run("quota exceeded", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("https://ya.ru/some"))
+		request = request.WithContext(context.WithValue(request.Context(), common.KeyUserID, currentUserID))
+		w := httptest.NewRecorder()
+		AddHandler(logger, storage)(w, request)
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+		assert.NotEmpty(t, res.Header.Get("Retry-After"))
+	})
+}
+
 func TestAPIAddHandler_Ok(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
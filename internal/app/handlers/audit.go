@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/logger"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+	"github.com/MihailSergeenkov/shortener/internal/app/services"
+)
+
+// APIFetchAuditEventsHandler обработчик получения событий журнала аудита.
+// Фильтры задаются параметрами action, user_id, short_url, transport, from и to (RFC 3339), limit.
+func APIFetchAuditEventsHandler(l *zap.Logger, s data.Storager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := parseAuditFilter(r.URL.Query())
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		events, err := services.FetchAuditEvents(r.Context(), s, filter)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidAuditFilter) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			logger.FromContext(r.Context(), l).Error("failed to fetch audit events from storage", zap.Error(err))
+			return
+		}
+
+		writeJSON(l, w, r, http.StatusOK, events)
+	}
+}
+
+func parseAuditFilter(query url.Values) (models.AuditFilter, error) {
+	filter := models.AuditFilter{
+		Action:    query.Get("action"),
+		UserID:    query.Get("user_id"),
+		ShortURL:  query.Get("short_url"),
+		Transport: query.Get("transport"),
+	}
+
+	var err error
+	if v := query.Get("from"); v != "" {
+		if filter.From, err = time.Parse(time.RFC3339, v); err != nil {
+			return models.AuditFilter{}, fmt.Errorf("failed to parse from: %w", err)
+		}
+	}
+	if v := query.Get("to"); v != "" {
+		if filter.To, err = time.Parse(time.RFC3339, v); err != nil {
+			return models.AuditFilter{}, fmt.Errorf("failed to parse to: %w", err)
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil {
+			return models.AuditFilter{}, fmt.Errorf("failed to parse limit: %w", err)
+		}
+	}
+
+	return filter, nil
+}
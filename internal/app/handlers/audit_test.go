@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/data/mock"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAPIFetchAuditEventsHandler(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	logger := zap.NewNop()
+	storage := mock.NewMockStorager(mockCtrl)
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	events := []models.AuditEvent{{Time: from, Action: models.AuditDelete, UserID: "user_1", Transport: "grpc"}}
+
+	t.Run("success fetch with filters", func(t *testing.T) {
+		filter := models.AuditFilter{From: from, Action: models.AuditDelete, UserID: "user_1", Limit: 1000}
+		storage.EXPECT().FetchAuditEvents(gomock.Any(), filter).Times(1).Return(events, nil)
+
+		target := "/api/internal/audit?action=delete&user_id=user_1&from=2024-05-01T00:00:00Z&limit=5000"
+		request := httptest.NewRequest(http.MethodGet, target, http.NoBody)
+		w := httptest.NewRecorder()
+		APIFetchAuditEventsHandler(logger, storage)(w, request)
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var got []models.AuditEvent
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+		assert.Equal(t, events, got)
+	})
+
+	t.Run("default limit", func(t *testing.T) {
+		storage.EXPECT().FetchAuditEvents(gomock.Any(), models.AuditFilter{Limit: 100}).Times(1).Return(events, nil)
+
+		request := httptest.NewRequest(http.MethodGet, "/api/internal/audit", http.NoBody)
+		w := httptest.NewRecorder()
+		APIFetchAuditEventsHandler(logger, storage)(w, request)
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	badQueries := []string{
+		"from=yesterday", "limit=ten", "limit=-1", "from=2024-05-02T00:00:00Z&to=2024-05-01T00:00:00Z",
+	}
+	for _, query := range badQueries {
+		t.Run("bad request "+query, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/api/internal/audit?"+query, http.NoBody)
+			w := httptest.NewRecorder()
+			APIFetchAuditEventsHandler(logger, storage)(w, request)
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		})
+	}
+
+	t.Run("when fetch failed", func(t *testing.T) {
+		storage.EXPECT().FetchAuditEvents(gomock.Any(), gomock.Any()).Times(1).Return(nil, errors.New("some error"))
+
+		request := httptest.NewRequest(http.MethodGet, "/api/internal/audit", http.NoBody)
+		w := httptest.NewRecorder()
+		APIFetchAuditEventsHandler(logger, storage)(w, request)
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	})
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/MihailSergeenkov/shortener/internal/app/common"
 	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/logger"
 	"github.com/MihailSergeenkov/shortener/internal/app/services"
 )
 
@@ -18,7 +19,7 @@ func APIDeleteUserURLsHandler(l *zap.Logger, s data.Storager) http.HandlerFunc {
 		dec := json.NewDecoder(r.Body)
 		if err := dec.Decode(&req); err != nil {
 			w.WriteHeader(http.StatusBadRequest)
-			l.Error(common.ReadReqErrStr, zap.Error(err))
+			logger.FromContext(r.Context(), l).Error(common.ReadReqErrStr, zap.Error(err))
 			return
 		}
 
@@ -26,7 +27,7 @@ func APIDeleteUserURLsHandler(l *zap.Logger, s data.Storager) http.HandlerFunc {
 
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
-			l.Error("failed to delete URLs from storage", zap.Error(err))
+			logger.FromContext(r.Context(), l).Error("failed to delete URLs from storage", zap.Error(err))
 			return
 		}
 
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/logger"
+	"github.com/MihailSergeenkov/shortener/internal/app/ratelimit"
+	"github.com/MihailSergeenkov/shortener/internal/app/services"
+)
+
+// httpStatuses соответствие видов ошибок сервиса кодам ответа HTTP.
+var httpStatuses = []struct {
+	kind error
+	code int
+}{
+	{kind: services.ErrInvalid, code: http.StatusBadRequest},
+	{kind: services.ErrPermission, code: http.StatusForbidden},
+	{kind: services.ErrNotFound, code: http.StatusNotFound},
+	{kind: services.ErrGone, code: http.StatusGone},
+	{kind: services.ErrConflict, code: http.StatusConflict},
+	{kind: services.ErrPrecondition, code: http.StatusConflict},
+	{kind: services.ErrExhausted, code: http.StatusTooManyRequests},
+	{kind: services.ErrUnavailable, code: http.StatusServiceUnavailable},
+}
+
+// httpStatus возвращает код ответа HTTP для ошибки сервиса, неизвестные ошибки считаются внутренними.
+func httpStatus(err error) int {
+	for _, s := range httpStatuses {
+		if errors.Is(err, s.kind) {
+			return s.code
+		}
+	}
+
+	return http.StatusInternalServerError
+}
+
+// writeError записывает код ответа для ошибки сервиса, внутренние ошибки логируются с сообщением msg
+// и полями запроса r.
+func writeError(l *zap.Logger, w http.ResponseWriter, r *http.Request, err error, msg string) {
+	code := httpStatus(err)
+
+	var svcErr *services.Error
+	if errors.As(err, &svcErr) && svcErr.RetryAfter > 0 {
+		w.Header().Set("Retry-After", ratelimit.RetryAfter(svcErr.RetryAfter))
+	}
+
+	if code == http.StatusInternalServerError {
+		logger.FromContext(r.Context(), l).Error(msg, zap.Error(err))
+	}
+
+	w.WriteHeader(code)
+}
+
+// conflictResource возвращает существующий ресурс, если ошибка сервиса - конфликт.
+func conflictResource(err error) (string, bool) {
+	var svcErr *services.Error
+	if !errors.As(err, &svcErr) || !errors.Is(svcErr.Kind, services.ErrConflict) {
+		return "", false
+	}
+
+	return svcErr.Resource, true
+}
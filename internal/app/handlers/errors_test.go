@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/services"
+)
+
+func TestWriteError(t *testing.T) {
+	logger := zap.NewNop()
+
+	tests := []struct {
+		name       string
+		err        error
+		code       int
+		retryAfter string
+	}{
+		{
+			name: "invalid",
+			err:  &services.Error{Kind: services.ErrInvalid, Err: errors.New("bad")},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "permission",
+			err:  fmt.Errorf("wrapped: %w", &services.Error{Kind: services.ErrPermission, Err: errors.New("denied")}),
+			code: http.StatusForbidden,
+		},
+		{
+			name: "not found",
+			err:  &services.Error{Kind: services.ErrNotFound, Err: errors.New("missing")},
+			code: http.StatusNotFound,
+		},
+		{
+			name: "gone",
+			err:  &services.Error{Kind: services.ErrGone, Err: services.ErrURLDeleted},
+			code: http.StatusGone,
+		},
+		{
+			name: "conflict",
+			err:  &services.Error{Kind: services.ErrConflict, Err: errors.New("exists")},
+			code: http.StatusConflict,
+		},
+		{
+			name: "precondition",
+			err:  &services.Error{Kind: services.ErrPrecondition, Err: services.ErrLastOwner},
+			code: http.StatusConflict,
+		},
+		{
+			name: "exhausted",
+			err: &services.Error{
+				Kind:       services.ErrExhausted,
+				Err:        errors.New("quota"),
+				RetryAfter: 90 * time.Second,
+			},
+			code:       http.StatusTooManyRequests,
+			retryAfter: "90",
+		},
+		{
+			name: "unavailable",
+			err:  &services.Error{Kind: services.ErrUnavailable, Err: errors.New("closed")},
+			code: http.StatusServiceUnavailable,
+		},
+		{
+			name: "internal",
+			err:  errors.New("some error"),
+			code: http.StatusInternalServerError,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			writeError(logger, w, httptest.NewRequest(http.MethodGet, "/", http.NoBody), test.err, "failed")
+
+			assert.Equal(t, test.code, w.Code)
+			assert.Equal(t, test.retryAfter, w.Header().Get("Retry-After"))
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/logger"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+	"github.com/MihailSergeenkov/shortener/internal/app/services"
+)
+
+// Заголовки и параметры потока событий.
+const (
+	EventStreamContentType = "text/event-stream"
+	LastEventIDHeader      = "Last-Event-ID"
+	LastEventIDParam       = "last_event_id" // для клиентов, которые не могут передать заголовок
+)
+
+// APIUserEventsHandler обработчик потока событий ссылок пользователя в формате Server-Sent Events.
+// Номер события передается в поле id, после переподключения поток возобновляется с события после
+// Last-Event-ID. Пока событий нет, каждые heartbeat отправляется комментарий для проверки соединения.
+// Поток завершается при отключении клиента, остановке сервиса или если клиент не успевает читать события.
+func APIUserEventsHandler(l *zap.Logger, heartbeat time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lastEventID, err := parseLastEventID(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		sub, err := services.SubscribeLinkEvents(r.Context(), lastEventID)
+		if err != nil {
+			writeError(l, w, r, err, "failed to subscribe to link events")
+			return
+		}
+		defer sub.Close()
+
+		rc := http.NewResponseController(w)
+		w.Header().Set(common.ContentTypeHeader, EventStreamContentType)
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		if err := rc.Flush(); err != nil {
+			logger.FromContext(r.Context(), l).Error("failed to flush event stream", zap.Error(err))
+			return
+		}
+
+		var heartbeats <-chan time.Time
+		if heartbeat > 0 {
+			ticker := time.NewTicker(heartbeat)
+			defer ticker.Stop()
+			heartbeats = ticker.C
+		}
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-sub.Done():
+				logger.FromContext(r.Context(), l).Debug("event stream closed", zap.Error(sub.Err()))
+				return
+			case event := <-sub.Events():
+				err = writeEvent(w, services.NewUserEvent(event))
+			case <-heartbeats:
+				_, err = io.WriteString(w, ": heartbeat\n\n")
+			}
+
+			if err == nil {
+				err = rc.Flush()
+			}
+			if err != nil {
+				logger.FromContext(r.Context(), l).Debug("failed to write event stream", zap.Error(err))
+				return
+			}
+		}
+	}
+}
+
+// parseLastEventID возвращает номер последнего полученного клиентом события, 0 - поток не возобновляется.
+func parseLastEventID(r *http.Request) (uint64, error) {
+	value := r.Header.Get(LastEventIDHeader)
+	if value == "" {
+		value = r.URL.Query().Get(LastEventIDParam)
+	}
+	if value == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse last event ID: %w", err)
+	}
+
+	return id, nil
+}
+
+// writeEvent записывает событие в формате Server-Sent Events.
+func writeEvent(w io.Writer, event models.UserEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+
+	return nil
+}
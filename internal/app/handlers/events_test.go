@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/feed"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+	"github.com/MihailSergeenkov/shortener/internal/app/services"
+)
+
+// sseEvent событие потока, прочитанное клиентом.
+type sseEvent struct {
+	id      string
+	event   string
+	data    string
+	comment string
+}
+
+// readEvent читает из потока следующее событие или комментарий.
+func readEvent(t *testing.T, r *bufio.Reader) sseEvent {
+	t.Helper()
+
+	var e sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return e
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "":
+			e.comment = value
+		case "id":
+			e.id = value
+		case "event":
+			e.event = value
+		case "data":
+			e.data = value
+		}
+	}
+}
+
+func openEventStream(t *testing.T, url string, lastEventID string) *http.Response {
+	t.Helper()
+
+	request, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, http.NoBody)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		request.Header.Set(LastEventIDHeader, lastEventID)
+	}
+
+	res, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+
+	return res
+}
+
+func TestAPIUserEventsHandler(t *testing.T) {
+	storage := data.NewBaseStorage()
+	ctx := context.WithValue(context.Background(), common.KeyUserID, "owner_id")
+
+	handler := APIUserEventsHandler(zap.NewNop(), 50*time.Millisecond)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(w, r.WithContext(context.WithValue(r.Context(), common.KeyUserID, "owner_id")))
+	}))
+	defer server.Close()
+
+	stop := services.StartLinkFeed(10)
+	defer stop()
+
+	res := openEventStream(t, server.URL, "")
+	defer closeBody(t, res)
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, EventStreamContentType, res.Header.Get(common.ContentTypeHeader))
+	assert.Equal(t, "no-cache", res.Header.Get("Cache-Control"))
+	stream := bufio.NewReader(res.Body)
+
+	shortURL, err := services.AddShortURL(ctx, storage, "https://ya.ru")
+	require.NoError(t, err)
+
+	var created sseEvent
+	for created.id == "" {
+		created = readEvent(t, stream)
+	}
+	assert.Equal(t, "1", created.id)
+	assert.Equal(t, models.LinkCreated, created.event)
+
+	var event models.UserEvent
+	require.NoError(t, json.Unmarshal([]byte(created.data), &event))
+	assert.Equal(t, uint64(1), event.ID)
+	assert.Equal(t, shortURL, event.ShortURL)
+	assert.Equal(t, services.ShortLink(shortURL), event.ShortLink)
+	assert.Equal(t, "https://ya.ru", event.OriginalURL)
+
+	t.Run("heartbeat", func(t *testing.T) {
+		assert.Equal(t, "heartbeat", readEvent(t, stream).comment)
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		_, err := services.AddShortURL(ctx, storage, "https://ya.ru/other")
+		require.NoError(t, err)
+
+		resumed := openEventStream(t, server.URL, "1")
+		defer closeBody(t, resumed)
+
+		e := readEvent(t, bufio.NewReader(resumed.Body))
+		assert.Equal(t, "2", e.id)
+		assert.Equal(t, models.LinkCreated, e.event)
+	})
+
+	t.Run("resume after restart", func(t *testing.T) {
+		resumed := openEventStream(t, server.URL+"?"+LastEventIDParam+"=100", "")
+		defer closeBody(t, resumed)
+
+		e := readEvent(t, bufio.NewReader(resumed.Body))
+		assert.Equal(t, "2", e.id)
+		assert.Equal(t, feed.EventReset, e.event)
+	})
+
+	t.Run("bad last event id", func(t *testing.T) {
+		bad := openEventStream(t, server.URL, "abc")
+		defer closeBody(t, bad)
+
+		assert.Equal(t, http.StatusBadRequest, bad.StatusCode)
+	})
+
+	t.Run("stopped", func(t *testing.T) {
+		stop()
+
+		_, err := io.Copy(io.Discard, stream)
+		require.NoError(t, err, "stream is finished")
+
+		stopped := openEventStream(t, server.URL, "")
+		defer closeBody(t, stopped)
+
+		assert.Equal(t, http.StatusServiceUnavailable, stopped.StatusCode)
+	})
+}
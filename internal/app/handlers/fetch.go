@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"encoding/json"
-	"errors"
 	"net/http"
 	"strings"
 
@@ -10,6 +9,8 @@ import (
 
 	"github.com/MihailSergeenkov/shortener/internal/app/common"
 	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/logger"
+	"github.com/MihailSergeenkov/shortener/internal/app/metrics"
 	"github.com/MihailSergeenkov/shortener/internal/app/services"
 )
 
@@ -17,25 +18,15 @@ import (
 func FetchHandler(l *zap.Logger, s data.Storager) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		shortURL := strings.TrimLeft(r.URL.Path, "/")
-		u, err := s.GetURL(r.Context(), shortURL)
-
+		originalURL, err := services.GetURL(r.Context(), s, shortURL)
 		if err != nil {
-			if errors.Is(err, data.ErrURLNotFound) {
-				w.WriteHeader(http.StatusNotFound)
-				return
-			}
-
-			w.WriteHeader(http.StatusInternalServerError)
-			l.Error("failed to fetch URL from storage", zap.Error(err))
+			metrics.ObserveRedirect(httpStatus(err))
+			writeError(l, w, r, err, "failed to fetch URL from storage")
 			return
 		}
 
-		if u.DeletedFlag {
-			w.WriteHeader(http.StatusGone)
-			return
-		}
-
-		w.Header().Set("Location", u.OriginalURL)
+		metrics.ObserveRedirect(http.StatusTemporaryRedirect)
+		w.Header().Set("Location", originalURL)
 		w.WriteHeader(http.StatusTemporaryRedirect)
 	}
 }
@@ -46,8 +37,7 @@ func APIFetchUserURLsHandler(l *zap.Logger, s data.Storager) http.HandlerFunc {
 		resp, err := services.FetchUserURLs(r.Context(), s)
 
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			l.Error("failed to fetch URLs from storage", zap.Error(err))
+			writeError(l, w, r, err, "failed to fetch URLs from storage")
 			return
 		}
 
@@ -62,7 +52,7 @@ func APIFetchUserURLsHandler(l *zap.Logger, s data.Storager) http.HandlerFunc {
 		enc := json.NewEncoder(w)
 		if err := enc.Encode(resp); err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
-			l.Error(common.EncRespErrStr, zap.Error(err))
+			logger.FromContext(r.Context(), l).Error(common.EncRespErrStr, zap.Error(err))
 			return
 		}
 	}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/health"
+)
+
+// HealthzHandler обработчик проверки живости сервиса.
+// Отчет по компонентам отдается, только если detailed разрешает это для запроса.
+func HealthzHandler(l *zap.Logger, detailed func(r *http.Request) bool) http.HandlerFunc {
+	return healthReportHandler(l, health.Liveness, detailed)
+}
+
+// ReadyzHandler обработчик проверки готовности сервиса, после начала плавной остановки возвращает 503.
+func ReadyzHandler(l *zap.Logger, detailed func(r *http.Request) bool) http.HandlerFunc {
+	return healthReportHandler(l, health.Readiness, detailed)
+}
+
+// healthReportHandler возвращает отчет проверки: 200, если все в порядке, иначе 503.
+// Без доступа к деталям в отчете остается только общий статус.
+func healthReportHandler(
+	l *zap.Logger,
+	check func(ctx context.Context) health.Report,
+	detailed func(r *http.Request) bool,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := check(r.Context())
+
+		code := http.StatusOK
+		if !report.OK() {
+			code = http.StatusServiceUnavailable
+		}
+
+		if !detailed(r) {
+			report = health.Report{Status: report.Status}
+		}
+
+		writeJSON(l, w, r, code, report)
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/health"
+)
+
+func TestHealthHandlers(t *testing.T) {
+	l := zap.NewNop()
+
+	grpcState := &health.ServerState{}
+	health.RegisterReadiness("grpc", grpcState.Check)
+
+	detailed := func(*http.Request) bool { return true }
+	public := func(*http.Request) bool { return false }
+
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "alive",
+			handler:    HealthzHandler(l, detailed),
+			wantStatus: http.StatusOK,
+			wantBody:   `{"status":"ok"}`,
+		},
+		{
+			name:       "not ready",
+			handler:    ReadyzHandler(l, detailed),
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody: `{"status":"failing","components":{"grpc":{"status":"failing",` +
+				`"error":"server is not serving","details":{"state":"not_serving"}}}}`,
+		},
+		{
+			name:       "not ready without details",
+			handler:    ReadyzHandler(l, public),
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   `{"status":"failing"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			w := httptest.NewRecorder()
+			test.handler(w, request)
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.Equal(t, test.wantStatus, res.StatusCode)
+			assert.JSONEq(t, test.wantBody, w.Body.String())
+		})
+	}
+
+	t.Run("ready", func(t *testing.T) {
+		grpcState.SetServing(true)
+
+		w := httptest.NewRecorder()
+		ReadyzHandler(l, public)(w, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, health.StatusOK, health.Readiness(context.Background()).Status)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/MihailSergeenkov/shortener/internal/app/data"
 	"github.com/MihailSergeenkov/shortener/internal/app/models"
@@ -47,6 +48,102 @@ func (s *MockStorage) FetchStats(_ context.Context) (int, int, error) {
 	return 0, 0, nil
 }
 
+func (s *MockStorage) ConsumeDailyQuota(_ context.Context, key string, count int, limit int) error {
+	return nil
+}
+
+func (s *MockStorage) RefundDailyQuota(_ context.Context, key string, count int) error {
+	return nil
+}
+
+func (s *MockStorage) StoreOrg(_ context.Context, org models.Org) error {
+	return nil
+}
+
+func (s *MockStorage) FetchUserOrgs(_ context.Context) ([]models.UserOrg, error) {
+	return []models.UserOrg{}, nil
+}
+
+func (s *MockStorage) GetOrgRole(_ context.Context, orgID string) (string, error) {
+	return "", data.ErrMemberNotFound
+}
+
+func (s *MockStorage) FetchOrgMembers(_ context.Context, orgID string) ([]models.Member, error) {
+	return []models.Member{}, nil
+}
+
+func (s *MockStorage) StoreOrgMember(_ context.Context, member models.Member) error {
+	return nil
+}
+
+func (s *MockStorage) DeleteOrgMember(_ context.Context, orgID string, userID string) error {
+	return nil
+}
+
+func (s *MockStorage) FetchOrgURLs(_ context.Context, orgID string) ([]models.URL, error) {
+	return []models.URL{}, nil
+}
+
+func (s *MockStorage) FetchURLsPage(_ context.Context, _ string, _ string, _ int) ([]models.URL, error) {
+	return []models.URL{}, nil
+}
+
+func (s *MockStorage) StoreAuditEvents(_ context.Context, _ []models.AuditEvent) error {
+	return nil
+}
+
+func (s *MockStorage) FetchAuditEvents(_ context.Context, _ models.AuditFilter) ([]models.AuditEvent, error) {
+	return []models.AuditEvent{}, nil
+}
+
+func (s *MockStorage) DropAuditEvents(_ context.Context, _ time.Time) (int, error) {
+	return 0, nil
+}
+
+func (s *MockStorage) StoreWebhook(_ context.Context, _ models.Webhook) error {
+	return nil
+}
+
+func (s *MockStorage) FetchUserWebhooks(_ context.Context) ([]models.Webhook, error) {
+	return []models.Webhook{}, nil
+}
+
+func (s *MockStorage) UpdateWebhook(_ context.Context, _ models.Webhook) error {
+	return nil
+}
+
+func (s *MockStorage) DeleteWebhook(_ context.Context, _ string) error {
+	return nil
+}
+
+func (s *MockStorage) FetchEventWebhooks(_ context.Context, _ string, _ string) ([]models.Webhook, error) {
+	return []models.Webhook{}, nil
+}
+
+func (s *MockStorage) StoreWebhookDeliveries(_ context.Context, _ []models.WebhookDelivery) error {
+	return nil
+}
+
+func (s *MockStorage) FetchDueWebhookDeliveries(
+	_ context.Context,
+	_ time.Time,
+	_ int,
+) ([]models.WebhookDelivery, error) {
+	return []models.WebhookDelivery{}, nil
+}
+
+func (s *MockStorage) UpdateWebhookDelivery(_ context.Context, _ models.WebhookDelivery) error {
+	return nil
+}
+
+func (s *MockStorage) DeleteWebhookDelivery(_ context.Context, _ string) error {
+	return nil
+}
+
+func (s *MockStorage) FetchDeadWebhookDeliveries(_ context.Context) ([]models.WebhookDelivery, error) {
+	return []models.WebhookDelivery{}, nil
+}
+
 func (s *MockStorage) Ping(_ context.Context) error {
 	return nil
 }
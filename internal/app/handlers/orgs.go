@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/logger"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+	"github.com/MihailSergeenkov/shortener/internal/app/services"
+)
+
+// Параметры маршрутов управления организациями.
+const (
+	OrgIDParam  = "orgID"
+	UserIDParam = "userID"
+)
+
+// APIAddOrgHandler обработчик создания организации для API.
+func APIAddOrgHandler(l *zap.Logger, s data.Storager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req models.OrgRequest
+		dec := json.NewDecoder(r.Body)
+		if err := dec.Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			logger.FromContext(r.Context(), l).Error(common.ReadReqErrStr, zap.Error(err))
+			return
+		}
+
+		resp, err := services.CreateOrg(r.Context(), s, req.Name)
+		if err != nil {
+			writeError(l, w, r, err, "failed to add org to storage")
+			return
+		}
+
+		writeJSON(l, w, r, http.StatusCreated, resp)
+	}
+}
+
+// APIFetchUserOrgsHandler обработчик получения организаций пользователя для API.
+func APIFetchUserOrgsHandler(l *zap.Logger, s data.Storager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp, err := services.FetchUserOrgs(r.Context(), s)
+		if err != nil {
+			writeError(l, w, r, err, "failed to fetch orgs from storage")
+			return
+		}
+
+		if len(resp) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		writeJSON(l, w, r, http.StatusOK, resp)
+	}
+}
+
+// APIFetchOrgMembersHandler обработчик получения участников организации для API.
+func APIFetchOrgMembersHandler(l *zap.Logger, s data.Storager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp, err := services.FetchOrgMembers(r.Context(), s, chi.URLParam(r, OrgIDParam))
+		if err != nil {
+			writeError(l, w, r, err, "failed to fetch org members from storage")
+			return
+		}
+
+		writeJSON(l, w, r, http.StatusOK, resp)
+	}
+}
+
+// APISetOrgMemberHandler обработчик добавления участника организации или изменения его роли для API.
+func APISetOrgMemberHandler(l *zap.Logger, s data.Storager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req models.MemberRequest
+		dec := json.NewDecoder(r.Body)
+		if err := dec.Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			logger.FromContext(r.Context(), l).Error(common.ReadReqErrStr, zap.Error(err))
+			return
+		}
+
+		member := models.Member{
+			OrgID:  chi.URLParam(r, OrgIDParam),
+			UserID: req.UserID,
+			Role:   req.Role,
+		}
+
+		if err := services.SetOrgMember(r.Context(), s, member); err != nil {
+			writeError(l, w, r, err, "failed to store org member")
+			return
+		}
+
+		writeJSON(l, w, r, http.StatusOK, member)
+	}
+}
+
+// APIDeleteOrgMemberHandler обработчик исключения участника из организации для API.
+func APIDeleteOrgMemberHandler(l *zap.Logger, s data.Storager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		orgID := chi.URLParam(r, OrgIDParam)
+		userID := chi.URLParam(r, UserIDParam)
+
+		if err := services.RemoveOrgMember(r.Context(), s, orgID, userID); err != nil {
+			writeError(l, w, r, err, "failed to delete org member")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func writeJSON(l *zap.Logger, w http.ResponseWriter, r *http.Request, code int, resp any) {
+	w.Header().Set(common.ContentTypeHeader, common.JSONContentType)
+	w.WriteHeader(code)
+
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(resp); err != nil {
+		logger.FromContext(r.Context(), l).Error(common.EncRespErrStr, zap.Error(err))
+		return
+	}
+}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/data/mock"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+)
+
+func orgRequest(method string, target string, body string, params map[string]string) *http.Request {
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+
+	rctx := chi.NewRouteContext()
+	for k, v := range params {
+		rctx.URLParams.Add(k, v)
+	}
+
+	ctx := context.WithValue(request.Context(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, common.KeyUserID, "owner_id")
+
+	return request.WithContext(ctx)
+}
+
+func TestAPIAddOrgHandler(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	logger := zap.NewNop()
+	storage := mock.NewMockStorager(mockCtrl)
+
+	tests := []struct {
+		name     string
+		body     string
+		storeErr error
+		store    bool
+		code     int
+	}{
+		{
+			name:  "success create",
+			body:  `{"name": "Team"}`,
+			store: true,
+			code:  http.StatusCreated,
+		},
+		{
+			name: "bad request",
+			body: `sdfsdf`,
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "empty name",
+			body: `{"name": " "}`,
+			code: http.StatusBadRequest,
+		},
+		{
+			name:     "failed store",
+			body:     `{"name": "Team"}`,
+			store:    true,
+			storeErr: errors.New("some error"),
+			code:     http.StatusInternalServerError,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.store {
+				storage.EXPECT().StoreOrg(gomock.Any(), gomock.Any()).Times(1).Return(test.storeErr)
+			}
+
+			w := httptest.NewRecorder()
+			APIAddOrgHandler(logger, storage)(w, orgRequest(http.MethodPost, "/api/orgs", test.body, nil))
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.Equal(t, test.code, res.StatusCode)
+
+			if test.code == http.StatusCreated {
+				var org models.UserOrg
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&org))
+				assert.Equal(t, "Team", org.Name)
+				assert.Equal(t, models.RoleOwner, org.Role)
+				assert.NotEmpty(t, org.ID)
+			}
+		})
+	}
+}
+
+func TestAPIFetchUserOrgsHandler(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	logger := zap.NewNop()
+	storage := mock.NewMockStorager(mockCtrl)
+
+	t.Run("no orgs", func(t *testing.T) {
+		storage.EXPECT().FetchUserOrgs(gomock.Any()).Times(1).Return([]models.UserOrg{}, nil)
+
+		w := httptest.NewRecorder()
+		APIFetchUserOrgsHandler(logger, storage)(w, orgRequest(http.MethodGet, "/api/orgs", "", nil))
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusNoContent, res.StatusCode)
+	})
+
+	t.Run("success fetch", func(t *testing.T) {
+		orgs := []models.UserOrg{{ID: "org_id", Name: "Team", Role: models.RoleViewer}}
+		storage.EXPECT().FetchUserOrgs(gomock.Any()).Times(1).Return(orgs, nil)
+
+		w := httptest.NewRecorder()
+		APIFetchUserOrgsHandler(logger, storage)(w, orgRequest(http.MethodGet, "/api/orgs", "", nil))
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		var got []models.UserOrg
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+		assert.Equal(t, orgs, got)
+	})
+}
+
+func TestAPIOrgMembersHandlers(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	logger := zap.NewNop()
+	storage := mock.NewMockStorager(mockCtrl)
+	params := map[string]string{OrgIDParam: "org_id", UserIDParam: "owner_id"}
+	owners := []models.Member{{OrgID: "org_id", UserID: "owner_id", Role: models.RoleOwner}}
+
+	t.Run("fetch members of foreign org", func(t *testing.T) {
+		storage.EXPECT().GetOrgRole(gomock.Any(), "org_id").Times(1).Return("", data.ErrMemberNotFound)
+
+		w := httptest.NewRecorder()
+		APIFetchOrgMembersHandler(logger, storage)(w, orgRequest(http.MethodGet, "/api/orgs/org_id/members", "", params))
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+
+	t.Run("fetch members", func(t *testing.T) {
+		storage.EXPECT().GetOrgRole(gomock.Any(), "org_id").Times(1).Return(models.RoleViewer, nil)
+		storage.EXPECT().FetchOrgMembers(gomock.Any(), "org_id").Times(1).Return(owners, nil)
+
+		w := httptest.NewRecorder()
+		APIFetchOrgMembersHandler(logger, storage)(w, orgRequest(http.MethodGet, "/api/orgs/org_id/members", "", params))
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("set member with unknown role", func(t *testing.T) {
+		body := `{"user_id": "other_id", "role": "admin"}`
+
+		w := httptest.NewRecorder()
+		APISetOrgMemberHandler(logger, storage)(w, orgRequest(http.MethodPut, "/api/orgs/org_id/members", body, params))
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("set member", func(t *testing.T) {
+		body := `{"user_id": "other_id", "role": "editor"}`
+		member := models.Member{OrgID: "org_id", UserID: "other_id", Role: models.RoleEditor}
+
+		storage.EXPECT().GetOrgRole(gomock.Any(), "org_id").Times(1).Return(models.RoleOwner, nil)
+		storage.EXPECT().FetchOrgMembers(gomock.Any(), "org_id").Times(1).Return(owners, nil)
+		storage.EXPECT().StoreOrgMember(gomock.Any(), member).Times(1).Return(nil)
+
+		w := httptest.NewRecorder()
+		APISetOrgMemberHandler(logger, storage)(w, orgRequest(http.MethodPut, "/api/orgs/org_id/members", body, params))
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("delete last owner", func(t *testing.T) {
+		storage.EXPECT().GetOrgRole(gomock.Any(), "org_id").Times(1).Return(models.RoleOwner, nil)
+		storage.EXPECT().FetchOrgMembers(gomock.Any(), "org_id").Times(1).Return(owners, nil)
+
+		w := httptest.NewRecorder()
+		request := orgRequest(http.MethodDelete, "/api/orgs/org_id/members/owner_id", "", params)
+		APIDeleteOrgMemberHandler(logger, storage)(w, request)
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusConflict, res.StatusCode)
+	})
+
+	t.Run("delete missing member", func(t *testing.T) {
+		missing := map[string]string{OrgIDParam: "org_id", UserIDParam: "other_id"}
+
+		storage.EXPECT().GetOrgRole(gomock.Any(), "org_id").Times(1).Return(models.RoleOwner, nil)
+		storage.EXPECT().FetchOrgMembers(gomock.Any(), "org_id").Times(1).Return(owners, nil)
+		storage.EXPECT().DeleteOrgMember(gomock.Any(), "org_id", "other_id").Times(1).Return(data.ErrMemberNotFound)
+
+		w := httptest.NewRecorder()
+		request := orgRequest(http.MethodDelete, "/api/orgs/org_id/members/other_id", "", missing)
+		APIDeleteOrgMemberHandler(logger, storage)(w, request)
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+}
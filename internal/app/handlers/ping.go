@@ -7,6 +7,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/logger"
 )
 
 // PingHandler обработчик для проверки работоспособности БД.
@@ -15,7 +16,7 @@ func PingHandler(l *zap.Logger, s data.Storager) http.HandlerFunc {
 		err := s.Ping(r.Context())
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
-			l.Error("failed to connect to DB", zap.Error(err))
+			logger.FromContext(r.Context(), l).Error("failed to connect to DB", zap.Error(err))
 			return
 		}
 
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/logger"
+	"github.com/MihailSergeenkov/shortener/internal/app/profiling"
+)
+
+// ProfileKindParam параметр маршрута снятия профиля с видом профиля.
+const ProfileKindParam = "kind"
+
+// Ограничения длительности снятия CPU профиля.
+const (
+	defaultCPUProfileDuration = 30 * time.Second
+	maxCPUProfileDuration     = 5 * time.Minute
+)
+
+type captureProfileResponse struct {
+	File string `json:"file"`
+}
+
+// CaptureProfileHandler обработчик снятия профиля в каталог dir,
+// CPU профиль снимается seconds секунд (по умолчанию 30).
+func CaptureProfileHandler(l *zap.Logger, dir string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		duration := defaultCPUProfileDuration
+		if v := r.URL.Query().Get("seconds"); v != "" {
+			seconds, err := strconv.Atoi(v)
+			if err != nil || seconds <= 0 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			duration = min(time.Duration(seconds)*time.Second, maxCPUProfileDuration)
+		}
+
+		name, err := profiling.Capture(r.Context(), dir, chi.URLParam(r, ProfileKindParam), duration)
+		switch {
+		case errors.Is(err, profiling.ErrUnknownProfile):
+			w.WriteHeader(http.StatusNotFound)
+			return
+		case errors.Is(err, profiling.ErrCPUProfiling):
+			w.WriteHeader(http.StatusConflict)
+			return
+		case err != nil:
+			w.WriteHeader(http.StatusInternalServerError)
+			logger.FromContext(r.Context(), l).Error("failed to capture profile", zap.Error(err))
+			return
+		}
+
+		writeJSON(l, w, r, http.StatusCreated, captureProfileResponse{File: name})
+	}
+}
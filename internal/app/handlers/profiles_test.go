@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestCaptureProfileHandler(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name       string
+		kind       string
+		query      string
+		wantStatus int
+	}{
+		{name: "heap profile", kind: "heap", wantStatus: http.StatusCreated},
+		{name: "unknown profile", kind: "disk", wantStatus: http.StatusNotFound},
+		{name: "bad duration", kind: "cpu", query: "?seconds=zero", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add(ProfileKindParam, test.kind)
+
+			request := httptest.NewRequest(http.MethodPost, "/profiles/"+test.kind+test.query, http.NoBody)
+			request = request.WithContext(context.WithValue(request.Context(), chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+			CaptureProfileHandler(zap.NewNop(), dir)(w, request)
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.Equal(t, test.wantStatus, res.StatusCode)
+			if test.wantStatus != http.StatusCreated {
+				return
+			}
+
+			var resp captureProfileResponse
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&resp))
+			assert.Equal(t, dir, filepath.Dir(resp.File))
+			assert.FileExists(t, resp.File)
+		})
+	}
+}
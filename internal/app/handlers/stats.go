@@ -6,6 +6,7 @@ import (
 
 	"github.com/MihailSergeenkov/shortener/internal/app/common"
 	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/logger"
 	"github.com/MihailSergeenkov/shortener/internal/app/services"
 	"go.uber.org/zap"
 )
@@ -16,7 +17,7 @@ func APIFetchStatsHandler(l *zap.Logger, s data.Storager) http.HandlerFunc {
 		resp, err := services.FetchStats(r.Context(), s)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
-			l.Error("failed to fetch stats from storage", zap.Error(err))
+			logger.FromContext(r.Context(), l).Error("failed to fetch stats from storage", zap.Error(err))
 			return
 		}
 
@@ -26,7 +27,7 @@ func APIFetchStatsHandler(l *zap.Logger, s data.Storager) http.HandlerFunc {
 		enc := json.NewEncoder(w)
 		if err := enc.Encode(resp); err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
-			l.Error(common.EncRespErrStr, zap.Error(err))
+			logger.FromContext(r.Context(), l).Error(common.EncRespErrStr, zap.Error(err))
 			return
 		}
 	}
@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/logger"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+	"github.com/MihailSergeenkov/shortener/internal/app/services"
+)
+
+// Форматы выгрузки и загрузки ссылок.
+const (
+	formatCSV    = "csv"
+	formatJSON   = "json"
+	formatNDJSON = "ndjson"
+	formatBitly  = "bitly" // CSV выгрузка Bitly, читается как CSV с колонками long_url и bitlink
+)
+
+const (
+	exportPageSize    = 500
+	maxImportBodySize = 10 << 20
+)
+
+var (
+	errUnknownFormat     = errors.New("unknown format")
+	errMissingURLColumn  = errors.New("csv has no original_url or long_url column")
+	exportCSVHeader      = []string{"short_url", "original_url", "created_at", "is_deleted"}
+	importOriginalColumn = []string{"original_url", "long_url", "url"}
+	importKeyColumn      = []string{"short_url", "custom_bitlinks", "bitlink", "link"}
+	importDeletedColumn  = []string{"is_deleted", "deleted"}
+)
+
+// APIExportUserURLsHandler обработчик выгрузки ссылок пользователя в формате csv, json или ndjson.
+// Ссылки выгружаются постранично по мере чтения из хранилища.
+func APIExportUserURLsHandler(l *zap.Logger, s data.Storager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = formatJSON
+		}
+
+		exp, err := newExporter(w, format)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err = services.WalkExportURLs(r.Context(), s, exportPageSize, exp.writePage)
+		if err != nil && !exp.started {
+			writeError(l, w, r, err, "failed to fetch URLs from storage")
+			return
+		}
+		if err != nil {
+			logger.FromContext(r.Context(), l).Error("failed to export URLs", zap.Error(err))
+			return
+		}
+
+		if err := exp.finish(); err != nil {
+			logger.FromContext(r.Context(), l).Error(common.EncRespErrStr, zap.Error(err))
+		}
+	}
+}
+
+// APIImportUserURLsHandler обработчик загрузки ссылок пользователя из csv (в том числе выгрузки Bitly),
+// json или ndjson. Формат задается параметром format или заголовком Content-Type.
+func APIImportUserURLsHandler(l *zap.Logger, s data.Storager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = formatFromContentType(r.Header.Get(common.ContentTypeHeader))
+		}
+
+		rows, err := readImportRows(http.MaxBytesReader(w, r.Body, maxImportBodySize), format)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+				return
+			}
+
+			w.WriteHeader(http.StatusBadRequest)
+			logger.FromContext(r.Context(), l).Error(common.ReadReqErrStr, zap.Error(err))
+			return
+		}
+
+		resp, err := services.ImportUserURLs(r.Context(), s, rows)
+		if err != nil {
+			writeError(l, w, r, err, "failed to import URLs to storage")
+			return
+		}
+
+		w.Header().Set(common.ContentTypeHeader, common.JSONContentType)
+		w.WriteHeader(http.StatusOK)
+
+		enc := json.NewEncoder(w)
+		if err := enc.Encode(resp); err != nil {
+			logger.FromContext(r.Context(), l).Error(common.EncRespErrStr, zap.Error(err))
+			return
+		}
+	}
+}
+
+// exporter записывает выгрузку, заголовки ответа отправляются вместе с первой страницей,
+// чтобы ошибка доступа до начала выгрузки вернулась кодом ответа.
+type exporter struct {
+	w       http.ResponseWriter
+	csv     *csv.Writer
+	enc     *json.Encoder
+	format  string
+	started bool
+	count   int
+}
+
+func newExporter(w http.ResponseWriter, format string) (*exporter, error) {
+	switch format {
+	case formatCSV, formatJSON, formatNDJSON:
+		return &exporter{w: w, format: format, enc: json.NewEncoder(w), csv: csv.NewWriter(w)}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnknownFormat, format)
+	}
+}
+
+func (e *exporter) start() error {
+	if e.started {
+		return nil
+	}
+	e.started = true
+
+	contentType := map[string]string{
+		formatCSV:    common.CSVContentType,
+		formatJSON:   common.JSONContentType,
+		formatNDJSON: common.NDJSONContentType,
+	}[e.format]
+
+	e.w.Header().Set(common.ContentTypeHeader, contentType)
+	e.w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="urls.%s"`, e.format))
+	e.w.WriteHeader(http.StatusOK)
+
+	switch e.format {
+	case formatCSV:
+		return e.csv.Write(exportCSVHeader)
+	case formatJSON:
+		_, err := io.WriteString(e.w, "[")
+		return err
+	}
+
+	return nil
+}
+
+func (e *exporter) writePage(page []models.ExportURL) error {
+	if err := e.start(); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+
+	for _, u := range page {
+		if err := e.write(u); err != nil {
+			return fmt.Errorf("failed to write export: %w", err)
+		}
+		e.count++
+	}
+
+	e.csv.Flush()
+	if flusher, ok := e.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+
+	return e.csv.Error()
+}
+
+func (e *exporter) write(u models.ExportURL) error {
+	switch e.format {
+	case formatCSV:
+		createdAt := ""
+		if u.CreatedAt != nil {
+			createdAt = u.CreatedAt.Format(time.RFC3339)
+		}
+
+		return e.csv.Write([]string{u.ShortURL, u.OriginalURL, createdAt, strconv.FormatBool(u.DeletedFlag)})
+	case formatJSON:
+		if e.count > 0 {
+			if _, err := io.WriteString(e.w, ","); err != nil {
+				return err
+			}
+		}
+	}
+
+	return e.enc.Encode(u)
+}
+
+func (e *exporter) finish() error {
+	if err := e.start(); err != nil {
+		return err
+	}
+
+	if e.format == formatJSON {
+		if _, err := io.WriteString(e.w, "]\n"); err != nil {
+			return fmt.Errorf("failed to write export: %w", err)
+		}
+	}
+
+	e.csv.Flush()
+	if err := e.csv.Error(); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+
+	return nil
+}
+
+func formatFromContentType(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch mediaType {
+	case common.CSVContentType:
+		return formatCSV
+	case common.NDJSONContentType:
+		return formatNDJSON
+	default:
+		return formatJSON
+	}
+}
+
+func readImportRows(r io.Reader, format string) ([]models.ImportRow, error) {
+	var rows []models.ImportRow
+
+	switch format {
+	case formatJSON:
+		if err := json.NewDecoder(r).Decode(&rows); err != nil {
+			return nil, fmt.Errorf("failed to parse json: %w", err)
+		}
+	case formatNDJSON:
+		dec := json.NewDecoder(r)
+		for dec.More() {
+			var row models.ImportRow
+			if err := dec.Decode(&row); err != nil {
+				return nil, fmt.Errorf("failed to parse ndjson line %d: %w", len(rows)+1, err)
+			}
+			rows = append(rows, row)
+		}
+	case formatCSV, formatBitly:
+		return readImportCSV(r)
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnknownFormat, format)
+	}
+
+	return rows, nil
+}
+
+// readImportCSV читает CSV по заголовку: колонки выгрузки сервиса или выгрузки Bitly (long_url, bitlink, custom_bitlinks).
+func readImportCSV(r io.Reader) ([]models.ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse csv: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[strings.ReplaceAll(name, " ", "_")] = i
+	}
+
+	originalCol := findColumn(columns, importOriginalColumn)
+	if originalCol < 0 {
+		return nil, errMissingURLColumn
+	}
+	keyCol := findColumn(columns, importKeyColumn)
+	deletedCol := findColumn(columns, importDeletedColumn)
+
+	var rows []models.ImportRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse csv: %w", err)
+		}
+
+		row := models.ImportRow{OriginalURL: field(record, originalCol)}
+		if key := strings.FieldsFunc(field(record, keyCol), isKeySeparator); len(key) > 0 {
+			row.ShortURL = key[0]
+		}
+		row.DeletedFlag, _ = strconv.ParseBool(field(record, deletedCol))
+
+		rows = append(rows, row)
+	}
+}
+
+func findColumn(columns map[string]int, names []string) int {
+	for _, name := range names {
+		if i, ok := columns[name]; ok {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func field(record []string, i int) string {
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+
+	return strings.TrimSpace(record[i])
+}
+
+// isKeySeparator разделитель нескольких ссылок в одной ячейке (например, custom_bitlinks).
+func isKeySeparator(r rune) bool {
+	return r == ' ' || r == ',' || r == '|'
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/data/mock"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+)
+
+func TestAPIExportUserURLsHandler(t *testing.T) {
+	logger := zap.NewNop()
+	storage := data.NewBaseStorage()
+	ctx := context.WithValue(context.Background(), common.KeyUserID, "some_id")
+
+	require.NoError(t, storage.StoreShortURL(ctx, "a", "https://ya.ru/a"))
+	require.NoError(t, storage.StoreShortURL(ctx, "b", "https://ya.ru/b"))
+	require.NoError(t, storage.DeleteShortURLs(ctx, []string{"b"}))
+
+	export := func(t *testing.T, format string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		request := httptest.NewRequest(http.MethodGet, "/api/user/urls/export?format="+format, http.NoBody)
+		w := httptest.NewRecorder()
+		APIExportUserURLsHandler(logger, storage)(w, request.WithContext(ctx))
+
+		return w
+	}
+
+	t.Run("json", func(t *testing.T) {
+		w := export(t, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, common.JSONContentType, w.Header().Get(common.ContentTypeHeader))
+
+		var urls []models.ExportURL
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &urls))
+		require.Len(t, urls, 2)
+		assert.Equal(t, "https://ya.ru/a", urls[0].OriginalURL)
+		assert.NotNil(t, urls[0].CreatedAt)
+		assert.True(t, urls[1].DeletedFlag)
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		w := export(t, formatNDJSON)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Len(t, strings.Split(strings.TrimSpace(w.Body.String()), "\n"), 2)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		w := export(t, formatCSV)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "urls.csv")
+
+		records, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		assert.Equal(t, exportCSVHeader, records[0])
+		assert.Equal(t, "true", records[2][3])
+	})
+
+	t.Run("empty export", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "/api/user/urls/export", http.NoBody)
+		w := httptest.NewRecorder()
+		otherCtx := context.WithValue(context.Background(), common.KeyUserID, "other_id")
+		APIExportUserURLsHandler(logger, storage)(w, request.WithContext(otherCtx))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, "[]", w.Body.String())
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		w := export(t, "xml")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		failing := mock.NewMockStorager(mockCtrl)
+		failing.EXPECT().FetchURLsPage(gomock.Any(), "", "", exportPageSize).Times(1).Return(nil, assert.AnError)
+
+		request := httptest.NewRequest(http.MethodGet, "/api/user/urls/export", http.NoBody)
+		w := httptest.NewRecorder()
+		APIExportUserURLsHandler(logger, failing)(w, request)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestAPIImportUserURLsHandler(t *testing.T) {
+	logger := zap.NewNop()
+	ctx := context.WithValue(context.Background(), common.KeyUserID, "some_id")
+
+	tests := []struct {
+		name        string
+		query       string
+		contentType string
+		body        string
+		created     int
+		code        int
+	}{
+		{
+			name:        "json",
+			contentType: common.JSONContentType,
+			body:        `[{"short_url":"http://localhost/abc","original_url":"https://ya.ru/1"},{"original_url":"https://ya.ru/2"}]`,
+			created:     2,
+			code:        http.StatusOK,
+		},
+		{
+			name:        "ndjson",
+			contentType: common.NDJSONContentType,
+			body:        "{\"original_url\":\"https://ya.ru/1\"}\n{\"original_url\":\"https://ya.ru/2\",\"is_deleted\":true}\n",
+			created:     1,
+			code:        http.StatusOK,
+		},
+		{
+			name:        "csv export",
+			contentType: common.CSVContentType + "; charset=utf-8",
+			body:        "short_url,original_url,created_at,is_deleted\nhttp://localhost/abc,https://ya.ru/1,,false\n",
+			created:     1,
+			code:        http.StatusOK,
+		},
+		{
+			name:        "bitly csv",
+			query:       "?format=bitly",
+			contentType: common.CSVContentType,
+			body:        "\ufeffTitle,Long URL,Bitlink,Custom Bitlinks,Clicks\nYa,https://ya.ru/1,bit.ly/3xYz,bit.ly/my-link,10\n",
+			created:     1,
+			code:        http.StatusOK,
+		},
+		{
+			name:        "csv without url column",
+			contentType: common.CSVContentType,
+			body:        "title\nYa\n",
+			code:        http.StatusBadRequest,
+		},
+		{
+			name:        "broken json",
+			contentType: common.JSONContentType,
+			body:        `{`,
+			code:        http.StatusBadRequest,
+		},
+		{
+			name:  "unknown format",
+			query: "?format=xml",
+			body:  `[]`,
+			code:  http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			storage := data.NewBaseStorage()
+			request := httptest.NewRequest(http.MethodPost, "/api/user/urls/import"+test.query, strings.NewReader(test.body))
+			request.Header.Set(common.ContentTypeHeader, test.contentType)
+			w := httptest.NewRecorder()
+			APIImportUserURLsHandler(logger, storage)(w, request.WithContext(ctx))
+
+			require.Equal(t, test.code, w.Code)
+			if test.code != http.StatusOK {
+				return
+			}
+
+			var resp models.ImportResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, test.created, resp.Created)
+		})
+	}
+
+	t.Run("bitly custom key preserved", func(t *testing.T) {
+		storage := data.NewBaseStorage()
+		body := "long_url,link,custom_bitlinks\nhttps://ya.ru/1,https://bit.ly/3xYz,https://bit.ly/my-link\n"
+		request := httptest.NewRequest(http.MethodPost, "/api/user/urls/import", strings.NewReader(body))
+		request.Header.Set(common.ContentTypeHeader, common.CSVContentType)
+		w := httptest.NewRecorder()
+		APIImportUserURLsHandler(logger, storage)(w, request.WithContext(ctx))
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		u, err := storage.GetURL(ctx, "my-link")
+		require.NoError(t, err)
+		assert.Equal(t, "https://ya.ru/1", u.OriginalURL)
+	})
+
+	t.Run("without user", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodPost, "/api/user/urls/import", strings.NewReader(`[{"original_url":"https://ya.ru"}]`))
+		w := httptest.NewRecorder()
+		APIImportUserURLsHandler(logger, data.NewBaseStorage())(w, request)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
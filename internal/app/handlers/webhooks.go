@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/logger"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+	"github.com/MihailSergeenkov/shortener/internal/app/services"
+)
+
+// WebhookIDParam параметр маршрутов управления вебхуком.
+const WebhookIDParam = "webhookID"
+
+// APIAddWebhookHandler обработчик создания вебхука для API, ключ подписи возвращается только в этом ответе.
+func APIAddWebhookHandler(l *zap.Logger, s data.Storager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, ok := decodeWebhookRequest(l, w, r)
+		if !ok {
+			return
+		}
+
+		resp, err := services.CreateWebhook(r.Context(), s, req)
+		if err != nil {
+			writeError(l, w, r, err, "failed to add webhook to storage")
+			return
+		}
+
+		writeJSON(l, w, r, http.StatusCreated, resp)
+	}
+}
+
+// APIFetchUserWebhooksHandler обработчик получения вебхуков пользователя для API.
+func APIFetchUserWebhooksHandler(l *zap.Logger, s data.Storager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp, err := services.FetchUserWebhooks(r.Context(), s)
+		if err != nil {
+			writeError(l, w, r, err, "failed to fetch webhooks from storage")
+			return
+		}
+
+		if len(resp) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		writeJSON(l, w, r, http.StatusOK, resp)
+	}
+}
+
+// APIUpdateWebhookHandler обработчик изменения вебхука для API.
+func APIUpdateWebhookHandler(l *zap.Logger, s data.Storager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, ok := decodeWebhookRequest(l, w, r)
+		if !ok {
+			return
+		}
+
+		resp, err := services.UpdateWebhook(r.Context(), s, chi.URLParam(r, WebhookIDParam), req)
+		if err != nil {
+			writeError(l, w, r, err, "failed to update webhook")
+			return
+		}
+
+		writeJSON(l, w, r, http.StatusOK, resp)
+	}
+}
+
+// APIDeleteWebhookHandler обработчик удаления вебхука для API.
+func APIDeleteWebhookHandler(l *zap.Logger, s data.Storager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := services.DeleteWebhook(r.Context(), s, chi.URLParam(r, WebhookIDParam)); err != nil {
+			writeError(l, w, r, err, "failed to delete webhook")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// APIFetchDeadWebhookDeliveriesHandler обработчик получения недоставленных событий вебхуков для API.
+func APIFetchDeadWebhookDeliveriesHandler(l *zap.Logger, s data.Storager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp, err := services.FetchDeadWebhookDeliveries(r.Context(), s)
+		if err != nil {
+			writeError(l, w, r, err, "failed to fetch dead webhook deliveries from storage")
+			return
+		}
+
+		if len(resp) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		writeJSON(l, w, r, http.StatusOK, resp)
+	}
+}
+
+func decodeWebhookRequest(l *zap.Logger, w http.ResponseWriter, r *http.Request) (models.WebhookRequest, bool) {
+	var req models.WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		logger.FromContext(r.Context(), l).Error(common.ReadReqErrStr, zap.Error(err))
+		return models.WebhookRequest{}, false
+	}
+
+	return req, true
+}
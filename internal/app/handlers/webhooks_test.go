@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/data/mock"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+)
+
+func TestAPIAddWebhookHandler(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	logger := zap.NewNop()
+	storage := mock.NewMockStorager(mockCtrl)
+
+	tests := []struct {
+		name     string
+		body     string
+		storeErr error
+		store    bool
+		code     int
+	}{
+		{
+			name:  "success create",
+			body:  `{"url": "https://example.com/hook", "secret": "key", "events": ["create", "delete"]}`,
+			store: true,
+			code:  http.StatusCreated,
+		},
+		{
+			name: "bad request",
+			body: `sdfsdf`,
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "invalid url",
+			body: `{"url": "example", "events": ["create"]}`,
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "unknown event",
+			body: `{"url": "https://example.com/hook", "events": ["update"]}`,
+			code: http.StatusBadRequest,
+		},
+		{
+			name:     "failed store",
+			body:     `{"url": "https://example.com/hook", "events": ["click"]}`,
+			store:    true,
+			storeErr: errors.New("some error"),
+			code:     http.StatusInternalServerError,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.store {
+				storage.EXPECT().StoreWebhook(gomock.Any(), gomock.Any()).Times(1).Return(test.storeErr)
+			}
+
+			w := httptest.NewRecorder()
+			APIAddWebhookHandler(logger, storage)(w, orgRequest(http.MethodPost, "/api/user/webhooks", test.body, nil))
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.Equal(t, test.code, res.StatusCode)
+
+			if test.code == http.StatusCreated {
+				var webhook models.Webhook
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&webhook))
+				assert.NotEmpty(t, webhook.ID)
+				assert.Equal(t, "key", webhook.Secret)
+				assert.Equal(t, []string{models.LinkCreated, models.LinkDeleted}, webhook.Events)
+			}
+		})
+	}
+}
+
+func TestAPIWebhookHandlers(t *testing.T) {
+	logger := zap.NewNop()
+	storage := data.NewBaseStorage()
+	webhook := models.Webhook{
+		ID:     "hook_id",
+		UserID: "owner_id",
+		URL:    "https://example.com/hook",
+		Secret: "key",
+		Events: []string{models.LinkCreated},
+	}
+	require.NoError(t, storage.StoreWebhook(orgRequest(http.MethodGet, "/", "", nil).Context(), webhook))
+	params := map[string]string{WebhookIDParam: webhook.ID}
+
+	t.Run("fetch without secret", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		APIFetchUserWebhooksHandler(logger, storage)(w, orgRequest(http.MethodGet, "/api/user/webhooks", "", nil))
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		var got []models.Webhook
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+		require.Len(t, got, 1)
+		assert.Equal(t, webhook.ID, got[0].ID)
+		assert.Empty(t, got[0].Secret)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		body := `{"url": "https://example.com/other", "events": ["click"]}`
+
+		w := httptest.NewRecorder()
+		APIUpdateWebhookHandler(logger, storage)(w, orgRequest(http.MethodPut, "/api/user/webhooks/hook_id", body, params))
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		var got models.Webhook
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+		assert.Equal(t, "https://example.com/other", got.URL)
+		assert.Equal(t, []string{models.LinkClicked}, got.Events)
+	})
+
+	t.Run("update unknown", func(t *testing.T) {
+		body := `{"url": "https://example.com/other", "events": ["click"]}`
+		unknown := map[string]string{WebhookIDParam: "unknown"}
+
+		w := httptest.NewRecorder()
+		APIUpdateWebhookHandler(logger, storage)(w, orgRequest(http.MethodPut, "/api/user/webhooks/unknown", body, unknown))
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("no dead letters", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		target := "/api/user/webhooks/dead-letters"
+		APIFetchDeadWebhookDeliveriesHandler(logger, storage)(w, orgRequest(http.MethodGet, target, "", nil))
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusNoContent, res.StatusCode)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		for _, code := range []int{http.StatusNoContent, http.StatusNotFound} {
+			w := httptest.NewRecorder()
+			APIDeleteWebhookHandler(logger, storage)(w, orgRequest(http.MethodDelete, "/api/user/webhooks/hook_id", "", params))
+
+			res := w.Result()
+			closeBody(t, res)
+
+			assert.Equal(t, code, res.StatusCode)
+		}
+	})
+}
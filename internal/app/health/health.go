@@ -0,0 +1,165 @@
+// Пакет health предназначен для проверок живости и готовности сервиса.
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Статусы проверок.
+const (
+	StatusOK      = "ok"
+	StatusFailing = "failing"
+)
+
+// checkTimeout время на все проверки одного отчета.
+const checkTimeout = 3 * time.Second
+
+// ErrShuttingDown ошибка готовности после начала плавной остановки сервиса.
+var ErrShuttingDown = errors.New("graceful shutdown in progress")
+
+var errNotServing = errors.New("server is not serving")
+
+// Result результат проверки компонента.
+type Result struct {
+	Details map[string]any `json:"details,omitempty"`
+	Status  string         `json:"status"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// OK возвращает успешный результат проверки.
+func OK(details map[string]any) Result {
+	return Result{Status: StatusOK, Details: details}
+}
+
+// Failing возвращает результат неудачной проверки с ошибкой err.
+func Failing(err error, details map[string]any) Result {
+	return Result{Status: StatusFailing, Error: err.Error(), Details: details}
+}
+
+// CheckFunc проверка компонента сервиса.
+type CheckFunc func(ctx context.Context) Result
+
+// Report отчет о проверке сервиса по компонентам.
+type Report struct {
+	Components map[string]Result `json:"components,omitempty"`
+	Status     string            `json:"status"`
+}
+
+// OK проверяет, что все компоненты отчета в порядке.
+func (r Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+// registry проверки сервиса, регистрируются при запуске.
+var registry = &checks{
+	liveness:  map[string]CheckFunc{},
+	readiness: map[string]CheckFunc{},
+}
+
+type checks struct {
+	liveness     map[string]CheckFunc
+	readiness    map[string]CheckFunc
+	mu           sync.RWMutex
+	shuttingDown atomic.Bool
+}
+
+// RegisterLiveness добавляет проверку живости: ее отказ означает, что сервис нужно перезапустить.
+// Проверка с тем же именем заменяется.
+func RegisterLiveness(name string, check CheckFunc) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.liveness[name] = check
+}
+
+// RegisterReadiness добавляет проверку готовности: ее отказ означает, что на сервис не нужно направлять запросы.
+// Проверка с тем же именем заменяется.
+func RegisterReadiness(name string, check CheckFunc) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.readiness[name] = check
+}
+
+// Shutdown переводит готовность в отказ, вызывается в начале плавной остановки сервиса.
+func Shutdown() {
+	registry.shuttingDown.Store(true)
+}
+
+// Liveness выполняет проверки живости.
+func Liveness(ctx context.Context) Report {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	return run(ctx, registry.liveness)
+}
+
+// Readiness выполняет проверки готовности, после Shutdown сервис не готов без выполнения проверок.
+func Readiness(ctx context.Context) Report {
+	if registry.shuttingDown.Load() {
+		return Report{
+			Status:     StatusFailing,
+			Components: map[string]Result{"shutdown": Failing(ErrShuttingDown, nil)},
+		}
+	}
+
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	return run(ctx, registry.readiness)
+}
+
+// run выполняет проверки параллельно, отчет успешен, если успешны все проверки.
+func run(ctx context.Context, checks map[string]CheckFunc) Report {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	report := Report{Status: StatusOK, Components: make(map[string]Result, len(checks))}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check CheckFunc) {
+			defer wg.Done()
+
+			result := check(ctx)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Components[name] = result
+			if result.Status != StatusOK {
+				report.Status = StatusFailing
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	return report
+}
+
+// ServerState состояние сервера, которое выставляет его владелец при запуске и остановке.
+type ServerState struct {
+	serving atomic.Bool
+}
+
+// SetServing выставляет признак того, что сервер принимает запросы.
+func (s *ServerState) SetServing(serving bool) {
+	s.serving.Store(serving)
+}
+
+// Check проверка того, что сервер принимает запросы.
+func (s *ServerState) Check(_ context.Context) Result {
+	if !s.serving.Load() {
+		return Failing(errNotServing, map[string]any{"state": "not_serving"})
+	}
+
+	return OK(map[string]any{"state": "serving"})
+}
@@ -0,0 +1,71 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func resetRegistry() {
+	registry.liveness = map[string]CheckFunc{}
+	registry.readiness = map[string]CheckFunc{}
+	registry.shuttingDown.Store(false)
+}
+
+func TestReadiness(t *testing.T) {
+	defer resetRegistry()
+
+	grpcState := &ServerState{}
+	RegisterReadiness("storage", func(_ context.Context) Result {
+		return OK(map[string]any{"type": "memory"})
+	})
+	RegisterReadiness("grpc", grpcState.Check)
+
+	t.Run("grpc server is not serving", func(t *testing.T) {
+		report := Readiness(context.Background())
+
+		assert.False(t, report.OK())
+		assert.Equal(t, StatusOK, report.Components["storage"].Status)
+		assert.Equal(t, StatusFailing, report.Components["grpc"].Status)
+	})
+
+	t.Run("all components are ready", func(t *testing.T) {
+		grpcState.SetServing(true)
+
+		report := Readiness(context.Background())
+
+		assert.True(t, report.OK())
+		assert.Equal(t, "serving", report.Components["grpc"].Details["state"])
+	})
+
+	t.Run("shutdown", func(t *testing.T) {
+		Shutdown()
+
+		report := Readiness(context.Background())
+
+		assert.False(t, report.OK())
+		assert.Equal(t, map[string]Result{"shutdown": Failing(ErrShuttingDown, nil)}, report.Components)
+	})
+}
+
+func TestLiveness(t *testing.T) {
+	defer resetRegistry()
+
+	t.Run("no checks", func(t *testing.T) {
+		assert.True(t, Liveness(context.Background()).OK())
+	})
+
+	t.Run("failing check", func(t *testing.T) {
+		RegisterLiveness("background_job", func(_ context.Context) Result {
+			return Failing(errors.New("stale"), nil)
+		})
+		Shutdown()
+
+		report := Liveness(context.Background())
+
+		assert.False(t, report.OK())
+		assert.Equal(t, "stale", report.Components["background_job"].Error)
+	})
+}
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/clientip"
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/requestid"
+)
+
+type contextKey struct{}
+
+// NewContext возвращает контекст с логгером запроса.
+func NewContext(ctx context.Context, l *zap.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext возвращает логгер из контекста или fallback, если его там нет,
+// дополненный полями запроса из Fields. Поля кодируются только при записи в журнал.
+func FromContext(ctx context.Context, fallback *zap.Logger) *zap.Logger {
+	l, ok := ctx.Value(contextKey{}).(*zap.Logger)
+	if !ok {
+		l = fallback
+	}
+
+	fields := Fields(ctx)
+	if len(fields) == 0 {
+		return l
+	}
+
+	return l.WithLazy(fields...)
+}
+
+// Fields возвращает известные в контексте поля запроса: идентификатор запроса, пользователя,
+// IP адрес клиента, шаблон маршрута chi или метод gRPC.
+func Fields(ctx context.Context) []zap.Field {
+	var fields []zap.Field
+
+	if id := requestid.FromContext(ctx); id != "" {
+		fields = append(fields, zap.String("request_id", id))
+	}
+	if userID, ok := ctx.Value(common.KeyUserID).(string); ok && userID != "" {
+		fields = append(fields, zap.String("user_id", userID))
+	}
+	if ip := clientip.String(ctx); ip != "" {
+		fields = append(fields, zap.String("client_ip", ip))
+	}
+	if rctx := chi.RouteContext(ctx); rctx != nil && rctx.RoutePattern() != "" {
+		fields = append(fields, zap.String("route", rctx.RoutePattern()))
+	}
+	if method, ok := grpc.Method(ctx); ok {
+		fields = append(fields, zap.String("grpc_method", method))
+	}
+
+	return fields
+}
@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/requestid"
+)
+
+func TestFromContext(t *testing.T) {
+	t.Run("use fallback with request fields", func(t *testing.T) {
+		core, logs := observer.New(zapcore.InfoLevel)
+		ctx := requestid.NewContext(context.Background(), "req-1")
+		ctx = context.WithValue(ctx, common.KeyUserID, "user-1")
+
+		FromContext(ctx, zap.New(core)).Info("test")
+
+		entries := logs.All()
+		assert.Len(t, entries, 1)
+		assert.Equal(t, map[string]interface{}{"request_id": "req-1", "user_id": "user-1"}, entries[0].ContextMap())
+	})
+
+	t.Run("prefer logger from context", func(t *testing.T) {
+		core, logs := observer.New(zapcore.InfoLevel)
+		ctx := NewContext(context.Background(), zap.New(core))
+
+		FromContext(ctx, zap.NewNop()).Info("test")
+
+		assert.Equal(t, 1, logs.Len())
+	})
+}
@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Компоненты сервиса с отдельным уровнем журналирования.
+const (
+	RootComponent = "root" // корневой логгер, его уровень задает LogLevel
+	ComponentHTTP = "http" // HTTP сервер, middleware и обработчики
+	ComponentGRPC = "grpc" // gRPC сервер
+	ComponentDB   = "db"   // хранилище и запросы к postgres
+)
+
+// ErrUnknownComponent ошибка неизвестного компонента журналирования.
+var ErrUnknownComponent = errors.New("unknown log component")
+
+// registry уровни журналирования, созданные Setup.
+var registry = &levelRegistry{}
+
+type levelRegistry struct {
+	levels map[string]zap.AtomicLevel
+	mu     sync.RWMutex
+}
+
+func (r *levelRegistry) set(levels map[string]zap.AtomicLevel) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.levels = levels
+}
+
+func (r *levelRegistry) get(name string) (zap.AtomicLevel, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	level, ok := r.levels[name]
+	return level, ok
+}
+
+func isComponent(name string) bool {
+	switch name {
+	case ComponentHTTP, ComponentGRPC, ComponentDB:
+		return true
+	default:
+		return false
+	}
+}
+
+// newLevels создает уровни корневого логгера и компонентов, незаданные уровни компонентов равны корневому.
+func newLevels(root zapcore.Level, components map[string]zapcore.Level) map[string]zap.AtomicLevel {
+	levels := map[string]zap.AtomicLevel{RootComponent: zap.NewAtomicLevelAt(root)}
+
+	for _, name := range []string{ComponentHTTP, ComponentGRPC, ComponentDB} {
+		level, ok := components[name]
+		if !ok {
+			level = root
+		}
+		levels[name] = zap.NewAtomicLevelAt(level)
+	}
+
+	return levels
+}
+
+// Component возвращает именованный логгер компонента со своим уровнем журналирования.
+// Если уровни не созданы Setup, логгер пишет с уровнем l.
+func Component(l *zap.Logger, name string) *zap.Logger {
+	named := l.Named(name)
+
+	level, ok := registry.get(name)
+	if !ok {
+		return named
+	}
+
+	return named.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &levelCore{Core: core, level: level}
+	}))
+}
+
+// levelCore заменяет уровень исходного ядра: записи проверяются только по level,
+// поэтому компонент может писать подробнее корневого логгера.
+type levelCore struct {
+	zapcore.Core
+	level zapcore.LevelEnabler
+}
+
+// Enabled переопределение оригинального метода.
+func (c *levelCore) Enabled(lvl zapcore.Level) bool {
+	return c.level.Enabled(lvl)
+}
+
+// With переопределение оригинального метода.
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), level: c.level}
+}
+
+// Check переопределение оригинального метода.
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+// LevelHandler возвращает обработчик уровней журналирования: GET возвращает уровень,
+// PUT с телом {"level":"debug"} меняет его. Компонент задается параметром component, по умолчанию root.
+func LevelHandler(l *zap.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("component")
+		if name == "" {
+			name = RootComponent
+		}
+
+		level, ok := registry.get(name)
+		if !ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+
+			resp := map[string]string{"error": fmt.Sprintf("%s: %q", ErrUnknownComponent, name)}
+			if err := json.NewEncoder(w).Encode(resp); err != nil {
+				FromContext(r.Context(), l).Error("failed to write log level response", zap.Error(err))
+			}
+			return
+		}
+
+		level.ServeHTTP(w, r)
+	})
+}
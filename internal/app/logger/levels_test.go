@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestComponent(t *testing.T) {
+	registry.set(newLevels(zapcore.ErrorLevel, map[string]zapcore.Level{ComponentDB: zapcore.DebugLevel}))
+	defer registry.set(nil)
+
+	core, logs := observer.New(zapcore.ErrorLevel)
+	root := zap.New(core)
+
+	t.Run("component writes below root level", func(t *testing.T) {
+		Component(root, ComponentDB).Debug("db query")
+		Component(root, ComponentHTTP).Info("http request")
+		root.Info("root info")
+
+		entries := logs.TakeAll()
+		require.Len(t, entries, 1)
+		assert.Equal(t, "db query", entries[0].Message)
+		assert.Equal(t, ComponentDB, entries[0].LoggerName)
+	})
+
+	t.Run("change level at runtime", func(t *testing.T) {
+		level, ok := registry.get(ComponentHTTP)
+		require.True(t, ok)
+		level.SetLevel(zapcore.InfoLevel)
+
+		Component(root, ComponentHTTP).Info("http request")
+
+		assert.Equal(t, 1, logs.Len())
+	})
+}
+
+func TestLevelHandler(t *testing.T) {
+	registry.set(newLevels(zapcore.ErrorLevel, nil))
+	defer registry.set(nil)
+
+	handler := LevelHandler(zap.NewNop())
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "get root level",
+			method:     http.MethodGet,
+			target:     "/log/level",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"level":"error"}`,
+		},
+		{
+			name:       "set component level",
+			method:     http.MethodPut,
+			target:     "/log/level?component=grpc",
+			body:       `{"level":"debug"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"level":"debug"}`,
+		},
+		{
+			name:       "unknown component",
+			method:     http.MethodGet,
+			target:     "/log/level?component=cache",
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"error":"unknown log component: \"cache\""}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(test.method, test.target, strings.NewReader(test.body))
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, request)
+
+			assert.Equal(t, test.wantStatus, w.Code)
+			assert.JSONEq(t, test.wantBody, w.Body.String())
+		})
+	}
+
+	level, ok := registry.get(ComponentGRPC)
+	require.True(t, ok)
+	assert.Equal(t, zapcore.DebugLevel, level.Level())
+}
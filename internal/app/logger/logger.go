@@ -2,12 +2,29 @@
 package logger
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/config"
 )
 
+const hoursPerDay = 24
+
+// ErrUnknownFormat ошибка неизвестного формата записей журнала.
+var ErrUnknownFormat = errors.New("unknown log format")
+
+// CloseFunc сбрасывает буферы журнала и закрывает файл журнала.
+type CloseFunc func() error
+
 // NewLogger функция инициализации логирования.
 func NewLogger(level zapcore.Level) (*zap.Logger, error) {
 	cfg := zap.NewProductionConfig()
@@ -28,3 +45,108 @@ func NewLogger(level zapcore.Level) (*zap.Logger, error) {
 
 	return logger, nil
 }
+
+// Setup инициализирует логгер сервиса по настройкам: формат записей, вывод в stderr или в файл с ротацией
+// по размеру и возрасту, уровни компонентов. Уровни регистрируются для изменения через LevelHandler.
+func Setup(s *config.Settings) (*zap.Logger, CloseFunc, error) {
+	encoder, err := newEncoder(s.LogFormat)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	componentLevels, err := parseComponentLevels(s.LogLevels)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	output, closer := newOutput(s)
+	levels := newLevels(s.LogLevel, componentLevels)
+
+	l := zap.New(
+		zapcore.NewCore(encoder, output, levels[RootComponent]),
+		zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel),
+	)
+	registry.set(levels)
+
+	return l, func() error {
+		// stderr не буферизуется, а его Sync на части систем возвращает ошибку.
+		if closer == nil {
+			return nil
+		}
+		if err := l.Sync(); err != nil {
+			return fmt.Errorf("failed to sync logger: %w", err)
+		}
+		if err := closer.Close(); err != nil {
+			return fmt.Errorf("failed to close log file: %w", err)
+		}
+		return nil
+	}, nil
+}
+
+// Sampled возвращает логгер, который в течение секунды пишет первые initial одинаковых записей
+// и далее каждую thereafter. Нулевой initial отключает выборку.
+func Sampled(l *zap.Logger, initial, thereafter int) *zap.Logger {
+	if initial <= 0 {
+		return l
+	}
+
+	return l.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewSamplerWithOptions(core, time.Second, initial, thereafter)
+	}))
+}
+
+// parseComponentLevels разбирает уровни компонентов в виде component=level.
+func parseComponentLevels(values []string) (map[string]zapcore.Level, error) {
+	levels := make(map[string]zapcore.Level, len(values))
+
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		name, text, found := strings.Cut(value, "=")
+		if !found || !isComponent(name) {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownComponent, value)
+		}
+
+		level, err := zapcore.ParseLevel(text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s log level: %w", name, err)
+		}
+		levels[name] = level
+	}
+
+	return levels, nil
+}
+
+func newEncoder(format string) (zapcore.Encoder, error) {
+	switch format {
+	case config.LogFormatJSON:
+		return zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), nil
+	case config.LogFormatConsole:
+		cfg := zap.NewProductionEncoderConfig()
+		cfg.EncodeTime = zapcore.ISO8601TimeEncoder
+		cfg.EncodeDuration = zapcore.StringDurationEncoder
+
+		return zapcore.NewConsoleEncoder(cfg), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+}
+
+// newOutput возвращает stderr или файл LogFile с ротацией, файл нужно закрыть при остановке сервиса.
+func newOutput(s *config.Settings) (zapcore.WriteSyncer, io.Closer) {
+	if s.LogFile == "" {
+		return zapcore.Lock(os.Stderr), nil
+	}
+
+	file := &lumberjack.Logger{
+		Filename:   s.LogFile,
+		MaxSize:    s.LogMaxSize,
+		MaxAge:     int(math.Ceil(s.LogMaxAge.Hours() / hoursPerDay)),
+		MaxBackups: s.LogMaxBackups,
+	}
+
+	return zapcore.AddSync(file), file
+}
@@ -1,11 +1,17 @@
 package logger
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/config"
 )
 
 func TestNewLogger(t *testing.T) {
@@ -18,3 +24,73 @@ func TestNewLogger(t *testing.T) {
 		assert.Equal(t, logLevel, logger.Level())
 	})
 }
+
+func TestSetup(t *testing.T) {
+	defer registry.set(nil)
+
+	t.Run("write console log to file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "shortener.log")
+		settings := config.Settings{
+			LogLevel:  zapcore.InfoLevel,
+			LogLevels: []string{"db=debug"},
+			LogFormat: config.LogFormatConsole,
+			LogFile:   path,
+		}
+
+		l, closeLogger, err := Setup(&settings)
+		require.NoError(t, err)
+
+		l.Info("root info")
+		Component(l, ComponentDB).Debug("db debug")
+		Component(l, ComponentHTTP).Debug("http debug")
+		require.NoError(t, closeLogger())
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "root info")
+		assert.Contains(t, string(content), "db debug")
+		assert.NotContains(t, string(content), "http debug")
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, _, err := Setup(&config.Settings{LogFormat: "xml"})
+
+		assert.ErrorIs(t, err, ErrUnknownFormat)
+	})
+
+	t.Run("unknown component", func(t *testing.T) {
+		_, _, err := Setup(&config.Settings{LogFormat: config.LogFormatJSON, LogLevels: []string{"cache=debug"}})
+
+		assert.ErrorIs(t, err, ErrUnknownComponent)
+	})
+
+	t.Run("bad component level", func(t *testing.T) {
+		_, _, err := Setup(&config.Settings{LogFormat: config.LogFormatJSON, LogLevels: []string{"db=loud"}})
+
+		assert.ErrorContains(t, err, "failed to parse db log level")
+	})
+}
+
+func TestSampled(t *testing.T) {
+	t.Run("sample repeated entries", func(t *testing.T) {
+		core, logs := observer.New(zapcore.InfoLevel)
+		l := Sampled(zap.New(core), 2, 3)
+
+		for range 8 {
+			l.Info("redirect")
+		}
+
+		assert.Equal(t, 4, logs.Len())
+	})
+
+	t.Run("disabled sampling", func(t *testing.T) {
+		core, logs := observer.New(zapcore.InfoLevel)
+		l := Sampled(zap.New(core), 0, 0)
+
+		for range 8 {
+			l.Info("redirect")
+		}
+
+		assert.Equal(t, 8, logs.Len())
+	})
+}
@@ -0,0 +1,113 @@
+// Пакет metrics предназначен для сбора метрик сервиса в формате Prometheus:
+// HTTP и gRPC запросы, операции хранилища, переходы по коротким ссылкам и данные статистики сервиса.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+)
+
+const namespace = "shortener"
+
+// UnknownRoute метка запросов, не попавших ни в один маршрут.
+const UnknownRoute = "unknown"
+
+// registry реестр метрик сервиса, общий для всех серверов процесса.
+var registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Number of HTTP requests by route pattern, method and status code.",
+	}, []string{"route", "method", "code"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by route pattern and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	grpcRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "requests_total",
+		Help:      "Number of gRPC calls by full method name and status code.",
+	}, []string{"method", "code"})
+
+	grpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "request_duration_seconds",
+		Help:      "gRPC call latency by full method name.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	storageDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "storage",
+		Name:      "operation_duration_seconds",
+		Help:      "Storage operation latency by Storager method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	storageErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "storage",
+		Name:      "operation_errors_total",
+		Help:      "Number of failed storage operations by Storager method, not found and conflicts are not errors.",
+	}, []string{"method"})
+
+	redirects = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "redirects_total",
+		Help:      "Number of short link redirects by status code.",
+	}, []string{"code"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration,
+		grpcRequests, grpcDuration,
+		storageDuration, storageErrors,
+		redirects,
+	)
+}
+
+// Handler возвращает обработчик выдачи метрик, данные статистики сервиса запрашиваются из s при каждом сборе.
+func Handler(l *zap.Logger, s data.Storager) http.Handler {
+	stats := prometheus.NewRegistry()
+	stats.MustRegister(newStatsCollector(l, s))
+
+	return promhttp.HandlerFor(prometheus.Gatherers{registry, stats}, promhttp.HandlerOpts{
+		ErrorLog: zap.NewStdLog(l),
+	})
+}
+
+// ObserveHTTP учитывает HTTP запрос, route - шаблон маршрута chi.
+func ObserveHTTP(route string, method string, code int, duration time.Duration) {
+	if route == "" {
+		route = UnknownRoute
+	}
+
+	httpRequests.WithLabelValues(route, method, strconv.Itoa(code)).Inc()
+	httpDuration.WithLabelValues(route, method).Observe(duration.Seconds())
+}
+
+// ObserveRedirect учитывает переход по короткой ссылке с итоговым кодом ответа.
+func ObserveRedirect(code int) {
+	redirects.WithLabelValues(strconv.Itoa(code)).Inc()
+}
@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/data/mock"
+)
+
+func scrape(t *testing.T, h http.Handler) string {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	return w.Body.String()
+}
+
+func TestHandler(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	storage := mock.NewMockStorager(mockCtrl)
+
+	t.Run("service stats", func(t *testing.T) {
+		storage.EXPECT().FetchStats(gomock.Any()).Times(1).Return(10, 4, nil)
+
+		ObserveHTTP("/{id}", http.MethodGet, http.StatusTemporaryRedirect, time.Millisecond)
+		ObserveRedirect(http.StatusTemporaryRedirect)
+
+		body := scrape(t, Handler(zap.NewNop(), storage))
+		assert.Contains(t, body, "shortener_urls 10")
+		assert.Contains(t, body, "shortener_users 4")
+		assert.Contains(t, body, `shortener_http_request_duration_seconds_count{method="GET",route="/{id}"}`)
+		assert.Contains(t, body, `shortener_redirects_total{code="307"}`)
+		assert.Contains(t, body, "go_goroutines")
+	})
+
+	t.Run("stats are skipped on storage error", func(t *testing.T) {
+		storage.EXPECT().FetchStats(gomock.Any()).Times(1).Return(0, 0, errors.New("some error"))
+
+		body := scrape(t, Handler(zap.NewNop(), storage))
+		assert.NotContains(t, body, "shortener_urls ")
+		assert.Contains(t, body, "go_goroutines")
+	})
+}
+
+func TestGRPCInterceptors(t *testing.T) {
+	method := "/shortener.Shortener/GetURL"
+	okBefore := testutil.ToFloat64(grpcRequests.WithLabelValues(method, codes.OK.String()))
+	notFoundBefore := testutil.ToFloat64(grpcRequests.WithLabelValues(method, codes.NotFound.String()))
+
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+	_, err := UnaryServerInterceptor(context.Background(), nil, info, func(context.Context, interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	require.NoError(t, err)
+
+	_, err = UnaryServerInterceptor(context.Background(), nil, info, func(context.Context, interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "not found")
+	})
+	require.Error(t, err)
+
+	assert.InDelta(t, okBefore+1, testutil.ToFloat64(grpcRequests.WithLabelValues(method, codes.OK.String())), 0)
+	assert.InDelta(t, notFoundBefore+1, testutil.ToFloat64(grpcRequests.WithLabelValues(method, codes.NotFound.String())), 0)
+
+	streamMethod := "/shortener.Shortener/StreamUserURLs"
+	streamBefore := testutil.ToFloat64(grpcRequests.WithLabelValues(streamMethod, codes.OK.String()))
+
+	err = StreamServerInterceptor(nil, nil, &grpc.StreamServerInfo{FullMethod: streamMethod}, func(interface{}, grpc.ServerStream) error {
+		return nil
+	})
+	require.NoError(t, err)
+	assert.InDelta(t, streamBefore+1, testutil.ToFloat64(grpcRequests.WithLabelValues(streamMethod, codes.OK.String())), 0)
+}
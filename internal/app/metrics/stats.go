@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+)
+
+const statsTimeout = 5 * time.Second
+
+// statsCollector отдает данные FetchStats как gauge метрики в момент сбора.
+type statsCollector struct {
+	logger *zap.Logger
+	store  data.Storager
+	urls   *prometheus.Desc
+	users  *prometheus.Desc
+}
+
+func newStatsCollector(l *zap.Logger, s data.Storager) *statsCollector {
+	return &statsCollector{
+		logger: l,
+		store:  s,
+		urls:   prometheus.NewDesc(namespace+"_urls", "Number of stored short links.", nil, nil),
+		users:  prometheus.NewDesc(namespace+"_users", "Number of users with short links.", nil, nil),
+	}
+}
+
+// Describe реализует prometheus.Collector.
+func (c *statsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.urls
+	ch <- c.users
+}
+
+// Collect реализует prometheus.Collector, при ошибке хранилища метрики не отдаются.
+func (c *statsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), statsTimeout)
+	defer cancel()
+
+	urls, users, err := c.store.FetchStats(ctx)
+	if err != nil {
+		c.logger.Error("failed to fetch stats for metrics", zap.Error(err))
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.urls, prometheus.GaugeValue, float64(urls))
+	ch <- prometheus.MustNewConstMetric(c.users, prometheus.GaugeValue, float64(users))
+}
@@ -0,0 +1,306 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+)
+
+// instrumentedStorage декоратор хранилища, учитывающий длительность и ошибки каждого метода Storager.
+// Ошибки возвращаются без изменений, чтобы вызывающий код мог их различать.
+type instrumentedStorage struct {
+	next data.Storager
+}
+
+// InstrumentStorage оборачивает любое хранилище сбором метрик операций.
+func InstrumentStorage(s data.Storager) data.Storager {
+	return &instrumentedStorage{next: s}
+}
+
+// observe учитывает операцию method, начатую в start, ожидаемые ответы хранилища ошибками не считаются.
+func observe(method string, start time.Time, err error) {
+	storageDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+
+	if err != nil && !isExpected(err) {
+		storageErrors.WithLabelValues(method).Inc()
+	}
+}
+
+func isExpected(err error) bool {
+	var origErr *data.OriginalURLAlreadyExistError
+
+	return errors.Is(err, data.ErrURLNotFound) ||
+		errors.Is(err, data.ErrShortURLAlreadyExist) ||
+		errors.Is(err, data.ErrQuotaExceeded) ||
+		errors.Is(err, data.ErrOrgAlreadyExist) ||
+		errors.Is(err, data.ErrMemberNotFound) ||
+		errors.Is(err, data.ErrWebhookNotFound) ||
+		errors.As(err, &origErr)
+}
+
+func (s *instrumentedStorage) StoreShortURL(ctx context.Context, shortURL string, originalURL string) error {
+	start := time.Now()
+	err := s.next.StoreShortURL(ctx, shortURL, originalURL)
+	observe("StoreShortURL", start, err)
+
+	return err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) StoreShortURLs(ctx context.Context, urls []models.URL) error {
+	start := time.Now()
+	err := s.next.StoreShortURLs(ctx, urls)
+	observe("StoreShortURLs", start, err)
+
+	return err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) GetURL(ctx context.Context, shortURL string) (models.URL, error) {
+	start := time.Now()
+	res, err := s.next.GetURL(ctx, shortURL)
+	observe("GetURL", start, err)
+
+	return res, err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) FetchUserURLs(ctx context.Context) ([]models.URL, error) {
+	start := time.Now()
+	res, err := s.next.FetchUserURLs(ctx)
+	observe("FetchUserURLs", start, err)
+
+	return res, err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) DeleteShortURLs(ctx context.Context, urls []string) error {
+	start := time.Now()
+	err := s.next.DeleteShortURLs(ctx, urls)
+	observe("DeleteShortURLs", start, err)
+
+	return err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) DropDeletedURLs(ctx context.Context) error {
+	start := time.Now()
+	err := s.next.DropDeletedURLs(ctx)
+	observe("DropDeletedURLs", start, err)
+
+	return err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) FetchStats(ctx context.Context) (int, int, error) {
+	start := time.Now()
+	urls, users, err := s.next.FetchStats(ctx)
+	observe("FetchStats", start, err)
+
+	return urls, users, err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) ConsumeDailyQuota(ctx context.Context, key string, count int, limit int) error {
+	start := time.Now()
+	err := s.next.ConsumeDailyQuota(ctx, key, count, limit)
+	observe("ConsumeDailyQuota", start, err)
+
+	return err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) RefundDailyQuota(ctx context.Context, key string, count int) error {
+	start := time.Now()
+	err := s.next.RefundDailyQuota(ctx, key, count)
+	observe("RefundDailyQuota", start, err)
+
+	return err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) StoreOrg(ctx context.Context, org models.Org) error {
+	start := time.Now()
+	err := s.next.StoreOrg(ctx, org)
+	observe("StoreOrg", start, err)
+
+	return err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) FetchUserOrgs(ctx context.Context) ([]models.UserOrg, error) {
+	start := time.Now()
+	res, err := s.next.FetchUserOrgs(ctx)
+	observe("FetchUserOrgs", start, err)
+
+	return res, err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) GetOrgRole(ctx context.Context, orgID string) (string, error) {
+	start := time.Now()
+	res, err := s.next.GetOrgRole(ctx, orgID)
+	observe("GetOrgRole", start, err)
+
+	return res, err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) FetchOrgMembers(ctx context.Context, orgID string) ([]models.Member, error) {
+	start := time.Now()
+	res, err := s.next.FetchOrgMembers(ctx, orgID)
+	observe("FetchOrgMembers", start, err)
+
+	return res, err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) StoreOrgMember(ctx context.Context, member models.Member) error {
+	start := time.Now()
+	err := s.next.StoreOrgMember(ctx, member)
+	observe("StoreOrgMember", start, err)
+
+	return err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) DeleteOrgMember(ctx context.Context, orgID string, userID string) error {
+	start := time.Now()
+	err := s.next.DeleteOrgMember(ctx, orgID, userID)
+	observe("DeleteOrgMember", start, err)
+
+	return err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) FetchOrgURLs(ctx context.Context, orgID string) ([]models.URL, error) {
+	start := time.Now()
+	res, err := s.next.FetchOrgURLs(ctx, orgID)
+	observe("FetchOrgURLs", start, err)
+
+	return res, err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) Ping(ctx context.Context) error {
+	start := time.Now()
+	err := s.next.Ping(ctx)
+	observe("Ping", start, err)
+
+	return err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) FetchURLsPage(ctx context.Context, orgID string, after string, limit int) ([]models.URL, error) {
+	start := time.Now()
+	res, err := s.next.FetchURLsPage(ctx, orgID, after, limit)
+	observe("FetchURLsPage", start, err)
+
+	return res, err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) StoreAuditEvents(ctx context.Context, events []models.AuditEvent) error {
+	start := time.Now()
+	err := s.next.StoreAuditEvents(ctx, events)
+	observe("StoreAuditEvents", start, err)
+
+	return err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) FetchAuditEvents(
+	ctx context.Context,
+	filter models.AuditFilter,
+) ([]models.AuditEvent, error) {
+	start := time.Now()
+	res, err := s.next.FetchAuditEvents(ctx, filter)
+	observe("FetchAuditEvents", start, err)
+
+	return res, err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) DropAuditEvents(ctx context.Context, before time.Time) (int, error) {
+	start := time.Now()
+	res, err := s.next.DropAuditEvents(ctx, before)
+	observe("DropAuditEvents", start, err)
+
+	return res, err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) StoreWebhook(ctx context.Context, webhook models.Webhook) error {
+	start := time.Now()
+	err := s.next.StoreWebhook(ctx, webhook)
+	observe("StoreWebhook", start, err)
+
+	return err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) FetchUserWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	start := time.Now()
+	res, err := s.next.FetchUserWebhooks(ctx)
+	observe("FetchUserWebhooks", start, err)
+
+	return res, err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) UpdateWebhook(ctx context.Context, webhook models.Webhook) error {
+	start := time.Now()
+	err := s.next.UpdateWebhook(ctx, webhook)
+	observe("UpdateWebhook", start, err)
+
+	return err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) DeleteWebhook(ctx context.Context, id string) error {
+	start := time.Now()
+	err := s.next.DeleteWebhook(ctx, id)
+	observe("DeleteWebhook", start, err)
+
+	return err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) FetchEventWebhooks(
+	ctx context.Context,
+	userID string,
+	event string,
+) ([]models.Webhook, error) {
+	start := time.Now()
+	res, err := s.next.FetchEventWebhooks(ctx, userID, event)
+	observe("FetchEventWebhooks", start, err)
+
+	return res, err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) StoreWebhookDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	start := time.Now()
+	err := s.next.StoreWebhookDeliveries(ctx, deliveries)
+	observe("StoreWebhookDeliveries", start, err)
+
+	return err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) FetchDueWebhookDeliveries(
+	ctx context.Context,
+	now time.Time,
+	limit int,
+) ([]models.WebhookDelivery, error) {
+	start := time.Now()
+	res, err := s.next.FetchDueWebhookDeliveries(ctx, now, limit)
+	observe("FetchDueWebhookDeliveries", start, err)
+
+	return res, err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) UpdateWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) error {
+	start := time.Now()
+	err := s.next.UpdateWebhookDelivery(ctx, delivery)
+	observe("UpdateWebhookDelivery", start, err)
+
+	return err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) DeleteWebhookDelivery(ctx context.Context, id string) error {
+	start := time.Now()
+	err := s.next.DeleteWebhookDelivery(ctx, id)
+	observe("DeleteWebhookDelivery", start, err)
+
+	return err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (s *instrumentedStorage) FetchDeadWebhookDeliveries(ctx context.Context) ([]models.WebhookDelivery, error) {
+	start := time.Now()
+	res, err := s.next.FetchDeadWebhookDeliveries(ctx)
+	observe("FetchDeadWebhookDeliveries", start, err)
+
+	return res, err //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+// Close закрывает обернутое хранилище, операция не учитывается.
+func (s *instrumentedStorage) Close() error {
+	return s.next.Close() //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/data/mock"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+)
+
+func TestInstrumentStorage(t *testing.T) {
+	ctx := context.Background()
+	someErr := errors.New("some error")
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	next := mock.NewMockStorager(mockCtrl)
+	s := InstrumentStorage(next)
+
+	t.Run("success", func(t *testing.T) {
+		next.EXPECT().GetURL(gomock.Any(), "short").Times(1).Return(models.URL{OriginalURL: "https://example.com"}, nil)
+		errorsBefore := testutil.ToFloat64(storageErrors.WithLabelValues("GetURL"))
+
+		u, err := s.GetURL(ctx, "short")
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com", u.OriginalURL)
+		assert.InDelta(t, errorsBefore, testutil.ToFloat64(storageErrors.WithLabelValues("GetURL")), 0)
+	})
+
+	t.Run("expected errors are returned as is and not counted", func(t *testing.T) {
+		next.EXPECT().GetURL(gomock.Any(), "missing").Times(1).Return(models.URL{}, data.ErrURLNotFound)
+		next.EXPECT().StoreShortURL(gomock.Any(), "short", "https://example.com").Times(1).
+			Return(&data.OriginalURLAlreadyExistError{ShortURL: "short"})
+		getBefore := testutil.ToFloat64(storageErrors.WithLabelValues("GetURL"))
+		storeBefore := testutil.ToFloat64(storageErrors.WithLabelValues("StoreShortURL"))
+
+		_, err := s.GetURL(ctx, "missing")
+		require.ErrorIs(t, err, data.ErrURLNotFound)
+
+		err = s.StoreShortURL(ctx, "short", "https://example.com")
+		var origErr *data.OriginalURLAlreadyExistError
+		require.ErrorAs(t, err, &origErr)
+
+		assert.InDelta(t, getBefore, testutil.ToFloat64(storageErrors.WithLabelValues("GetURL")), 0)
+		assert.InDelta(t, storeBefore, testutil.ToFloat64(storageErrors.WithLabelValues("StoreShortURL")), 0)
+	})
+
+	t.Run("failures are counted per method", func(t *testing.T) {
+		next.EXPECT().FetchStats(gomock.Any()).Times(1).Return(0, 0, someErr)
+		next.EXPECT().Ping(gomock.Any()).Times(1).Return(someErr)
+		statsBefore := testutil.ToFloat64(storageErrors.WithLabelValues("FetchStats"))
+		pingBefore := testutil.ToFloat64(storageErrors.WithLabelValues("Ping"))
+
+		_, _, err := s.FetchStats(ctx)
+		require.ErrorIs(t, err, someErr)
+		require.ErrorIs(t, s.Ping(ctx), someErr)
+
+		assert.InDelta(t, statsBefore+1, testutil.ToFloat64(storageErrors.WithLabelValues("FetchStats")), 0)
+		assert.InDelta(t, pingBefore+1, testutil.ToFloat64(storageErrors.WithLabelValues("Ping")), 0)
+	})
+
+	t.Run("close is passed through", func(t *testing.T) {
+		next.EXPECT().Close().Times(1).Return(nil)
+		require.NoError(t, s.Close())
+	})
+}
@@ -1,6 +1,11 @@
 // Модуль моделей сервиса.
 package models
 
+import (
+	"encoding/json"
+	"time"
+)
+
 // Request модель запроса короткой ссылки для оригинальной.
 type Request struct {
 	URL string `json:"url"`
@@ -13,11 +18,13 @@ type Response struct {
 
 // URL модель ссылки.
 type URL struct {
-	ShortURL    string `json:"short_url"`
-	OriginalURL string `json:"original_url"`
-	UserID      string `json:"user_id"`
-	ID          uint   `json:"id"`
-	DeletedFlag bool   `json:"is_deleted"`
+	ShortURL    string    `json:"short_url"`
+	OriginalURL string    `json:"original_url"`
+	UserID      string    `json:"user_id"`
+	OrgID       string    `json:"org_id,omitempty"`
+	ID          uint      `json:"id"`
+	DeletedFlag bool      `json:"is_deleted"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 // BatchRequest модель запроса можественного получения коротких ссылок.
@@ -47,8 +54,215 @@ type UserURLsDataResponse struct {
 	OriginalURL string `json:"original_url"`
 }
 
+// ExportURL модель ссылки при выгрузке ссылок пользователя.
+type ExportURL struct {
+	ShortURL    string     `json:"short_url"`
+	OriginalURL string     `json:"original_url"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"` // неизвестна для ссылок, созданных до учета даты
+	DeletedFlag bool       `json:"is_deleted"`
+}
+
+// ImportRow модель строки загружаемого файла ссылок.
+type ImportRow struct {
+	ShortURL    string `json:"short_url"` // желаемый ключ или короткая ссылка целиком
+	OriginalURL string `json:"original_url"`
+	DeletedFlag bool   `json:"is_deleted"`
+}
+
+// Статусы строк загрузки ссылок.
+const (
+	ImportCreated = "created" // ссылка создана с желаемым ключом или новым, если ключ не задан
+	ImportRenamed = "renamed" // желаемый ключ занят или недопустим, ссылка создана с новым ключом
+	ImportSkipped = "skipped" // ссылка удалена в источнике или повторяет предыдущую строку
+	ImportInvalid = "invalid" // некорректная оригинальная ссылка
+)
+
+// ImportRowResult модель результата загрузки строки.
+type ImportRowResult struct {
+	Row         int    `json:"row"`
+	Status      string `json:"status"`
+	ShortURL    string `json:"short_url,omitempty"`
+	OriginalURL string `json:"original_url"`
+	Message     string `json:"message,omitempty"`
+}
+
+// ImportResponse модель ответа на загрузку ссылок.
+type ImportResponse struct {
+	Created int               `json:"created"`
+	Renamed int               `json:"renamed"`
+	Skipped int               `json:"skipped"`
+	Invalid int               `json:"invalid"`
+	Results []ImportRowResult `json:"results"`
+}
+
 // StatsResponse модель статистических данных.
 type StatsResponse struct {
 	URLs  int `json:"urls"`
 	Users int `json:"users"`
 }
+
+// LoginResponse модель ответа на успешный вход через внешнего провайдера.
+type LoginResponse struct {
+	UserID string `json:"user_id"`
+}
+
+// CSRFTokenResponse модель ответа с CSRF токеном пользователя.
+type CSRFTokenResponse struct {
+	Token string `json:"token"`
+}
+
+// Роли участников организации.
+const (
+	RoleOwner  = "owner"  // управляет участниками и ссылками организации
+	RoleEditor = "editor" // создает и удаляет ссылки организации
+	RoleViewer = "viewer" // просматривает ссылки организации
+)
+
+// Org модель организации.
+type Org struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Member модель участника организации.
+type Member struct {
+	OrgID  string `json:"org_id"`
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// UserOrg модель организации пользователя с его ролью.
+type UserOrg struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+// OrgRequest модель запроса на создание организации.
+type OrgRequest struct {
+	Name string `json:"name"`
+}
+
+// MemberRequest модель запроса на добавление участника или изменение его роли.
+type MemberRequest struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// Действия с ссылками, которые записываются в журнал аудита.
+const (
+	AuditCreate      = "create"       // создание короткой ссылки
+	AuditBatchCreate = "batch_create" // создание ссылки в составе пакета или загрузки
+	AuditDelete      = "delete"       // мягкое удаление ссылки
+	AuditPurge       = "purge"        // очистка удаленных ссылок из БД
+	AuditStats       = "stats"        // доступ к статистике сервиса
+)
+
+// AuditEvent модель события журнала аудита.
+type AuditEvent struct {
+	Time        time.Time `json:"time"`
+	Action      string    `json:"action"`
+	UserID      string    `json:"user_id,omitempty"`
+	Transport   string    `json:"transport"`
+	ClientIP    string    `json:"client_ip,omitempty"`
+	RequestID   string    `json:"request_id,omitempty"`
+	ShortURL    string    `json:"short_url,omitempty"`
+	OriginalURL string    `json:"original_url,omitempty"`
+}
+
+// AuditFilter модель фильтра событий журнала аудита, пустые поля не ограничивают выборку.
+type AuditFilter struct {
+	From      time.Time // события не раньше
+	To        time.Time // события раньше
+	Action    string
+	UserID    string
+	ShortURL  string
+	Transport string
+	Limit     int
+}
+
+// Match проверяет, что событие подходит под фильтр (без учета Limit).
+func (f AuditFilter) Match(e AuditEvent) bool {
+	switch {
+	case !f.From.IsZero() && e.Time.Before(f.From),
+		!f.To.IsZero() && !e.Time.Before(f.To),
+		f.Action != "" && e.Action != f.Action,
+		f.UserID != "" && e.UserID != f.UserID,
+		f.ShortURL != "" && e.ShortURL != f.ShortURL,
+		f.Transport != "" && e.Transport != f.Transport:
+		return false
+	default:
+		return true
+	}
+}
+
+// События жизненного цикла ссылок, на которые подписываются вебхуки.
+const (
+	LinkCreated = "create" // создание короткой ссылки, в том числе в составе пакета или загрузки
+	LinkDeleted = "delete" // мягкое удаление ссылки
+	LinkClicked = "click"  // переход по короткой ссылке
+)
+
+// LinkEvent модель события жизненного цикла ссылки.
+type LinkEvent struct {
+	Time        time.Time `json:"time"`
+	Type        string    `json:"type"`
+	ShortURL    string    `json:"short_url"`
+	OriginalURL string    `json:"original_url,omitempty"`
+	UserID      string    `json:"user_id"` // владелец ссылки
+	OrgID       string    `json:"org_id,omitempty"`
+}
+
+// Webhook модель вебхука пользователя.
+type Webhook struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"` // ключ подписи, возвращается только при создании
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookRequest модель запроса на создание или изменение вебхука.
+type WebhookRequest struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret,omitempty"` // если не задан при создании, генерируется сервисом
+	Events []string `json:"events"`
+}
+
+// WebhookDelivery модель доставки события вебхуку из исходящей очереди.
+type WebhookDelivery struct {
+	ID          string          `json:"id"`
+	WebhookID   string          `json:"webhook_id"`
+	Event       string          `json:"event"`
+	Payload     json.RawMessage `json:"payload"`
+	Attempts    int             `json:"attempts"`
+	NextAttempt time.Time       `json:"next_attempt"`
+	LastError   string          `json:"last_error,omitempty"`
+	Dead        bool            `json:"dead"` // попытки исчерпаны, доставка перенесена в список недоставленных
+	CreatedAt   time.Time       `json:"created_at"`
+	URL         string          `json:"-"` // адрес вебхука, заполняется при выборке доставок к отправке
+	Secret      string          `json:"-"` // ключ подписи вебхука, заполняется при выборке доставок к отправке
+}
+
+// OutboxEvent модель изменения ссылки в транзакционной исходящей очереди для публикации в шину сообщений.
+type OutboxEvent struct {
+	ID          int64     `json:"id"`    // возрастающий номер события, по нему получатель отбрасывает повторы
+	Event       string    `json:"event"` // LinkCreated или LinkDeleted
+	ShortURL    string    `json:"short_url"`
+	OriginalURL string    `json:"original_url"`
+	UserID      string    `json:"user_id,omitempty"`
+	OrgID       string    `json:"org_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// UserEvent модель события ссылки в потоке событий пользователя.
+type UserEvent struct {
+	ID          uint64    `json:"id"`
+	Type        string    `json:"type"`
+	Time        time.Time `json:"time"`
+	ShortURL    string    `json:"short_url,omitempty"`
+	ShortLink   string    `json:"short_link,omitempty"`
+	OriginalURL string    `json:"original_url,omitempty"`
+	OrgID       string    `json:"org_id,omitempty"`
+}
@@ -0,0 +1,93 @@
+// Пакет oidc предназначен для входа пользователей через внешнего OpenID Connect провайдера.
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+const userIDBytes = 8
+
+// Ошибки OIDC авторизации.
+var (
+	ErrMissingIDToken = errors.New("id token missing in token response") // провайдер не вернул id_token
+	ErrNonceMismatch  = errors.New("id token nonce mismatch")            // nonce в id_token не совпадает с ожидаемым
+)
+
+// Config структура настроек OIDC клиента.
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+}
+
+// Authenticator структура OIDC клиента.
+type Authenticator struct {
+	verifier *gooidc.IDTokenVerifier
+	oauth    oauth2.Config
+	issuer   string
+}
+
+// NewAuthenticator инициализирует OIDC клиента, получая настройки провайдера через discovery.
+func NewAuthenticator(ctx context.Context, cfg Config) (*Authenticator, error) {
+	provider, err := gooidc.NewProvider(ctx, cfg.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC provider: %w", err)
+	}
+
+	return &Authenticator{
+		verifier: provider.Verifier(&gooidc.Config{ClientID: cfg.ClientID}),
+		oauth: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       []string{gooidc.ScopeOpenID},
+		},
+		issuer: cfg.Issuer,
+	}, nil
+}
+
+// AuthCodeURL возвращает адрес страницы входа провайдера.
+func (a *Authenticator) AuthCodeURL(state string, nonce string) string {
+	return a.oauth.AuthCodeURL(state, gooidc.Nonce(nonce))
+}
+
+// Exchange обменивает код авторизации на id_token, проверяет его и возвращает subject пользователя.
+func (a *Authenticator) Exchange(ctx context.Context, code string, nonce string) (string, error) {
+	token, err := a.oauth.Exchange(ctx, code)
+	if err != nil {
+		return "", fmt.Errorf("failed to exchange code: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return "", ErrMissingIDToken
+	}
+
+	idToken, err := a.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return "", fmt.Errorf("failed to verify id token: %w", err)
+	}
+
+	if idToken.Nonce != nonce {
+		return "", ErrNonceMismatch
+	}
+
+	return idToken.Subject, nil
+}
+
+// UserID возвращает ID пользователя сервиса для subject провайдера.
+// ID стабилен между перезапусками и не зависит от секретного ключа сервиса.
+func (a *Authenticator) UserID(subject string) string {
+	sum := sha256.Sum256([]byte(a.issuer + "\x00" + subject))
+
+	return hex.EncodeToString(sum[:userIDBytes])
+}
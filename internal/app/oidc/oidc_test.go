@@ -0,0 +1,99 @@
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/oidc/oidctest"
+)
+
+const redirectURL = "http://localhost:8080/auth/oidc/callback"
+
+// authorize проходит страницу входа провайдера и возвращает код авторизации.
+func authorize(t *testing.T, a *Authenticator, state string, nonce string) string {
+	t.Helper()
+
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+
+	res, err := client.Get(a.AuthCodeURL(state, nonce))
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	require.Equal(t, http.StatusFound, res.StatusCode)
+
+	location, err := url.Parse(res.Header.Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, state, location.Query().Get("state"))
+
+	return location.Query().Get("code")
+}
+
+func TestAuthenticator(t *testing.T) {
+	provider, err := oidctest.NewProvider("shortener", "secret", "alice")
+	require.NoError(t, err)
+	defer provider.Close()
+
+	ctx := context.Background()
+	cfg := Config{
+		Issuer:       provider.Issuer(),
+		ClientID:     "shortener",
+		ClientSecret: "secret",
+		RedirectURL:  redirectURL,
+	}
+
+	a, err := NewAuthenticator(ctx, cfg)
+	require.NoError(t, err)
+
+	t.Run("success exchange", func(t *testing.T) {
+		code := authorize(t, a, "state", "nonce")
+
+		subject, err := a.Exchange(ctx, code, "nonce")
+		require.NoError(t, err)
+		assert.Equal(t, "alice", subject)
+	})
+
+	t.Run("nonce mismatch", func(t *testing.T) {
+		code := authorize(t, a, "state", "nonce")
+
+		_, err := a.Exchange(ctx, code, "other")
+		assert.ErrorIs(t, err, ErrNonceMismatch)
+	})
+
+	t.Run("code reuse", func(t *testing.T) {
+		code := authorize(t, a, "state", "nonce")
+
+		_, err := a.Exchange(ctx, code, "nonce")
+		require.NoError(t, err)
+
+		_, err = a.Exchange(ctx, code, "nonce")
+		assert.Error(t, err)
+	})
+
+	t.Run("wrong client secret", func(t *testing.T) {
+		wrong := cfg
+		wrong.ClientSecret = "wrong"
+
+		b, err := NewAuthenticator(ctx, wrong)
+		require.NoError(t, err)
+
+		_, err = b.Exchange(ctx, authorize(t, b, "state", "nonce"), "nonce")
+		assert.Error(t, err)
+	})
+
+	t.Run("stable user id", func(t *testing.T) {
+		assert.Equal(t, a.UserID("alice"), a.UserID("alice"))
+		assert.NotEqual(t, a.UserID("alice"), a.UserID("bob"))
+		assert.Len(t, a.UserID("alice"), 2*userIDBytes)
+	})
+}
+
+func TestNewAuthenticator_Unavailable(t *testing.T) {
+	_, err := NewAuthenticator(context.Background(), Config{Issuer: "http://127.0.0.1:1"})
+	assert.Error(t, err)
+}
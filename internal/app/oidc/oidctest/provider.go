@@ -0,0 +1,208 @@
+// Пакет oidctest содержит встроенный OIDC провайдер для тестирования входа без доступа к сети.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	keyBits   = 2048
+	codeBytes = 16
+	keyID     = "oidctest"
+	tokenTTL  = time.Hour
+)
+
+// Provider структура тестового OIDC провайдера.
+type Provider struct {
+	server       *httptest.Server
+	key          *rsa.PrivateKey
+	codes        map[string]string
+	clientID     string
+	clientSecret string
+	subject      string
+	mu           sync.Mutex
+}
+
+// NewProvider запускает тестовый OIDC провайдер, который автоматически авторизует пользователя subject.
+func NewProvider(clientID string, clientSecret string, subject string) (*Provider, error) {
+	key, err := rsa.GenerateKey(rand.Reader, keyBits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	p := &Provider{
+		key:          key,
+		codes:        make(map[string]string),
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		subject:      subject,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("GET /authorize", p.authorize)
+	mux.HandleFunc("POST /token", p.token)
+	mux.HandleFunc("GET /jwks", p.jwks)
+
+	p.server = httptest.NewServer(mux)
+
+	return p, nil
+}
+
+// Issuer возвращает адрес провайдера.
+func (p *Provider) Issuer() string {
+	return p.server.URL
+}
+
+// SetSubject меняет пользователя, которого авторизует провайдер.
+func (p *Provider) SetSubject(subject string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.subject = subject
+}
+
+// Close останавливает провайдер.
+func (p *Provider) Close() {
+	p.server.Close()
+}
+
+func (p *Provider) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                p.Issuer(),
+		"authorization_endpoint":                p.Issuer() + "/authorize",
+		"token_endpoint":                        p.Issuer() + "/token",
+		"jwks_uri":                              p.Issuer() + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (p *Provider) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("client_id") != p.clientID || q.Get("response_type") != "code" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	redirectURL, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || redirectURL.Host == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	code, err := randomString()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	p.mu.Lock()
+	p.codes[code] = q.Get("nonce")
+	p.mu.Unlock()
+
+	params := redirectURL.Query()
+	params.Set("code", code)
+	params.Set("state", q.Get("state"))
+	redirectURL.RawQuery = params.Encode()
+
+	http.Redirect(w, r, redirectURL.String(), http.StatusFound)
+}
+
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != p.clientID || clientSecret != p.clientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	p.mu.Lock()
+	nonce, found := p.codes[r.PostForm.Get("code")]
+	delete(p.codes, r.PostForm.Get("code"))
+	subject := p.subject
+	p.mu.Unlock()
+
+	if !found || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   p.Issuer(),
+		"sub":   subject,
+		"aud":   p.clientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(tokenTTL).Unix(),
+		"nonce": nonce,
+	})
+	idToken.Header["kid"] = keyID
+
+	signed, err := idToken.SignedString(p.key)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": "access-" + subject,
+		"token_type":   "Bearer",
+		"expires_in":   int(tokenTTL.Seconds()),
+		"id_token":     signed,
+	})
+}
+
+func (p *Provider) jwks(w http.ResponseWriter, _ *http.Request) {
+	pub := p.key.PublicKey
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"kid": keyID,
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			},
+		},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func randomString() (string, error) {
+	bytes := make([]byte, codeBytes)
+
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("generate random string error: %w", err)
+	}
+
+	return hex.EncodeToString(bytes), nil
+}
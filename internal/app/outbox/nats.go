@@ -0,0 +1,133 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+)
+
+const (
+	natsDefaultPort = "4222"
+	natsTimeout     = 5 * time.Second
+	natsClientName  = "shortener"
+)
+
+// NATSPublisher публикатор в поток JetStream сервера NATS.
+// Событие публикуется в тему <subject>.<event>, которую должен захватывать поток JetStream.
+// Публикация считается успешной после подтверждения сервера о сохранении сообщения в потоке,
+// повторно опубликованное событие сервер отбрасывает по заголовку Nats-Msg-Id.
+type NATSPublisher struct {
+	logger  *zap.Logger
+	addr    string
+	subject string
+	options []nats.Option
+	conn    *nats.Conn
+	js      jetstream.JetStream
+	mu      sync.Mutex
+}
+
+// NewNATSPublisher создает публикатор по адресу nats://[user:pass@]host[:port][/subject],
+// имя пользователя без пароля передается как токен. Соединение устанавливается при первой публикации.
+func NewNATSPublisher(l *zap.Logger, u *url.URL) *NATSPublisher {
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), natsDefaultPort)
+	}
+
+	subject := strings.Trim(u.Path, "/")
+	if subject == "" {
+		subject = DefaultSubject
+	}
+
+	options := []nats.Option{nats.Name(natsClientName), nats.Timeout(natsTimeout)}
+	if u.User != nil {
+		if pass, ok := u.User.Password(); ok {
+			options = append(options, nats.UserInfo(u.User.Username(), pass))
+		} else {
+			options = append(options, nats.Token(u.User.Username()))
+		}
+	}
+
+	return &NATSPublisher{
+		logger:  l,
+		addr:    addr,
+		subject: subject,
+		options: options,
+	}
+}
+
+// Publish публикует события по порядку и ждет подтверждения JetStream для каждого.
+func (p *NATSPublisher) Publish(ctx context.Context, events []models.OutboxEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.connect(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, natsTimeout)
+	defer cancel()
+
+	for _, event := range events {
+		body, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode outbox event: %w", err)
+		}
+
+		msgID := jetstream.WithMsgID(strconv.FormatInt(event.ID, 10))
+		if _, err := p.js.Publish(ctx, p.subject+"."+event.Event, body, msgID); err != nil {
+			return fmt.Errorf("failed to publish event %d to nats: %w", event.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// connect подключается к серверу, если соединения еще нет или оно закрыто.
+// Разрывы открытого соединения клиент NATS восстанавливает сам.
+func (p *NATSPublisher) connect() error {
+	if p.conn != nil && !p.conn.IsClosed() {
+		return nil
+	}
+
+	conn, err := nats.Connect("nats://"+p.addr, p.options...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to nats: %w", err)
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to create jetstream context: %w", err)
+	}
+	p.conn, p.js = conn, js
+
+	p.logger.Info("connected to nats", zap.String("addr", p.addr), zap.String("subject", p.subject))
+
+	return nil
+}
+
+// Close закрывает соединение с NATS.
+func (p *NATSPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn != nil {
+		p.conn.Close()
+		p.conn, p.js = nil, nil
+	}
+
+	return nil
+}
@@ -0,0 +1,269 @@
+package outbox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+)
+
+// natsMessage сообщение, принятое тестовым сервером.
+type natsMessage struct {
+	Subject string
+	MsgID   string
+	Event   models.OutboxEvent
+}
+
+// natsConnect нужные тестам параметры команды CONNECT клиента.
+type natsConnect struct {
+	Name      string `json:"name"`
+	User      string `json:"user"`
+	Pass      string `json:"pass"`
+	AuthToken string `json:"auth_token"`
+}
+
+// natsServer локальная замена сервера NATS с потоком JetStream: принимает CONNECT, SUB, HPUB и PING
+// и подтверждает каждое сообщение ответом JetStream в тему ответа.
+type natsServer struct {
+	listener   net.Listener
+	maxPayload int64
+	connects   []natsConnect
+	messages   []natsMessage
+	reject     string // если задано, на публикацию отвечает ошибкой JetStream с этим текстом
+	mu         sync.Mutex
+}
+
+func newNATSServer(t *testing.T, maxPayload int64) *natsServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	srv := &natsServer{listener: listener, maxPayload: maxPayload}
+	go srv.serve(t)
+
+	return srv
+}
+
+func (srv *natsServer) url(path string) *url.URL {
+	return &url.URL{Scheme: "nats", Host: srv.listener.Addr().String(), Path: path}
+}
+
+func (srv *natsServer) serve(t *testing.T) {
+	for {
+		conn, err := srv.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go srv.handle(t, conn)
+	}
+}
+
+func (srv *natsServer) handle(t *testing.T, conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	info := fmt.Sprintf(`{"server_id":"test","version":"2.10.0","proto":1,"headers":true,"max_payload":%d}`,
+		srv.maxPayload)
+	if _, err := fmt.Fprintf(conn, "INFO %s\r\n", info); err != nil {
+		return
+	}
+
+	var sid string
+	seq := 0
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		op, args, _ := strings.Cut(strings.TrimRight(line, "\r\n"), " ")
+
+		switch op {
+		case "CONNECT":
+			var c natsConnect
+			assert.NoError(t, json.Unmarshal([]byte(args), &c))
+			srv.mu.Lock()
+			srv.connects = append(srv.connects, c)
+			srv.mu.Unlock()
+		case "SUB":
+			fields := strings.Fields(args)
+			sid = fields[len(fields)-1]
+		case "HPUB":
+			var subject, reply string
+			var headerSize, totalSize int
+			_, err := fmt.Sscan(args, &subject, &reply, &headerSize, &totalSize)
+			if !assert.NoError(t, err) {
+				return
+			}
+			msg := make([]byte, totalSize+2)
+			if _, err := io.ReadFull(r, msg); err != nil {
+				return
+			}
+			hr := textproto.NewReader(bufio.NewReader(bytes.NewReader(msg[:headerSize])))
+			version, err := hr.ReadLine()
+			if !assert.NoError(t, err) || !assert.Equal(t, "NATS/1.0", version) {
+				return
+			}
+			headers, err := hr.ReadMIMEHeader()
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			srv.mu.Lock()
+			ack := fmt.Sprintf(`{"error":{"code":503,"err_code":10077,"description":%q}}`, srv.reject)
+			if srv.reject == "" {
+				var event models.OutboxEvent
+				assert.NoError(t, json.Unmarshal(msg[headerSize:totalSize], &event))
+				srv.messages = append(srv.messages, natsMessage{
+					Subject: subject,
+					MsgID:   headers.Get("Nats-Msg-Id"),
+					Event:   event,
+				})
+				seq++
+				ack = fmt.Sprintf(`{"stream":"SHORTENER","seq":%d}`, seq)
+			}
+			srv.mu.Unlock()
+
+			if _, err := fmt.Fprintf(conn, "MSG %s %s %d\r\n%s\r\n", reply, sid, len(ack), ack); err != nil {
+				return
+			}
+		case "PING":
+			if _, err := conn.Write([]byte("PONG\r\n")); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (srv *natsServer) snapshot() ([]natsConnect, []natsMessage) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	return append([]natsConnect(nil), srv.connects...), append([]natsMessage(nil), srv.messages...)
+}
+
+func TestNATSPublisher(t *testing.T) {
+	ctx := context.Background()
+	events := []models.OutboxEvent{
+		{ID: 1, Event: models.LinkCreated, ShortURL: "abc", OriginalURL: "https://ya.ru", UserID: "user_1"},
+		{ID: 2, Event: models.LinkDeleted, ShortURL: "abc", OriginalURL: "https://ya.ru", UserID: "user_1"},
+	}
+
+	t.Run("publish", func(t *testing.T) {
+		srv := newNATSServer(t, 1048576)
+		u := srv.url("")
+		u.User = url.UserPassword("user", "pass")
+		p := NewNATSPublisher(zap.NewNop(), u)
+		defer func() { require.NoError(t, p.Close()) }()
+
+		require.NoError(t, p.Publish(ctx, events[:1]))
+		require.NoError(t, p.Publish(ctx, events[1:]))
+
+		connects, messages := srv.snapshot()
+		require.Len(t, connects, 1, "connection is reused")
+		assert.Equal(t, "user", connects[0].User)
+		assert.Equal(t, "pass", connects[0].Pass)
+		assert.Equal(t, natsClientName, connects[0].Name)
+
+		require.Len(t, messages, 2)
+		assert.Equal(t, "shortener.urls.create", messages[0].Subject)
+		assert.Equal(t, "shortener.urls.delete", messages[1].Subject)
+		assert.Equal(t, "1", messages[0].MsgID, "event id is used for deduplication")
+		assert.Equal(t, "2", messages[1].MsgID)
+		assert.Equal(t, events[0].ShortURL, messages[0].Event.ShortURL)
+		assert.Equal(t, int64(2), messages[1].Event.ID)
+	})
+
+	t.Run("subject and token from url", func(t *testing.T) {
+		srv := newNATSServer(t, 1048576)
+		u := srv.url("/analytics.links")
+		u.User = url.User("token")
+		p := NewNATSPublisher(zap.NewNop(), u)
+		defer func() { require.NoError(t, p.Close()) }()
+
+		require.NoError(t, p.Publish(ctx, events[:1]))
+
+		connects, messages := srv.snapshot()
+		require.Len(t, connects, 1)
+		assert.Equal(t, "token", connects[0].AuthToken)
+		require.Len(t, messages, 1)
+		assert.Equal(t, "analytics.links.create", messages[0].Subject)
+	})
+
+	t.Run("not acknowledged", func(t *testing.T) {
+		srv := newNATSServer(t, 1048576)
+		srv.reject = "no stream matches subject"
+		p := NewNATSPublisher(zap.NewNop(), srv.url(""))
+		defer func() { require.NoError(t, p.Close()) }()
+
+		err := p.Publish(ctx, events)
+		require.ErrorContains(t, err, "failed to publish event 1")
+		assert.ErrorContains(t, err, "no stream matches subject")
+
+		srv.mu.Lock()
+		srv.reject = ""
+		srv.mu.Unlock()
+
+		require.NoError(t, p.Publish(ctx, events))
+		_, messages := srv.snapshot()
+		assert.Len(t, messages, 2)
+	})
+
+	t.Run("reconnect after close", func(t *testing.T) {
+		srv := newNATSServer(t, 1048576)
+		p := NewNATSPublisher(zap.NewNop(), srv.url(""))
+
+		require.NoError(t, p.Publish(ctx, events[:1]))
+		require.NoError(t, p.Close())
+		require.NoError(t, p.Publish(ctx, events[1:]))
+		require.NoError(t, p.Close())
+
+		connects, messages := srv.snapshot()
+		assert.Len(t, connects, 2)
+		assert.Len(t, messages, 2)
+	})
+
+	t.Run("payload too large", func(t *testing.T) {
+		srv := newNATSServer(t, 16)
+		p := NewNATSPublisher(zap.NewNop(), srv.url(""))
+		defer func() { require.NoError(t, p.Close()) }()
+
+		require.ErrorIs(t, p.Publish(ctx, events[:1]), nats.ErrMaxPayload)
+	})
+
+	t.Run("server unavailable", func(t *testing.T) {
+		srv := newNATSServer(t, 1048576)
+		u := srv.url("")
+		require.NoError(t, srv.listener.Close())
+		p := NewNATSPublisher(zap.NewNop(), u)
+
+		require.ErrorContains(t, p.Publish(ctx, events[:1]), "failed to connect to nats")
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		srv := newNATSServer(t, 1048576)
+		p := NewNATSPublisher(zap.NewNop(), srv.url(""))
+		defer func() { require.NoError(t, p.Close()) }()
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		require.ErrorIs(t, p.Publish(canceled, events[:1]), context.Canceled)
+	})
+}
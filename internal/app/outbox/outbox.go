@@ -0,0 +1,133 @@
+// Пакет outbox предназначен для публикации изменений ссылок из исходящей очереди БД в шину сообщений.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+)
+
+// DefaultSubject тема сообщений по умолчанию, к ней добавляется событие: shortener.urls.create.
+const DefaultSubject = "shortener.urls"
+
+const filePerm = 0o600
+
+// ErrUnknownPublisher адрес публикации не поддерживается.
+var ErrUnknownPublisher = errors.New("unknown outbox publisher")
+
+// Publisher интерфейс к шине сообщений, в которую публикуются события исходящей очереди.
+type Publisher interface {
+	Publish(ctx context.Context, events []models.OutboxEvent) error // опубликовать события по порядку
+	Close() error                                                   // закрыть соединение с шиной
+}
+
+// NewPublisher создает публикатор по адресу: memory:, file:/path/to/events.jsonl
+// или nats://[user:pass@]host[:port][/subject].
+func NewPublisher(l *zap.Logger, rawURL string) (Publisher, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse outbox url: %w", err)
+	}
+
+	switch u.Scheme {
+	case "memory":
+		return NewMemoryPublisher(), nil
+	case "file":
+		path := u.Path
+		if path == "" {
+			path = u.Opaque
+		}
+		if path == "" {
+			return nil, fmt.Errorf("%w: file path is empty", ErrUnknownPublisher)
+		}
+
+		return NewFilePublisher(path)
+	case "nats":
+		return NewNATSPublisher(l, u), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownPublisher, u.Scheme)
+	}
+}
+
+// MemoryPublisher публикатор, сохраняющий события в памяти, для тестов и отладки.
+type MemoryPublisher struct {
+	events []models.OutboxEvent
+	mu     sync.Mutex
+}
+
+// NewMemoryPublisher создает публикатор в памяти.
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+// Publish сохраняет события.
+func (p *MemoryPublisher) Publish(_ context.Context, events []models.OutboxEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.events = append(p.events, events...)
+
+	return nil
+}
+
+// Events возвращает опубликованные события.
+func (p *MemoryPublisher) Events() []models.OutboxEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return slices.Clone(p.events)
+}
+
+// Close ничего не делает.
+func (p *MemoryPublisher) Close() error {
+	return nil
+}
+
+// FilePublisher публикатор, дописывающий события в файл по строке JSON на событие.
+type FilePublisher struct {
+	file *os.File
+	mu   sync.Mutex
+}
+
+// NewFilePublisher открывает файл path для дописывания событий.
+func NewFilePublisher(path string) (*FilePublisher, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, filePerm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open outbox file: %w", err)
+	}
+
+	return &FilePublisher{file: file}, nil
+}
+
+// Publish дописывает события в файл.
+func (p *FilePublisher) Publish(_ context.Context, events []models.OutboxEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	encoder := json.NewEncoder(p.file)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return fmt.Errorf("failed to write outbox event: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Close закрывает файл.
+func (p *FilePublisher) Close() error {
+	if err := p.file.Close(); err != nil {
+		return fmt.Errorf("failed to close outbox file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+)
+
+func TestNewPublisher(t *testing.T) {
+	path := t.TempDir() + "/events.jsonl"
+
+	tests := []struct {
+		name    string
+		url     string
+		want    any
+		wantErr error
+	}{
+		{name: "memory", url: "memory:", want: &MemoryPublisher{}},
+		{name: "file", url: "file://" + path, want: &FilePublisher{}},
+		{name: "nats", url: "nats://localhost", want: &NATSPublisher{}},
+		{name: "empty file path", url: "file:", wantErr: ErrUnknownPublisher},
+		{name: "unknown scheme", url: "kafka://localhost:9092", wantErr: ErrUnknownPublisher},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := NewPublisher(zap.NewNop(), test.url)
+			if test.wantErr != nil {
+				require.ErrorIs(t, err, test.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.IsType(t, test.want, p)
+			require.NoError(t, p.Close())
+		})
+	}
+
+	t.Run("nats default address and subject", func(t *testing.T) {
+		p, err := NewPublisher(zap.NewNop(), "nats://localhost")
+		require.NoError(t, err)
+
+		nats, ok := p.(*NATSPublisher)
+		require.True(t, ok)
+		assert.Equal(t, "localhost:4222", nats.addr)
+		assert.Equal(t, DefaultSubject, nats.subject)
+	})
+}
+
+func TestFilePublisher(t *testing.T) {
+	ctx := context.Background()
+	path := t.TempDir() + "/events.jsonl"
+	events := []models.OutboxEvent{
+		{ID: 1, Event: models.LinkCreated, ShortURL: "abc", OriginalURL: "https://ya.ru"},
+		{ID: 2, Event: models.LinkDeleted, ShortURL: "abc", OriginalURL: "https://ya.ru"},
+	}
+
+	for _, event := range events {
+		p, err := NewFilePublisher(path)
+		require.NoError(t, err)
+		require.NoError(t, p.Publish(ctx, []models.OutboxEvent{event}))
+		require.NoError(t, p.Close())
+	}
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, file.Close()) }()
+
+	var got []models.OutboxEvent
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event models.OutboxEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		got = append(got, event)
+	}
+	require.NoError(t, scanner.Err())
+
+	assert.Equal(t, events, got, "events are appended across reopen")
+}
+
+func TestMemoryPublisher(t *testing.T) {
+	p := NewMemoryPublisher()
+	events := []models.OutboxEvent{{ID: 1, Event: models.LinkCreated, ShortURL: "abc"}}
+
+	require.NoError(t, p.Publish(context.Background(), events))
+
+	got := p.Events()
+	assert.Equal(t, events, got)
+
+	got[0].ShortURL = "changed"
+	assert.Equal(t, "abc", p.Events()[0].ShortURL)
+}
@@ -0,0 +1,90 @@
+// Пакет profiling предназначен для снятия профилей сервиса по запросу.
+package profiling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"time"
+)
+
+// KindCPU профиль процессора, остальные виды профилей берутся из runtime/pprof (heap, goroutine, allocs, ...).
+const KindCPU = "cpu"
+
+const (
+	dirPerm    fs.FileMode = 0o750
+	filePerm   fs.FileMode = 0o600
+	timeLayout             = "20060102T150405.000Z"
+)
+
+// Ошибки снятия профилей.
+var (
+	ErrUnknownProfile = errors.New("unknown profile")                  // вид профиля не поддерживается
+	ErrCPUProfiling   = errors.New("cpu profiling is already running") // CPU профиль уже снимается
+)
+
+// Capture снимает профиль kind и сохраняет его в каталог dir под именем kind-<время UTC>.pprof,
+// чтобы профили можно было сравнить позже через go tool pprof -base. Возвращает путь к файлу.
+// CPU профиль снимается в течение duration или до отмены ctx, перед профилем heap выполняется сборка мусора.
+func Capture(ctx context.Context, dir, kind string, duration time.Duration) (_ string, err error) {
+	profile := pprof.Lookup(kind)
+	if kind != KindCPU && profile == nil {
+		return "", fmt.Errorf("%w: %q", ErrUnknownProfile, kind)
+	}
+
+	if err := os.MkdirAll(dir, dirPerm); err != nil {
+		return "", fmt.Errorf("failed to create profiles dir: %w", err)
+	}
+
+	name := filepath.Join(dir, fmt.Sprintf("%s-%s.pprof", kind, time.Now().UTC().Format(timeLayout)))
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, filePerm)
+	if err != nil {
+		return "", fmt.Errorf("failed to create profile file: %w", err)
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close profile file: %w", closeErr)
+		}
+		if err != nil {
+			_ = os.Remove(name)
+		}
+	}()
+
+	if kind == KindCPU {
+		if err := captureCPU(ctx, file, duration); err != nil {
+			return "", err
+		}
+		return name, nil
+	}
+
+	if kind == "heap" {
+		runtime.GC()
+	}
+	if err := profile.WriteTo(file, 0); err != nil {
+		return "", fmt.Errorf("failed to write %s profile: %w", kind, err)
+	}
+
+	return name, nil
+}
+
+func captureCPU(ctx context.Context, file *os.File, duration time.Duration) error {
+	if err := pprof.StartCPUProfile(file); err != nil {
+		return fmt.Errorf("%w: %w", ErrCPUProfiling, err)
+	}
+	defer pprof.StopCPUProfile()
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package profiling
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapture(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "profiles")
+
+	t.Run("heap profile", func(t *testing.T) {
+		name, err := Capture(context.Background(), dir, "heap", 0)
+		require.NoError(t, err)
+
+		assert.Equal(t, dir, filepath.Dir(name))
+		assert.True(t, strings.HasPrefix(filepath.Base(name), "heap-"))
+		assert.Equal(t, ".pprof", filepath.Ext(name))
+
+		info, err := os.Stat(name)
+		require.NoError(t, err)
+		assert.Positive(t, info.Size())
+	})
+
+	t.Run("cpu profile until ctx done", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		name, err := Capture(ctx, dir, KindCPU, time.Minute)
+		require.NoError(t, err)
+		assert.FileExists(t, name)
+	})
+
+	t.Run("cpu profile is already running", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _ = Capture(ctx, dir, KindCPU, time.Minute)
+		}()
+		time.Sleep(20 * time.Millisecond)
+
+		_, err := Capture(context.Background(), dir, KindCPU, time.Millisecond)
+
+		cancel()
+		<-done
+		assert.ErrorIs(t, err, ErrCPUProfiling)
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		_, err := Capture(context.Background(), dir, "disk", 0)
+
+		assert.ErrorIs(t, err, ErrUnknownProfile)
+	})
+}
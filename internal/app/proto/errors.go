@@ -0,0 +1,111 @@
+package proto
+
+import (
+	context "context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	status "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/logger"
+	"github.com/MihailSergeenkov/shortener/internal/app/ratelimit"
+	"github.com/MihailSergeenkov/shortener/internal/app/services"
+)
+
+const (
+	errorDomain           = "shortener"
+	reasonRateLimit       = "RATE_LIMIT_EXCEEDED"
+	shortURLMetadataField = "short_url"
+)
+
+// grpcCodes соответствие видов ошибок сервиса кодам gRPC.
+var grpcCodes = []struct {
+	kind error
+	code codes.Code
+}{
+	{kind: services.ErrInvalid, code: codes.InvalidArgument},
+	{kind: services.ErrPermission, code: codes.PermissionDenied},
+	{kind: services.ErrNotFound, code: codes.NotFound},
+	{kind: services.ErrGone, code: codes.NotFound},
+	{kind: services.ErrConflict, code: codes.AlreadyExists},
+	{kind: services.ErrPrecondition, code: codes.FailedPrecondition},
+	{kind: services.ErrExhausted, code: codes.ResourceExhausted},
+	{kind: services.ErrUnavailable, code: codes.Unavailable},
+}
+
+// grpcCode возвращает код gRPC для ошибки сервиса, неизвестные ошибки считаются внутренними.
+func grpcCode(err error) codes.Code {
+	for _, c := range grpcCodes {
+		if errors.Is(err, c.kind) {
+			return c.code
+		}
+	}
+
+	return codes.Internal
+}
+
+// statusError преобразует ошибку сервиса в gRPC статус с подробностями errdetails.
+// Внутренние ошибки логируются, клиенту возвращается только сообщение msg.
+func (s *ProtoServer) statusError(ctx context.Context, err error, msg string) error {
+	code := grpcCode(err)
+	if code == codes.Internal {
+		logger.FromContext(ctx, s.logger).Error(msg, zap.Error(err))
+		return status.Error(codes.Internal, msg) //nolint:wrapcheck // FalsePositive
+	}
+
+	var svcErr *services.Error
+	if !errors.As(err, &svcErr) {
+		return status.Error(code, err.Error()) //nolint:wrapcheck // FalsePositive
+	}
+
+	info := &errdetails.ErrorInfo{Reason: svcErr.Reason, Domain: errorDomain}
+	details := []protoadapt.MessageV1{info}
+
+	if svcErr.Resource != "" {
+		if svcErr.ResourceType == services.ResourceURL {
+			info.Metadata = map[string]string{shortURLMetadataField: svcErr.Resource}
+		}
+
+		details = append(details, &errdetails.ResourceInfo{
+			ResourceType: svcErr.ResourceType,
+			ResourceName: svcErr.Resource,
+			Description:  svcErr.Error(),
+		})
+	}
+
+	if svcErr.RetryAfter > 0 {
+		setRetryAfter(ctx, svcErr.RetryAfter)
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(svcErr.RetryAfter)})
+	}
+
+	return withDetails(status.New(code, svcErr.Error()), details...)
+}
+
+func resourceExhausted(ctx context.Context, retryAfter time.Duration, msg string) error {
+	setRetryAfter(ctx, retryAfter)
+
+	return withDetails(status.New(codes.ResourceExhausted, msg),
+		&errdetails.ErrorInfo{Reason: reasonRateLimit, Domain: errorDomain},
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)},
+	)
+}
+
+func setRetryAfter(ctx context.Context, retryAfter time.Duration) {
+	// Заголовок нельзя отправить вне gRPC вызова, но ошибку вернуть нужно в любом случае.
+	_ = grpc.SetHeader(ctx, metadata.Pairs(retryAfterMetadataKey, ratelimit.RetryAfter(retryAfter)))
+}
+
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) error {
+	if detailed, err := st.WithDetails(details...); err == nil {
+		st = detailed
+	}
+
+	return st.Err() //nolint:wrapcheck // FalsePositive
+}
@@ -0,0 +1,63 @@
+package proto
+
+import (
+	context "context"
+	"fmt"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+)
+
+const retryAfterMetadataKey = "retry-after"
+
+// NewGateway создает REST шлюз по HTTP аннотациям shortener.proto, который вызывает ProtoServer в том же процессе.
+// Интерсепторы gRPC не применяются: пользователь, организация и IP клиента берутся из контекста HTTP запроса,
+// их заполняют middleware роутера. Поля JSON совпадают с именами полей в proto.
+func NewGateway(logger *zap.Logger, storage data.Storager) (http.Handler, error) {
+	mux := runtime.NewServeMux(
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions:   protojson.MarshalOptions{UseProtoNames: true},
+			UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+		}),
+		runtime.WithOutgoingHeaderMatcher(gatewayHeaderMatcher),
+		runtime.WithForwardResponseOption(gatewayResponseStatus),
+	)
+
+	err := RegisterShortenerHandlerServer(context.Background(), mux, &ProtoServer{
+		logger:  logger,
+		storage: storage,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to register gateway handlers: %w", err)
+	}
+
+	return mux, nil
+}
+
+// gatewayResponseStatus выставляет коды успешных ответов как у маршрутов /api:
+// 201 при создании ссылок и организаций, 202 при удалении ссылок.
+func gatewayResponseStatus(_ context.Context, w http.ResponseWriter, resp protoreflect.ProtoMessage) error {
+	switch resp.(type) {
+	case *AddShortURLResponse, *AddShortURLsResponse, *CreateOrgResponse:
+		w.WriteHeader(http.StatusCreated)
+	case *DeleteUserURLsResponse:
+		w.WriteHeader(http.StatusAccepted)
+	}
+
+	return nil
+}
+
+// gatewayHeaderMatcher передает retry-after как стандартный заголовок Retry-After,
+// остальные метаданные ответа - с префиксом Grpc-Metadata-.
+func gatewayHeaderMatcher(key string) (string, bool) {
+	if key == retryAfterMetadataKey {
+		return "Retry-After", true
+	}
+
+	return runtime.MetadataHeaderPrefix + key, true
+}
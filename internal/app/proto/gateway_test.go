@@ -0,0 +1,124 @@
+package proto
+
+import (
+	context "context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/config"
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+)
+
+func gatewayRequest(t *testing.T, gateway http.Handler, method string, path string, body string) *http.Response {
+	t.Helper()
+
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set("Content-Type", common.JSONContentType)
+	request = request.WithContext(context.WithValue(request.Context(), common.KeyUserID, "some_id"))
+
+	w := httptest.NewRecorder()
+	gateway.ServeHTTP(w, request)
+
+	return w.Result()
+}
+
+func decodeBody(t *testing.T, res *http.Response) map[string]any {
+	t.Helper()
+	defer func() { _ = res.Body.Close() }()
+
+	var body map[string]any
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+
+	return body
+}
+
+func TestNewGateway(t *testing.T) {
+	params := config.Params
+	defer func() { config.Params = params }()
+	config.Params.DailyURLsQuota = 0
+
+	gateway, err := NewGateway(zap.NewNop(), data.NewBaseStorage())
+	require.NoError(t, err)
+
+	var shortURL string
+
+	t.Run("add short url", func(t *testing.T) {
+		res := gatewayRequest(t, gateway, http.MethodPost, "/v2/api/shorten", `{"original_url":"https://practicum.yandex.ru/"}`)
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+		body := decodeBody(t, res)
+		shortURL, _ = body["short_url"].(string)
+		assert.NotEmpty(t, shortURL)
+	})
+
+	t.Run("get url", func(t *testing.T) {
+		key := path.Base(shortURL)
+		res := gatewayRequest(t, gateway, http.MethodGet, "/v2/api/urls/"+key, "")
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "https://practicum.yandex.ru/", decodeBody(t, res)["original_url"])
+	})
+
+	t.Run("url not found", func(t *testing.T) {
+		res := gatewayRequest(t, gateway, http.MethodGet, "/v2/api/urls/unknown", "")
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		assert.Contains(t, decodeBody(t, res), "details")
+	})
+
+	t.Run("fetch user urls", func(t *testing.T) {
+		res := gatewayRequest(t, gateway, http.MethodGet, "/v2/api/user/urls", "")
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Len(t, decodeBody(t, res)["urls"], 1)
+	})
+
+	t.Run("add short urls", func(t *testing.T) {
+		res := gatewayRequest(t, gateway, http.MethodPost, "/v2/api/shorten/batch",
+			`{"urls":[{"correlation_id":"1","original_url":"https://practicum.yandex.ru/batch"}]}`)
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+		assert.Len(t, decodeBody(t, res)["urls"], 1)
+	})
+
+	t.Run("delete user urls", func(t *testing.T) {
+		res := gatewayRequest(t, gateway, http.MethodDelete, "/v2/api/user/urls", `{"urls":["unknown"]}`)
+		assert.Equal(t, http.StatusAccepted, res.StatusCode)
+		_ = res.Body.Close()
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		res := gatewayRequest(t, gateway, http.MethodPost, "/v2/api/shorten", `{`)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		_ = res.Body.Close()
+	})
+
+	t.Run("streaming methods are not exposed", func(t *testing.T) {
+		res := gatewayRequest(t, gateway, http.MethodPost, "/v2/api/import", "")
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		_ = res.Body.Close()
+	})
+}
+
+func TestNewGateway_QuotaExceeded(t *testing.T) {
+	params := config.Params
+	defer func() { config.Params = params }()
+	config.Params.DailyURLsQuota = 1
+
+	gateway, err := NewGateway(zap.NewNop(), data.NewBaseStorage())
+	require.NoError(t, err)
+
+	res := gatewayRequest(t, gateway, http.MethodPost, "/v2/api/shorten", `{"original_url":"https://example.com/1"}`)
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+	_ = res.Body.Close()
+
+	res = gatewayRequest(t, gateway, http.MethodPost, "/v2/api/shorten", `{"original_url":"https://example.com/2"}`)
+	assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	assert.NotEmpty(t, res.Header.Get("Retry-After"))
+	_ = res.Body.Close()
+}
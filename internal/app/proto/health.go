@@ -0,0 +1,78 @@
+package proto
+
+import (
+	context "context"
+	"time"
+
+	"go.uber.org/zap"
+	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+)
+
+// HealthChecker структура стандартного сервиса grpc.health.v1, статус которого зависит от доступности БД.
+type HealthChecker struct {
+	server  *health.Server
+	logger  *zap.Logger
+	storage data.Storager
+}
+
+// NewHealthChecker создает сервис проверки здоровья, до первой проверки БД статус NOT_SERVING.
+func NewHealthChecker(logger *zap.Logger, storage data.Storager) *HealthChecker {
+	h := &HealthChecker{
+		server:  health.NewServer(),
+		logger:  logger,
+		storage: storage,
+	}
+	h.setStatus(healthgrpc.HealthCheckResponse_NOT_SERVING)
+
+	return h
+}
+
+// Register регистрирует сервис grpc.health.v1 на gRPC сервере.
+func (h *HealthChecker) Register(s *grpc.Server) {
+	healthgrpc.RegisterHealthServer(s, h.server)
+}
+
+// Check проверяет БД и обновляет статус сервера и сервиса Shortener, подписчики Watch получают изменения.
+func (h *HealthChecker) Check(ctx context.Context) {
+	if err := h.storage.Ping(ctx); err != nil {
+		h.logger.Error("health check failed", zap.Error(err))
+		h.setStatus(healthgrpc.HealthCheckResponse_NOT_SERVING)
+		return
+	}
+
+	h.setStatus(healthgrpc.HealthCheckResponse_SERVING)
+}
+
+// Run периодически проверяет БД до отмены контекста, после чего переводит все сервисы в NOT_SERVING.
+// Нулевой интервал отключает периодические проверки, БД проверяется только при запуске.
+func (h *HealthChecker) Run(ctx context.Context, interval time.Duration) {
+	defer h.server.Shutdown()
+
+	h.Check(ctx)
+
+	if interval <= 0 {
+		<-ctx.Done()
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.Check(ctx)
+		}
+	}
+}
+
+func (h *HealthChecker) setStatus(status healthgrpc.HealthCheckResponse_ServingStatus) {
+	h.server.SetServingStatus("", status)
+	h.server.SetServingStatus(Shortener_ServiceDesc.ServiceName, status)
+}
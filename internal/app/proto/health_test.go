@@ -0,0 +1,127 @@
+package proto
+
+import (
+	context "context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/config"
+	"github.com/MihailSergeenkov/shortener/internal/app/data/mock"
+)
+
+func TestHealthChecker(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	logger := zap.NewNop()
+	storage := mock.NewMockStorager(mockCtrl)
+
+	listen := bufconn.Listen(1024 * 1024)
+	s := NewGRPCServer(logger, storage)
+	checker := NewHealthChecker(logger, storage)
+	checker.Register(s)
+	go func() { _ = s.Serve(listen) }()
+	defer s.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listen.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	client := healthgrpc.NewHealthClient(conn)
+	ctx := context.Background()
+
+	check := func(t *testing.T, service string) healthgrpc.HealthCheckResponse_ServingStatus {
+		t.Helper()
+
+		resp, err := client.Check(ctx, &healthgrpc.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+
+		return resp.GetStatus()
+	}
+
+	t.Run("not serving before first check", func(t *testing.T) {
+		assert.Equal(t, healthgrpc.HealthCheckResponse_NOT_SERVING, check(t, ""))
+	})
+
+	t.Run("serving when DB is available", func(t *testing.T) {
+		storage.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+		checker.Check(ctx)
+
+		assert.Equal(t, healthgrpc.HealthCheckResponse_SERVING, check(t, ""))
+		assert.Equal(t, healthgrpc.HealthCheckResponse_SERVING, check(t, "shortener.Shortener"))
+	})
+
+	t.Run("watch notified on DB failure", func(t *testing.T) {
+		watchCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		stream, err := client.Watch(watchCtx, &healthgrpc.HealthCheckRequest{Service: "shortener.Shortener"})
+		require.NoError(t, err)
+
+		resp, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, healthgrpc.HealthCheckResponse_SERVING, resp.GetStatus())
+
+		storage.EXPECT().Ping(gomock.Any()).Times(1).Return(errors.New("some error"))
+		checker.Check(ctx)
+
+		resp, err = stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, healthgrpc.HealthCheckResponse_NOT_SERVING, resp.GetStatus())
+	})
+
+	t.Run("run checks periodically and shuts down", func(t *testing.T) {
+		storage.EXPECT().Ping(gomock.Any()).MinTimes(2).Return(nil)
+
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			checker.Run(runCtx, 10*time.Millisecond)
+		}()
+
+		assert.Eventually(t, func() bool {
+			return check(t, "") == healthgrpc.HealthCheckResponse_SERVING
+		}, time.Second, 10*time.Millisecond)
+		time.Sleep(30 * time.Millisecond)
+
+		cancel()
+		<-done
+		assert.Equal(t, healthgrpc.HealthCheckResponse_NOT_SERVING, check(t, ""))
+	})
+}
+
+func TestNewGRPCServer_Reflection(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	params := config.Params
+	defer func() { config.Params = params }()
+
+	storage := mock.NewMockStorager(mockCtrl)
+	reflectionService := "grpc.reflection.v1.ServerReflection"
+
+	config.Params.GRPCReflection = true
+	_, ok := NewGRPCServer(zap.NewNop(), storage).GetServiceInfo()[reflectionService]
+	assert.True(t, ok)
+
+	config.Params.GRPCReflection = false
+	_, ok = NewGRPCServer(zap.NewNop(), storage).GetServiceInfo()[reflectionService]
+	assert.False(t, ok)
+}
@@ -0,0 +1,98 @@
+package proto
+
+import (
+	context "context"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+	"github.com/MihailSergeenkov/shortener/internal/app/services"
+)
+
+// CreateOrg реализует интерфейс создания организации.
+func (s *ProtoServer) CreateOrg(ctx context.Context, in *CreateOrgRequest) (*CreateOrgResponse, error) {
+	org, err := services.CreateOrg(ctx, s.storage, in.GetName())
+	if err != nil {
+		return nil, s.statusError(ctx, err, "failed to add org to storage")
+	}
+
+	var response CreateOrgResponse
+	response.Org = &Org{
+		Id:   org.ID,
+		Name: org.Name,
+		Role: org.Role,
+	}
+
+	return &response, nil
+}
+
+// FetchUserOrgs реализует интерфейс получения организаций пользователя.
+func (s *ProtoServer) FetchUserOrgs(ctx context.Context, _ *FetchUserOrgsRequest) (*FetchUserOrgsResponse, error) {
+	orgs, err := services.FetchUserOrgs(ctx, s.storage)
+	if err != nil {
+		return nil, s.statusError(ctx, err, "failed to fetch orgs from storage")
+	}
+
+	var response FetchUserOrgsResponse
+	response.Orgs = make([]*Org, 0, len(orgs))
+
+	for _, o := range orgs {
+		response.Orgs = append(response.Orgs, &Org{
+			Id:   o.ID,
+			Name: o.Name,
+			Role: o.Role,
+		})
+	}
+
+	return &response, nil
+}
+
+// FetchOrgMembers реализует интерфейс получения участников организации.
+func (s *ProtoServer) FetchOrgMembers(ctx context.Context, in *FetchOrgMembersRequest) (*FetchOrgMembersResponse, error) {
+	members, err := services.FetchOrgMembers(ctx, s.storage, in.GetOrgId())
+	if err != nil {
+		return nil, s.statusError(ctx, err, "failed to fetch org members from storage")
+	}
+
+	var response FetchOrgMembersResponse
+	response.Members = make([]*Member, 0, len(members))
+
+	for _, m := range members {
+		response.Members = append(response.Members, &Member{
+			OrgId:  m.OrgID,
+			UserId: m.UserID,
+			Role:   m.Role,
+		})
+	}
+
+	return &response, nil
+}
+
+// SetOrgMember реализует интерфейс добавления участника организации или изменения его роли.
+func (s *ProtoServer) SetOrgMember(ctx context.Context, in *SetOrgMemberRequest) (*SetOrgMemberResponse, error) {
+	member := models.Member{
+		OrgID:  in.GetOrgId(),
+		UserID: in.GetUserId(),
+		Role:   in.GetRole(),
+	}
+
+	if err := services.SetOrgMember(ctx, s.storage, member); err != nil {
+		return nil, s.statusError(ctx, err, "failed to store org member")
+	}
+
+	var response SetOrgMemberResponse
+	response.Member = &Member{
+		OrgId:  member.OrgID,
+		UserId: member.UserID,
+		Role:   member.Role,
+	}
+
+	return &response, nil
+}
+
+// DeleteOrgMember реализует интерфейс исключения участника из организации.
+func (s *ProtoServer) DeleteOrgMember(ctx context.Context, in *DeleteOrgMemberRequest) (*DeleteOrgMemberResponse, error) {
+	if err := services.RemoveOrgMember(ctx, s.storage, in.GetOrgId(), in.GetUserId()); err != nil {
+		return nil, s.statusError(ctx, err, "failed to delete org member")
+	}
+
+	return &DeleteOrgMemberResponse{}, nil
+}
@@ -0,0 +1,122 @@
+package proto
+
+import (
+	context "context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/data/mock"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+)
+
+func TestOrgs(t *testing.T) {
+	server := ProtoServer{
+		logger:  zap.NewNop(),
+		storage: data.NewBaseStorage(),
+	}
+	ownerCtx := context.WithValue(context.Background(), common.KeyUserID, "owner_id")
+	viewerCtx := context.WithValue(context.Background(), common.KeyUserID, "viewer_id")
+
+	created, err := server.CreateOrg(ownerCtx, &CreateOrgRequest{Name: "Team"})
+	require.NoError(t, err)
+	orgID := created.GetOrg().GetId()
+
+	t.Run("set member", func(t *testing.T) {
+		resp, err := server.SetOrgMember(ownerCtx, &SetOrgMemberRequest{
+			OrgId:  orgID,
+			UserId: "viewer_id",
+			Role:   models.RoleViewer,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, models.RoleViewer, resp.GetMember().GetRole())
+	})
+
+	t.Run("fetch user orgs", func(t *testing.T) {
+		resp, err := server.FetchUserOrgs(viewerCtx, &FetchUserOrgsRequest{})
+		require.NoError(t, err)
+		require.Len(t, resp.GetOrgs(), 1)
+		assert.Equal(t, "Team", resp.GetOrgs()[0].GetName())
+		assert.Equal(t, models.RoleViewer, resp.GetOrgs()[0].GetRole())
+	})
+
+	t.Run("fetch members", func(t *testing.T) {
+		resp, err := server.FetchOrgMembers(viewerCtx, &FetchOrgMembersRequest{OrgId: orgID})
+		require.NoError(t, err)
+		assert.Len(t, resp.GetMembers(), 2)
+	})
+
+	t.Run("viewer can not create org links", func(t *testing.T) {
+		orgCtx := context.WithValue(viewerCtx, common.KeyOrgID, orgID)
+
+		_, err := server.AddShortURL(orgCtx, &AddShortURLRequest{OriginalUrl: "https://ya.ru"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		req  *SetOrgMemberRequest
+		code codes.Code
+	}{
+		{
+			name: "invalid role",
+			ctx:  ownerCtx,
+			req:  &SetOrgMemberRequest{OrgId: orgID, UserId: "viewer_id", Role: "admin"},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "not owner",
+			ctx:  viewerCtx,
+			req:  &SetOrgMemberRequest{OrgId: orgID, UserId: "viewer_id", Role: models.RoleOwner},
+			code: codes.PermissionDenied,
+		},
+		{
+			name: "demote last owner",
+			ctx:  ownerCtx,
+			req:  &SetOrgMemberRequest{OrgId: orgID, UserId: "owner_id", Role: models.RoleEditor},
+			code: codes.FailedPrecondition,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := server.SetOrgMember(test.ctx, test.req)
+			assert.Equal(t, test.code, status.Code(err))
+		})
+	}
+
+	t.Run("delete member", func(t *testing.T) {
+		_, err := server.DeleteOrgMember(viewerCtx, &DeleteOrgMemberRequest{OrgId: orgID, UserId: "viewer_id"})
+		require.NoError(t, err)
+
+		_, err = server.DeleteOrgMember(ownerCtx, &DeleteOrgMemberRequest{OrgId: orgID, UserId: "viewer_id"})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestCreateOrg_Failed(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	storage := mock.NewMockStorager(mockCtrl)
+	server := ProtoServer{
+		logger:  zap.NewNop(),
+		storage: storage,
+	}
+	ctx := context.WithValue(context.Background(), common.KeyUserID, "some_id")
+
+	storage.EXPECT().StoreOrg(ctx, gomock.Any()).Times(1).Return(errors.New("some error"))
+
+	_, err := server.CreateOrg(ctx, &CreateOrgRequest{Name: "Team"})
+	require.Error(t, err)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.ErrorContains(t, err, "failed to add org to storage")
+}
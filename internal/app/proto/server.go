@@ -2,17 +2,23 @@ package proto
 
 import (
 	context "context"
-	"errors"
 	"fmt"
-	"path"
 	"strings"
 
+	"github.com/MihailSergeenkov/shortener/internal/app/audit"
+	"github.com/MihailSergeenkov/shortener/internal/app/clientip"
 	"github.com/MihailSergeenkov/shortener/internal/app/common"
 	"github.com/MihailSergeenkov/shortener/internal/app/config"
 	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/logger"
+	"github.com/MihailSergeenkov/shortener/internal/app/metrics"
 	"github.com/MihailSergeenkov/shortener/internal/app/models"
+	"github.com/MihailSergeenkov/shortener/internal/app/ratelimit"
+	"github.com/MihailSergeenkov/shortener/internal/app/requestid"
 	"github.com/MihailSergeenkov/shortener/internal/app/services"
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.uber.org/zap"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
@@ -72,74 +78,282 @@ func authInterceptor(
 	req interface{},
 	info *grpc.UnaryServerInfo,
 	handler grpc.UnaryHandler) (interface{}, error) {
+	newContext, err := authContext(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(newContext, req)
+}
+
+func authStreamInterceptor(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	newContext, err := authContext(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, wrapStream(newContext, ss))
+}
+
+// authContext добавляет в контекст пользователя и организацию из метаданных, если метод требует авторизации.
+func authContext(ctx context.Context, fullMethod string) (context.Context, error) {
 	methods := map[string]bool{
-		"AddShortURL":    true,
-		"AddShortURLs":   true,
-		"FetchUserURLs":  true,
-		"DeleteUserURLs": true,
+		"AddShortURL":      true,
+		"AddShortURLs":     true,
+		"FetchUserURLs":    true,
+		"DeleteUserURLs":   true,
+		"CreateOrg":        true,
+		"FetchUserOrgs":    true,
+		"FetchOrgMembers":  true,
+		"SetOrgMember":     true,
+		"DeleteOrgMember":  true,
+		"ImportURLs":       true,
+		"StreamUserURLs":   true,
+		"StreamUserEvents": true,
 	}
 
-	method := strings.TrimPrefix(info.FullMethod, "/shortener.Shortener/")
+	method := strings.TrimPrefix(fullMethod, "/shortener.Shortener/")
 	if _, ok := methods[method]; !ok {
-		return handler(ctx, req)
+		return ctx, nil
 	}
 
-	var userID string
+	var userID, orgID string
 	if md, ok := metadata.FromIncomingContext(ctx); ok {
 		values := md.Get("user_id")
 		if len(values) > 0 {
 			userID = values[0]
 		}
+
+		if values := md.Get("org_id"); len(values) > 0 {
+			orgID = values[0]
+		}
 	}
 
 	if len(userID) == 0 {
 		return nil, status.Error(codes.Unauthenticated, "missing user id") //nolint:wrapcheck // FalsePositive
 	}
 
+	// ID пользователя из метаданных ничем не подтвержден, ограничения частоты и квоты считаются по IP адресу.
 	newContext := context.WithValue(ctx, common.KeyUserID, userID)
+	newContext = context.WithValue(newContext, common.KeyUnverified, true)
+	if orgID != "" {
+		newContext = context.WithValue(newContext, common.KeyOrgID, orgID)
+	}
 
-	return handler(newContext, req)
+	return newContext, nil
+}
+
+func clientIPInterceptor(resolver *clientip.Resolver) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		_ *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		newContext := clientip.NewContext(ctx, resolver.FromPeer(ctx))
+
+		return handler(newContext, req)
+	}
+}
+
+func clientIPStreamInterceptor(resolver *clientip.Resolver) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		_ *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		newContext := clientip.NewContext(ss.Context(), resolver.FromPeer(ss.Context()))
+
+		return handler(srv, wrapStream(newContext, ss))
+	}
+}
+
+// auditTransportInterceptor отмечает запрос транспортом gRPC для журнала аудита.
+func auditTransportInterceptor(
+	ctx context.Context,
+	req interface{},
+	_ *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	return handler(audit.NewTransportContext(ctx, audit.TransportGRPC), req)
+}
+
+func auditTransportStreamInterceptor(
+	srv interface{},
+	ss grpc.ServerStream,
+	_ *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	return handler(srv, wrapStream(audit.NewTransportContext(ss.Context(), audit.TransportGRPC), ss))
+}
+
+// requestIDContext берет идентификатор запроса из метаданных x-request-id или генерирует новый
+// и добавляет его в контекст вместе с логгером запроса.
+func requestIDContext(ctx context.Context, l *zap.Logger) (context.Context, string) {
+	var incoming string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestid.MetadataKey); len(values) > 0 {
+			incoming = values[0]
+		}
+	}
+
+	id := requestid.Resolve(incoming)
+
+	return logger.NewContext(requestid.NewContext(ctx, id), l), id
+}
+
+// requestIDInterceptor возвращает идентификатор запроса клиенту в заголовке ответа x-request-id.
+func requestIDInterceptor(l *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		_ *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		newContext, id := requestIDContext(ctx, l)
+		if err := grpc.SetHeader(newContext, metadata.Pairs(requestid.MetadataKey, id)); err != nil {
+			logger.FromContext(newContext, l).Warn("failed to send request id", zap.Error(err))
+		}
+
+		return handler(newContext, req)
+	}
+}
+
+func requestIDStreamInterceptor(l *zap.Logger) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		_ *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		newContext, id := requestIDContext(ss.Context(), l)
+		if err := ss.SetHeader(metadata.Pairs(requestid.MetadataKey, id)); err != nil {
+			logger.FromContext(newContext, l).Warn("failed to send request id", zap.Error(err))
+		}
+
+		return handler(srv, wrapStream(newContext, ss))
+	}
+}
+
+func rateLimitInterceptor(create *ratelimit.Limiter, redirect *ratelimit.Limiter) grpc.UnaryServerInterceptor {
+	limiters := rateLimiters(create, redirect)
+
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		if err := checkRateLimit(ctx, limiters, info.FullMethod); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// rateLimitStreamInterceptor ограничивает частоту открытия потоков, одно открытие считается одним запросом.
+func rateLimitStreamInterceptor(create *ratelimit.Limiter, redirect *ratelimit.Limiter) grpc.StreamServerInterceptor {
+	limiters := rateLimiters(create, redirect)
+
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		if err := checkRateLimit(ss.Context(), limiters, info.FullMethod); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+func rateLimiters(create *ratelimit.Limiter, redirect *ratelimit.Limiter) map[string]*ratelimit.Limiter {
+	return map[string]*ratelimit.Limiter{
+		"AddShortURL":  create,
+		"AddShortURLs": create,
+		"ImportURLs":   create,
+		"GetURL":       redirect,
+	}
+}
+
+func checkRateLimit(ctx context.Context, limiters map[string]*ratelimit.Limiter, fullMethod string) error {
+	method := strings.TrimPrefix(fullMethod, "/shortener.Shortener/")
+	limiter, ok := limiters[method]
+	if !ok {
+		return nil
+	}
+
+	if allow, retryAfter := limiter.Allow(ratelimit.Key(ctx)); !allow {
+		return resourceExhausted(ctx, retryAfter, "rate limit exceeded")
+	}
+
+	return nil
+}
+
+// wrapStream подменяет контекст потока.
+func wrapStream(ctx context.Context, ss grpc.ServerStream) grpc.ServerStream {
+	wrapped := middleware.WrapServerStream(ss)
+	wrapped.WrappedContext = ctx
+
+	return wrapped
 }
 
-// NewGRPCServer функция инициализации gRPC сервера.
-func NewGRPCServer(logger *zap.Logger, storage data.Storager) *grpc.Server {
-	s := grpc.NewServer(
+func requestFields(ctx context.Context) logging.Fields {
+	return logging.Fields{"client_ip", clientip.String(ctx), "request_id", requestid.FromContext(ctx)}
+}
+
+// NewGRPCServer функция инициализации gRPC сервера с дополнительными опциями opts, например TLS.
+func NewGRPCServer(logger *zap.Logger, storage data.Storager, opts ...grpc.ServerOption) *grpc.Server {
+	resolver := clientip.NewResolver(config.Params.TrustedProxies)
+	createLimiter := ratelimit.NewLimiter(config.Params.CreateRPS, config.Params.CreateBurst)
+	redirectLimiter := ratelimit.NewLimiter(config.Params.RedirectRPS, config.Params.RedirectBurst)
+	loggingOpt := logging.WithFieldsFromContext(requestFields)
+
+	opts = append(opts,
+		// Спаны вызовов создает otelgrpc, контекст трассировки берется из метаданных.
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
 		grpc.ChainUnaryInterceptor(
-			logging.UnaryServerInterceptor(loggerInterceptor(logger)),
+			clientIPInterceptor(resolver),
+			auditTransportInterceptor,
+			requestIDInterceptor(logger),
+			metrics.UnaryServerInterceptor,
+			logging.UnaryServerInterceptor(loggerInterceptor(logger), loggingOpt),
 			authInterceptor,
+			rateLimitInterceptor(createLimiter, redirectLimiter),
+		),
+		// Потоковые методы проходят ту же цепочку интерсепторов, что и унарные.
+		grpc.ChainStreamInterceptor(
+			clientIPStreamInterceptor(resolver),
+			auditTransportStreamInterceptor,
+			requestIDStreamInterceptor(logger),
+			metrics.StreamServerInterceptor,
+			logging.StreamServerInterceptor(loggerInterceptor(logger), loggingOpt),
+			authStreamInterceptor,
+			rateLimitStreamInterceptor(createLimiter, redirectLimiter),
 		),
 	)
+	s := grpc.NewServer(opts...)
 	RegisterShortenerServer(s, &ProtoServer{
 		logger:  logger,
 		storage: storage,
 	})
-	reflection.Register(s)
+	if config.Params.GRPCReflection {
+		reflection.Register(s)
+	}
 
 	return s
 }
 
 // AddShortURL реализует интерфейс сохранения короткой ссылки.
+// Если оригинальная ссылка уже сохранена, возвращается AlreadyExists с существующей короткой ссылкой в подробностях.
 func (s *ProtoServer) AddShortURL(ctx context.Context, in *AddShortURLRequest) (*AddShortURLResponse, error) {
-	var response AddShortURLResponse
-
-	baseURL := config.Params.BaseURL
 	shortURL, err := services.AddShortURL(ctx, s.storage, in.GetOriginalUrl())
 	if err != nil {
-		var origErr *data.OriginalURLAlreadyExistError
-		if errors.As(err, &origErr) {
-			newPath := path.Join(baseURL.Path, origErr.ShortURL)
-			baseURL.Path = newPath
-			response.ShortUrl = baseURL.String()
-
-			return &response, nil
-		}
-		s.logger.Error("failed to add URL to storage", zap.Error(err))
-		return nil, status.Error(codes.Aborted, "failed to add URL to storage") //nolint:wrapcheck // FalsePositive
+		return nil, s.statusError(ctx, err, "failed to add URL to storage")
 	}
 
-	newPath := path.Join(baseURL.Path, shortURL)
-	baseURL.Path = newPath
-	response.ShortUrl = baseURL.String()
+	var response AddShortURLResponse
+	response.ShortUrl = services.ShortLink(shortURL)
 
 	return &response, nil
 }
@@ -161,8 +375,7 @@ func (s *ProtoServer) AddShortURLs(ctx context.Context, in *AddShortURLsRequest)
 
 	resp, err := services.AddBatchShortURL(ctx, s.storage, req)
 	if err != nil {
-		s.logger.Error("failed to add URLs to storage", zap.Error(err))
-		return nil, status.Error(codes.Aborted, "failed to add URLs to storage") //nolint:wrapcheck // FalsePositive
+		return nil, s.statusError(ctx, err, "failed to add URLs to storage")
 	}
 
 	for _, r := range resp {
@@ -180,22 +393,13 @@ func (s *ProtoServer) AddShortURLs(ctx context.Context, in *AddShortURLsRequest)
 
 // GetURL реализует интерфейс получения оригинальной ссылки по короткой.
 func (s *ProtoServer) GetURL(ctx context.Context, in *GetURLRequest) (*GetURLResponse, error) {
-	u, err := s.storage.GetURL(ctx, in.GetShortUrl())
+	originalURL, err := services.GetURL(ctx, s.storage, in.GetShortUrl())
 	if err != nil {
-		if errors.Is(err, data.ErrURLNotFound) {
-			return nil, status.Error(codes.NotFound, "URL not found") //nolint:wrapcheck // FalsePositive
-		}
-
-		s.logger.Error("failed to fetch URL from storage", zap.Error(err))
-		return nil, status.Error(codes.Aborted, "failed to fetch URL from storage") //nolint:wrapcheck // FalsePositive
-	}
-
-	if u.DeletedFlag {
-		return nil, status.Error(codes.Unavailable, "URL deleted") //nolint:wrapcheck // FalsePositive
+		return nil, s.statusError(ctx, err, "failed to fetch URL from storage")
 	}
 
 	var response GetURLResponse
-	response.OriginalUrl = u.OriginalURL
+	response.OriginalUrl = originalURL
 
 	return &response, nil
 }
@@ -204,12 +408,7 @@ func (s *ProtoServer) GetURL(ctx context.Context, in *GetURLRequest) (*GetURLRes
 func (s *ProtoServer) FetchUserURLs(ctx context.Context, _ *FetchUserURLsRequest) (*FetchUserURLsResponse, error) {
 	resp, err := services.FetchUserURLs(ctx, s.storage)
 	if err != nil {
-		s.logger.Error("failed to fetch URLs from storage", zap.Error(err))
-		return nil, status.Error(codes.Aborted, "failed to fetch URLs from storage") //nolint:wrapcheck // FalsePositive
-	}
-
-	if len(resp) == 0 {
-		return nil, status.Error(codes.NotFound, "URLs not found") //nolint:wrapcheck // FalsePositive
+		return nil, s.statusError(ctx, err, "failed to fetch URLs from storage")
 	}
 
 	var response FetchUserURLsResponse
@@ -232,8 +431,7 @@ func (s *ProtoServer) FetchUserURLs(ctx context.Context, _ *FetchUserURLsRequest
 func (s *ProtoServer) DeleteUserURLs(ctx context.Context, in *DeleteUserURLsRequest) (*DeleteUserURLsResponse, error) {
 	err := services.DeleteUserURLs(ctx, s.logger, s.storage, in.GetUrls())
 	if err != nil {
-		s.logger.Error("failed to delete URLs from storage", zap.Error(err))
-		return nil, status.Error(codes.Aborted, "failed to delete URLs from storage") //nolint:wrapcheck // FalsePositive
+		return nil, s.statusError(ctx, err, "failed to delete URLs from storage")
 	}
 
 	var response DeleteUserURLsResponse
@@ -246,8 +444,7 @@ func (s *ProtoServer) DeleteUserURLs(ctx context.Context, in *DeleteUserURLsRequ
 func (s *ProtoServer) FetchStats(ctx context.Context, _ *FetchStatsRequest) (*FetchStatsResponse, error) {
 	resp, err := services.FetchStats(ctx, s.storage)
 	if err != nil {
-		s.logger.Error("failed to fetch stats from storage", zap.Error(err))
-		return nil, status.Error(codes.Aborted, "failed to fetch stats from storage") //nolint:wrapcheck // FalsePositive
+		return nil, s.statusError(ctx, err, "failed to fetch stats from storage")
 	}
 
 	var response FetchStatsResponse
@@ -261,8 +458,8 @@ func (s *ProtoServer) FetchStats(ctx context.Context, _ *FetchStatsRequest) (*Fe
 func (s *ProtoServer) Ping(ctx context.Context, _ *PingRequest) (*PingResponse, error) {
 	err := s.storage.Ping(ctx)
 	if err != nil {
-		s.logger.Error("failed to connect to DB", zap.Error(err))
-		return nil, status.Error(codes.Aborted, "failed to connect to DB") //nolint:wrapcheck // FalsePositive
+		logger.FromContext(ctx, s.logger).Error("failed to connect to DB", zap.Error(err))
+		return nil, status.Error(codes.Unavailable, "failed to connect to DB") //nolint:wrapcheck // FalsePositive
 	}
 
 	var response PingResponse
@@ -2,19 +2,38 @@ package proto
 
 import (
 	context "context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
+	"net"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/MihailSergeenkov/shortener/internal/app/audit"
+	"github.com/MihailSergeenkov/shortener/internal/app/certs"
+	"github.com/MihailSergeenkov/shortener/internal/app/certs/certstest"
+	"github.com/MihailSergeenkov/shortener/internal/app/clientip"
 	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/config"
 	"github.com/MihailSergeenkov/shortener/internal/app/data"
 	"github.com/MihailSergeenkov/shortener/internal/app/data/mock"
 	"github.com/MihailSergeenkov/shortener/internal/app/models"
+	"github.com/MihailSergeenkov/shortener/internal/app/ratelimit"
+	"github.com/MihailSergeenkov/shortener/internal/app/requestid"
+	"github.com/MihailSergeenkov/shortener/internal/app/services"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	status "google.golang.org/grpc/status"
 )
 
 func TestAuthInterceptor(t *testing.T) {
@@ -64,6 +83,104 @@ func TestAuthInterceptor(t *testing.T) {
 			}
 		})
 	}
+
+	t.Run("with org", func(t *testing.T) {
+		md := metadata.New(map[string]string{"user_id": "12345", "org_id": "org_id"})
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+		info := &grpc.UnaryServerInfo{FullMethod: "/shortener.Shortener/FetchUserURLs"}
+
+		orgHandler := func(ctx context.Context, _ interface{}) (interface{}, error) {
+			return ctx.Value(common.KeyOrgID), nil
+		}
+
+		orgID, err := authInterceptor(ctx, "test", info, orgHandler)
+		require.NoError(t, err)
+		assert.Equal(t, "org_id", orgID)
+	})
+}
+
+func TestClientIPInterceptor(t *testing.T) {
+	_, trustedProxy, err := net.ParseCIDR("127.0.0.0/8")
+	require.NoError(t, err)
+
+	var got string
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		got = clientip.String(ctx)
+		return req, nil
+	}
+
+	t.Run("resolve client ip", func(t *testing.T) {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{
+			Addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 3200},
+		})
+		ctx = metadata.NewIncomingContext(ctx, metadata.New(map[string]string{"x-real-ip": "198.51.100.5"}))
+
+		interceptor := clientIPInterceptor(clientip.NewResolver([]*net.IPNet{trustedProxy}))
+		_, err := interceptor(ctx, "test", &grpc.UnaryServerInfo{}, handler)
+
+		require.NoError(t, err)
+		assert.Equal(t, "198.51.100.5", got)
+	})
+}
+
+func TestAuditTransportInterceptor(t *testing.T) {
+	var got string
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		got = audit.TransportFromContext(ctx)
+		return req, nil
+	}
+
+	_, err := auditTransportInterceptor(context.Background(), "test", &grpc.UnaryServerInfo{}, handler)
+
+	require.NoError(t, err)
+	assert.Equal(t, audit.TransportGRPC, got)
+}
+
+func TestRateLimitInterceptor(t *testing.T) {
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return req, nil
+	}
+	interceptor := rateLimitInterceptor(ratelimit.NewLimiter(1, 1), ratelimit.NewLimiter(0, 0))
+	ctx := context.WithValue(context.Background(), common.KeyUserID, "some_id")
+
+	tests := []struct {
+		name    string
+		method  string
+		wantErr bool
+	}{
+		{
+			name:    "first create allowed",
+			method:  "/shortener.Shortener/AddShortURL",
+			wantErr: false,
+		},
+		{
+			name:    "second create limited",
+			method:  "/shortener.Shortener/AddShortURLs",
+			wantErr: true,
+		},
+		{
+			name:    "redirect without limit",
+			method:  "/shortener.Shortener/GetURL",
+			wantErr: false,
+		},
+		{
+			name:    "method without limit",
+			method:  "/shortener.Shortener/Ping",
+			wantErr: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := interceptor(ctx, "test", &grpc.UnaryServerInfo{FullMethod: test.method}, handler)
+
+			if test.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
 }
 
 func TestNewGRPCServer(t *testing.T) {
@@ -97,23 +214,26 @@ func TestAddShortURL(t *testing.T) {
 
 	tests := []struct {
 		name    string
-		wantErr bool
 		err     error
+		code    codes.Code
+		errText string
 	}{
 		{
-			name:    "success add",
-			wantErr: false,
-			err:     nil,
+			name: "success add",
+			err:  nil,
+			code: codes.OK,
 		},
 		{
 			name:    "when original url already exist",
-			wantErr: false,
 			err:     &data.OriginalURLAlreadyExistError{ShortURL: shortURL},
+			code:    codes.AlreadyExists,
+			errText: "original url already exist",
 		},
 		{
 			name:    "failed add",
-			wantErr: true,
 			err:     errors.New("some error"),
+			code:    codes.Internal,
+			errText: "failed to add URL to storage",
 		},
 	}
 
@@ -123,15 +243,67 @@ func TestAddShortURL(t *testing.T) {
 
 			resp, err := server.AddShortURL(ctx, &AddShortURLRequest{OriginalUrl: originalURL})
 
-			if test.wantErr {
-				require.Error(t, err)
-				require.ErrorContains(t, err, "failed to add URL to storage")
-			} else {
+			assert.Equal(t, test.code, status.Code(err))
+			if test.code == codes.OK {
 				require.NoError(t, err)
 				assert.NotEmpty(t, resp.GetShortUrl())
+			} else {
+				require.ErrorContains(t, err, test.errText)
 			}
 		})
 	}
+
+	t.Run("conflict details contain existing short url", func(t *testing.T) {
+		storage.EXPECT().StoreShortURL(ctx, gomock.Any(), originalURL).Times(1).
+			Return(&data.OriginalURLAlreadyExistError{ShortURL: shortURL})
+
+		_, err := server.AddShortURL(ctx, &AddShortURLRequest{OriginalUrl: originalURL})
+
+		info, resource := errorDetails(t, err)
+		assert.Equal(t, services.ReasonOriginalURLExists, info.GetReason())
+		assert.Equal(t, services.ShortLink(shortURL), info.GetMetadata()["short_url"])
+		assert.Equal(t, services.ShortLink(shortURL), resource.GetResourceName())
+	})
+
+	t.Run("quota exceeded", func(t *testing.T) {
+		params := config.Params
+		defer func() { config.Params = params }()
+		config.Params.DailyURLsQuota = 1
+
+		storage.EXPECT().ConsumeDailyQuota(ctx, "user:some_id", 1, 1).Times(1).Return(data.ErrQuotaExceeded)
+
+		_, err := server.AddShortURL(ctx, &AddShortURLRequest{OriginalUrl: originalURL})
+
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		var retry *errdetails.RetryInfo
+		for _, d := range status.Convert(err).Details() {
+			if r, ok := d.(*errdetails.RetryInfo); ok {
+				retry = r
+			}
+		}
+		require.NotNil(t, retry)
+		assert.Positive(t, retry.GetRetryDelay().AsDuration())
+	})
+}
+
+// errorDetails возвращает подробности ErrorInfo и ResourceInfo из gRPC ошибки.
+func errorDetails(t *testing.T, err error) (*errdetails.ErrorInfo, *errdetails.ResourceInfo) {
+	t.Helper()
+
+	var info *errdetails.ErrorInfo
+	var resource *errdetails.ResourceInfo
+	for _, d := range status.Convert(err).Details() {
+		switch v := d.(type) {
+		case *errdetails.ErrorInfo:
+			info = v
+		case *errdetails.ResourceInfo:
+			resource = v
+		}
+	}
+	require.NotNil(t, info)
+	require.NotNil(t, resource)
+
+	return info, resource
 }
 
 func TestAddShortURLs(t *testing.T) {
@@ -237,7 +409,7 @@ func TestGetURL(t *testing.T) {
 			url:     models.URL{},
 			err:     data.ErrURLNotFound,
 			wantErr: true,
-			errText: "URL not found",
+			errText: "url not found",
 		},
 		{
 			name: "when url deleted",
@@ -248,7 +420,7 @@ func TestGetURL(t *testing.T) {
 			},
 			err:     nil,
 			wantErr: true,
-			errText: "URL deleted",
+			errText: "url deleted",
 		},
 	}
 
@@ -267,6 +439,16 @@ func TestGetURL(t *testing.T) {
 			}
 		})
 	}
+
+	t.Run("deleted url reason", func(t *testing.T) {
+		storage.EXPECT().GetURL(ctx, shortURL).Times(1).Return(models.URL{DeletedFlag: true}, nil)
+
+		_, err := server.GetURL(ctx, &GetURLRequest{ShortUrl: shortURL})
+
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		info, _ := errorDetails(t, err)
+		assert.Equal(t, services.ReasonURLDeleted, info.GetReason())
+	})
 }
 
 func TestFetchUserURLs(t *testing.T) {
@@ -314,8 +496,7 @@ func TestFetchUserURLs(t *testing.T) {
 			name:    "urls not found",
 			urls:    []models.URL{},
 			err:     nil,
-			wantErr: true,
-			errText: "URLs not found",
+			wantErr: false,
 		},
 	}
 
@@ -330,8 +511,11 @@ func TestFetchUserURLs(t *testing.T) {
 				require.ErrorContains(t, err, test.errText)
 			} else {
 				require.NoError(t, err)
-				assert.Equal(t, shortURL, resp.GetUrls()[0].GetShortUrl())
-				assert.Equal(t, originalURL, resp.GetUrls()[0].GetOriginalUrl())
+				require.Len(t, resp.GetUrls(), len(test.urls))
+				for i, u := range test.urls {
+					assert.Equal(t, services.ShortLink(u.ShortURL), resp.GetUrls()[i].GetShortUrl())
+					assert.Equal(t, u.OriginalURL, resp.GetUrls()[i].GetOriginalUrl())
+				}
 			}
 		})
 	}
@@ -495,3 +679,103 @@ func TestPing(t *testing.T) {
 		})
 	}
 }
+
+func TestNewGRPCServer_TLS(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	logger := zap.NewNop()
+	storage := mock.NewMockStorager(mockCtrl)
+	storage.EXPECT().Ping(gomock.Any()).AnyTimes().Return(nil)
+
+	dir := t.TempDir()
+	ca, err := certstest.NewCA("test CA")
+	require.NoError(t, err)
+	caFile := filepath.Join(dir, "ca.crt")
+	require.NoError(t, os.WriteFile(caFile, ca.PEM, 0o600))
+
+	serverPair, err := ca.IssueServer("server", "127.0.0.1")
+	require.NoError(t, err)
+	certFile, keyFile, err := serverPair.WriteFiles(dir, "server")
+	require.NoError(t, err)
+
+	clientPair, err := ca.IssueClient("client")
+	require.NoError(t, err)
+	clientCert, err := tls.X509KeyPair(clientPair.CertPEM, clientPair.KeyPEM)
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(ca.PEM))
+
+	reloader, err := certs.NewReloader(logger, certFile, keyFile, caFile)
+	require.NoError(t, err)
+
+	listen, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := NewGRPCServer(logger, storage, grpc.Creds(credentials.NewTLS(reloader.ServerConfig())))
+	go func() { _ = s.Serve(listen) }()
+	defer s.Stop()
+
+	ping := func(t *testing.T, clientCfg *tls.Config) error {
+		t.Helper()
+
+		conn, err := grpc.NewClient(listen.Addr().String(), grpc.WithTransportCredentials(credentials.NewTLS(clientCfg)))
+		require.NoError(t, err)
+		defer func() { _ = conn.Close() }()
+
+		_, err = NewShortenerClient(conn).Ping(context.Background(), &PingRequest{})
+		return err //nolint:wrapcheck // Тестовый хелпер
+	}
+
+	t.Run("client with certificate", func(t *testing.T) {
+		err := ping(t, &tls.Config{
+			RootCAs:      roots,
+			Certificates: []tls.Certificate{clientCert},
+			MinVersion:   tls.VersionTLS13,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("client without certificate", func(t *testing.T) {
+		err := ping(t, &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS13})
+		require.Error(t, err)
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	})
+
+	t.Run("plaintext client", func(t *testing.T) {
+		conn, err := grpc.NewClient(listen.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, err)
+		defer func() { _ = conn.Close() }()
+
+		_, err = NewShortenerClient(conn).Ping(context.Background(), &PingRequest{})
+		require.Error(t, err)
+	})
+}
+
+func TestRequestIDInterceptor(t *testing.T) {
+	var got string
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		got = requestid.FromContext(ctx)
+		return req, nil
+	}
+
+	interceptor := requestIDInterceptor(zap.NewNop())
+
+	t.Run("keep incoming id", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(requestid.MetadataKey, "client-request-1"))
+
+		_, err := interceptor(ctx, "test", &grpc.UnaryServerInfo{}, handler)
+
+		require.NoError(t, err)
+		assert.Equal(t, "client-request-1", got)
+	})
+
+	t.Run("generate id without metadata", func(t *testing.T) {
+		_, err := interceptor(context.Background(), "test", &grpc.UnaryServerInfo{}, handler)
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, got)
+		assert.NotEqual(t, "client-request-1", got)
+	})
+}
@@ -19,18 +19,29 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Shortener_AddShortURL_FullMethodName    = "/shortener.Shortener/AddShortURL"
-	Shortener_AddShortURLs_FullMethodName   = "/shortener.Shortener/AddShortURLs"
-	Shortener_GetURL_FullMethodName         = "/shortener.Shortener/GetURL"
-	Shortener_FetchUserURLs_FullMethodName  = "/shortener.Shortener/FetchUserURLs"
-	Shortener_DeleteUserURLs_FullMethodName = "/shortener.Shortener/DeleteUserURLs"
-	Shortener_FetchStats_FullMethodName     = "/shortener.Shortener/FetchStats"
-	Shortener_Ping_FullMethodName           = "/shortener.Shortener/Ping"
+	Shortener_AddShortURL_FullMethodName      = "/shortener.Shortener/AddShortURL"
+	Shortener_AddShortURLs_FullMethodName     = "/shortener.Shortener/AddShortURLs"
+	Shortener_GetURL_FullMethodName           = "/shortener.Shortener/GetURL"
+	Shortener_FetchUserURLs_FullMethodName    = "/shortener.Shortener/FetchUserURLs"
+	Shortener_DeleteUserURLs_FullMethodName   = "/shortener.Shortener/DeleteUserURLs"
+	Shortener_FetchStats_FullMethodName       = "/shortener.Shortener/FetchStats"
+	Shortener_Ping_FullMethodName             = "/shortener.Shortener/Ping"
+	Shortener_CreateOrg_FullMethodName        = "/shortener.Shortener/CreateOrg"
+	Shortener_FetchUserOrgs_FullMethodName    = "/shortener.Shortener/FetchUserOrgs"
+	Shortener_FetchOrgMembers_FullMethodName  = "/shortener.Shortener/FetchOrgMembers"
+	Shortener_SetOrgMember_FullMethodName     = "/shortener.Shortener/SetOrgMember"
+	Shortener_DeleteOrgMember_FullMethodName  = "/shortener.Shortener/DeleteOrgMember"
+	Shortener_ImportURLs_FullMethodName       = "/shortener.Shortener/ImportURLs"
+	Shortener_StreamUserURLs_FullMethodName   = "/shortener.Shortener/StreamUserURLs"
+	Shortener_StreamUserEvents_FullMethodName = "/shortener.Shortener/StreamUserEvents"
 )
 
 // ShortenerClient is the client API for Shortener service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// HTTP аннотации задают маршруты REST шлюза под /v2/api.
+// Потоковые методы доступны только по gRPC: шлюз вызывает ProtoServer в том же процессе без потоков.
 type ShortenerClient interface {
 	AddShortURL(ctx context.Context, in *AddShortURLRequest, opts ...grpc.CallOption) (*AddShortURLResponse, error)
 	AddShortURLs(ctx context.Context, in *AddShortURLsRequest, opts ...grpc.CallOption) (*AddShortURLsResponse, error)
@@ -39,6 +50,14 @@ type ShortenerClient interface {
 	DeleteUserURLs(ctx context.Context, in *DeleteUserURLsRequest, opts ...grpc.CallOption) (*DeleteUserURLsResponse, error)
 	FetchStats(ctx context.Context, in *FetchStatsRequest, opts ...grpc.CallOption) (*FetchStatsResponse, error)
 	Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error)
+	CreateOrg(ctx context.Context, in *CreateOrgRequest, opts ...grpc.CallOption) (*CreateOrgResponse, error)
+	FetchUserOrgs(ctx context.Context, in *FetchUserOrgsRequest, opts ...grpc.CallOption) (*FetchUserOrgsResponse, error)
+	FetchOrgMembers(ctx context.Context, in *FetchOrgMembersRequest, opts ...grpc.CallOption) (*FetchOrgMembersResponse, error)
+	SetOrgMember(ctx context.Context, in *SetOrgMemberRequest, opts ...grpc.CallOption) (*SetOrgMemberResponse, error)
+	DeleteOrgMember(ctx context.Context, in *DeleteOrgMemberRequest, opts ...grpc.CallOption) (*DeleteOrgMemberResponse, error)
+	ImportURLs(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportURLsRequest, ImportURLsResponse], error)
+	StreamUserURLs(ctx context.Context, in *StreamUserURLsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[URL], error)
+	StreamUserEvents(ctx context.Context, in *StreamUserEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LinkEvent], error)
 }
 
 type shortenerClient struct {
@@ -119,9 +138,113 @@ func (c *shortenerClient) Ping(ctx context.Context, in *PingRequest, opts ...grp
 	return out, nil
 }
 
+func (c *shortenerClient) CreateOrg(ctx context.Context, in *CreateOrgRequest, opts ...grpc.CallOption) (*CreateOrgResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateOrgResponse)
+	err := c.cc.Invoke(ctx, Shortener_CreateOrg_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortenerClient) FetchUserOrgs(ctx context.Context, in *FetchUserOrgsRequest, opts ...grpc.CallOption) (*FetchUserOrgsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FetchUserOrgsResponse)
+	err := c.cc.Invoke(ctx, Shortener_FetchUserOrgs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortenerClient) FetchOrgMembers(ctx context.Context, in *FetchOrgMembersRequest, opts ...grpc.CallOption) (*FetchOrgMembersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FetchOrgMembersResponse)
+	err := c.cc.Invoke(ctx, Shortener_FetchOrgMembers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortenerClient) SetOrgMember(ctx context.Context, in *SetOrgMemberRequest, opts ...grpc.CallOption) (*SetOrgMemberResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetOrgMemberResponse)
+	err := c.cc.Invoke(ctx, Shortener_SetOrgMember_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortenerClient) DeleteOrgMember(ctx context.Context, in *DeleteOrgMemberRequest, opts ...grpc.CallOption) (*DeleteOrgMemberResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteOrgMemberResponse)
+	err := c.cc.Invoke(ctx, Shortener_DeleteOrgMember_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortenerClient) ImportURLs(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportURLsRequest, ImportURLsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Shortener_ServiceDesc.Streams[0], Shortener_ImportURLs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ImportURLsRequest, ImportURLsResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Shortener_ImportURLsClient = grpc.ClientStreamingClient[ImportURLsRequest, ImportURLsResponse]
+
+func (c *shortenerClient) StreamUserURLs(ctx context.Context, in *StreamUserURLsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[URL], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Shortener_ServiceDesc.Streams[1], Shortener_StreamUserURLs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamUserURLsRequest, URL]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Shortener_StreamUserURLsClient = grpc.ServerStreamingClient[URL]
+
+func (c *shortenerClient) StreamUserEvents(ctx context.Context, in *StreamUserEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LinkEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Shortener_ServiceDesc.Streams[2], Shortener_StreamUserEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamUserEventsRequest, LinkEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Shortener_StreamUserEventsClient = grpc.ServerStreamingClient[LinkEvent]
+
 // ShortenerServer is the server API for Shortener service.
 // All implementations must embed UnimplementedShortenerServer
 // for forward compatibility.
+//
+// HTTP аннотации задают маршруты REST шлюза под /v2/api.
+// Потоковые методы доступны только по gRPC: шлюз вызывает ProtoServer в том же процессе без потоков.
 type ShortenerServer interface {
 	AddShortURL(context.Context, *AddShortURLRequest) (*AddShortURLResponse, error)
 	AddShortURLs(context.Context, *AddShortURLsRequest) (*AddShortURLsResponse, error)
@@ -130,6 +253,14 @@ type ShortenerServer interface {
 	DeleteUserURLs(context.Context, *DeleteUserURLsRequest) (*DeleteUserURLsResponse, error)
 	FetchStats(context.Context, *FetchStatsRequest) (*FetchStatsResponse, error)
 	Ping(context.Context, *PingRequest) (*PingResponse, error)
+	CreateOrg(context.Context, *CreateOrgRequest) (*CreateOrgResponse, error)
+	FetchUserOrgs(context.Context, *FetchUserOrgsRequest) (*FetchUserOrgsResponse, error)
+	FetchOrgMembers(context.Context, *FetchOrgMembersRequest) (*FetchOrgMembersResponse, error)
+	SetOrgMember(context.Context, *SetOrgMemberRequest) (*SetOrgMemberResponse, error)
+	DeleteOrgMember(context.Context, *DeleteOrgMemberRequest) (*DeleteOrgMemberResponse, error)
+	ImportURLs(grpc.ClientStreamingServer[ImportURLsRequest, ImportURLsResponse]) error
+	StreamUserURLs(*StreamUserURLsRequest, grpc.ServerStreamingServer[URL]) error
+	StreamUserEvents(*StreamUserEventsRequest, grpc.ServerStreamingServer[LinkEvent]) error
 	mustEmbedUnimplementedShortenerServer()
 }
 
@@ -161,6 +292,30 @@ func (UnimplementedShortenerServer) FetchStats(context.Context, *FetchStatsReque
 func (UnimplementedShortenerServer) Ping(context.Context, *PingRequest) (*PingResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Ping not implemented")
 }
+func (UnimplementedShortenerServer) CreateOrg(context.Context, *CreateOrgRequest) (*CreateOrgResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateOrg not implemented")
+}
+func (UnimplementedShortenerServer) FetchUserOrgs(context.Context, *FetchUserOrgsRequest) (*FetchUserOrgsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FetchUserOrgs not implemented")
+}
+func (UnimplementedShortenerServer) FetchOrgMembers(context.Context, *FetchOrgMembersRequest) (*FetchOrgMembersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FetchOrgMembers not implemented")
+}
+func (UnimplementedShortenerServer) SetOrgMember(context.Context, *SetOrgMemberRequest) (*SetOrgMemberResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetOrgMember not implemented")
+}
+func (UnimplementedShortenerServer) DeleteOrgMember(context.Context, *DeleteOrgMemberRequest) (*DeleteOrgMemberResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteOrgMember not implemented")
+}
+func (UnimplementedShortenerServer) ImportURLs(grpc.ClientStreamingServer[ImportURLsRequest, ImportURLsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ImportURLs not implemented")
+}
+func (UnimplementedShortenerServer) StreamUserURLs(*StreamUserURLsRequest, grpc.ServerStreamingServer[URL]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamUserURLs not implemented")
+}
+func (UnimplementedShortenerServer) StreamUserEvents(*StreamUserEventsRequest, grpc.ServerStreamingServer[LinkEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamUserEvents not implemented")
+}
 func (UnimplementedShortenerServer) mustEmbedUnimplementedShortenerServer() {}
 func (UnimplementedShortenerServer) testEmbeddedByValue()                   {}
 
@@ -308,6 +463,125 @@ func _Shortener_Ping_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Shortener_CreateOrg_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateOrgRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortenerServer).CreateOrg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Shortener_CreateOrg_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortenerServer).CreateOrg(ctx, req.(*CreateOrgRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Shortener_FetchUserOrgs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchUserOrgsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortenerServer).FetchUserOrgs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Shortener_FetchUserOrgs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortenerServer).FetchUserOrgs(ctx, req.(*FetchUserOrgsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Shortener_FetchOrgMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchOrgMembersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortenerServer).FetchOrgMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Shortener_FetchOrgMembers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortenerServer).FetchOrgMembers(ctx, req.(*FetchOrgMembersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Shortener_SetOrgMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetOrgMemberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortenerServer).SetOrgMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Shortener_SetOrgMember_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortenerServer).SetOrgMember(ctx, req.(*SetOrgMemberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Shortener_DeleteOrgMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteOrgMemberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortenerServer).DeleteOrgMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Shortener_DeleteOrgMember_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortenerServer).DeleteOrgMember(ctx, req.(*DeleteOrgMemberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Shortener_ImportURLs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ShortenerServer).ImportURLs(&grpc.GenericServerStream[ImportURLsRequest, ImportURLsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Shortener_ImportURLsServer = grpc.ClientStreamingServer[ImportURLsRequest, ImportURLsResponse]
+
+func _Shortener_StreamUserURLs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamUserURLsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ShortenerServer).StreamUserURLs(m, &grpc.GenericServerStream[StreamUserURLsRequest, URL]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Shortener_StreamUserURLsServer = grpc.ServerStreamingServer[URL]
+
+func _Shortener_StreamUserEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamUserEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ShortenerServer).StreamUserEvents(m, &grpc.GenericServerStream[StreamUserEventsRequest, LinkEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Shortener_StreamUserEventsServer = grpc.ServerStreamingServer[LinkEvent]
+
 // Shortener_ServiceDesc is the grpc.ServiceDesc for Shortener service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -343,7 +617,43 @@ var Shortener_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Ping",
 			Handler:    _Shortener_Ping_Handler,
 		},
+		{
+			MethodName: "CreateOrg",
+			Handler:    _Shortener_CreateOrg_Handler,
+		},
+		{
+			MethodName: "FetchUserOrgs",
+			Handler:    _Shortener_FetchUserOrgs_Handler,
+		},
+		{
+			MethodName: "FetchOrgMembers",
+			Handler:    _Shortener_FetchOrgMembers_Handler,
+		},
+		{
+			MethodName: "SetOrgMember",
+			Handler:    _Shortener_SetOrgMember_Handler,
+		},
+		{
+			MethodName: "DeleteOrgMember",
+			Handler:    _Shortener_DeleteOrgMember_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ImportURLs",
+			Handler:       _Shortener_ImportURLs_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "StreamUserURLs",
+			Handler:       _Shortener_StreamUserURLs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamUserEvents",
+			Handler:       _Shortener_StreamUserEvents_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "internal/app/proto/shortener.proto",
 }
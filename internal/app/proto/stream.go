@@ -0,0 +1,167 @@
+package proto
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"go.uber.org/zap"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/config"
+	"github.com/MihailSergeenkov/shortener/internal/app/logger"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+	"github.com/MihailSergeenkov/shortener/internal/app/services"
+)
+
+const (
+	importChunkSize     = 1000 // количество ссылок, сохраняемых в БД за один раз при импорте
+	defaultStreamPage   = 500  // размер страницы выгрузки ссылок по умолчанию
+	maxStreamPageSize   = 5000 // максимальный размер страницы выгрузки ссылок
+	importFailedMessage = "failed to import URLs"
+	eventHeartbeat      = "heartbeat" // событие проверки соединения в потоке событий
+)
+
+// ImportURLs реализует интерфейс потокового импорта ссылок.
+// Ссылки сохраняются частями по importChunkSize, при ошибке уже сохраненные части не откатываются.
+func (s *ProtoServer) ImportURLs(stream grpc.ClientStreamingServer[ImportURLsRequest, ImportURLsResponse]) error {
+	ctx := stream.Context()
+	chunk := make(models.BatchRequest, 0, importChunkSize)
+
+	var response ImportURLsResponse
+
+	flush := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+
+		if _, err := services.AddBatchShortURL(ctx, s.storage, chunk); err != nil {
+			logger.FromContext(ctx, s.logger).Info("import interrupted",
+				zap.Int64("imported", response.GetImported()), zap.Error(err))
+			return s.statusError(ctx, err, importFailedMessage)
+		}
+
+		response.Imported += int64(len(chunk))
+		response.Chunks++
+		chunk = chunk[:0]
+
+		return nil
+	}
+
+	for {
+		in, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to receive import request: %w", err)
+		}
+
+		for _, u := range in.GetUrls() {
+			chunk = append(chunk, models.BatchDataRequest{
+				CorrelationID: u.GetCorrelationId(),
+				OriginalURL:   u.GetOriginalUrl(),
+			})
+
+			if len(chunk) == importChunkSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return err
+	}
+
+	if err := stream.SendAndClose(&response); err != nil {
+		return fmt.Errorf("failed to send import response: %w", err)
+	}
+
+	return nil
+}
+
+// StreamUserURLs реализует интерфейс потоковой выгрузки ссылок пользователя или организации.
+func (s *ProtoServer) StreamUserURLs(in *StreamUserURLsRequest, stream grpc.ServerStreamingServer[URL]) error {
+	pageSize := int(in.GetPageSize())
+	if pageSize <= 0 {
+		pageSize = defaultStreamPage
+	}
+	pageSize = min(pageSize, maxStreamPageSize)
+
+	err := services.WalkUserURLs(stream.Context(), s.storage, pageSize, func(page models.UserURLsResponse) error {
+		for _, u := range page {
+			if err := stream.Send(&URL{ShortUrl: u.ShortURL, OriginalUrl: u.OriginalURL}); err != nil {
+				return fmt.Errorf("failed to send URL: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		if ctxErr := stream.Context().Err(); ctxErr != nil {
+			return status.FromContextError(ctxErr).Err() //nolint:wrapcheck // FalsePositive
+		}
+
+		return s.statusError(stream.Context(), err, "failed to stream URLs from storage")
+	}
+
+	return nil
+}
+
+// StreamUserEvents реализует интерфейс потока событий ссылок пользователя.
+// Поток возобновляется после события last_event_id, пока событий нет, каждые EventsHeartbeat
+// отправляется событие heartbeat. Поток завершается кодом Unavailable при остановке сервиса
+// или если клиент не успевает читать события.
+func (s *ProtoServer) StreamUserEvents(
+	in *StreamUserEventsRequest,
+	stream grpc.ServerStreamingServer[LinkEvent],
+) error {
+	ctx := stream.Context()
+
+	sub, err := services.SubscribeLinkEvents(ctx, in.GetLastEventId())
+	if err != nil {
+		return s.statusError(ctx, err, "failed to subscribe to link events")
+	}
+	defer sub.Close()
+
+	var heartbeats <-chan time.Time
+	if config.Params.EventsHeartbeat > 0 {
+		ticker := time.NewTicker(config.Params.EventsHeartbeat)
+		defer ticker.Stop()
+		heartbeats = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err() //nolint:wrapcheck // FalsePositive
+		case <-sub.Done():
+			return status.Error(codes.Unavailable, sub.Err().Error()) //nolint:wrapcheck // FalsePositive
+		case event := <-sub.Events():
+			err = stream.Send(linkEvent(services.NewUserEvent(event)))
+		case <-heartbeats:
+			err = stream.Send(&LinkEvent{Type: eventHeartbeat, Time: time.Now().UTC().Format(time.RFC3339Nano)})
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to send link event: %w", err)
+		}
+	}
+}
+
+func linkEvent(event models.UserEvent) *LinkEvent {
+	return &LinkEvent{
+		Id:          event.ID,
+		Type:        event.Type,
+		Time:        event.Time.Format(time.RFC3339Nano),
+		ShortUrl:    event.ShortURL,
+		ShortLink:   event.ShortLink,
+		OriginalUrl: event.OriginalURL,
+		OrgId:       event.OrgID,
+	}
+}
@@ -0,0 +1,266 @@
+package proto
+
+import (
+	context "context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	status "google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/config"
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/feed"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+	"github.com/MihailSergeenkov/shortener/internal/app/ratelimit"
+	"github.com/MihailSergeenkov/shortener/internal/app/services"
+)
+
+func newStreamClient(t *testing.T, storage data.Storager) ShortenerClient {
+	t.Helper()
+
+	listen := bufconn.Listen(1024 * 1024)
+	s := NewGRPCServer(zap.NewNop(), storage)
+	go func() { _ = s.Serve(listen) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listen.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return NewShortenerClient(conn)
+}
+
+func importURLs(ctx context.Context, client ShortenerClient, messages int, perMessage int) (*ImportURLsResponse, error) {
+	stream, err := client.ImportURLs(ctx)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // Тестовый хелпер
+	}
+
+	for i := range messages {
+		req := &ImportURLsRequest{}
+		for j := range perMessage {
+			req.Urls = append(req.Urls, &BatchRequest{
+				CorrelationId: fmt.Sprint(j),
+				OriginalUrl:   fmt.Sprintf("https://example.com/%d/%d", i, j),
+			})
+		}
+
+		if err := stream.Send(req); err != nil {
+			break
+		}
+	}
+
+	return stream.CloseAndRecv() //nolint:wrapcheck // Тестовый хелпер
+}
+
+func TestImportAndStreamURLs(t *testing.T) {
+	params := config.Params
+	defer func() { config.Params = params }()
+	config.Params.CreateRPS = 0
+	config.Params.DailyURLsQuota = 0
+
+	client := newStreamClient(t, data.NewBaseStorage())
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "user_id", "some_id")
+
+	t.Run("import in chunks", func(t *testing.T) {
+		resp, err := importURLs(ctx, client, 5, 500)
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(2500), resp.GetImported())
+		assert.Equal(t, int64(3), resp.GetChunks())
+	})
+
+	t.Run("stream all pages", func(t *testing.T) {
+		stream, err := client.StreamUserURLs(ctx, &StreamUserURLsRequest{PageSize: 1000})
+		require.NoError(t, err)
+
+		seen := map[string]bool{}
+		for {
+			u, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			seen[u.GetOriginalUrl()] = true
+		}
+
+		assert.Len(t, seen, 2500)
+	})
+
+	t.Run("stream other user", func(t *testing.T) {
+		otherCtx := metadata.AppendToOutgoingContext(context.Background(), "user_id", "other_id")
+		stream, err := client.StreamUserURLs(otherCtx, &StreamUserURLsRequest{})
+		require.NoError(t, err)
+
+		_, err = stream.Recv()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		_, err := importURLs(context.Background(), client, 1, 1)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		stream, err := client.StreamUserURLs(context.Background(), &StreamUserURLsRequest{})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("org without membership", func(t *testing.T) {
+		orgCtx := metadata.AppendToOutgoingContext(ctx, "org_id", "some_org")
+
+		_, err := importURLs(orgCtx, client, 1, 1)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+		stream, err := client.StreamUserURLs(orgCtx, &StreamUserURLsRequest{})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
+
+func TestImportURLs_QuotaExceeded(t *testing.T) {
+	params := config.Params
+	defer func() { config.Params = params }()
+	config.Params.CreateRPS = 0
+	config.Params.DailyURLsQuota = importChunkSize
+
+	storage := data.NewBaseStorage()
+	client := newStreamClient(t, storage)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "user_id", "some_id")
+
+	_, err := importURLs(ctx, client, 3, importChunkSize)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	userCtx := context.WithValue(context.Background(), common.KeyUserID, "some_id")
+	urls, err := storage.FetchUserURLs(userCtx)
+	require.NoError(t, err)
+	assert.Len(t, urls, importChunkSize)
+}
+
+func TestStreamUserEvents(t *testing.T) {
+	params := config.Params
+	defer func() { config.Params = params }()
+	config.Params.CreateRPS = 0
+	config.Params.DailyURLsQuota = 0
+	config.Params.EventsHeartbeat = 20 * time.Millisecond
+
+	stop := services.StartLinkFeed(10)
+	defer stop()
+
+	client := newStreamClient(t, data.NewBaseStorage())
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "user_id", "some_id")
+
+	stream, err := client.StreamUserEvents(ctx, &StreamUserEventsRequest{})
+	require.NoError(t, err)
+
+	// Первый heartbeat означает, что подписка уже оформлена.
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, eventHeartbeat, event.GetType())
+
+	resp, err := client.AddShortURL(ctx, &AddShortURLRequest{OriginalUrl: "https://ya.ru"})
+	require.NoError(t, err)
+
+	for event.GetType() == eventHeartbeat {
+		event, err = stream.Recv()
+		require.NoError(t, err)
+	}
+	assert.Equal(t, models.LinkCreated, event.GetType())
+	assert.Equal(t, uint64(1), event.GetId())
+	assert.Equal(t, resp.GetShortUrl(), event.GetShortLink())
+	assert.Equal(t, "https://ya.ru", event.GetOriginalUrl())
+
+	t.Run("resume", func(t *testing.T) {
+		resumed, err := client.StreamUserEvents(ctx, &StreamUserEventsRequest{LastEventId: 100})
+		require.NoError(t, err)
+
+		event, err := resumed.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, feed.EventReset, event.GetType())
+		assert.Equal(t, uint64(1), event.GetId())
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		unauthenticated, err := client.StreamUserEvents(context.Background(), &StreamUserEventsRequest{})
+		require.NoError(t, err)
+
+		_, err = unauthenticated.Recv()
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("stopped", func(t *testing.T) {
+		stop()
+
+		var recvErr error
+		for recvErr == nil {
+			_, recvErr = stream.Recv()
+		}
+		assert.Equal(t, codes.Unavailable, status.Code(recvErr))
+
+		stopped, err := client.StreamUserEvents(ctx, &StreamUserEventsRequest{})
+		require.NoError(t, err)
+
+		_, err = stopped.Recv()
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	})
+}
+
+func TestRateLimit_ChangingUserID(t *testing.T) {
+	params := config.Params
+	defer func() { config.Params = params }()
+	config.Params.CreateRPS, config.Params.CreateBurst = 1, 1
+	config.Params.DailyURLsQuota = 0
+
+	client := newStreamClient(t, data.NewBaseStorage())
+
+	first := metadata.AppendToOutgoingContext(context.Background(), "user_id", "first_id")
+	_, err := client.AddShortURL(first, &AddShortURLRequest{OriginalUrl: "https://ya.ru/1"})
+	require.NoError(t, err)
+
+	second := metadata.AppendToOutgoingContext(context.Background(), "user_id", "second_id")
+	_, err = client.AddShortURL(second, &AddShortURLRequest{OriginalUrl: "https://ya.ru/2"})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestRateLimitStreamInterceptor(t *testing.T) {
+	interceptor := rateLimitStreamInterceptor(ratelimit.NewLimiter(1, 1), ratelimit.NewLimiter(0, 0))
+	handler := func(any, grpc.ServerStream) error { return nil }
+	ss := &testServerStream{ctx: context.Background()}
+
+	info := &grpc.StreamServerInfo{FullMethod: "/shortener.Shortener/ImportURLs"}
+	require.NoError(t, interceptor(nil, ss, info, handler))
+
+	err := interceptor(nil, ss, info, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	info = &grpc.StreamServerInfo{FullMethod: "/shortener.Shortener/StreamUserURLs"}
+	assert.NoError(t, interceptor(nil, ss, info, handler))
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,112 @@
+// Пакет ratelimit предназначен для ограничения частоты запросов к сервису.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/clientip"
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+)
+
+const idleTTL = 10 * time.Minute
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// Limiter структура ограничителя запросов по алгоритму token bucket с отдельным ведром на каждый ключ.
+type Limiter struct {
+	buckets   map[string]*bucket
+	now       func() time.Time
+	limit     rate.Limit
+	burst     int
+	mu        sync.Mutex
+	lastClean time.Time
+}
+
+// NewLimiter инициализирует ограничитель: rps - количество запросов в секунду, burst - размер ведра.
+// Нулевое значение rps отключает ограничение.
+func NewLimiter(rps float64, burst int) *Limiter {
+	return &Limiter{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+		limit:   rate.Limit(rps),
+		burst:   burst,
+	}
+}
+
+// Enabled проверяет, включено ли ограничение.
+func (l *Limiter) Enabled() bool {
+	return l != nil && l.limit > 0
+}
+
+// Allow проверяет, можно ли выполнить запрос для ключа.
+// Если нельзя, возвращает время, через которое стоит повторить запрос.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	if !l.Enabled() {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.cleanup(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+
+	r := b.limiter.ReserveN(now, 1)
+	if !r.OK() {
+		return false, time.Second
+	}
+
+	delay := r.DelayFrom(now)
+	if delay == 0 {
+		return true, 0
+	}
+
+	r.CancelAt(now)
+	return false, delay
+}
+
+func (l *Limiter) cleanup(now time.Time) {
+	if now.Sub(l.lastClean) < idleTTL {
+		return
+	}
+	l.lastClean = now
+
+	for key, b := range l.buckets {
+		if now.Sub(b.lastSeen) > idleTTL {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Key возвращает ключ ограничения для запроса: ID пользователя, если он подтвержден ранее выданным токеном,
+// иначе IP адрес клиента. Неподтвержденный ID клиент может менять в каждом запросе, обходя ограничение.
+func Key(ctx context.Context) string {
+	userID, ok := ctx.Value(common.KeyUserID).(string)
+	unverified, _ := ctx.Value(common.KeyUnverified).(bool)
+	if ok && userID != "" && !unverified {
+		return "user:" + userID
+	}
+
+	return "ip:" + clientip.String(ctx)
+}
+
+// RetryAfter возвращает значение заголовка Retry-After в секундах.
+func RetryAfter(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
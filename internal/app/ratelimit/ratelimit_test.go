@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/clientip"
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+)
+
+func TestAllow(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewLimiter(1, 2)
+	limiter.now = func() time.Time { return now }
+
+	t.Run("burst allowed", func(t *testing.T) {
+		for range 2 {
+			ok, retryAfter := limiter.Allow("user:1")
+			assert.True(t, ok)
+			assert.Zero(t, retryAfter)
+		}
+	})
+
+	t.Run("limit exceeded", func(t *testing.T) {
+		ok, retryAfter := limiter.Allow("user:1")
+		assert.False(t, ok)
+		assert.Equal(t, time.Second, retryAfter)
+	})
+
+	t.Run("other key has own bucket", func(t *testing.T) {
+		ok, _ := limiter.Allow("user:2")
+		assert.True(t, ok)
+	})
+
+	t.Run("tokens refill over time", func(t *testing.T) {
+		now = now.Add(time.Second)
+
+		ok, _ := limiter.Allow("user:1")
+		assert.True(t, ok)
+	})
+
+	t.Run("idle buckets removed", func(t *testing.T) {
+		now = now.Add(2 * idleTTL)
+
+		_, _ = limiter.Allow("user:3")
+		assert.Len(t, limiter.buckets, 1)
+	})
+}
+
+func TestAllow_Disabled(t *testing.T) {
+	limiter := NewLimiter(0, 0)
+
+	t.Run("always allowed", func(t *testing.T) {
+		for range 100 {
+			ok, _ := limiter.Allow("user:1")
+			assert.True(t, ok)
+		}
+	})
+}
+
+func TestKey(t *testing.T) {
+	ctx := clientip.NewContext(context.Background(), net.ParseIP("198.51.100.5"))
+
+	t.Run("key by client ip", func(t *testing.T) {
+		assert.Equal(t, "ip:198.51.100.5", Key(ctx))
+	})
+
+	t.Run("key by user id", func(t *testing.T) {
+		userCtx := context.WithValue(ctx, common.KeyUserID, "some_id")
+		assert.Equal(t, "user:some_id", Key(userCtx))
+	})
+
+	t.Run("unverified user keyed by client ip", func(t *testing.T) {
+		userCtx := context.WithValue(ctx, common.KeyUserID, "some_id")
+		userCtx = context.WithValue(userCtx, common.KeyUnverified, true)
+		assert.Equal(t, "ip:198.51.100.5", Key(userCtx))
+	})
+}
+
+func TestRetryAfter(t *testing.T) {
+	assert.Equal(t, "1", RetryAfter(10*time.Millisecond))
+	assert.Equal(t, "3", RetryAfter(2500*time.Millisecond))
+}
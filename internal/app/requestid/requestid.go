@@ -0,0 +1,67 @@
+// Пакет requestid предназначен для сквозного идентификатора запроса,
+// который принимается от клиента или генерируется сервисом и возвращается в ответе.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+)
+
+const (
+	Header      = "X-Request-ID" // заголовок HTTP с идентификатором запроса
+	MetadataKey = "x-request-id" // ключ метаданных gRPC с идентификатором запроса
+
+	idBytes   = 16
+	maxLength = 128
+)
+
+// New генерирует новый идентификатор запроса.
+func New() string {
+	bytes := make([]byte, idBytes)
+	if _, err := rand.Read(bytes); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+
+	return hex.EncodeToString(bytes)
+}
+
+// Valid проверяет идентификатор, полученный от клиента: непустой, не длиннее 128 символов,
+// только печатные символы ASCII без пробелов, чтобы его можно было безопасно писать в журнал и заголовки.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+
+	for i := range len(id) {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Resolve возвращает идентификатор клиента, если он допустим, иначе новый.
+func Resolve(incoming string) string {
+	if Valid(incoming) {
+		return incoming
+	}
+
+	return New()
+}
+
+// NewContext возвращает контекст с идентификатором запроса.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, common.KeyRequestID, id)
+}
+
+// FromContext получает идентификатор запроса из контекста.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(common.KeyRequestID).(string)
+	return id
+}
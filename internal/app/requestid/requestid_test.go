@@ -0,0 +1,43 @@
+package requestid
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{name: "client id", incoming: "req-1:abc.DEF_2", keep: true},
+		{name: "empty", incoming: ""},
+		{name: "with spaces", incoming: "req 1"},
+		{name: "with new line", incoming: "req\n1"},
+		{name: "non ascii", incoming: "запрос"},
+		{name: "too long", incoming: strings.Repeat("a", maxLength+1)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			id := Resolve(test.incoming)
+			if test.keep {
+				assert.Equal(t, test.incoming, id)
+				return
+			}
+
+			assert.NotEqual(t, test.incoming, id)
+			assert.Len(t, id, idBytes*2)
+			assert.True(t, Valid(id))
+		})
+	}
+}
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, FromContext(ctx))
+	assert.Equal(t, "req-1", FromContext(NewContext(ctx, "req-1")))
+}
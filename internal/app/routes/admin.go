@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/config"
+	"github.com/MihailSergeenkov/shortener/internal/app/handlers"
+	"github.com/MihailSergeenkov/shortener/internal/app/logger"
+)
+
+// NewAdminRouter функция инициализации роутинга административного сервера:
+// профилировщик pprof под /debug, снятие профилей в каталог ProfilesDir и уровни журналирования.
+// Сервер не требует авторизации, поэтому его адрес не должен быть доступен извне.
+func NewAdminRouter(l *zap.Logger) chi.Router {
+	r := chi.NewRouter()
+	r.Use(withRequestID(l), withRequestLogging(l))
+
+	r.Mount("/debug", middleware.Profiler())
+	r.Post("/profiles/{"+handlers.ProfileKindParam+"}", handlers.CaptureProfileHandler(l, config.Params.ProfilesDir))
+	r.Handle("/log/level", logger.LevelHandler(l))
+
+	return r
+}
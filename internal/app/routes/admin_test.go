@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+)
+
+func TestNewAdminRouter(t *testing.T) {
+	t.Run("pprof only on admin router", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		NewAdminRouter(zap.NewNop()).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/pprof/", http.NoBody))
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = httptest.NewRecorder()
+		r := newTestRouter(t, zap.NewNop(), data.NewBaseStorage())
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/pprof/", http.NoBody))
+		assert.NotEqual(t, http.StatusOK, w.Code)
+	})
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/audit"
+)
+
+// withAuditTransport отмечает запрос транспортом HTTP для журнала аудита.
+func withAuditTransport(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(audit.NewTransportContext(r.Context(), audit.TransportHTTP)))
+	})
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/audit"
+)
+
+func TestWithAuditTransport(t *testing.T) {
+	var transport string
+	handler := withAuditTransport(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		transport = audit.TransportFromContext(r.Context())
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	assert.Equal(t, audit.TransportHTTP, transport)
+}
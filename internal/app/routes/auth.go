@@ -7,12 +7,14 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap"
 
 	"github.com/MihailSergeenkov/shortener/internal/app/common"
 	"github.com/MihailSergeenkov/shortener/internal/app/config"
+	"github.com/MihailSergeenkov/shortener/internal/app/logger"
 )
 
 type claims struct {
@@ -20,24 +22,28 @@ type claims struct {
 	UserID string
 }
 
-const keyBytes int = 8
+const (
+	keyBytes       int = 8
+	authCookieName     = "AUTH_TOKEN"
+)
 
 func setAuthMiddleware(l *zap.Logger) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			authCookie, err := r.Cookie("AUTH_TOKEN")
+			authCookie, err := r.Cookie(authCookieName)
+			issued := err != nil
 
 			if err != nil {
 				if !errors.Is(err, http.ErrNoCookie) {
 					w.WriteHeader(http.StatusInternalServerError)
-					l.Error("failed to fetch cookie", zap.Error(err))
+					logger.FromContext(r.Context(), l).Error("failed to fetch cookie", zap.Error(err))
 					return
 				}
 
 				authCookie, err = setAuthCookie(w)
 				if err != nil {
 					w.WriteHeader(http.StatusInternalServerError)
-					l.Error("failed to build auth token", zap.Error(err))
+					logger.FromContext(r.Context(), l).Error("failed to build auth token", zap.Error(err))
 					return
 				}
 			}
@@ -48,15 +54,20 @@ func setAuthMiddleware(l *zap.Logger) func(next http.Handler) http.Handler {
 				authCookie, err := setAuthCookie(w)
 				if err != nil {
 					w.WriteHeader(http.StatusInternalServerError)
-					l.Error("failed to build auth token", zap.Error(err))
+					logger.FromContext(r.Context(), l).Error("failed to build auth token", zap.Error(err))
 					return
 				}
 				userID = getUserID(authCookie.Value)
+				issued = true
+			}
+
+			r = withUser(r, userID)
+			if issued {
+				// Новый ID выдается каждому запросу без cookie, ограничения частоты и квоты считаются по IP адресу.
+				r = r.WithContext(context.WithValue(r.Context(), common.KeyUnverified, true))
 			}
 
-			newContext := context.WithValue(r.Context(), common.KeyUserID, userID)
-			newRequest := r.WithContext(newContext)
-			next.ServeHTTP(w, newRequest)
+			next.ServeHTTP(w, r)
 		})
 	}
 }
@@ -64,11 +75,11 @@ func setAuthMiddleware(l *zap.Logger) func(next http.Handler) http.Handler {
 func checkAuthMiddleware(l *zap.Logger) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			authCookie, cookieErr := r.Cookie("AUTH_TOKEN")
+			authCookie, cookieErr := r.Cookie(authCookieName)
 
 			if cookieErr != nil {
 				w.WriteHeader(http.StatusUnauthorized)
-				l.Error("failed to fetch auth token", zap.Error(cookieErr))
+				logger.FromContext(r.Context(), l).Error("failed to fetch auth token", zap.Error(cookieErr))
 				return
 			}
 
@@ -76,13 +87,11 @@ func checkAuthMiddleware(l *zap.Logger) func(next http.Handler) http.Handler {
 
 			if userID == "" {
 				w.WriteHeader(http.StatusUnauthorized)
-				l.Error("failed to parse auth token")
+				logger.FromContext(r.Context(), l).Error("failed to parse auth token")
 				return
 			}
 
-			newContext := context.WithValue(r.Context(), common.KeyUserID, userID)
-			newRequest := r.WithContext(newContext)
-			next.ServeHTTP(w, newRequest)
+			next.ServeHTTP(w, withUser(r, userID))
 		})
 	}
 }
@@ -93,15 +102,31 @@ func setAuthCookie(w http.ResponseWriter) (*http.Cookie, error) {
 		return nil, fmt.Errorf("failed to build auth token: %w", err)
 	}
 
-	cookie := &http.Cookie{
-		Name:     "AUTH_TOKEN",
-		Value:    authToken,
-		HttpOnly: true,
-	}
+	return writeAuthCookie(w, authToken), nil
+}
 
+func writeAuthCookie(w http.ResponseWriter, authToken string) *http.Cookie {
+	cookie := newCookie(authCookieName, authToken, true)
 	http.SetCookie(w, cookie)
 
-	return cookie, nil
+	if config.Params.CSRFProtection {
+		http.SetCookie(w, newCookie(csrfCookieName, buildCSRFToken(getUserID(authToken)), false))
+	}
+
+	return cookie
+}
+
+func newCookie(name string, value string, httpOnly bool) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     config.Params.CookiePath,
+		Domain:   config.Params.CookieDomain,
+		MaxAge:   int(config.Params.CookieMaxAge.Seconds()),
+		Secure:   config.Params.SecureCookies(),
+		HttpOnly: httpOnly,
+		SameSite: config.Params.CookieSameSite,
+	}
 }
 
 func buildJWTString() (string, error) {
@@ -110,9 +135,17 @@ func buildJWTString() (string, error) {
 		return "", fmt.Errorf("failed to generate user id: %w", err)
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
-		UserID: userID,
-	})
+	return buildUserJWTString(userID)
+}
+
+// buildUserJWTString подписывает токен пользователя, срок действия которого совпадает со сроком жизни cookie.
+func buildUserJWTString(userID string) (string, error) {
+	c := claims{UserID: userID}
+	if config.Params.CookieMaxAge > 0 {
+		c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(config.Params.CookieMaxAge))
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, c)
 
 	tokenString, err := token.SignedString([]byte(config.Params.SecretKey))
 	if err != nil {
@@ -4,10 +4,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/config"
 )
 
 func TestSetAuthMiddleware(t *testing.T) {
@@ -25,6 +29,44 @@ func TestSetAuthMiddleware(t *testing.T) {
 	assert.Equal(t, 200, res.StatusCode)
 }
 
+func TestSetAuthCookie_SecureWithHTTPS(t *testing.T) {
+	params := config.Params
+	config.Params.EnableHTTPS = true
+	config.Params.CookieDomain = "example.com"
+	defer func() { config.Params = params }()
+
+	w := httptest.NewRecorder()
+	cookie, err := setAuthCookie(w)
+
+	require.NoError(t, err)
+	assert.True(t, cookie.Secure)
+	assert.True(t, cookie.HttpOnly)
+	assert.Equal(t, "example.com", cookie.Domain)
+}
+
+func TestBuildUserJWTString_Expiration(t *testing.T) {
+	params := config.Params
+	defer func() { config.Params = params }()
+
+	config.Params.CookieMaxAge = time.Hour
+	token, err := buildUserJWTString("user_id")
+	require.NoError(t, err)
+
+	c := &claims{}
+	_, err = jwt.ParseWithClaims(token, c, func(*jwt.Token) (interface{}, error) {
+		return []byte(config.Params.SecretKey), nil
+	})
+	require.NoError(t, err)
+	require.NotNil(t, c.ExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), c.ExpiresAt.Time, time.Minute)
+	assert.Equal(t, "user_id", getUserID(token))
+
+	c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	expired, err := jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString([]byte(config.Params.SecretKey))
+	require.NoError(t, err)
+	assert.Empty(t, getUserID(expired), "expired token is rejected")
+}
+
 func TestCheckAuthMiddleware_OK(t *testing.T) {
 	logger := zap.NewNop()
 	authToken, err := buildJWTString()
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/clientip"
+)
+
+func withClientIP(resolver *clientip.Resolver) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := resolver.FromRequest(r)
+
+			newContext := clientip.NewContext(r.Context(), ip)
+			next.ServeHTTP(w, r.WithContext(newContext))
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/clientip"
+)
+
+func TestWithClientIP(t *testing.T) {
+	_, trustedProxy, err := net.ParseCIDR("192.0.2.0/24")
+	require.NoError(t, err)
+
+	var got string
+	someHandler := func(w http.ResponseWriter, r *http.Request) {
+		got = clientip.String(r.Context())
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	request.Header.Add("X-Forwarded-For", "203.0.113.7")
+	w := httptest.NewRecorder()
+	m := withClientIP(clientip.NewResolver([]*net.IPNet{trustedProxy}))(http.HandlerFunc(someHandler))
+	m.ServeHTTP(w, request)
+
+	res := w.Result()
+	defer closeBody(t, res)
+
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, "203.0.113.7", got)
+}
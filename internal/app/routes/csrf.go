@@ -0,0 +1,106 @@
+package routes
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/config"
+	"github.com/MihailSergeenkov/shortener/internal/app/logger"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+)
+
+const (
+	csrfCookieName = "CSRF_TOKEN"
+	csrfHeaderName = "X-CSRF-Token"
+)
+
+// csrfMiddleware реализует защиту от CSRF по схеме double-submit cookie.
+// Токен подписан секретным ключом и привязан к пользователю, поэтому подменить его,
+// записав свою cookie, нельзя. Проверяются только изменяющие запросы, авторизованные через cookie.
+func csrfMiddleware(l *zap.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !config.Params.CSRFProtection {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			authCookie, err := r.Cookie(authCookieName)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			userID := getUserID(authCookie.Value)
+			if userID == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			expected := buildCSRFToken(userID)
+
+			if isSafeMethod(r.Method) {
+				if csrfCookie, err := r.Cookie(csrfCookieName); err != nil || csrfCookie.Value != expected {
+					http.SetCookie(w, newCookie(csrfCookieName, expected, false))
+				}
+
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			csrfCookie, err := r.Cookie(csrfCookieName)
+			if err != nil || !validCSRFToken(csrfCookie.Value, expected) ||
+				!validCSRFToken(r.Header.Get(csrfHeaderName), expected) {
+				w.WriteHeader(http.StatusForbidden)
+				logger.FromContext(r.Context(), l).Warn("csrf token mismatch",
+					zap.String("method", r.Method), zap.String("uri", r.RequestURI))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// csrfTokenHandler выдает CSRF токен пользователя в cookie и в теле ответа, клиенты без браузера
+// запрашивают его перед изменяющими запросами.
+func csrfTokenHandler(l *zap.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, _ := r.Context().Value(common.KeyUserID).(string)
+		token := buildCSRFToken(userID)
+
+		http.SetCookie(w, newCookie(csrfCookieName, token, false))
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set(common.ContentTypeHeader, common.JSONContentType)
+
+		if err := json.NewEncoder(w).Encode(models.CSRFTokenResponse{Token: token}); err != nil {
+			logger.FromContext(r.Context(), l).Error(common.EncRespErrStr, zap.Error(err))
+		}
+	}
+}
+
+func buildCSRFToken(userID string) string {
+	mac := hmac.New(sha256.New, []byte(config.Params.SecretKey))
+	mac.Write([]byte("csrf:" + userID))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func validCSRFToken(token string, expected string) bool {
+	return token != "" && hmac.Equal([]byte(token), []byte(expected))
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,232 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/config"
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/data/mock"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+)
+
+func TestCSRFMiddleware(t *testing.T) {
+	logger := zap.NewNop()
+	someHandler := func(w http.ResponseWriter, r *http.Request) {}
+	m := csrfMiddleware(logger)(http.HandlerFunc(someHandler))
+
+	config.Params.CSRFProtection = true
+	defer func() { config.Params.CSRFProtection = false }()
+
+	authToken, err := buildJWTString()
+	require.NoError(t, err)
+	csrfToken := buildCSRFToken(getUserID(authToken))
+
+	type request struct {
+		method     string
+		authCookie string
+		csrfCookie string
+		csrfHeader string
+	}
+	tests := []struct {
+		name    string
+		request request
+		code    int
+	}{
+		{
+			name:    "mutating request without auth cookie",
+			request: request{method: http.MethodPost},
+			code:    http.StatusOK,
+		},
+		{
+			name:    "safe request with auth cookie",
+			request: request{method: http.MethodGet, authCookie: authToken},
+			code:    http.StatusOK,
+		},
+		{
+			name:    "mutating request without csrf token",
+			request: request{method: http.MethodDelete, authCookie: authToken, csrfCookie: csrfToken},
+			code:    http.StatusForbidden,
+		},
+		{
+			name: "mutating request with foreign csrf token",
+			request: request{
+				method:     http.MethodPost,
+				authCookie: authToken,
+				csrfCookie: "forged",
+				csrfHeader: "forged",
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name: "mutating request with valid csrf token",
+			request: request{
+				method:     http.MethodDelete,
+				authCookie: authToken,
+				csrfCookie: csrfToken,
+				csrfHeader: csrfToken,
+			},
+			code: http.StatusOK,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(test.request.method, "/", http.NoBody)
+			if test.request.authCookie != "" {
+				request.AddCookie(&http.Cookie{Name: authCookieName, Value: test.request.authCookie})
+			}
+			if test.request.csrfCookie != "" {
+				request.AddCookie(&http.Cookie{Name: csrfCookieName, Value: test.request.csrfCookie})
+			}
+			if test.request.csrfHeader != "" {
+				request.Header.Set(csrfHeaderName, test.request.csrfHeader)
+			}
+
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, request)
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.Equal(t, test.code, res.StatusCode)
+		})
+	}
+}
+
+func TestCSRF_BrowserFlow(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	logger := zap.NewNop()
+	storage := mock.NewMockStorager(mockCtrl)
+
+	params := config.Params
+	config.Params.CSRFProtection = true
+	config.Params.CookiePath = "/"
+	config.Params.CookieMaxAge = time.Hour
+	config.Params.CookieSameSite = http.SameSiteStrictMode
+	config.Params.CreateRPS = 0
+	defer func() { config.Params = params }()
+
+	srv := httptest.NewServer(newTestRouter(t, logger, storage))
+	defer srv.Close()
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	client := srv.Client()
+	client.Jar = jar
+	srvURL, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	storage.EXPECT().StoreShortURL(gomock.Any(), gomock.Any(), "https://ya.ru").Times(1).Return(nil)
+	storage.EXPECT().DeleteShortURLs(gomock.Any(), []string{}).Times(1).Return(nil)
+
+	t.Run("first visit issues hardened cookies", func(t *testing.T) {
+		res, err := client.Post(srv.URL, "text/plain", strings.NewReader("https://ya.ru"))
+		require.NoError(t, err)
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+		cookies := map[string]*http.Cookie{}
+		for _, c := range res.Cookies() {
+			cookies[c.Name] = c
+		}
+
+		require.Contains(t, cookies, authCookieName)
+		require.Contains(t, cookies, csrfCookieName)
+		assert.True(t, cookies[authCookieName].HttpOnly)
+		assert.False(t, cookies[csrfCookieName].HttpOnly)
+		assert.Equal(t, "/", cookies[authCookieName].Path)
+		assert.Equal(t, 3600, cookies[authCookieName].MaxAge)
+		assert.Equal(t, http.SameSiteStrictMode, cookies[authCookieName].SameSite)
+	})
+
+	deleteRequest := func(t *testing.T, csrfToken string) *http.Response {
+		t.Helper()
+
+		req, err := http.NewRequest(http.MethodDelete, srv.URL+"/api/user/urls", strings.NewReader("[]"))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if csrfToken != "" {
+			req.Header.Set(csrfHeaderName, csrfToken)
+		}
+
+		res, err := client.Do(req)
+		require.NoError(t, err)
+
+		return res
+	}
+
+	t.Run("cross-site request without token rejected", func(t *testing.T) {
+		res := deleteRequest(t, "")
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+
+	t.Run("request with token from cookie accepted", func(t *testing.T) {
+		var csrfToken string
+		for _, c := range jar.Cookies(srvURL) {
+			if c.Name == csrfCookieName {
+				csrfToken = c.Value
+			}
+		}
+		require.NotEmpty(t, csrfToken)
+
+		res := deleteRequest(t, csrfToken)
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusAccepted, res.StatusCode)
+	})
+}
+
+func TestCSRFTokenHandler(t *testing.T) {
+	params := config.Params
+	config.Params.CookiePath = "/"
+	defer func() { config.Params = params }()
+
+	r := newTestRouter(t, zap.NewNop(), data.NewBaseStorage())
+
+	t.Run("without auth", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/user/csrf", http.NoBody))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("issues user token", func(t *testing.T) {
+		authToken, err := buildJWTString()
+		require.NoError(t, err)
+
+		request := httptest.NewRequest(http.MethodGet, "/api/user/csrf", http.NoBody)
+		request.AddCookie(&http.Cookie{Name: authCookieName, Value: authToken})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, request)
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "no-store", res.Header.Get("Cache-Control"))
+
+		var got models.CSRFTokenResponse
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+		assert.Equal(t, buildCSRFToken(getUserID(authToken)), got.Token)
+
+		require.Len(t, res.Cookies(), 1)
+		assert.Equal(t, csrfCookieName, res.Cookies()[0].Name)
+		assert.Equal(t, got.Token, res.Cookies()[0].Value)
+	})
+}
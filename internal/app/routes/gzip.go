@@ -11,37 +11,62 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/logger"
 )
 
 const maxStatusCode = 300
 
+// compressWriter сжимает только успешные ответы с телом, ответы с ошибкой и без тела отправляются как есть,
+// поэтому заголовок Content-Encoding всегда соответствует телу.
 type compressWriter struct {
 	http.ResponseWriter
-	zw *gzip.Writer
+	zw          *gzip.Writer
+	wroteHeader bool
 }
 
 func newCompressWriter(w http.ResponseWriter) *compressWriter {
 	return &compressWriter{
 		ResponseWriter: w,
-		zw:             gzip.NewWriter(w),
 	}
 }
 
 // Write переопределенние оригинального метода.
+// Если обработчик не выставил код ответа, ответ сжимается как для 200.
 func (c *compressWriter) Write(p []byte) (int, error) {
+	if !c.wroteHeader {
+		c.WriteHeader(http.StatusOK)
+	}
+
+	if c.zw == nil {
+		return c.ResponseWriter.Write(p) //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+	}
+
 	return c.zw.Write(p) //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
 }
 
 // WriteHeader переопределенние оригинального метода.
+// Длина, выставленная обработчиком, относится к несжатому телу, поэтому для сжатого ответа удаляется.
 func (c *compressWriter) WriteHeader(statusCode int) {
-	if statusCode < maxStatusCode {
+	if c.wroteHeader {
+		return
+	}
+	c.wroteHeader = true
+
+	if statusCode >= http.StatusOK && statusCode < maxStatusCode && statusCode != http.StatusNoContent {
+		c.ResponseWriter.Header().Del("Content-Length")
 		c.ResponseWriter.Header().Set("Content-Encoding", "gzip")
+		c.zw = gzip.NewWriter(c.ResponseWriter)
 	}
+	c.ResponseWriter.Header().Add("Vary", "Accept-Encoding")
 	c.ResponseWriter.WriteHeader(statusCode)
 }
 
 // Close переопределенние оригинального метода.
 func (c *compressWriter) Close() error {
+	if c.zw == nil {
+		return nil
+	}
+
 	return c.zw.Close() //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
 }
 
@@ -91,7 +116,7 @@ func gzipMiddleware(l *zap.Logger) func(next http.Handler) http.Handler {
 					err := cw.Close()
 
 					if err != nil {
-						l.Error("failed to close compress writer", zap.Error(err))
+						logger.FromContext(r.Context(), l).Error("failed to close compress writer", zap.Error(err))
 					}
 				}()
 			}
@@ -101,14 +126,15 @@ func gzipMiddleware(l *zap.Logger) func(next http.Handler) http.Handler {
 			if sendsGzip {
 				contentType := r.Header.Get(common.ContentTypeHeader)
 				if !(strings.Contains(contentType, common.JSONContentType) || strings.Contains(contentType, "text/html")) {
-					l.Warn("content encoding for bad content type", zap.String("content_type", contentType))
+					logger.FromContext(r.Context(), l).Warn("content encoding for bad content type",
+						zap.String("content_type", contentType))
 				}
 
 				cr, err := newCompressReader(r.Body, l)
 				if err != nil {
 					log.Print(err)
 					w.WriteHeader(http.StatusInternalServerError)
-					l.Error("failed to create compress reader", zap.Error(err))
+					logger.FromContext(r.Context(), l).Error("failed to create compress reader", zap.Error(err))
 					return
 				}
 
@@ -117,7 +143,7 @@ func gzipMiddleware(l *zap.Logger) func(next http.Handler) http.Handler {
 					err := cr.Close()
 
 					if err != nil {
-						l.Error("failed to close compress reader", zap.Error(err))
+						logger.FromContext(r.Context(), l).Error("failed to close compress reader", zap.Error(err))
 					}
 				}()
 			}
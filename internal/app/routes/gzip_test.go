@@ -3,8 +3,10 @@ package routes
 import (
 	"bytes"
 	"compress/gzip"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -36,3 +38,68 @@ func TestGzipMiddleware(t *testing.T) {
 
 	assert.Equal(t, 200, res.StatusCode)
 }
+
+func TestGzipMiddleware_ContentLength(t *testing.T) {
+	body := []byte(`{"result":"some data"}`)
+	someHandler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		_, _ = w.Write(body)
+	}
+
+	server := httptest.NewServer(gzipMiddleware(zap.NewNop())(http.HandlerFunc(someHandler)))
+	defer server.Close()
+
+	res, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer closeBody(t, res)
+
+	got, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.True(t, res.Uncompressed)
+	assert.Equal(t, body, got)
+}
+
+func TestGzipMiddleware_NotCompressed(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		code    int
+		body    string
+	}{
+		{
+			name: "error response",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "bad request", http.StatusBadRequest)
+			},
+			code: http.StatusBadRequest,
+			body: "bad request\n",
+		},
+		{
+			name: "empty response",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			},
+			code: http.StatusNoContent,
+		},
+		{
+			name:    "handler without response",
+			handler: func(w http.ResponseWriter, r *http.Request) {},
+			code:    http.StatusOK,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			request.Header.Set("Accept-Encoding", "gzip")
+			w := httptest.NewRecorder()
+			gzipMiddleware(zap.NewNop())(test.handler).ServeHTTP(w, request)
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.Equal(t, test.code, res.StatusCode)
+			assert.Empty(t, res.Header.Get("Content-Encoding"))
+			assert.Equal(t, test.body, w.Body.String())
+		})
+	}
+}
@@ -1,10 +1,15 @@
 package routes
 
 import (
+	"context"
 	"net/http"
 	"time"
 
 	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/config"
+	"github.com/MihailSergeenkov/shortener/internal/app/logger"
 )
 
 const defaultStatus = 200
@@ -19,6 +24,15 @@ type (
 		http.ResponseWriter
 		responseData *responseData
 	}
+
+	// requestLog данные для журнала запросов, которые определяются ниже по цепочке middleware:
+	// пользователь запроса и признак выборочной записи.
+	requestLog struct {
+		userID  string
+		sampled bool
+	}
+
+	requestLogKey struct{}
 )
 
 // Write переопределенние оригинального метода.
@@ -35,7 +49,16 @@ func (r *loggingResponseWriter) WriteHeader(statusCode int) {
 	r.responseData.status = statusCode
 }
 
+// Unwrap возвращает оригинальный ResponseWriter, чтобы http.ResponseController мог отправить
+// данные потока клиенту до завершения обработчика.
+func (r *loggingResponseWriter) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// withRequestLogging пишет запросы в журнал, запросы с sampledRequestLog проходят выборку.
 func withRequestLogging(l *zap.Logger) func(next http.Handler) http.Handler {
+	sampled := logger.Sampled(l, config.Params.LogSampleFirst, config.Params.LogSampleEvery)
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			responseData := &responseData{
@@ -46,16 +69,27 @@ func withRequestLogging(l *zap.Logger) func(next http.Handler) http.Handler {
 				ResponseWriter: w,
 				responseData:   responseData,
 			}
+			state := &requestLog{}
 
 			start := time.Now()
 			uri := r.RequestURI
 			method := r.Method
 
-			next.ServeHTTP(&lw, r)
+			next.ServeHTTP(&lw, r.WithContext(context.WithValue(r.Context(), requestLogKey{}, state)))
 
 			duration := time.Since(start)
 
-			l.Info("got incoming HTTP request",
+			ctx := r.Context()
+			if state.userID != "" {
+				ctx = context.WithValue(ctx, common.KeyUserID, state.userID)
+			}
+
+			rl := l
+			if state.sampled {
+				rl = sampled
+			}
+
+			logger.FromContext(ctx, rl).Info("got incoming HTTP request",
 				zap.String("uri", uri),
 				zap.String("method", method),
 				zap.String("duration", duration.String()),
@@ -65,3 +99,23 @@ func withRequestLogging(l *zap.Logger) func(next http.Handler) http.Handler {
 		})
 	}
 }
+
+// withUser возвращает запрос с пользователем в контексте и запоминает пользователя для журнала запросов.
+func withUser(r *http.Request, userID string) *http.Request {
+	if state, ok := r.Context().Value(requestLogKey{}).(*requestLog); ok {
+		state.userID = userID
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), common.KeyUserID, userID))
+}
+
+// sampledRequestLog отмечает запрос для выборочной записи в журнал, используется для частых переходов по ссылкам.
+func sampledRequestLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if state, ok := r.Context().Value(requestLogKey{}).(*requestLog); ok {
+			state.sampled = true
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -7,6 +7,10 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/config"
 )
 
 func TestWithRequestLogging(t *testing.T) {
@@ -23,3 +27,45 @@ func TestWithRequestLogging(t *testing.T) {
 
 	assert.Equal(t, 200, res.StatusCode)
 }
+
+func TestSampledRequestLog(t *testing.T) {
+	first, every := config.Params.LogSampleFirst, config.Params.LogSampleEvery
+	config.Params.LogSampleFirst, config.Params.LogSampleEvery = 1, 100
+	defer func() { config.Params.LogSampleFirst, config.Params.LogSampleEvery = first, every }()
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	someHandler := func(w http.ResponseWriter, r *http.Request) {}
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		want    int
+	}{
+		{name: "sample redirect logs", handler: sampledRequestLog(http.HandlerFunc(someHandler)), want: 1},
+		{name: "log other requests", handler: http.HandlerFunc(someHandler), want: 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := withRequestLogging(zap.New(core))(test.handler)
+
+			for range 3 {
+				m.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+			}
+
+			assert.Len(t, logs.TakeAll(), test.want)
+		})
+	}
+}
+
+func TestWithRequestLogging_Flush(t *testing.T) {
+	someHandler := func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, http.NewResponseController(w).Flush())
+	}
+
+	w := httptest.NewRecorder()
+	m := withRequestLogging(zap.NewNop())(http.HandlerFunc(someHandler))
+	m.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	assert.True(t, w.Flushed, "flush reaches original response writer")
+}
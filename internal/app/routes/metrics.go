@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/metrics"
+)
+
+// withMetrics учитывает запрос по шаблону маршрута chi, известному только после обработки запроса,
+// так что число меток не зависит от значений параметров в пути.
+func withMetrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		responseData := &responseData{status: defaultStatus}
+		lw := loggingResponseWriter{
+			ResponseWriter: w,
+			responseData:   responseData,
+		}
+
+		start := time.Now()
+		next.ServeHTTP(&lw, r)
+
+		route := ""
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			route = rctx.RoutePattern()
+		}
+
+		metrics.ObserveHTTP(route, r.Method, responseData.status, time.Since(start))
+	})
+}
@@ -0,0 +1,39 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/metrics"
+)
+
+func TestWithMetrics(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(withMetrics)
+	r.Route("/api/orgs", func(r chi.Router) {
+		r.Get("/{orgID}/members", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})
+	})
+
+	for _, path := range []string{"/api/orgs/first/members", "/api/orgs/second/members", "/missing"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, http.NoBody))
+	}
+
+	h := httptest.NewRecorder()
+	metrics.Handler(zap.NewNop(), data.NewBaseStorage()).ServeHTTP(h, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+	require.Equal(t, http.StatusOK, h.Code)
+
+	body := h.Body.String()
+	assert.Contains(t, body, `shortener_http_requests_total{code="204",method="GET",route="/api/orgs/{orgID}/members"} 2`)
+	assert.Contains(t, body, `shortener_http_requests_total{code="404",method="GET",route="unknown"} 1`)
+	assert.NotContains(t, body, "first")
+}
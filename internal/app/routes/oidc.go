@@ -0,0 +1,167 @@
+package routes
+
+import (
+	"crypto/hmac"
+	"encoding/json"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/config"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+	"github.com/MihailSergeenkov/shortener/internal/app/oidc"
+)
+
+const (
+	oidcStateCookieName = "OIDC_STATE"
+	oidcCallbackPath    = "/auth/oidc/callback"
+	oidcStateTTL        = 10 * time.Minute
+)
+
+// oidcLogin обработчики входа через OIDC провайдера.
+// Провайдер инициализируется при первом обращении, чтобы недоступность провайдера не мешала старту сервиса.
+type oidcLogin struct {
+	logger *zap.Logger
+	auth   *oidc.Authenticator
+	cfg    oidc.Config
+	mu     sync.Mutex
+}
+
+func newOIDCLogin(l *zap.Logger) *oidcLogin {
+	redirectURL := config.Params.OIDCRedirectURL
+	if redirectURL == "" {
+		baseURL := config.Params.BaseURL
+		baseURL.Path = path.Join(baseURL.Path, oidcCallbackPath)
+		redirectURL = baseURL.String()
+	}
+
+	return &oidcLogin{
+		logger: l,
+		cfg: oidc.Config{
+			Issuer:       config.Params.OIDCIssuer,
+			ClientID:     config.Params.OIDCClientID,
+			ClientSecret: config.Params.OIDCSecret,
+			RedirectURL:  redirectURL,
+		},
+	}
+}
+
+func (o *oidcLogin) authenticator(r *http.Request) (*oidc.Authenticator, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.auth != nil {
+		return o.auth, nil
+	}
+
+	auth, err := oidc.NewAuthenticator(r.Context(), o.cfg)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // Ошибка уже обернута
+	}
+
+	o.auth = auth
+
+	return auth, nil
+}
+
+func (o *oidcLogin) loginHandler(w http.ResponseWriter, r *http.Request) {
+	auth, err := o.authenticator(r)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		o.logger.Error("failed to init OIDC provider", zap.Error(err))
+		return
+	}
+
+	state, err := generateUserID()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		o.logger.Error("failed to generate OIDC state", zap.Error(err))
+		return
+	}
+
+	nonce, err := generateUserID()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		o.logger.Error("failed to generate OIDC nonce", zap.Error(err))
+		return
+	}
+
+	http.SetCookie(w, newOIDCStateCookie(state+"."+nonce, int(oidcStateTTL.Seconds())))
+
+	http.Redirect(w, r, auth.AuthCodeURL(state, nonce), http.StatusFound)
+}
+
+func (o *oidcLogin) callbackHandler(w http.ResponseWriter, r *http.Request) {
+	stateCookie, err := r.Cookie(oidcStateCookieName)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		o.logger.Error("failed to fetch OIDC state cookie", zap.Error(err))
+		return
+	}
+
+	http.SetCookie(w, newOIDCStateCookie("", -1))
+
+	state, nonce, _ := strings.Cut(stateCookie.Value, ".")
+	query := r.URL.Query()
+
+	if state == "" || !hmac.Equal([]byte(state), []byte(query.Get("state"))) {
+		w.WriteHeader(http.StatusBadRequest)
+		o.logger.Error("OIDC state mismatch")
+		return
+	}
+
+	if providerErr := query.Get("error"); providerErr != "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		o.logger.Error("OIDC provider denied login", zap.String("error", providerErr))
+		return
+	}
+
+	auth, err := o.authenticator(r)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		o.logger.Error("failed to init OIDC provider", zap.Error(err))
+		return
+	}
+
+	subject, err := auth.Exchange(r.Context(), query.Get("code"), nonce)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		o.logger.Error("failed to complete OIDC login", zap.Error(err))
+		return
+	}
+
+	userID := auth.UserID(subject)
+	authToken, err := buildUserJWTString(userID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		o.logger.Error("failed to build auth token", zap.Error(err))
+		return
+	}
+
+	writeAuthCookie(w, authToken)
+
+	w.Header().Set(common.ContentTypeHeader, common.JSONContentType)
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(models.LoginResponse{UserID: userID}); err != nil {
+		o.logger.Error(common.EncRespErrStr, zap.Error(err))
+		return
+	}
+}
+
+// newOIDCStateCookie создает cookie состояния входа, доступную только обработчику возврата от провайдера.
+// SameSite=Lax нужен, чтобы cookie передавалась при перенаправлении с сайта провайдера.
+func newOIDCStateCookie(value string, maxAge int) *http.Cookie {
+	cookie := newCookie(oidcStateCookieName, value, true)
+	cookie.Path = oidcCallbackPath
+	cookie.MaxAge = maxAge
+	cookie.SameSite = http.SameSiteLaxMode
+
+	return cookie
+}
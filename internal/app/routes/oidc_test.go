@@ -0,0 +1,162 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/config"
+	"github.com/MihailSergeenkov/shortener/internal/app/data/mock"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+	"github.com/MihailSergeenkov/shortener/internal/app/oidc/oidctest"
+)
+
+func TestOIDCLogin(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	logger := zap.NewNop()
+	storage := mock.NewMockStorager(mockCtrl)
+
+	provider, err := oidctest.NewProvider("shortener", "secret", "alice")
+	require.NoError(t, err)
+	defer provider.Close()
+
+	srv := httptest.NewUnstartedServer(nil)
+
+	params := config.Params
+	config.Params.OIDCIssuer = provider.Issuer()
+	config.Params.OIDCClientID = "shortener"
+	config.Params.OIDCSecret = "secret"
+	config.Params.OIDCRedirectURL = "http://" + srv.Listener.Addr().String() + oidcCallbackPath
+	defer func() { config.Params = params }()
+
+	srv.Config.Handler = newTestRouter(t, logger, storage)
+	srv.Start()
+	defer srv.Close()
+
+	login := func(t *testing.T) (models.LoginResponse, *http.Cookie) {
+		t.Helper()
+
+		jar, err := cookiejar.New(nil)
+		require.NoError(t, err)
+		client := &http.Client{Jar: jar}
+
+		res, err := client.Get(srv.URL + "/auth/oidc/login")
+		require.NoError(t, err)
+		defer closeBody(t, res)
+
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var resp models.LoginResponse
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&resp))
+
+		var authCookie *http.Cookie
+		for _, c := range res.Cookies() {
+			if c.Name == authCookieName {
+				authCookie = c
+			}
+		}
+		require.NotNil(t, authCookie)
+
+		return resp, authCookie
+	}
+
+	t.Run("login issues auth token for provider subject", func(t *testing.T) {
+		resp, cookie := login(t)
+
+		assert.NotEmpty(t, resp.UserID)
+		assert.Equal(t, resp.UserID, getUserID(cookie.Value))
+		assert.True(t, cookie.HttpOnly)
+	})
+
+	t.Run("same subject maps to same user", func(t *testing.T) {
+		first, _ := login(t)
+		second, _ := login(t)
+
+		assert.Equal(t, first.UserID, second.UserID)
+	})
+
+	t.Run("different subject maps to different user", func(t *testing.T) {
+		first, _ := login(t)
+
+		provider.SetSubject("bob")
+		defer provider.SetSubject("alice")
+
+		second, _ := login(t)
+
+		assert.NotEqual(t, first.UserID, second.UserID)
+	})
+
+	t.Run("callback without state cookie", func(t *testing.T) {
+		res, err := http.Get(srv.URL + oidcCallbackPath + "?state=abc&code=def")
+		require.NoError(t, err)
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("callback with foreign state", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+oidcCallbackPath+"?state=forged&code=def", http.NoBody)
+		require.NoError(t, err)
+		req.AddCookie(&http.Cookie{Name: oidcStateCookieName, Value: "state.nonce"})
+
+		config.Params.CookieSecure = true
+		defer func() { config.Params.CookieSecure = false }()
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+		cookies := res.Cookies()
+		require.Len(t, cookies, 1)
+		cleared := cookies[0]
+		assert.Equal(t, oidcStateCookieName, cleared.Name)
+		assert.Equal(t, oidcCallbackPath, cleared.Path)
+		assert.Negative(t, cleared.MaxAge)
+		assert.True(t, cleared.HttpOnly, "cleared state cookie keeps login attributes")
+		assert.Equal(t, http.SameSiteLaxMode, cleared.SameSite)
+		assert.True(t, cleared.Secure)
+	})
+
+	t.Run("callback with unknown code", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+oidcCallbackPath+"?state=state&code=def", http.NoBody)
+		require.NoError(t, err)
+		req.AddCookie(&http.Cookie{Name: oidcStateCookieName, Value: "state.nonce"})
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+}
+
+func TestOIDCLogin_Disabled(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	storage := mock.NewMockStorager(mockCtrl)
+
+	params := config.Params
+	config.Params.OIDCIssuer = ""
+	defer func() { config.Params = params }()
+
+	srv := httptest.NewServer(newTestRouter(t, zap.NewNop(), storage))
+	defer srv.Close()
+
+	res, err := srv.Client().Get(srv.URL + oidcCallbackPath)
+	require.NoError(t, err)
+	defer closeBody(t, res)
+
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
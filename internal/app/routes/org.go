@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+)
+
+const orgHeaderName = "X-Org-ID"
+
+// withOrg middleware выбора организации, от имени которой выполняется запрос.
+// Права пользователя в организации проверяются на уровне бизнес-логики.
+func withOrg(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		orgID := strings.TrimSpace(r.Header.Get(orgHeaderName))
+		if orgID == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), common.KeyOrgID, orgID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+
+	return http.HandlerFunc(fn)
+}
@@ -0,0 +1,51 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+)
+
+func TestWithOrg(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   any
+	}{
+		{
+			name:   "without org",
+			header: "",
+			want:   nil,
+		},
+		{
+			name:   "with org",
+			header: " org_id ",
+			want:   "org_id",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got any
+			someHandler := func(w http.ResponseWriter, r *http.Request) {
+				got = r.Context().Value(common.KeyOrgID)
+			}
+
+			request := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			if test.header != "" {
+				request.Header.Set(orgHeaderName, test.header)
+			}
+			w := httptest.NewRecorder()
+			withOrg(http.HandlerFunc(someHandler)).ServeHTTP(w, request)
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/logger"
+	"github.com/MihailSergeenkov/shortener/internal/app/ratelimit"
+)
+
+func rateLimitMiddleware(l *zap.Logger, limiter *ratelimit.Limiter) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := ratelimit.Key(r.Context())
+
+			ok, retryAfter := limiter.Allow(key)
+			if !ok {
+				w.Header().Set("Retry-After", ratelimit.RetryAfter(retryAfter))
+				w.WriteHeader(http.StatusTooManyRequests)
+				logger.FromContext(r.Context(), l).Warn("rate limit exceeded", zap.String("key", key))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/ratelimit"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	logger := zap.NewNop()
+	someHandler := func(w http.ResponseWriter, r *http.Request) {}
+	m := rateLimitMiddleware(logger, ratelimit.NewLimiter(1, 1))(http.HandlerFunc(someHandler))
+
+	tests := []struct {
+		name       string
+		code       int
+		retryAfter string
+	}{
+		{
+			name:       "first request allowed",
+			code:       http.StatusOK,
+			retryAfter: "",
+		},
+		{
+			name:       "second request limited",
+			code:       http.StatusTooManyRequests,
+			retryAfter: "1",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/", http.NoBody)
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, request)
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.Equal(t, test.code, res.StatusCode)
+			assert.Equal(t, test.retryAfter, res.Header.Get("Retry-After"))
+		})
+	}
+}
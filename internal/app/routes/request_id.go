@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/logger"
+	"github.com/MihailSergeenkov/shortener/internal/app/requestid"
+)
+
+// withRequestID принимает идентификатор запроса из заголовка X-Request-ID или генерирует новый,
+// возвращает его в ответе и добавляет в контекст вместе с логгером запроса.
+func withRequestID(l *zap.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := requestid.Resolve(r.Header.Get(requestid.Header))
+			w.Header().Set(requestid.Header, id)
+
+			ctx := logger.NewContext(requestid.NewContext(r.Context(), id), l)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
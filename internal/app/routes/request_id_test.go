@@ -0,0 +1,51 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/requestid"
+)
+
+func TestWithRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{name: "keep incoming id", incoming: "client-request-1", keep: true},
+		{name: "generate id without header", incoming: "", keep: false},
+		{name: "replace invalid id", incoming: "bad id", keep: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got string
+			someHandler := func(w http.ResponseWriter, r *http.Request) {
+				got = requestid.FromContext(r.Context())
+			}
+
+			request := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			if test.incoming != "" {
+				request.Header.Set(requestid.Header, test.incoming)
+			}
+			w := httptest.NewRecorder()
+			withRequestID(zap.NewNop())(http.HandlerFunc(someHandler)).ServeHTTP(w, request)
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.NotEmpty(t, got)
+			assert.Equal(t, got, res.Header.Get(requestid.Header))
+			if test.keep {
+				assert.Equal(t, test.incoming, got)
+			} else {
+				assert.NotEqual(t, test.incoming, got)
+			}
+		})
+	}
+}
@@ -2,31 +2,60 @@
 package routes
 
 import (
+	"fmt"
+	"net/http"
+
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"go.uber.org/zap"
 
+	"github.com/MihailSergeenkov/shortener/internal/app/clientip"
 	"github.com/MihailSergeenkov/shortener/internal/app/common"
 	"github.com/MihailSergeenkov/shortener/internal/app/config"
 	"github.com/MihailSergeenkov/shortener/internal/app/data"
 	"github.com/MihailSergeenkov/shortener/internal/app/handlers"
+	"github.com/MihailSergeenkov/shortener/internal/app/logger"
+	"github.com/MihailSergeenkov/shortener/internal/app/metrics"
+	"github.com/MihailSergeenkov/shortener/internal/app/proto"
+	"github.com/MihailSergeenkov/shortener/internal/app/ratelimit"
 )
 
 // NewRouter функция инициализации роутинга.
-func NewRouter(l *zap.Logger, s data.Storager) chi.Router {
+func NewRouter(l *zap.Logger, s data.Storager) (chi.Router, error) {
 	r := chi.NewRouter()
-	r.Use(withRequestLogging(l))
-	r.Mount("/debug", middleware.Profiler())
+	r.Use(
+		withClientIP(clientip.NewResolver(config.Params.TrustedProxies)), withAuditTransport,
+		withRequestID(l), withTracing, withRequestLogging(l), withMetrics,
+	)
+
+	// Метрики отдаются здесь, только если для них не задан отдельный адрес.
+	if config.Params.MetricsAddr == "" {
+		r.With(checkSubnetMiddleware(l, config.Params.TrustedSubnet)).Handle("/metrics", metrics.Handler(l, s))
+	}
+	r.With(checkSubnetMiddleware(l, config.Params.TrustedSubnet)).Handle("/log/level", logger.LevelHandler(l))
 
 	r.Get("/ping", handlers.PingHandler(l, s))
+	// Отчет по компонентам получают только клиенты из доверенной подсети, остальным - только статус.
+	trustedClient := func(req *http.Request) bool { return isTrustedClient(l, req, config.Params.TrustedSubnet) }
+	r.Get("/healthz", handlers.HealthzHandler(l, trustedClient))
+	r.Get("/readyz", handlers.ReadyzHandler(l, trustedClient))
+
+	if config.Params.OIDCIssuer != "" {
+		login := newOIDCLogin(l)
+		r.Get("/auth/oidc/login", login.loginHandler)
+		r.Get(oidcCallbackPath, login.callbackHandler)
+	}
+
+	createLimit := rateLimitMiddleware(l, ratelimit.NewLimiter(config.Params.CreateRPS, config.Params.CreateBurst))
+	redirectLimit := rateLimitMiddleware(l, ratelimit.NewLimiter(config.Params.RedirectRPS, config.Params.RedirectBurst))
 
 	r.Route("/", func(r chi.Router) {
-		r.Use(setAuthMiddleware(l), gzipMiddleware(l))
-		r.Post("/", handlers.AddHandler(l, s))
-		r.Get("/{id}", handlers.FetchHandler(l, s))
+		r.Use(setAuthMiddleware(l), csrfMiddleware(l), withOrg, gzipMiddleware(l))
+		r.With(createLimit).Post("/", handlers.AddHandler(l, s))
+		r.With(redirectLimit, sampledRequestLog).Get("/{id}", handlers.FetchHandler(l, s))
 
 		r.Group(func(r chi.Router) {
-			r.Use(middleware.AllowContentType(common.JSONContentType))
+			r.Use(middleware.AllowContentType(common.JSONContentType), createLimit)
 
 			r.Route("/api", func(r chi.Router) {
 				r.Route("/shorten", func(r chi.Router) {
@@ -38,19 +67,76 @@ func NewRouter(l *zap.Logger, s data.Storager) chi.Router {
 	})
 
 	r.Group(func(r chi.Router) {
-		r.Use(middleware.AllowContentType(common.JSONContentType), checkAuthMiddleware(l))
+		r.Use(
+			middleware.AllowContentType(common.JSONContentType, common.CSVContentType, common.NDJSONContentType),
+			checkAuthMiddleware(l), csrfMiddleware(l), withOrg, gzipMiddleware(l),
+		)
+
+		r.Get("/api/user/urls/export", handlers.APIExportUserURLsHandler(l, s))
+		r.With(createLimit).Post("/api/user/urls/import", handlers.APIImportUserURLsHandler(l, s))
+	})
+
+	r.With(checkAuthMiddleware(l)).Get("/api/user/csrf", csrfTokenHandler(l))
+
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.AllowContentType(common.JSONContentType), checkAuthMiddleware(l), csrfMiddleware(l), withOrg)
 
 		r.Route("/api/user/urls", func(r chi.Router) {
 			r.Get("/", handlers.APIFetchUserURLsHandler(l, s))
 			r.Delete("/", handlers.APIDeleteUserURLsHandler(l, s))
 		})
+
+		r.Route("/api/orgs", func(r chi.Router) {
+			r.Get("/", handlers.APIFetchUserOrgsHandler(l, s))
+			r.Post("/", handlers.APIAddOrgHandler(l, s))
+			r.Get("/{orgID}/members", handlers.APIFetchOrgMembersHandler(l, s))
+			r.Put("/{orgID}/members", handlers.APISetOrgMemberHandler(l, s))
+			r.Delete("/{orgID}/members/{userID}", handlers.APIDeleteOrgMemberHandler(l, s))
+		})
+
+		// Поток событий отдается без сжатия, чтобы события не задерживались в буфере gzip.
+		r.Get("/api/user/events", handlers.APIUserEventsHandler(l, config.Params.EventsHeartbeat))
+
+		r.Route("/api/user/webhooks", func(r chi.Router) {
+			r.Get("/", handlers.APIFetchUserWebhooksHandler(l, s))
+			r.Post("/", handlers.APIAddWebhookHandler(l, s))
+			r.Get("/dead-letters", handlers.APIFetchDeadWebhookDeliveriesHandler(l, s))
+			r.Put("/{webhookID}", handlers.APIUpdateWebhookHandler(l, s))
+			r.Delete("/{webhookID}", handlers.APIDeleteWebhookHandler(l, s))
+		})
 	})
 
 	r.Group(func(r chi.Router) {
 		r.Use(middleware.AllowContentType(common.JSONContentType), checkSubnetMiddleware(l, config.Params.TrustedSubnet))
 
 		r.Get("/api/internal/stats", handlers.APIFetchStatsHandler(l, s))
+		r.Get("/api/internal/audit", handlers.APIFetchAuditEventsHandler(l, s))
+	})
+
+	gateway, err := proto.NewGateway(l, s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init REST gateway: %w", err)
+	}
+
+	// REST шлюз gRPC методов, маршруты /api остаются без изменений.
+	r.Route("/v2/api", func(r chi.Router) {
+		r.Use(middleware.AllowContentType(common.JSONContentType), gzipMiddleware(l))
+		r.Get("/ping", gateway.ServeHTTP)
+		r.With(checkSubnetMiddleware(l, config.Params.TrustedSubnet)).Get("/internal/stats", gateway.ServeHTTP)
+
+		r.Group(func(r chi.Router) {
+			r.Use(setAuthMiddleware(l), csrfMiddleware(l), withOrg)
+			r.With(createLimit).Post("/shorten", gateway.ServeHTTP)
+			r.With(createLimit).Post("/shorten/batch", gateway.ServeHTTP)
+			r.With(redirectLimit, sampledRequestLog).Get("/urls/{id}", gateway.ServeHTTP)
+		})
+
+		// Остальные методы, в том числе новые, требуют авторизации.
+		r.Group(func(r chi.Router) {
+			r.Use(checkAuthMiddleware(l), csrfMiddleware(l), withOrg)
+			r.Handle("/*", gateway)
+		})
 	})
 
-	return r
+	return r, nil
 }
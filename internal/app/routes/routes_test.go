@@ -1,16 +1,35 @@
 package routes
 
 import (
+	"context"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
 
+	"github.com/MihailSergeenkov/shortener/internal/app/config"
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
 	"github.com/MihailSergeenkov/shortener/internal/app/data/mock"
+	"github.com/MihailSergeenkov/shortener/internal/app/health"
 	"github.com/go-chi/chi/v5"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
+// newTestRouter создает роутер и проверяет, что инициализация прошла без ошибок.
+func newTestRouter(t *testing.T, l *zap.Logger, s data.Storager) chi.Router {
+	t.Helper()
+
+	r, err := NewRouter(l, s)
+	require.NoError(t, err)
+
+	return r
+}
+
 func TestNewRouter(t *testing.T) {
 	t.Run("init router", func(t *testing.T) {
 		mockCtrl := gomock.NewController(t)
@@ -19,9 +38,177 @@ func TestNewRouter(t *testing.T) {
 		logger := zap.NewNop()
 		storage := mock.NewMockStorager(mockCtrl)
 
-		r := NewRouter(logger, storage)
+		r, err := NewRouter(logger, storage)
+		require.NoError(t, err)
 		assert.Implements(t, (*chi.Router)(nil), r)
 	})
+
+	t.Run("rest gateway", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		storage := mock.NewMockStorager(mockCtrl)
+		storage.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+
+		r := newTestRouter(t, zap.NewNop(), storage)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v2/api/ping", http.NoBody))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"text":"pong"}`, w.Body.String())
+
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v2/api/user/urls", http.NoBody))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("export and import require auth", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		r := newTestRouter(t, zap.NewNop(), mock.NewMockStorager(mockCtrl))
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/user/urls/export?format=csv", http.NoBody))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/user/urls/import", http.NoBody))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("metrics require trusted subnet", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		storage := mock.NewMockStorager(mockCtrl)
+		storage.EXPECT().FetchStats(gomock.Any()).Times(1).Return(3, 2, nil)
+
+		defer func(subnet *net.IPNet) { config.Params.TrustedSubnet = subnet }(config.Params.TrustedSubnet)
+		config.Params.TrustedSubnet = nil
+
+		r := newTestRouter(t, zap.NewNop(), storage)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		_, config.Params.TrustedSubnet, _ = net.ParseCIDR("192.0.2.0/24")
+		r = newTestRouter(t, zap.NewNop(), storage)
+
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "shortener_urls 3")
+		assert.Contains(t, w.Body.String(), `shortener_http_requests_total{code="403",method="GET",route="/metrics"}`)
+	})
+
+	t.Run("log level requires trusted subnet", func(t *testing.T) {
+		defer func(subnet *net.IPNet) { config.Params.TrustedSubnet = subnet }(config.Params.TrustedSubnet)
+		config.Params.TrustedSubnet = nil
+
+		r := newTestRouter(t, zap.NewNop(), data.NewBaseStorage())
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/log/level", strings.NewReader(`{"level":"debug"}`)))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("audit requires trusted subnet", func(t *testing.T) {
+		defer func(subnet *net.IPNet) { config.Params.TrustedSubnet = subnet }(config.Params.TrustedSubnet)
+		config.Params.TrustedSubnet = nil
+
+		r := newTestRouter(t, zap.NewNop(), data.NewBaseStorage())
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/internal/audit", http.NoBody))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("health details require trusted subnet", func(t *testing.T) {
+		health.RegisterReadiness("storage", func(context.Context) health.Result {
+			return health.OK(map[string]any{"type": "memory"})
+		})
+
+		defer func(subnet *net.IPNet) { config.Params.TrustedSubnet = subnet }(config.Params.TrustedSubnet)
+		config.Params.TrustedSubnet = nil
+
+		r := newTestRouter(t, zap.NewNop(), data.NewBaseStorage())
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", http.NoBody))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+
+		_, config.Params.TrustedSubnet, _ = net.ParseCIDR("192.0.2.0/24")
+		r = newTestRouter(t, zap.NewNop(), data.NewBaseStorage())
+
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", http.NoBody))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"status":"ok","components":{"storage":{"status":"ok","details":{"type":"memory"}}}}`,
+			w.Body.String())
+	})
+
+	t.Run("cookie only api clients pass with default settings", func(t *testing.T) {
+		params := config.Params
+		defer func() { config.Params = params }()
+		t.Setenv("CSRF_PROTECTION", "")
+		require.NoError(t, os.Unsetenv("CSRF_PROTECTION"))
+		require.NoError(t, config.Setup(false))
+
+		r := newTestRouter(t, zap.NewNop(), data.NewBaseStorage())
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("https://ya.ru/legacy/1")))
+		require.Equal(t, http.StatusCreated, w.Code)
+		cookies := w.Result().Cookies()
+
+		requests := []*http.Request{
+			httptest.NewRequest(http.MethodPost, "/", strings.NewReader("https://ya.ru/legacy/2")),
+			httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url":"https://ya.ru/legacy/3"}`)),
+			httptest.NewRequest(http.MethodDelete, "/api/user/urls", strings.NewReader(`["abc"]`)),
+		}
+		wantCodes := []int{http.StatusCreated, http.StatusCreated, http.StatusAccepted}
+		for i, request := range requests {
+			for _, cookie := range cookies {
+				request.AddCookie(cookie)
+			}
+			if request.URL.Path != "/" {
+				request.Header.Set("Content-Type", "application/json")
+			}
+
+			w = httptest.NewRecorder()
+			r.ServeHTTP(w, request)
+			assert.Equal(t, wantCodes[i], w.Code, request.Method+" "+request.URL.Path)
+		}
+	})
+
+	t.Run("cookieless requests are limited by ip", func(t *testing.T) {
+		params := config.Params
+		defer func() { config.Params = params }()
+		config.Params.CreateRPS, config.Params.CreateBurst = 1, 1
+		config.Params.DailyURLsQuota = 0
+
+		r := newTestRouter(t, zap.NewNop(), data.NewBaseStorage())
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("https://ya.ru/1")))
+		assert.Equal(t, http.StatusCreated, w.Code)
+		cookies := w.Result().Cookies()
+
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("https://ya.ru/2")))
+		assert.Equal(t, http.StatusTooManyRequests, w.Code, "new user id does not reset limit")
+
+		request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("https://ya.ru/3"))
+		for _, cookie := range cookies {
+			request.AddCookie(cookie)
+		}
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, request)
+		assert.Equal(t, http.StatusCreated, w.Code, "issued user id is limited separately")
+	})
 }
 
 func closeBody(t *testing.T, r *http.Response) {
@@ -5,26 +5,15 @@ import (
 	"net/http"
 
 	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/clientip"
+	"github.com/MihailSergeenkov/shortener/internal/app/logger"
 )
 
 func checkSubnetMiddleware(l *zap.Logger, trustedSubnet *net.IPNet) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if trustedSubnet == nil {
-				w.WriteHeader(http.StatusForbidden)
-				return
-			}
-
-			ipStr := r.Header.Get("X-Real-IP")
-			ip := net.ParseIP(ipStr)
-			if ip == nil {
-				w.WriteHeader(http.StatusInternalServerError)
-				l.Error("failed to parse ip address in header")
-				return
-			}
-
-			ok := trustedSubnet.Contains(ip)
-			if !ok {
+			if !isTrustedClient(l, r, trustedSubnet) {
 				w.WriteHeader(http.StatusForbidden)
 				return
 			}
@@ -32,3 +21,18 @@ func checkSubnetMiddleware(l *zap.Logger, trustedSubnet *net.IPNet) func(next ht
 		})
 	}
 }
+
+// isTrustedClient проверяет, что запрос пришел из доверенной подсети.
+func isTrustedClient(l *zap.Logger, r *http.Request, trustedSubnet *net.IPNet) bool {
+	if trustedSubnet == nil {
+		return false
+	}
+
+	ip := clientip.FromContext(r.Context())
+	if ip == nil {
+		logger.FromContext(r.Context(), l).Error("failed to resolve client ip address")
+		return false
+	}
+
+	return trustedSubnet.Contains(ip)
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/clientip"
 )
 
 func TestCheckSubnetMiddleware(t *testing.T) {
@@ -16,6 +18,8 @@ func TestCheckSubnetMiddleware(t *testing.T) {
 
 	_, trustedSubnet, err := net.ParseCIDR("192.168.1.0/24")
 	require.NoError(t, err)
+	_, trustedProxy, err := net.ParseCIDR("192.0.2.0/24")
+	require.NoError(t, err)
 
 	type want struct {
 		code int
@@ -23,12 +27,14 @@ func TestCheckSubnetMiddleware(t *testing.T) {
 	tests := []struct {
 		name          string
 		trustedSubnet *net.IPNet
+		trustedProxy  *net.IPNet
 		clientIP      string
 		want          want
 	}{
 		{
 			name:          "success check",
 			trustedSubnet: trustedSubnet,
+			trustedProxy:  trustedProxy,
 			clientIP:      "192.168.1.33",
 			want: want{
 				code: http.StatusOK,
@@ -37,6 +43,7 @@ func TestCheckSubnetMiddleware(t *testing.T) {
 		{
 			name:          "without trusted subnet",
 			trustedSubnet: nil,
+			trustedProxy:  trustedProxy,
 			clientIP:      "192.168.1.33",
 			want: want{
 				code: http.StatusForbidden,
@@ -45,19 +52,30 @@ func TestCheckSubnetMiddleware(t *testing.T) {
 		{
 			name:          "failed parse client IP",
 			trustedSubnet: trustedSubnet,
+			trustedProxy:  trustedProxy,
 			clientIP:      "some string",
 			want: want{
-				code: http.StatusInternalServerError,
+				code: http.StatusForbidden,
 			},
 		},
 		{
 			name:          "client IP not from trusted subnet",
 			trustedSubnet: trustedSubnet,
+			trustedProxy:  trustedProxy,
 			clientIP:      "192.168.100.33",
 			want: want{
 				code: http.StatusForbidden,
 			},
 		},
+		{
+			name:          "header from untrusted proxy",
+			trustedSubnet: trustedSubnet,
+			trustedProxy:  nil,
+			clientIP:      "192.168.1.33",
+			want: want{
+				code: http.StatusForbidden,
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -68,7 +86,8 @@ func TestCheckSubnetMiddleware(t *testing.T) {
 
 			w := httptest.NewRecorder()
 
-			m := checkSubnetMiddleware(logger, test.trustedSubnet)(http.HandlerFunc(someHandler))
+			resolver := clientip.NewResolver([]*net.IPNet{test.trustedProxy})
+			m := withClientIP(resolver)(checkSubnetMiddleware(logger, test.trustedSubnet)(http.HandlerFunc(someHandler)))
 			m.ServeHTTP(w, request)
 
 			res := w.Result()
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+	"github.com/MihailSergeenkov/shortener/internal/app/tracing"
+)
+
+// Ограничения выборки журнала аудита.
+const (
+	DefaultAuditLimit = 100  // число событий, если лимит не задан
+	MaxAuditLimit     = 1000 // максимальное число событий в одном ответе
+)
+
+// ErrInvalidAuditFilter ошибка фильтра журнала аудита.
+var ErrInvalidAuditFilter = errors.New("invalid audit filter")
+
+// FetchAuditEvents функция получения событий журнала аудита по фильтру, новые события первыми.
+// Лимит по умолчанию DefaultAuditLimit, больший MaxAuditLimit лимит уменьшается до него.
+func FetchAuditEvents(
+	ctx context.Context,
+	s data.Storager,
+	filter models.AuditFilter,
+) (_ []models.AuditEvent, err error) {
+	ctx, span := tracing.Start(ctx, "services.FetchAuditEvents")
+	defer tracing.End(span, &err)
+
+	switch {
+	case filter.Limit < 0:
+		return nil, fmt.Errorf("%w: negative limit", ErrInvalidAuditFilter)
+	case !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From):
+		return nil, fmt.Errorf("%w: to is before from", ErrInvalidAuditFilter)
+	case filter.Limit == 0:
+		filter.Limit = DefaultAuditLimit
+	}
+	filter.Limit = min(filter.Limit, MaxAuditLimit)
+
+	events, err := s.FetchAuditEvents(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch audit events: %w", err)
+	}
+
+	return events, nil
+}
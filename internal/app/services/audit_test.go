@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/data/mock"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+)
+
+func TestFetchAuditEvents(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	storage := mock.NewMockStorager(mockCtrl)
+	ctx := context.Background()
+	now := time.Now()
+
+	t.Run("limit defaults and capped", func(t *testing.T) {
+		storage.EXPECT().FetchAuditEvents(gomock.Any(), models.AuditFilter{Limit: DefaultAuditLimit}).Return(nil, nil)
+		storage.EXPECT().FetchAuditEvents(gomock.Any(), models.AuditFilter{Limit: MaxAuditLimit}).Return(nil, nil)
+
+		_, err := FetchAuditEvents(ctx, storage, models.AuditFilter{})
+		require.NoError(t, err)
+		_, err = FetchAuditEvents(ctx, storage, models.AuditFilter{Limit: MaxAuditLimit + 1})
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		_, err := FetchAuditEvents(ctx, storage, models.AuditFilter{Limit: -1})
+		require.ErrorIs(t, err, ErrInvalidAuditFilter)
+
+		_, err = FetchAuditEvents(ctx, storage, models.AuditFilter{From: now, To: now.Add(-time.Hour)})
+		require.ErrorIs(t, err, ErrInvalidAuditFilter)
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		storage.EXPECT().FetchAuditEvents(gomock.Any(), gomock.Any()).Return(nil, errors.New("some error"))
+
+		_, err := FetchAuditEvents(ctx, storage, models.AuditFilter{})
+		assert.ErrorContains(t, err, "failed to fetch audit events")
+	})
+}
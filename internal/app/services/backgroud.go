@@ -2,17 +2,39 @@ package services
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/health"
 )
 
+// staleJobPeriods число пропущенных периодов, после которого фоновая задача считается зависшей.
+const staleJobPeriods = 3
+
+var errStaleJob = errors.New("background job has not run for too long")
+
+// jobState состояние фоновой задачи для проверки живости сервиса.
+var jobState struct {
+	started time.Time
+	lastRun time.Time
+	lastErr error
+	mu      sync.Mutex
+}
+
 // BackgroundJob функция запуска отложенных задач сервиса (очистка из БД удаленных ссылкок).
 func BackgroundJob(ctx context.Context, l *zap.Logger, s data.Storager, dropPeriod time.Duration) {
 	ticker := time.NewTicker(dropPeriod)
 
+	setJobState(func() {
+		jobState.started = time.Now()
+		jobState.lastRun = time.Time{}
+		jobState.lastErr = nil
+	})
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -24,6 +46,50 @@ func BackgroundJob(ctx context.Context, l *zap.Logger, s data.Storager, dropPeri
 			if err != nil {
 				l.Error("failed to drop URLs from storage", zap.Error(err))
 			}
+
+			setJobState(func() {
+				jobState.lastRun = time.Now()
+				jobState.lastErr = err
+			})
+		}
+	}
+}
+
+// BackgroundJobCheck возвращает проверку живости фоновой задачи: задача, которая не запускалась
+// дольше staleJobPeriods периодов, считается зависшей. Ошибки очистки ссылок на живость не влияют.
+func BackgroundJobCheck(dropPeriod time.Duration) health.CheckFunc {
+	return func(_ context.Context) health.Result {
+		jobState.mu.Lock()
+		started, lastRun, lastErr := jobState.started, jobState.lastRun, jobState.lastErr
+		jobState.mu.Unlock()
+
+		details := map[string]any{"period": dropPeriod.String()}
+		if started.IsZero() {
+			details["state"] = "not_started"
+			return health.OK(details)
+		}
+
+		details["started"] = started
+		since := started
+		if !lastRun.IsZero() {
+			details["last_run"] = lastRun
+			since = lastRun
+		}
+		if lastErr != nil {
+			details["last_error"] = lastErr.Error()
+		}
+
+		if time.Since(since) > staleJobPeriods*dropPeriod {
+			return health.Failing(errStaleJob, details)
 		}
+
+		return health.OK(details)
 	}
 }
+
+func setJobState(update func()) {
+	jobState.mu.Lock()
+	defer jobState.mu.Unlock()
+
+	update()
+}
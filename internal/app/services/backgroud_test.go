@@ -7,7 +7,9 @@ import (
 	"time"
 
 	"github.com/MihailSergeenkov/shortener/internal/app/data/mock"
+	"github.com/MihailSergeenkov/shortener/internal/app/health"
 	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 )
 
@@ -63,3 +65,34 @@ func TestBackgroundJob_CtxDone(t *testing.T) {
 		BackgroundJob(ctx, logger, storage, dropPeriod)
 	})
 }
+
+func TestBackgroundJobCheck(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	dropPeriod := 10 * time.Millisecond
+	check := BackgroundJobCheck(dropPeriod)
+
+	t.Run("job runs", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		storage := mock.NewMockStorager(mockCtrl)
+		storage.EXPECT().DropDeletedURLs(ctx).MinTimes(1).Return(errors.New("some error"))
+
+		BackgroundJob(ctx, zap.NewNop(), storage, dropPeriod)
+
+		result := check(context.Background())
+		assert.Equal(t, health.StatusOK, result.Status)
+		assert.Contains(t, result.Details, "last_run")
+		assert.Equal(t, "some error", result.Details["last_error"])
+	})
+
+	t.Run("job is stale", func(t *testing.T) {
+		time.Sleep(staleJobPeriods*dropPeriod + dropPeriod)
+
+		result := check(context.Background())
+		assert.Equal(t, health.StatusFailing, result.Status)
+		assert.Equal(t, errStaleJob.Error(), result.Error)
+	})
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+)
+
+// Виды ошибок сервиса. Транспорты (HTTP и gRPC) определяют код ответа по виду ошибки через errors.Is.
+var (
+	ErrInvalid      = errors.New("invalid argument")    // некорректные входные данные
+	ErrNotFound     = errors.New("not found")           // ресурс не найден
+	ErrGone         = errors.New("gone")                // ресурс удален
+	ErrConflict     = errors.New("conflict")            // ресурс уже существует
+	ErrPermission   = errors.New("permission denied")   // недостаточно прав
+	ErrPrecondition = errors.New("failed precondition") // операция недопустима в текущем состоянии
+	ErrExhausted    = errors.New("resource exhausted")  // исчерпана квота
+	ErrUnavailable  = errors.New("unavailable")         // сервис временно не может выполнить запрос
+)
+
+// ErrURLDeleted ошибка, когда короткая ссылка удалена.
+var ErrURLDeleted = errors.New("url deleted")
+
+// Причины ошибок, передаются клиенту в машиночитаемом виде.
+const (
+	ReasonOriginalURLExists = "ORIGINAL_URL_EXISTS"
+	ReasonURLNotFound       = "URL_NOT_FOUND"
+	ReasonURLDeleted        = "URL_DELETED"
+	ReasonPermissionDenied  = "PERMISSION_DENIED"
+	ReasonQuotaExceeded     = "DAILY_QUOTA_EXCEEDED"
+	ReasonInvalidOrg        = "INVALID_ORG"
+	ReasonInvalidMember     = "INVALID_MEMBER"
+	ReasonMemberNotFound    = "MEMBER_NOT_FOUND"
+	ReasonLastOwner         = "LAST_OWNER"
+	ReasonInvalidWebhook    = "INVALID_WEBHOOK"
+	ReasonWebhookNotFound   = "WEBHOOK_NOT_FOUND"
+	ReasonEventsUnavailable = "EVENTS_UNAVAILABLE"
+)
+
+// Типы ресурсов, к которым относится ошибка.
+const (
+	ResourceURL     = "url"
+	ResourceMember  = "org_member"
+	ResourceWebhook = "webhook"
+)
+
+// Error структура ошибки сервиса с видом и подробностями для клиента.
+type Error struct {
+	Kind         error         // вид ошибки, одна из ErrInvalid, ErrNotFound и т.д.
+	Err          error         // исходная ошибка
+	Reason       string        // машиночитаемая причина
+	ResourceType string        // тип связанного ресурса
+	Resource     string        // связанный ресурс, например существующая короткая ссылка при конфликте
+	RetryAfter   time.Duration // время, через которое можно повторить запрос
+}
+
+// Error возвращает описание ошибки.
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap позволяет проверять через errors.Is как вид ошибки, так и исходную ошибку.
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+func newError(kind error, reason string, err error) *Error {
+	return &Error{
+		Kind:   kind,
+		Err:    err,
+		Reason: reason,
+	}
+}
+
+func newResourceError(kind error, reason string, err error, resourceType string, resource string) *Error {
+	e := newError(kind, reason, err)
+	e.ResourceType = resourceType
+	e.Resource = resource
+
+	return e
+}
+
+func permissionError() error {
+	return newError(ErrPermission, ReasonPermissionDenied, common.ErrPermDenied)
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+)
+
+func TestError(t *testing.T) {
+	origErr := &data.OriginalURLAlreadyExistError{ShortURL: "short"}
+	err := newResourceError(ErrConflict, ReasonOriginalURLExists, origErr, ResourceURL, "http://localhost/short")
+
+	assert.EqualError(t, err, "original url already exist")
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.NotErrorIs(t, err, ErrNotFound)
+
+	var target *data.OriginalURLAlreadyExistError
+	assert.ErrorAs(t, err, &target)
+	assert.Equal(t, "short", target.ShortURL)
+
+	permErr := permissionError()
+	assert.ErrorIs(t, permErr, ErrPermission)
+	assert.ErrorIs(t, permErr, common.ErrPermDenied)
+	assert.False(t, errors.Is(permErr, ErrConflict))
+}
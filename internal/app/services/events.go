@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+)
+
+// LinkEventHandler обработчик событий жизненного цикла ссылок.
+type LinkEventHandler func(ctx context.Context, event models.LinkEvent)
+
+// linkEvents обработчики событий ссылок, подписываются при запуске сервиса.
+var linkEvents = &linkEventHandlers{handlers: map[int]LinkEventHandler{}}
+
+type linkEventHandlers struct {
+	handlers map[int]LinkEventHandler
+	next     int
+	mu       sync.RWMutex
+}
+
+// OnLinkEvent подписывает обработчик на события ссылок и возвращает функцию отписки.
+// Обработчики вызываются синхронно после успешного создания, удаления ссылки или перехода по ней,
+// поэтому не должны надолго задерживать запрос.
+func OnLinkEvent(handler LinkEventHandler) func() {
+	linkEvents.mu.Lock()
+	defer linkEvents.mu.Unlock()
+
+	id := linkEvents.next
+	linkEvents.next++
+	linkEvents.handlers[id] = handler
+
+	return func() {
+		linkEvents.mu.Lock()
+		defer linkEvents.mu.Unlock()
+
+		delete(linkEvents.handlers, id)
+	}
+}
+
+// publishLinkEvents передает события подписанным обработчикам, время событий выставляется при публикации.
+// Обработчики вызываются без блокировки, поэтому могут подписываться и отписываться.
+func publishLinkEvents(ctx context.Context, events ...models.LinkEvent) {
+	linkEvents.mu.RLock()
+	handlers := make([]LinkEventHandler, 0, len(linkEvents.handlers))
+	for _, handler := range linkEvents.handlers {
+		handlers = append(handlers, handler)
+	}
+	linkEvents.mu.RUnlock()
+
+	if len(handlers) == 0 {
+		return
+	}
+
+	now := time.Now().UTC()
+	for _, event := range events {
+		event.Time = now
+		for _, handler := range handlers {
+			handler(ctx, event)
+		}
+	}
+}
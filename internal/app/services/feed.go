@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/feed"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+)
+
+// linkFeed рассылка событий ссылок в потоки событий пользователей, запускается StartLinkFeed.
+var linkFeed atomic.Pointer[feed.Hub]
+
+// StartLinkFeed запускает рассылку событий ссылок в потоки событий пользователей с буфером последних
+// replay событий для возобновления потоков. Возвращает функцию остановки, которая завершает открытые потоки,
+// ее нужно вызвать до остановки серверов, иначе серверы будут ждать завершения потоков.
+func StartLinkFeed(replay int) func() {
+	hub := feed.NewHub(replay)
+	unsubscribe := OnLinkEvent(hub.Publish)
+	linkFeed.Store(hub)
+
+	return func() {
+		unsubscribe()
+		hub.Close()
+	}
+}
+
+// SubscribeLinkEvents функция подписки пользователя из контекста на события его ссылок.
+// Если lastEventID больше нуля, поток возобновляется после этого события, см. feed.Hub.Subscribe.
+// Подписку нужно закрыть после завершения потока.
+func SubscribeLinkEvents(ctx context.Context, lastEventID uint64) (*feed.Subscription, error) {
+	userID, ok := ctx.Value(common.KeyUserID).(string)
+	if !ok {
+		return nil, common.ErrFetchUserIDFromContext
+	}
+
+	hub := linkFeed.Load()
+	if hub == nil {
+		return nil, newError(ErrUnavailable, ReasonEventsUnavailable, feed.ErrClosed)
+	}
+
+	sub, err := hub.Subscribe(userID, lastEventID)
+	if err != nil {
+		return nil, newError(ErrUnavailable, ReasonEventsUnavailable, err)
+	}
+
+	return sub, nil
+}
+
+// NewUserEvent функция преобразования события рассылки в событие потока пользователя.
+func NewUserEvent(event feed.Event) models.UserEvent {
+	userEvent := models.UserEvent{
+		ID:          event.ID,
+		Type:        event.Link.Type,
+		Time:        event.Link.Time,
+		ShortURL:    event.Link.ShortURL,
+		OriginalURL: event.Link.OriginalURL,
+		OrgID:       event.Link.OrgID,
+	}
+	if event.Link.ShortURL != "" {
+		userEvent.ShortLink = ShortLink(event.Link.ShortURL)
+	}
+
+	return userEvent
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/feed"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+)
+
+func TestLinkFeed(t *testing.T) {
+	store := data.NewBaseStorage()
+	ctx := userContext("owner_id")
+
+	stop := StartLinkFeed(10)
+	defer stop()
+
+	sub, err := SubscribeLinkEvents(ctx, 0)
+	require.NoError(t, err)
+	defer sub.Close()
+
+	other, err := SubscribeLinkEvents(userContext("other_id"), 0)
+	require.NoError(t, err)
+	defer other.Close()
+
+	shortURL, err := AddShortURL(ctx, store, "https://ya.ru")
+	require.NoError(t, err)
+	_, err = GetURL(context.Background(), store, shortURL)
+	require.NoError(t, err)
+	require.NoError(t, DeleteUserURLs(ctx, zap.NewNop(), store, []string{shortURL}))
+
+	for _, eventType := range []string{models.LinkCreated, models.LinkClicked, models.LinkDeleted} {
+		event := NewUserEvent(<-sub.Events())
+		assert.Equal(t, eventType, event.Type)
+		assert.Equal(t, shortURL, event.ShortURL)
+		assert.Equal(t, ShortLink(shortURL), event.ShortLink)
+		assert.Equal(t, "https://ya.ru", event.OriginalURL)
+		assert.NotZero(t, event.ID)
+	}
+	assert.Empty(t, other.Events(), "events of foreign links are not sent")
+
+	t.Run("resume", func(t *testing.T) {
+		resumed, err := SubscribeLinkEvents(ctx, 1)
+		require.NoError(t, err)
+		defer resumed.Close()
+
+		assert.Len(t, resumed.Events(), 2)
+	})
+
+	t.Run("without user", func(t *testing.T) {
+		_, err := SubscribeLinkEvents(context.Background(), 0)
+		require.ErrorIs(t, err, common.ErrFetchUserIDFromContext)
+	})
+
+	t.Run("stopped", func(t *testing.T) {
+		stop()
+
+		<-sub.Done()
+		require.ErrorIs(t, sub.Err(), feed.ErrClosed)
+
+		_, err := SubscribeLinkEvents(ctx, 0)
+		require.ErrorIs(t, err, ErrUnavailable)
+		require.ErrorIs(t, err, feed.ErrClosed)
+
+		var serviceErr *Error
+		require.ErrorAs(t, err, &serviceErr)
+		assert.Equal(t, ReasonEventsUnavailable, serviceErr.Reason)
+	})
+}
+
+func TestNewUserEventReset(t *testing.T) {
+	event := NewUserEvent(feed.Event{ID: 5, Link: models.LinkEvent{Type: feed.EventReset}})
+
+	assert.Equal(t, uint64(5), event.ID)
+	assert.Equal(t, feed.EventReset, event.Type)
+	assert.Empty(t, event.ShortLink)
+}
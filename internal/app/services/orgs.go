@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+	"github.com/MihailSergeenkov/shortener/internal/app/tracing"
+)
+
+// Ошибки управления организациями.
+var (
+	ErrInvalidOrgName = errors.New("invalid org name")                 // пустое название организации
+	ErrInvalidMember  = errors.New("invalid org member")               // не указан пользователь или неизвестная роль
+	ErrLastOwner      = errors.New("org must have at least one owner") // нельзя исключить или понизить последнего владельца
+)
+
+var roleRanks = map[string]int{
+	models.RoleViewer: 1,
+	models.RoleEditor: 2,
+	models.RoleOwner:  3,
+}
+
+// CreateOrg функция создания организации, пользователь становится ее владельцем.
+func CreateOrg(ctx context.Context, s data.Storager, name string) (_ models.UserOrg, err error) {
+	ctx, span := tracing.Start(ctx, "services.CreateOrg")
+	defer tracing.End(span, &err)
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return models.UserOrg{}, newError(ErrInvalid, ReasonInvalidOrg, ErrInvalidOrgName)
+	}
+
+	orgID, err := generateShortURL()
+	if err != nil {
+		return models.UserOrg{}, fmt.Errorf("failed to generate org ID: %w", err)
+	}
+
+	if err := s.StoreOrg(ctx, models.Org{ID: orgID, Name: name}); err != nil {
+		return models.UserOrg{}, fmt.Errorf("failed to store org: %w", err)
+	}
+
+	return models.UserOrg{
+		ID:   orgID,
+		Name: name,
+		Role: models.RoleOwner,
+	}, nil
+}
+
+// FetchUserOrgs функция получения организаций пользователя.
+func FetchUserOrgs(ctx context.Context, s data.Storager) (_ []models.UserOrg, err error) {
+	ctx, span := tracing.Start(ctx, "services.FetchUserOrgs")
+	defer tracing.End(span, &err)
+
+	orgs, err := s.FetchUserOrgs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch orgs: %w", err)
+	}
+
+	return orgs, nil
+}
+
+// FetchOrgMembers функция получения участников организации, доступна любому участнику.
+func FetchOrgMembers(ctx context.Context, s data.Storager, orgID string) (_ []models.Member, err error) {
+	ctx, span := tracing.Start(ctx, "services.FetchOrgMembers")
+	defer tracing.End(span, &err)
+
+	if err := authorizeOrg(ctx, s, orgID, models.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	members, err := s.FetchOrgMembers(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch org members: %w", err)
+	}
+
+	return members, nil
+}
+
+// SetOrgMember функция добавления участника организации или изменения его роли, доступна владельцу.
+func SetOrgMember(ctx context.Context, s data.Storager, member models.Member) (err error) {
+	ctx, span := tracing.Start(ctx, "services.SetOrgMember")
+	defer tracing.End(span, &err)
+
+	if _, ok := roleRanks[member.Role]; !ok || member.UserID == "" {
+		return newError(ErrInvalid, ReasonInvalidMember, ErrInvalidMember)
+	}
+
+	if err := authorizeOrg(ctx, s, member.OrgID, models.RoleOwner); err != nil {
+		return err
+	}
+
+	if member.Role != models.RoleOwner {
+		if err := checkLastOwner(ctx, s, member.OrgID, member.UserID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.StoreOrgMember(ctx, member); err != nil {
+		return fmt.Errorf("failed to store org member: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveOrgMember функция исключения участника из организации.
+// Владелец может исключить любого участника, остальные участники - только себя.
+func RemoveOrgMember(ctx context.Context, s data.Storager, orgID string, userID string) (err error) {
+	ctx, span := tracing.Start(ctx, "services.RemoveOrgMember")
+	defer tracing.End(span, &err)
+
+	currentUserID, ok := ctx.Value(common.KeyUserID).(string)
+	if !ok {
+		return common.ErrFetchUserIDFromContext
+	}
+
+	minRole := models.RoleOwner
+	if userID == currentUserID {
+		minRole = models.RoleViewer
+	}
+
+	if err := authorizeOrg(ctx, s, orgID, minRole); err != nil {
+		return err
+	}
+
+	if err := checkLastOwner(ctx, s, orgID, userID); err != nil {
+		return err
+	}
+
+	if err := s.DeleteOrgMember(ctx, orgID, userID); err != nil {
+		if errors.Is(err, data.ErrMemberNotFound) {
+			return newResourceError(ErrNotFound, ReasonMemberNotFound, err, ResourceMember, orgID+"/"+userID)
+		}
+
+		return fmt.Errorf("failed to delete org member: %w", err)
+	}
+
+	return nil
+}
+
+// authorizeOrg проверяет, что у пользователя есть роль не ниже minRole в организации.
+func authorizeOrg(ctx context.Context, s data.Storager, orgID string, minRole string) error {
+	role, err := s.GetOrgRole(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, data.ErrMemberNotFound) {
+			return permissionError()
+		}
+
+		return fmt.Errorf("failed to get org role: %w", err)
+	}
+
+	if roleRanks[role] < roleRanks[minRole] {
+		return permissionError()
+	}
+
+	return nil
+}
+
+// checkLastOwner проверяет, что после ухода пользователя из владельцев у организации останется владелец.
+func checkLastOwner(ctx context.Context, s data.Storager, orgID string, userID string) error {
+	members, err := s.FetchOrgMembers(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch org members: %w", err)
+	}
+
+	for _, m := range members {
+		if m.Role == models.RoleOwner && m.UserID != userID {
+			return nil
+		}
+	}
+
+	for _, m := range members {
+		if m.Role == models.RoleOwner && m.UserID == userID {
+			return newError(ErrPrecondition, ReasonLastOwner, ErrLastOwner)
+		}
+	}
+
+	return nil
+}
+
+func orgFromContext(ctx context.Context) string {
+	orgID, _ := ctx.Value(common.KeyOrgID).(string)
+	return orgID
+}
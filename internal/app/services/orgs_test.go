@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/data/mock"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+)
+
+func userContext(userID string) context.Context {
+	return context.WithValue(context.Background(), common.KeyUserID, userID)
+}
+
+func TestOrgWorkflow(t *testing.T) {
+	store := data.NewBaseStorage()
+	logger := zap.NewNop()
+	ownerCtx := userContext("owner_id")
+	editorCtx := userContext("editor_id")
+	viewerCtx := userContext("viewer_id")
+
+	org, err := CreateOrg(ownerCtx, store, " Team ")
+	require.NoError(t, err)
+	assert.Equal(t, "Team", org.Name)
+	assert.Equal(t, models.RoleOwner, org.Role)
+
+	require.NoError(t, SetOrgMember(ownerCtx, store, models.Member{OrgID: org.ID, UserID: "editor_id", Role: models.RoleEditor}))
+	require.NoError(t, SetOrgMember(ownerCtx, store, models.Member{OrgID: org.ID, UserID: "viewer_id", Role: models.RoleViewer}))
+
+	withOrg := func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, common.KeyOrgID, org.ID)
+	}
+
+	var shortURL string
+
+	t.Run("editor creates org link", func(t *testing.T) {
+		shortURL, err = AddShortURL(withOrg(editorCtx), store, "https://ya.ru")
+		require.NoError(t, err)
+	})
+
+	t.Run("viewer can not create org link", func(t *testing.T) {
+		_, err := AddShortURL(withOrg(viewerCtx), store, "https://ya.ru/other")
+		require.ErrorIs(t, err, common.ErrPermDenied)
+
+		_, err = AddBatchShortURL(withOrg(viewerCtx), store, models.BatchRequest{{OriginalURL: "https://ya.ru/batch"}})
+		require.ErrorIs(t, err, common.ErrPermDenied)
+	})
+
+	t.Run("members see org links", func(t *testing.T) {
+		urls, err := FetchUserURLs(withOrg(viewerCtx), store)
+		require.NoError(t, err)
+		require.Len(t, urls, 1)
+
+		urls, err = FetchUserURLs(editorCtx, store)
+		require.NoError(t, err)
+		assert.Empty(t, urls)
+	})
+
+	t.Run("outsider can not see org links", func(t *testing.T) {
+		_, err := FetchUserURLs(withOrg(userContext("other_id")), store)
+		require.ErrorIs(t, err, common.ErrPermDenied)
+	})
+
+	t.Run("link survives creator leaving", func(t *testing.T) {
+		require.NoError(t, RemoveOrgMember(editorCtx, store, org.ID, "editor_id"))
+
+		require.NoError(t, DeleteUserURLs(editorCtx, logger, store, []string{shortURL}))
+		u, err := store.GetURL(ownerCtx, shortURL)
+		require.NoError(t, err)
+		assert.False(t, u.DeletedFlag)
+
+		require.NoError(t, DeleteUserURLs(viewerCtx, logger, store, []string{shortURL}))
+		u, err = store.GetURL(ownerCtx, shortURL)
+		require.NoError(t, err)
+		assert.False(t, u.DeletedFlag)
+
+		require.NoError(t, DeleteUserURLs(ownerCtx, logger, store, []string{shortURL}))
+		u, err = store.GetURL(ownerCtx, shortURL)
+		require.NoError(t, err)
+		assert.True(t, u.DeletedFlag)
+	})
+
+	t.Run("only owner manages members", func(t *testing.T) {
+		err := SetOrgMember(viewerCtx, store, models.Member{OrgID: org.ID, UserID: "viewer_id", Role: models.RoleOwner})
+		require.ErrorIs(t, err, common.ErrPermDenied)
+
+		err = RemoveOrgMember(viewerCtx, store, org.ID, "owner_id")
+		require.ErrorIs(t, err, common.ErrPermDenied)
+
+		members, err := FetchOrgMembers(viewerCtx, store, org.ID)
+		require.NoError(t, err)
+		assert.Len(t, members, 2)
+	})
+
+	t.Run("last owner can not leave", func(t *testing.T) {
+		err := SetOrgMember(ownerCtx, store, models.Member{OrgID: org.ID, UserID: "owner_id", Role: models.RoleViewer})
+		require.ErrorIs(t, err, ErrLastOwner)
+
+		err = RemoveOrgMember(ownerCtx, store, org.ID, "owner_id")
+		require.ErrorIs(t, err, ErrLastOwner)
+
+		require.NoError(t, SetOrgMember(ownerCtx, store, models.Member{OrgID: org.ID, UserID: "viewer_id", Role: models.RoleOwner}))
+		require.NoError(t, RemoveOrgMember(ownerCtx, store, org.ID, "owner_id"))
+	})
+}
+
+func TestCreateOrg_Failed(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ctx := userContext("some_id")
+	store := mock.NewMockStorager(mockCtrl)
+
+	t.Run("invalid name", func(t *testing.T) {
+		_, err := CreateOrg(ctx, store, "  ")
+		assert.ErrorIs(t, err, ErrInvalidOrgName)
+	})
+
+	t.Run("failed store", func(t *testing.T) {
+		store.EXPECT().StoreOrg(ctx, gomock.Any()).Times(1).Return(errors.New("some error"))
+
+		_, err := CreateOrg(ctx, store, "Team")
+		assert.ErrorContains(t, err, "failed to store org")
+	})
+}
+
+func TestSetOrgMember_Invalid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ctx := userContext("some_id")
+	store := mock.NewMockStorager(mockCtrl)
+
+	tests := []struct {
+		name   string
+		member models.Member
+	}{
+		{
+			name:   "unknown role",
+			member: models.Member{OrgID: "org_id", UserID: "other_id", Role: "admin"},
+		},
+		{
+			name:   "empty user",
+			member: models.Member{OrgID: "org_id", Role: models.RoleViewer},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := SetOrgMember(ctx, store, test.member)
+			assert.ErrorIs(t, err, ErrInvalidMember)
+		})
+	}
+
+	t.Run("failed get role", func(t *testing.T) {
+		store.EXPECT().GetOrgRole(ctx, "org_id").Times(1).Return("", errors.New("some error"))
+
+		err := SetOrgMember(ctx, store, models.Member{OrgID: "org_id", UserID: "other_id", Role: models.RoleViewer})
+		assert.ErrorContains(t, err, "failed to get org role")
+	})
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/outbox"
+)
+
+// outboxBatchSize число событий исходящей очереди, публикуемых за раз.
+const outboxBatchSize = 100
+
+// OutboxRelay функция публикации событий исходящей очереди o в шину сообщений p каждые period.
+// Событие удаляется из очереди только после публикации, поэтому каждое событие публикуется хотя бы один раз.
+func OutboxRelay(ctx context.Context, l *zap.Logger, o data.Outbox, p outbox.Publisher, period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			l.Info("outbox relay stopped", zap.Error(ctx.Err()))
+			return
+		case <-ticker.C:
+			count, err := relayOutbox(ctx, o, p)
+			if err != nil && ctx.Err() == nil {
+				l.Error("failed to relay outbox events", zap.Error(err))
+			}
+			if count > 0 {
+				l.Debug("outbox events published", zap.Int("count", count))
+			}
+		}
+	}
+}
+
+// relayOutbox публикует накопленные события очереди пачками по порядку и возвращает число опубликованных.
+func relayOutbox(ctx context.Context, o data.Outbox, p outbox.Publisher) (int, error) {
+	published := 0
+
+	for {
+		events, err := o.FetchOutboxEvents(ctx, outboxBatchSize)
+		if err != nil {
+			return published, fmt.Errorf("failed to fetch outbox events: %w", err)
+		}
+		if len(events) == 0 {
+			return published, nil
+		}
+
+		if err := p.Publish(ctx, events); err != nil {
+			return published, fmt.Errorf("failed to publish outbox events: %w", err)
+		}
+
+		ids := make([]int64, 0, len(events))
+		for _, event := range events {
+			ids = append(ids, event.ID)
+		}
+		if err := o.DeleteOutboxEvents(ctx, ids); err != nil {
+			return published, fmt.Errorf("failed to delete outbox events: %w", err)
+		}
+
+		published += len(events)
+		if len(events) < outboxBatchSize {
+			return published, nil
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+	"github.com/MihailSergeenkov/shortener/internal/app/outbox"
+)
+
+// testOutbox исходящая очередь в памяти.
+type testOutbox struct {
+	events []models.OutboxEvent
+}
+
+func newTestOutbox(count int) *testOutbox {
+	o := &testOutbox{}
+	for i := range count {
+		o.events = append(o.events, models.OutboxEvent{ID: int64(i + 1), Event: models.LinkCreated})
+	}
+
+	return o
+}
+
+func (o *testOutbox) FetchOutboxEvents(_ context.Context, limit int) ([]models.OutboxEvent, error) {
+	return slices.Clone(o.events[:min(limit, len(o.events))]), nil
+}
+
+func (o *testOutbox) DeleteOutboxEvents(_ context.Context, ids []int64) error {
+	o.events = slices.DeleteFunc(o.events, func(e models.OutboxEvent) bool { return slices.Contains(ids, e.ID) })
+	return nil
+}
+
+// failingPublisher публикатор, всегда возвращающий ошибку.
+type failingPublisher struct{}
+
+func (failingPublisher) Publish(context.Context, []models.OutboxEvent) error {
+	return errors.New("bus is unavailable")
+}
+
+func (failingPublisher) Close() error {
+	return nil
+}
+
+func TestRelayOutbox(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("publishes all batches in order", func(t *testing.T) {
+		o := newTestOutbox(outboxBatchSize + 5)
+		p := outbox.NewMemoryPublisher()
+
+		count, err := relayOutbox(ctx, o, p)
+		require.NoError(t, err)
+		assert.Equal(t, outboxBatchSize+5, count)
+		assert.Empty(t, o.events)
+
+		published := p.Events()
+		require.Len(t, published, outboxBatchSize+5)
+		assert.True(t, slices.IsSortedFunc(published, func(a, b models.OutboxEvent) int { return int(a.ID - b.ID) }))
+	})
+
+	t.Run("failed publish keeps events", func(t *testing.T) {
+		o := newTestOutbox(3)
+
+		count, err := relayOutbox(ctx, o, failingPublisher{})
+		require.ErrorContains(t, err, "failed to publish outbox events")
+		assert.Zero(t, count)
+		assert.Len(t, o.events, 3)
+	})
+}
+
+func TestOutboxRelay(t *testing.T) {
+	o := newTestOutbox(2)
+	p := outbox.NewMemoryPublisher()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		OutboxRelay(ctx, zap.NewNop(), o, p, 10*time.Millisecond)
+	}()
+
+	require.Eventually(t, func() bool { return len(p.Events()) == 2 }, time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+}
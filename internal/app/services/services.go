@@ -8,19 +8,54 @@ import (
 	"errors"
 	"fmt"
 	"path"
+	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/MihailSergeenkov/shortener/internal/app/common"
 	"github.com/MihailSergeenkov/shortener/internal/app/config"
 	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/logger"
 	"github.com/MihailSergeenkov/shortener/internal/app/models"
+	"github.com/MihailSergeenkov/shortener/internal/app/ratelimit"
+	"github.com/MihailSergeenkov/shortener/internal/app/tracing"
 )
 
-const keyBytes int = 8
+const (
+	keyBytes         int = 8
+	maxKeyLength     int = 64
+	maxStoreAttempts int = 3
+)
+
+// reservedKeys ключи, совпадающие с путями сервиса.
+var reservedKeys = map[string]struct{}{
+	"api":     {},
+	"auth":    {},
+	"debug":   {},
+	"healthz": {},
+	"metrics": {},
+	"ping":    {},
+	"readyz":  {},
+	"v2":      {},
+}
 
 // AddShortURL функция сохранения короткой ссылки.
-func AddShortURL(ctx context.Context, s data.Storager, originalURL string) (string, error) {
+// Если в контексте выбрана организация, ссылка принадлежит ей и требует роли не ниже редактора.
+func AddShortURL(ctx context.Context, s data.Storager, originalURL string) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "services.AddShortURL")
+	defer tracing.End(span, &err)
+
+	if orgID := orgFromContext(ctx); orgID != "" {
+		if err := authorizeOrg(ctx, s, orgID, models.RoleEditor); err != nil {
+			return "", err
+		}
+	}
+
+	if err := consumeQuota(ctx, s, 1); err != nil {
+		return "", err
+	}
+	defer refundQuota(ctx, s, 1, &err)
+
 	shortURL, err := generateShortURL()
 	if err != nil {
 		return "", fmt.Errorf("failed to generate short URL: %w", err)
@@ -29,70 +64,201 @@ func AddShortURL(ctx context.Context, s data.Storager, originalURL string) (stri
 	storeErr := s.StoreShortURL(ctx, shortURL, originalURL)
 
 	if storeErr != nil {
+		var origErr *data.OriginalURLAlreadyExistError
+		if errors.As(storeErr, &origErr) {
+			return "", newResourceError(ErrConflict, ReasonOriginalURLExists, storeErr, ResourceURL, ShortLink(origErr.ShortURL))
+		}
+
 		return "", fmt.Errorf("failed to store short URL: %w", storeErr)
 	}
 
+	userID, _ := ctx.Value(common.KeyUserID).(string)
+	publishLinkEvents(ctx, models.LinkEvent{
+		Type:        models.LinkCreated,
+		ShortURL:    shortURL,
+		OriginalURL: originalURL,
+		UserID:      userID,
+		OrgID:       orgFromContext(ctx),
+	})
+
 	return shortURL, nil
 }
 
+// GetURL функция получения оригинальной ссылки по короткой.
+func GetURL(ctx context.Context, s data.Storager, shortURL string) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "services.GetURL")
+	defer tracing.End(span, &err)
+
+	u, err := s.GetURL(ctx, shortURL)
+	if err != nil {
+		if errors.Is(err, data.ErrURLNotFound) {
+			return "", newResourceError(ErrNotFound, ReasonURLNotFound, err, ResourceURL, ShortLink(shortURL))
+		}
+
+		return "", fmt.Errorf("failed to get URL: %w", err)
+	}
+
+	if u.DeletedFlag {
+		return "", newResourceError(ErrGone, ReasonURLDeleted, ErrURLDeleted, ResourceURL, ShortLink(shortURL))
+	}
+
+	publishLinkEvents(ctx, linkEvent(models.LinkClicked, u))
+
+	return u.OriginalURL, nil
+}
+
+// ShortLink возвращает полную короткую ссылку для ключа.
+func ShortLink(shortURL string) string {
+	baseURL := config.Params.BaseURL
+	baseURL.Path = path.Join(baseURL.Path, shortURL)
+
+	return baseURL.String()
+}
+
 // AddBatchShortURL функция сохранения нескольких коротких ссылок.
-func AddBatchShortURL(ctx context.Context, s data.Storager, req models.BatchRequest) (models.BatchResponse, error) {
-	arrURLs := []models.URL{}
-	resp := models.BatchResponse{}
+func AddBatchShortURL(
+	ctx context.Context,
+	s data.Storager,
+	req models.BatchRequest,
+) (_ models.BatchResponse, err error) {
+	ctx, span := tracing.Start(ctx, "services.AddBatchShortURL")
+	defer tracing.End(span, &err)
+
+	return addBatchShortURL(ctx, s, req, nil)
+}
 
+// addBatchShortURL сохраняет несколько коротких ссылок. Желаемый ключ keys[i] ссылки req[i] сохраняется,
+// если он допустим и свободен, иначе генерируется новый. Занятость ключа определяет хранилище при сохранении.
+func addBatchShortURL(
+	ctx context.Context,
+	s data.Storager,
+	req models.BatchRequest,
+	keys []string,
+) (_ models.BatchResponse, err error) {
 	userID, ok := ctx.Value(common.KeyUserID).(string)
 	if !ok {
 		return models.BatchResponse{}, common.ErrFetchUserIDFromContext
 	}
 
-	for _, reqData := range req {
-		shortURL, err := generateShortURL()
-		if err != nil {
-			return models.BatchResponse{}, fmt.Errorf("failed to generate short URL: %w", err)
+	orgID := orgFromContext(ctx)
+	if orgID != "" {
+		if err := authorizeOrg(ctx, s, orgID, models.RoleEditor); err != nil {
+			return models.BatchResponse{}, err
 		}
+	}
 
-		u := models.URL{
+	if err := consumeQuota(ctx, s, len(req)); err != nil {
+		return models.BatchResponse{}, err
+	}
+	defer refundQuota(ctx, s, len(req), &err)
+
+	arrURLs := make([]models.URL, 0, len(req))
+	used := make(map[string]struct{}, len(req))
+
+	for i, reqData := range req {
+		var shortURL string
+		if i < len(keys) {
+			shortURL = keys[i]
+		}
+		if _, ok := used[shortURL]; ok || !validKey(shortURL) {
+			if shortURL, err = generateShortURL(); err != nil {
+				return models.BatchResponse{}, fmt.Errorf("failed to generate short URL: %w", err)
+			}
+		}
+		used[shortURL] = struct{}{}
+
+		arrURLs = append(arrURLs, models.URL{
 			ShortURL:    shortURL,
 			OriginalURL: reqData.OriginalURL,
 			UserID:      userID,
-		}
+			OrgID:       orgID,
+		})
+	}
+
+	if err = storeShortURLs(ctx, s, arrURLs); err != nil {
+		return models.BatchResponse{}, err
+	}
 
-		baseURL := config.Params.BaseURL
-		newPath := path.Join(baseURL.Path, shortURL)
-		baseURL.Path = newPath
+	resp := make(models.BatchResponse, 0, len(arrURLs))
+	events := make([]models.LinkEvent, 0, len(arrURLs))
+	for i, u := range arrURLs {
+		resp = append(resp, models.BatchDataResponse{
+			ShortURL:      ShortLink(u.ShortURL),
+			CorrelationID: req[i].CorrelationID,
+		})
+		events = append(events, linkEvent(models.LinkCreated, u))
+	}
+	publishLinkEvents(ctx, events...)
+
+	return resp, nil
+}
 
-		respData := models.BatchDataResponse{
-			ShortURL:      baseURL.String(),
-			CorrelationID: reqData.CorrelationID,
+// storeShortURLs сохраняет ссылки пакета. Если хранилище отклонило пакет из-за занятого ключа,
+// занятые ключи заменяются новыми и пакет сохраняется повторно.
+func storeShortURLs(ctx context.Context, s data.Storager, urls []models.URL) error {
+	for attempt := 1; ; attempt++ {
+		storeErr := s.StoreShortURLs(ctx, urls)
+		if storeErr == nil {
+			return nil
+		}
+		if !errors.Is(storeErr, data.ErrShortURLAlreadyExist) || attempt == maxStoreAttempts {
+			return fmt.Errorf("failed to store short URLs: %w", storeErr)
 		}
 
-		arrURLs = append(arrURLs, u)
-		resp = append(resp, respData)
+		if err := renameTakenKeys(ctx, s, urls); err != nil {
+			return err
+		}
 	}
+}
+
+// renameTakenKeys заменяет новыми ключи ссылок, которые уже есть в хранилище.
+func renameTakenKeys(ctx context.Context, s data.Storager, urls []models.URL) error {
+	for i := range urls {
+		_, err := s.GetURL(ctx, urls[i].ShortURL)
+		if errors.Is(err, data.ErrURLNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to check short URL: %w", err)
+		}
 
-	if storeErr := s.StoreShortURLs(ctx, arrURLs); storeErr != nil {
-		return models.BatchResponse{}, fmt.Errorf("failed to store short URLs: %w", storeErr)
+		shortURL, err := generateShortURL()
+		if err != nil {
+			return fmt.Errorf("failed to generate short URL: %w", err)
+		}
+		urls[i].ShortURL = shortURL
 	}
 
-	return resp, nil
+	return nil
 }
 
 // FetchUserURLs функция получения всех сохраненных ссылок пользователя.
-func FetchUserURLs(ctx context.Context, s data.Storager) (models.UserURLsResponse, error) {
+// Если в контексте выбрана организация, возвращает ссылки организации.
+func FetchUserURLs(ctx context.Context, s data.Storager) (_ models.UserURLsResponse, err error) {
+	ctx, span := tracing.Start(ctx, "services.FetchUserURLs")
+	defer tracing.End(span, &err)
+
 	resp := models.UserURLsResponse{}
 
-	urls, err := s.FetchUserURLs(ctx)
+	var urls []models.URL
+
+	if orgID := orgFromContext(ctx); orgID != "" {
+		if authErr := authorizeOrg(ctx, s, orgID, models.RoleViewer); authErr != nil {
+			return models.UserURLsResponse{}, authErr
+		}
+
+		urls, err = s.FetchOrgURLs(ctx, orgID)
+	} else {
+		urls, err = s.FetchUserURLs(ctx)
+	}
+
 	if err != nil {
 		return models.UserURLsResponse{}, fmt.Errorf("failed to fetch URLs: %w", err)
 	}
 
 	for _, u := range urls {
-		baseURL := config.Params.BaseURL
-		newPath := path.Join(baseURL.Path, u.ShortURL)
-		baseURL.Path = newPath
-
 		respData := models.UserURLsDataResponse{
-			ShortURL:    baseURL.String(),
+			ShortURL:    ShortLink(u.ShortURL),
 			OriginalURL: u.OriginalURL,
 		}
 
@@ -102,27 +268,91 @@ func FetchUserURLs(ctx context.Context, s data.Storager) (models.UserURLsRespons
 	return resp, nil
 }
 
+// WalkUserURLs функция постраничного обхода ссылок пользователя или выбранной в контексте организации.
+// Для каждой страницы размером не больше pageSize вызывается fn, обход прекращается при первой ошибке.
+func WalkUserURLs(
+	ctx context.Context,
+	s data.Storager,
+	pageSize int,
+	fn func(models.UserURLsResponse) error,
+) (err error) {
+	ctx, span := tracing.Start(ctx, "services.WalkUserURLs")
+	defer tracing.End(span, &err)
+
+	return walkURLs(ctx, s, pageSize, func(urls []models.URL) error {
+		page := make(models.UserURLsResponse, 0, len(urls))
+		for _, u := range urls {
+			page = append(page, models.UserURLsDataResponse{
+				ShortURL:    ShortLink(u.ShortURL),
+				OriginalURL: u.OriginalURL,
+			})
+		}
+
+		return fn(page)
+	})
+}
+
+func walkURLs(ctx context.Context, s data.Storager, pageSize int, fn func([]models.URL) error) error {
+	orgID := orgFromContext(ctx)
+	if orgID != "" {
+		if err := authorizeOrg(ctx, s, orgID, models.RoleViewer); err != nil {
+			return err
+		}
+	}
+
+	var after string
+	for {
+		urls, err := s.FetchURLsPage(ctx, orgID, after, pageSize)
+		if err != nil {
+			return fmt.Errorf("failed to fetch URLs page: %w", err)
+		}
+		if len(urls) == 0 {
+			return nil
+		}
+
+		if err := fn(urls); err != nil {
+			return err
+		}
+
+		if len(urls) < pageSize {
+			return nil
+		}
+		after = urls[len(urls)-1].ShortURL
+	}
+}
+
 // DeleteUserURLs функция мягкого удаления ссылок.
-func DeleteUserURLs(ctx context.Context, l *zap.Logger, s data.Storager, shortURLs []string) error {
+// Ссылки организаций может удалить участник с ролью не ниже редактора.
+func DeleteUserURLs(ctx context.Context, l *zap.Logger, s data.Storager, shortURLs []string) (err error) {
+	ctx, span := tracing.Start(ctx, "services.DeleteUserURLs")
+	defer tracing.End(span, &err)
+
 	urls := make([]string, 0)
+	events := make([]models.LinkEvent, 0)
 
 	inputCh := generator(ctx, shortURLs)
 	checkResultCh := checkCh(ctx, l, s, inputCh)
 
-	for url := range checkResultCh {
-		urls = append(urls, url)
+	for u := range checkResultCh {
+		urls = append(urls, u.ShortURL)
+		events = append(events, linkEvent(models.LinkDeleted, u))
 	}
 
-	err := s.DeleteShortURLs(ctx, urls)
+	err = s.DeleteShortURLs(ctx, urls)
 	if err != nil {
 		return fmt.Errorf("failed to delete URLs: %w", err)
 	}
 
+	publishLinkEvents(ctx, events...)
+
 	return nil
 }
 
 // FetchStats функция получения статистических данных.
-func FetchStats(ctx context.Context, s data.Storager) (models.StatsResponse, error) {
+func FetchStats(ctx context.Context, s data.Storager) (_ models.StatsResponse, err error) {
+	ctx, span := tracing.Start(ctx, "services.FetchStats")
+	defer tracing.End(span, &err)
+
 	urls, users, err := s.FetchStats(ctx)
 	if err != nil {
 		return models.StatsResponse{}, fmt.Errorf("failed to fetch stats: %w", err)
@@ -136,6 +366,65 @@ func FetchStats(ctx context.Context, s data.Storager) (models.StatsResponse, err
 	return stats, nil
 }
 
+// QuotaRetryAfter возвращает время до сброса дневной квоты пользователя.
+func QuotaRetryAfter() time.Duration {
+	now := time.Now().UTC()
+	nextDay := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+
+	return nextDay.Sub(now)
+}
+
+func consumeQuota(ctx context.Context, s data.Storager, count int) error {
+	limit := config.Params.DailyURLsQuota
+	if limit <= 0 {
+		return nil
+	}
+
+	// Квота считается по тому же ключу, что и ограничение частоты: по IP адресу для неподтвержденных пользователей.
+	if err := s.ConsumeDailyQuota(ctx, ratelimit.Key(ctx), count, limit); err != nil {
+		if errors.Is(err, data.ErrQuotaExceeded) {
+			quotaErr := newError(ErrExhausted, ReasonQuotaExceeded, err)
+			quotaErr.RetryAfter = QuotaRetryAfter()
+
+			return quotaErr
+		}
+
+		return fmt.Errorf("failed to consume daily quota: %w", err)
+	}
+
+	return nil
+}
+
+// refundQuota возвращает списанную квоту, если ссылки не сохранены: конфликты и ошибки хранилища ее не расходуют.
+func refundQuota(ctx context.Context, s data.Storager, count int, err *error) {
+	if *err == nil || config.Params.DailyURLsQuota <= 0 {
+		return
+	}
+
+	if refundErr := s.RefundDailyQuota(ctx, ratelimit.Key(ctx), count); refundErr != nil {
+		*err = errors.Join(*err, fmt.Errorf("failed to refund daily quota: %w", refundErr))
+	}
+}
+
+// validKey проверяет, что ключ можно использовать в короткой ссылке и он не совпадает с путями сервиса.
+func validKey(key string) bool {
+	if key == "" || len(key) > maxKeyLength {
+		return false
+	}
+
+	if _, ok := reservedKeys[key]; ok {
+		return false
+	}
+
+	for _, r := range key {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+
+	return true
+}
+
 func generateShortURL() (string, error) {
 	bytes := make([]byte, keyBytes)
 
@@ -164,8 +453,8 @@ func generator(ctx context.Context, shortURLs []string) chan string {
 	return inputCh
 }
 
-func checkCh(ctx context.Context, l *zap.Logger, s data.Storager, inputCh chan string) chan string {
-	checkRes := make(chan string)
+func checkCh(ctx context.Context, l *zap.Logger, s data.Storager, inputCh chan string) chan models.URL {
+	checkRes := make(chan models.URL)
 
 	go func() {
 		defer close(checkRes)
@@ -177,7 +466,7 @@ func checkCh(ctx context.Context, l *zap.Logger, s data.Storager, inputCh chan s
 					continue
 				}
 
-				l.Error("failed to check URL", zap.Error(err))
+				logger.FromContext(ctx, l).Error("failed to check URL", zap.Error(err))
 				continue
 			}
 
@@ -191,20 +480,39 @@ func checkCh(ctx context.Context, l *zap.Logger, s data.Storager, inputCh chan s
 	return checkRes
 }
 
-func checkURL(ctx context.Context, s data.Storager, shortURL string) (string, error) {
+func checkURL(ctx context.Context, s data.Storager, shortURL string) (models.URL, error) {
 	userID, ok := ctx.Value(common.KeyUserID).(string)
 	if !ok {
-		return "", common.ErrFetchUserIDFromContext
+		return models.URL{}, common.ErrFetchUserIDFromContext
 	}
 
 	u, err := s.GetURL(ctx, shortURL)
 	if err != nil {
-		return "", fmt.Errorf("failed to get URL: %w", err)
+		return models.URL{}, fmt.Errorf("failed to get URL: %w", err)
+	}
+
+	if u.OrgID != "" {
+		if err := authorizeOrg(ctx, s, u.OrgID, models.RoleEditor); err != nil {
+			return models.URL{}, err
+		}
+
+		return u, nil
 	}
 
 	if u.UserID != userID {
-		return "", common.ErrPermDenied
+		return models.URL{}, permissionError()
 	}
 
-	return shortURL, nil
+	return u, nil
+}
+
+// linkEvent возвращает событие eventType для ссылки u.
+func linkEvent(eventType string, u models.URL) models.LinkEvent {
+	return models.LinkEvent{
+		Type:        eventType,
+		ShortURL:    u.ShortURL,
+		OriginalURL: u.OriginalURL,
+		UserID:      u.UserID,
+		OrgID:       u.OrgID,
+	}
 }
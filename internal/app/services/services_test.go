@@ -4,12 +4,21 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 	"go.uber.org/zap"
 
 	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/config"
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
 	"github.com/MihailSergeenkov/shortener/internal/app/data/mock"
 	"github.com/MihailSergeenkov/shortener/internal/app/models"
 )
@@ -48,6 +57,66 @@ func TestAddShortURL_Failed(t *testing.T) {
 	})
 }
 
+func TestAddShortURL_QuotaExceeded(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ctx := context.WithValue(context.Background(), common.KeyUserID, "some_id")
+	store := mock.NewMockStorager(mockCtrl)
+
+	config.Params.DailyURLsQuota = 1
+	defer func() { config.Params.DailyURLsQuota = 0 }()
+
+	store.EXPECT().ConsumeDailyQuota(ctx, "user:some_id", 1, 1).Times(1).Return(data.ErrQuotaExceeded)
+	store.EXPECT().StoreShortURL(ctx, gomock.Any(), gomock.Any()).Times(0)
+
+	t.Run("add short URL quota exceeded", func(t *testing.T) {
+		_, err := AddShortURL(ctx, store, "some_url")
+		assert.ErrorIs(t, err, data.ErrQuotaExceeded)
+	})
+}
+
+func TestAddShortURL_QuotaRefunded(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ctx := context.WithValue(context.Background(), common.KeyUserID, "some_id")
+	store := mock.NewMockStorager(mockCtrl)
+
+	config.Params.DailyURLsQuota = 1
+	defer func() { config.Params.DailyURLsQuota = 0 }()
+
+	t.Run("conflict refunds quota", func(t *testing.T) {
+		store.EXPECT().ConsumeDailyQuota(ctx, "user:some_id", 1, 1).Times(1).Return(nil)
+		store.EXPECT().StoreShortURL(ctx, gomock.Any(), "some_url").Times(1).
+			Return(&data.OriginalURLAlreadyExistError{ShortURL: "abc"})
+		store.EXPECT().RefundDailyQuota(ctx, "user:some_id", 1).Times(1).Return(nil)
+
+		_, err := AddShortURL(ctx, store, "some_url")
+		assert.ErrorIs(t, err, ErrConflict)
+	})
+
+	t.Run("failed refund is reported", func(t *testing.T) {
+		errSome := errors.New("some error")
+		store.EXPECT().ConsumeDailyQuota(ctx, "user:some_id", 1, 1).Times(1).Return(nil)
+		store.EXPECT().StoreShortURL(ctx, gomock.Any(), "some_url").Times(1).Return(errSome)
+		store.EXPECT().RefundDailyQuota(ctx, "user:some_id", 1).Times(1).Return(errors.New("refund error"))
+
+		_, err := AddShortURL(ctx, store, "some_url")
+		assert.ErrorIs(t, err, errSome)
+		assert.ErrorContains(t, err, "failed to refund daily quota")
+	})
+
+	t.Run("stored url keeps quota", func(t *testing.T) {
+		store.EXPECT().ConsumeDailyQuota(ctx, "user:some_id", 1, 1).Times(1).Return(nil)
+		store.EXPECT().StoreShortURL(ctx, gomock.Any(), "some_url").Times(1).Return(nil)
+		store.EXPECT().RefundDailyQuota(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		_, err := AddShortURL(ctx, store, "some_url")
+		assert.NoError(t, err)
+	})
+}
+
 func BenchmarkAddShortURL(b *testing.B) {
 	mockCtrl := gomock.NewController(b)
 	defer mockCtrl.Finish()
@@ -113,6 +182,58 @@ func TestAddBatchShortURL_Failed(t *testing.T) {
 	})
 }
 
+func TestAddBatchShortURL_QuotaExceeded(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ctx := context.WithValue(context.Background(), common.KeyUserID, "some_id")
+	store := mock.NewMockStorager(mockCtrl)
+	batch := models.BatchRequest{
+		{CorrelationID: "1", OriginalURL: "some_url"},
+		{CorrelationID: "2", OriginalURL: "other_url"},
+	}
+
+	config.Params.DailyURLsQuota = 10
+	defer func() { config.Params.DailyURLsQuota = 0 }()
+
+	store.EXPECT().ConsumeDailyQuota(ctx, "user:some_id", len(batch), 10).Times(1).Return(data.ErrQuotaExceeded)
+	store.EXPECT().StoreShortURLs(ctx, gomock.Any()).Times(0)
+
+	t.Run("add batch short URL quota exceeded", func(t *testing.T) {
+		_, err := AddBatchShortURL(ctx, store, batch)
+		assert.ErrorIs(t, err, data.ErrQuotaExceeded)
+	})
+}
+
+func TestAddBatchShortURL_QuotaRefunded(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ctx := context.WithValue(context.Background(), common.KeyUserID, "some_id")
+	store := mock.NewMockStorager(mockCtrl)
+	batch := models.BatchRequest{
+		{CorrelationID: "1", OriginalURL: "some_url"},
+		{CorrelationID: "2", OriginalURL: "other_url"},
+	}
+
+	config.Params.DailyURLsQuota = 10
+	defer func() { config.Params.DailyURLsQuota = 0 }()
+
+	store.EXPECT().ConsumeDailyQuota(ctx, "user:some_id", len(batch), 10).Times(1).Return(nil)
+	store.EXPECT().StoreShortURLs(ctx, gomock.Any()).Times(1).Return(errors.New("some error"))
+	store.EXPECT().RefundDailyQuota(ctx, "user:some_id", len(batch)).Times(1).Return(nil)
+
+	_, err := AddBatchShortURL(ctx, store, batch)
+	assert.ErrorContains(t, err, "failed to store short URLs")
+}
+
+func TestQuotaRetryAfter(t *testing.T) {
+	d := QuotaRetryAfter()
+
+	assert.Positive(t, d)
+	assert.LessOrEqual(t, d, 24*time.Hour)
+}
+
 func BenchmarkAddBatchShortURL(b *testing.B) {
 	mockCtrl := gomock.NewController(b)
 	defer mockCtrl.Finish()
@@ -179,6 +300,106 @@ func TestFetchUserURLs_Failed(t *testing.T) {
 	})
 }
 
+func TestGetURL(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ctx := context.Background()
+	store := mock.NewMockStorager(mockCtrl)
+
+	tests := []struct {
+		name string
+		url  models.URL
+		err  error
+		kind error
+	}{
+		{name: "success get", url: models.URL{OriginalURL: "https://ya.ru"}},
+		{name: "not found", err: data.ErrURLNotFound, kind: ErrNotFound},
+		{name: "deleted", url: models.URL{OriginalURL: "https://ya.ru", DeletedFlag: true}, kind: ErrGone},
+		{name: "storage error", err: errors.New("some error")},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store.EXPECT().GetURL(ctx, "short").Times(1).Return(test.url, test.err)
+
+			originalURL, err := GetURL(ctx, store, "short")
+
+			switch {
+			case test.kind != nil:
+				assert.ErrorIs(t, err, test.kind)
+			case test.err != nil:
+				assert.ErrorContains(t, err, "failed to get URL")
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, test.url.OriginalURL, originalURL)
+			}
+		})
+	}
+}
+
+func TestWalkUserURLs(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ctx := context.Background()
+	store := mock.NewMockStorager(mockCtrl)
+	errSome := errors.New("some error")
+
+	t.Run("walk pages until short page", func(t *testing.T) {
+		gomock.InOrder(
+			store.EXPECT().FetchURLsPage(ctx, "", "", 2).Times(1).Return([]models.URL{
+				{ShortURL: "a", OriginalURL: "https://a.ru"},
+				{ShortURL: "b", OriginalURL: "https://b.ru"},
+			}, nil),
+			store.EXPECT().FetchURLsPage(ctx, "", "b", 2).Times(1).Return([]models.URL{
+				{ShortURL: "c", OriginalURL: "https://c.ru"},
+			}, nil),
+		)
+
+		var pages []models.UserURLsResponse
+		err := WalkUserURLs(ctx, store, 2, func(page models.UserURLsResponse) error {
+			pages = append(pages, page)
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, pages, 2)
+		assert.Equal(t, "https://c.ru", pages[1][0].OriginalURL)
+	})
+
+	t.Run("empty page stops walk", func(t *testing.T) {
+		store.EXPECT().FetchURLsPage(ctx, "", "", 2).Times(1).Return([]models.URL{}, nil)
+
+		err := WalkUserURLs(ctx, store, 2, func(models.UserURLsResponse) error {
+			t.Fatal("unexpected page")
+			return nil
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("callback error stops walk", func(t *testing.T) {
+		store.EXPECT().FetchURLsPage(ctx, "", "", 1).Times(1).Return([]models.URL{{ShortURL: "a"}}, nil)
+
+		err := WalkUserURLs(ctx, store, 1, func(models.UserURLsResponse) error { return errSome })
+		assert.ErrorIs(t, err, errSome)
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		store.EXPECT().FetchURLsPage(ctx, "", "", 1).Times(1).Return(nil, errSome)
+
+		err := WalkUserURLs(ctx, store, 1, func(models.UserURLsResponse) error { return nil })
+		assert.ErrorContains(t, err, "failed to fetch URLs page")
+	})
+
+	t.Run("org viewer required", func(t *testing.T) {
+		orgCtx := context.WithValue(ctx, common.KeyOrgID, "org")
+		store.EXPECT().GetOrgRole(orgCtx, "org").Times(1).Return("", data.ErrMemberNotFound)
+
+		err := WalkUserURLs(orgCtx, store, 1, func(models.UserURLsResponse) error { return nil })
+		assert.ErrorIs(t, err, common.ErrPermDenied)
+	})
+}
+
 func BenchmarkFetchUserURLs(b *testing.B) {
 	mockCtrl := gomock.NewController(b)
 	defer mockCtrl.Finish()
@@ -371,3 +592,28 @@ func TestFetchStats_Failed(t *testing.T) {
 		assert.ErrorContains(t, err, "failed to fetch stats", "some error")
 	})
 }
+
+func TestGetURL_Span(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ctx := context.Background()
+	store := mock.NewMockStorager(mockCtrl)
+	store.EXPECT().GetURL(gomock.Any(), "short").Times(1).DoAndReturn(func(ctx context.Context, _ string) (models.URL, error) {
+		assert.True(t, trace.SpanContextFromContext(ctx).IsValid())
+		return models.URL{}, data.ErrURLNotFound
+	})
+
+	_, err := GetURL(ctx, store, "short")
+	assert.ErrorIs(t, err, data.ErrURLNotFound)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, "services.GetURL", spans[0].Name())
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+	}
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+	"github.com/MihailSergeenkov/shortener/internal/app/tracing"
+)
+
+// WalkExportURLs функция постраничной выгрузки ссылок пользователя или выбранной в контексте организации
+// вместе с датой создания и признаком удаления.
+func WalkExportURLs(ctx context.Context, s data.Storager, pageSize int, fn func([]models.ExportURL) error) (err error) {
+	ctx, span := tracing.Start(ctx, "services.WalkExportURLs")
+	defer tracing.End(span, &err)
+
+	return walkURLs(ctx, s, pageSize, func(urls []models.URL) error {
+		page := make([]models.ExportURL, 0, len(urls))
+		for _, u := range urls {
+			e := models.ExportURL{
+				ShortURL:    ShortLink(u.ShortURL),
+				OriginalURL: u.OriginalURL,
+				DeletedFlag: u.DeletedFlag,
+			}
+			if !u.CreatedAt.IsZero() {
+				createdAt := u.CreatedAt
+				e.CreatedAt = &createdAt
+			}
+
+			page = append(page, e)
+		}
+
+		return fn(page)
+	})
+}
+
+// ImportUserURLs функция загрузки ссылок пакетом с результатом по каждой строке.
+// Желаемые ключи сохраняются, если свободны; удаленные в источнике и повторяющиеся ссылки пропускаются.
+func ImportUserURLs(
+	ctx context.Context,
+	s data.Storager,
+	rows []models.ImportRow,
+) (_ models.ImportResponse, err error) {
+	ctx, span := tracing.Start(ctx, "services.ImportUserURLs")
+	defer tracing.End(span, &err)
+
+	resp := models.ImportResponse{Results: make([]models.ImportRowResult, len(rows))}
+	batch := models.BatchRequest{}
+	keys := make([]string, 0, len(rows))
+	wanted := make(map[string]string, len(rows))
+	seen := make(map[string]int, len(rows))
+
+	for i, row := range rows {
+		originalURL := strings.TrimSpace(row.OriginalURL)
+		result := &resp.Results[i]
+		result.Row = i + 1
+		result.OriginalURL = originalURL
+
+		if !validOriginalURL(originalURL) {
+			result.Status, result.Message = models.ImportInvalid, "invalid original url"
+			continue
+		}
+
+		if row.DeletedFlag {
+			result.Status, result.Message = models.ImportSkipped, "url is deleted"
+			continue
+		}
+
+		if prev, ok := seen[originalURL]; ok {
+			result.Status, result.Message = models.ImportSkipped, fmt.Sprintf("duplicate of row %d", prev)
+			continue
+		}
+		seen[originalURL] = result.Row
+
+		correlationID := strconv.Itoa(i)
+		key := linkKey(row.ShortURL)
+		wanted[correlationID] = key
+		keys = append(keys, key)
+		batch = append(batch, models.BatchDataRequest{
+			CorrelationID: correlationID,
+			OriginalURL:   originalURL,
+		})
+	}
+
+	if len(batch) > 0 {
+		created, err := addBatchShortURL(ctx, s, batch, keys)
+		if err != nil {
+			return models.ImportResponse{}, err
+		}
+
+		for _, c := range created {
+			i, _ := strconv.Atoi(c.CorrelationID)
+			result := &resp.Results[i]
+			result.ShortURL = c.ShortURL
+			result.Status = models.ImportCreated
+
+			if key := wanted[c.CorrelationID]; key != "" && ShortLink(key) != c.ShortURL {
+				result.Status, result.Message = models.ImportRenamed, fmt.Sprintf("key %q is unavailable", key)
+			}
+		}
+
+		if err := checkImported(ctx, s, resp.Results); err != nil {
+			return models.ImportResponse{}, err
+		}
+	}
+
+	for _, result := range resp.Results {
+		switch result.Status {
+		case models.ImportCreated:
+			resp.Created++
+		case models.ImportRenamed:
+			resp.Renamed++
+		case models.ImportSkipped:
+			resp.Skipped++
+		case models.ImportInvalid:
+			resp.Invalid++
+		}
+	}
+
+	return resp, nil
+}
+
+// checkImported помечает пропущенными строки, которые хранилище не сохранило,
+// так как оригинальная ссылка уже сокращена ранее.
+func checkImported(ctx context.Context, s data.Storager, results []models.ImportRowResult) error {
+	for i := range results {
+		result := &results[i]
+		if result.Status != models.ImportCreated && result.Status != models.ImportRenamed {
+			continue
+		}
+
+		u, err := s.GetURL(ctx, path.Base(result.ShortURL))
+		if err != nil && !errors.Is(err, data.ErrURLNotFound) {
+			return fmt.Errorf("failed to check imported URL: %w", err)
+		}
+
+		if err != nil || u.OriginalURL != result.OriginalURL {
+			result.Status, result.Message = models.ImportSkipped, "original url is already shortened"
+			result.ShortURL = ""
+		}
+	}
+
+	return nil
+}
+
+// linkKey возвращает ключ из короткой ссылки любого сокращателя (https://bit.ly/abc, bit.ly/abc) или сам ключ.
+func linkKey(link string) string {
+	link = strings.TrimSpace(link)
+	if i := strings.IndexAny(link, "?#"); i >= 0 {
+		link = link[:i]
+	}
+	link = strings.TrimRight(link, "/")
+
+	return link[strings.LastIndex(link, "/")+1:]
+}
+
+func validOriginalURL(rawURL string) bool {
+	u, err := url.ParseRequestURI(rawURL)
+	if err != nil {
+		return false
+	}
+
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/data/mock"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+)
+
+func TestImportUserURLs(t *testing.T) {
+	store := data.NewBaseStorage()
+	ctx := userContext("user_id")
+	otherCtx := userContext("other_id")
+
+	require.NoError(t, store.StoreShortURL(otherCtx, "taken", "https://ya.ru/taken"))
+
+	rows := []models.ImportRow{
+		{ShortURL: "https://bit.ly/custom", OriginalURL: "https://ya.ru/1"},
+		{ShortURL: "bit.ly/taken", OriginalURL: "https://ya.ru/2"},
+		{OriginalURL: " https://ya.ru/3 "},
+		{ShortURL: "deleted", OriginalURL: "https://ya.ru/4", DeletedFlag: true},
+		{ShortURL: "again", OriginalURL: "https://ya.ru/1"},
+		{ShortURL: "bad", OriginalURL: "not a url"},
+		{ShortURL: "api", OriginalURL: "https://ya.ru/5"},
+	}
+
+	resp, err := ImportUserURLs(ctx, store, rows)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, resp.Created)
+	assert.Equal(t, 2, resp.Renamed)
+	assert.Equal(t, 2, resp.Skipped)
+	assert.Equal(t, 1, resp.Invalid)
+	require.Len(t, resp.Results, len(rows))
+
+	statuses := make([]string, 0, len(rows))
+	for _, r := range resp.Results {
+		statuses = append(statuses, r.Status)
+	}
+	assert.Equal(t, []string{
+		models.ImportCreated,
+		models.ImportRenamed,
+		models.ImportCreated,
+		models.ImportSkipped,
+		models.ImportSkipped,
+		models.ImportInvalid,
+		models.ImportRenamed,
+	}, statuses)
+
+	assert.Equal(t, ShortLink("custom"), resp.Results[0].ShortURL)
+	assert.Equal(t, "duplicate of row 1", resp.Results[4].Message)
+	assert.Equal(t, "https://ya.ru/3", resp.Results[2].OriginalURL)
+
+	u, err := store.GetURL(ctx, "custom")
+	require.NoError(t, err)
+	assert.Equal(t, "user_id", u.UserID)
+	assert.False(t, u.CreatedAt.IsZero())
+
+	t.Run("nothing to import", func(t *testing.T) {
+		resp, err := ImportUserURLs(ctx, store, []models.ImportRow{{OriginalURL: "ftp://ya.ru"}})
+		require.NoError(t, err)
+		assert.Equal(t, 1, resp.Invalid)
+	})
+}
+
+func TestImportUserURLs_AlreadyShortened(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ctx := userContext("user_id")
+	store := mock.NewMockStorager(mockCtrl)
+
+	store.EXPECT().StoreShortURLs(ctx, gomock.Any()).Times(1).Return(nil)
+	store.EXPECT().GetURL(ctx, gomock.Any()).Times(1).Return(models.URL{OriginalURL: "https://ya.ru/other"}, nil)
+
+	resp, err := ImportUserURLs(ctx, store, []models.ImportRow{{OriginalURL: "https://ya.ru"}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, resp.Skipped)
+	assert.Equal(t, "original url is already shortened", resp.Results[0].Message)
+	assert.Empty(t, resp.Results[0].ShortURL)
+}
+
+func TestWalkExportURLs(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ctx := context.Background()
+	store := mock.NewMockStorager(mockCtrl)
+	createdAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	store.EXPECT().FetchURLsPage(ctx, "", "", 10).Times(1).Return([]models.URL{
+		{ShortURL: "a", OriginalURL: "https://a.ru", CreatedAt: createdAt},
+		{ShortURL: "b", OriginalURL: "https://b.ru", DeletedFlag: true},
+	}, nil)
+
+	var exported []models.ExportURL
+	err := WalkExportURLs(ctx, store, 10, func(page []models.ExportURL) error {
+		exported = append(exported, page...)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, exported, 2)
+	assert.Equal(t, &createdAt, exported[0].CreatedAt)
+	assert.Nil(t, exported[1].CreatedAt)
+	assert.True(t, exported[1].DeletedFlag)
+
+	errSome := errors.New("some error")
+	store.EXPECT().FetchURLsPage(ctx, "", "", 10).Times(1).Return(nil, errSome)
+	err = WalkExportURLs(ctx, store, 10, func([]models.ExportURL) error { return nil })
+	assert.ErrorIs(t, err, errSome)
+}
+
+func TestLinkKey(t *testing.T) {
+	tests := map[string]string{
+		"https://bit.ly/abc":    "abc",
+		"bit.ly/abc/":           "abc",
+		"abc":                   "abc",
+		"http://host/x/abc?q=1": "abc",
+		"":                      "",
+	}
+
+	for link, key := range tests {
+		assert.Equal(t, key, linkKey(link), link)
+	}
+}
+
+func TestImportUserURLs_KeyTakenWhileStoring(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ctx := userContext("user_id")
+	store := mock.NewMockStorager(mockCtrl)
+
+	var stored []models.URL
+	first := store.EXPECT().StoreShortURLs(ctx, gomock.Any()).Times(1).
+		Return(fmt.Errorf("unable to insert batch: %w", data.ErrShortURLAlreadyExist))
+	store.EXPECT().StoreShortURLs(ctx, gomock.Any()).Times(1).After(first).
+		DoAndReturn(func(_ context.Context, urls []models.URL) error {
+			stored = urls
+			return nil
+		})
+	store.EXPECT().GetURL(ctx, "my-key").Times(1).Return(models.URL{OriginalURL: "https://ya.ru/other"}, nil)
+	store.EXPECT().GetURL(ctx, "free-key").Times(1).Return(models.URL{}, data.ErrURLNotFound)
+	store.EXPECT().GetURL(ctx, gomock.Any()).Times(2).DoAndReturn(
+		func(_ context.Context, shortURL string) (models.URL, error) {
+			for _, u := range stored {
+				if u.ShortURL == shortURL {
+					return u, nil
+				}
+			}
+			return models.URL{}, data.ErrURLNotFound
+		})
+
+	resp, err := ImportUserURLs(ctx, store, []models.ImportRow{
+		{ShortURL: "my-key", OriginalURL: "https://ya.ru/1"},
+		{ShortURL: "free-key", OriginalURL: "https://ya.ru/2"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, resp.Renamed)
+	assert.Equal(t, 1, resp.Created)
+	assert.NotEqual(t, ShortLink("my-key"), resp.Results[0].ShortURL)
+	assert.Equal(t, ShortLink("free-key"), resp.Results[1].ShortURL)
+}
+
+func TestImportUserURLs_KeysInBatch(t *testing.T) {
+	store := data.NewBaseStorage()
+	ctx := userContext("user_id")
+
+	resp, err := ImportUserURLs(ctx, store, []models.ImportRow{
+		{ShortURL: "my-key", OriginalURL: "https://ya.ru/1"},
+		{ShortURL: "my-key", OriginalURL: "https://ya.ru/2"},
+		{ShortURL: "bad key", OriginalURL: "https://ya.ru/3"},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 3)
+	assert.Equal(t, ShortLink("my-key"), resp.Results[0].ShortURL)
+	assert.Equal(t, models.ImportRenamed, resp.Results[1].Status)
+	assert.Equal(t, models.ImportRenamed, resp.Results[2].Status)
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+	"github.com/MihailSergeenkov/shortener/internal/app/tracing"
+)
+
+const secretBytes = 32
+
+// Ошибки управления вебхуками.
+var (
+	ErrInvalidWebhookURL    = errors.New("invalid webhook url")    // адрес вебхука не абсолютный http(s) адрес
+	ErrInvalidWebhookEvents = errors.New("invalid webhook events") // не заданы или неизвестны события вебхука
+)
+
+// webhookEvents события, на которые можно подписать вебхук.
+var webhookEvents = []string{models.LinkCreated, models.LinkDeleted, models.LinkClicked}
+
+// CreateWebhook функция создания вебхука пользователя. Если ключ подписи не задан, он генерируется.
+// Ключ возвращается только в ответе на создание.
+func CreateWebhook(ctx context.Context, s data.Storager, req models.WebhookRequest) (_ models.Webhook, err error) {
+	ctx, span := tracing.Start(ctx, "services.CreateWebhook")
+	defer tracing.End(span, &err)
+
+	userID, ok := ctx.Value(common.KeyUserID).(string)
+	if !ok {
+		return models.Webhook{}, common.ErrFetchUserIDFromContext
+	}
+
+	events, err := validateWebhook(req)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+
+	id, err := generateShortURL()
+	if err != nil {
+		return models.Webhook{}, fmt.Errorf("failed to generate webhook ID: %w", err)
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		if secret, err = generateSecret(); err != nil {
+			return models.Webhook{}, err
+		}
+	}
+
+	webhook := models.Webhook{
+		ID:        id,
+		UserID:    userID,
+		URL:       req.URL,
+		Secret:    secret,
+		Events:    events,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if err := s.StoreWebhook(ctx, webhook); err != nil {
+		return models.Webhook{}, fmt.Errorf("failed to store webhook: %w", err)
+	}
+
+	return webhook, nil
+}
+
+// FetchUserWebhooks функция получения вебхуков пользователя без ключей подписи.
+func FetchUserWebhooks(ctx context.Context, s data.Storager) (_ []models.Webhook, err error) {
+	ctx, span := tracing.Start(ctx, "services.FetchUserWebhooks")
+	defer tracing.End(span, &err)
+
+	webhooks, err := s.FetchUserWebhooks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch webhooks: %w", err)
+	}
+
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+
+	return webhooks, nil
+}
+
+// UpdateWebhook функция изменения адреса и событий вебхука пользователя.
+// Ключ подписи меняется, только если он задан в запросе.
+func UpdateWebhook(
+	ctx context.Context,
+	s data.Storager,
+	id string,
+	req models.WebhookRequest,
+) (_ models.Webhook, err error) {
+	ctx, span := tracing.Start(ctx, "services.UpdateWebhook")
+	defer tracing.End(span, &err)
+
+	events, err := validateWebhook(req)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+
+	webhooks, err := s.FetchUserWebhooks(ctx)
+	if err != nil {
+		return models.Webhook{}, fmt.Errorf("failed to fetch webhooks: %w", err)
+	}
+
+	i := slices.IndexFunc(webhooks, func(w models.Webhook) bool { return w.ID == id })
+	if i < 0 {
+		return models.Webhook{}, webhookNotFound(id, data.ErrWebhookNotFound)
+	}
+
+	webhook := webhooks[i]
+	webhook.URL = req.URL
+	webhook.Events = events
+	if req.Secret != "" {
+		webhook.Secret = req.Secret
+	}
+
+	if err := s.UpdateWebhook(ctx, webhook); err != nil {
+		if errors.Is(err, data.ErrWebhookNotFound) {
+			return models.Webhook{}, webhookNotFound(id, err)
+		}
+
+		return models.Webhook{}, fmt.Errorf("failed to update webhook: %w", err)
+	}
+
+	webhook.Secret = ""
+
+	return webhook, nil
+}
+
+// DeleteWebhook функция удаления вебхука пользователя вместе с неотправленными доставками.
+func DeleteWebhook(ctx context.Context, s data.Storager, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "services.DeleteWebhook")
+	defer tracing.End(span, &err)
+
+	if err := s.DeleteWebhook(ctx, id); err != nil {
+		if errors.Is(err, data.ErrWebhookNotFound) {
+			return webhookNotFound(id, err)
+		}
+
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	return nil
+}
+
+// FetchDeadWebhookDeliveries функция получения недоставленных событий вебхуков пользователя.
+func FetchDeadWebhookDeliveries(ctx context.Context, s data.Storager) (_ []models.WebhookDelivery, err error) {
+	ctx, span := tracing.Start(ctx, "services.FetchDeadWebhookDeliveries")
+	defer tracing.End(span, &err)
+
+	deliveries, err := s.FetchDeadWebhookDeliveries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch dead webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// validateWebhook проверяет запрос и возвращает события вебхука без повторов.
+func validateWebhook(req models.WebhookRequest) ([]string, error) {
+	if !validOriginalURL(req.URL) {
+		return nil, newError(ErrInvalid, ReasonInvalidWebhook, ErrInvalidWebhookURL)
+	}
+
+	if len(req.Events) == 0 {
+		return nil, newError(ErrInvalid, ReasonInvalidWebhook, ErrInvalidWebhookEvents)
+	}
+
+	events := make([]string, 0, len(req.Events))
+	for _, event := range req.Events {
+		if !slices.Contains(webhookEvents, event) {
+			return nil, newError(ErrInvalid, ReasonInvalidWebhook, fmt.Errorf("%w: %q", ErrInvalidWebhookEvents, event))
+		}
+		if !slices.Contains(events, event) {
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+func webhookNotFound(id string, err error) error {
+	return newResourceError(ErrNotFound, ReasonWebhookNotFound, err, ResourceWebhook, id)
+}
+
+func generateSecret() (string, error) {
+	bytes := make([]byte, secretBytes)
+
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("generate webhook secret error: %w", err)
+	}
+
+	return hex.EncodeToString(bytes), nil
+}
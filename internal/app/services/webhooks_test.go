@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+)
+
+func TestWebhookWorkflow(t *testing.T) {
+	store := data.NewBaseStorage()
+	ctx := userContext("owner_id")
+
+	webhook, err := CreateWebhook(ctx, store, models.WebhookRequest{
+		URL:    "https://example.com/hook",
+		Events: []string{models.LinkCreated, models.LinkCreated, models.LinkClicked},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, webhook.ID)
+	assert.NotEmpty(t, webhook.Secret, "secret is generated")
+	assert.Equal(t, []string{models.LinkCreated, models.LinkClicked}, webhook.Events)
+
+	t.Run("list hides secret", func(t *testing.T) {
+		webhooks, err := FetchUserWebhooks(ctx, store)
+		require.NoError(t, err)
+		require.Len(t, webhooks, 1)
+		assert.Empty(t, webhooks[0].Secret)
+
+		others, err := FetchUserWebhooks(userContext("other_id"), store)
+		require.NoError(t, err)
+		assert.Empty(t, others)
+	})
+
+	t.Run("update keeps secret", func(t *testing.T) {
+		updated, err := UpdateWebhook(ctx, store, webhook.ID, models.WebhookRequest{
+			URL:    "https://example.com/other",
+			Events: []string{models.LinkDeleted},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com/other", updated.URL)
+		assert.Empty(t, updated.Secret)
+
+		webhooks, err := store.FetchEventWebhooks(ctx, "owner_id", models.LinkDeleted)
+		require.NoError(t, err)
+		require.Len(t, webhooks, 1)
+		assert.Equal(t, webhook.Secret, webhooks[0].Secret)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		_, err := CreateWebhook(ctx, store, models.WebhookRequest{URL: "ftp://x", Events: []string{models.LinkCreated}})
+		require.ErrorIs(t, err, ErrInvalidWebhookURL)
+		require.ErrorIs(t, err, ErrInvalid)
+
+		_, err = CreateWebhook(ctx, store, models.WebhookRequest{URL: "https://example.com"})
+		require.ErrorIs(t, err, ErrInvalidWebhookEvents)
+
+		_, err = CreateWebhook(ctx, store, models.WebhookRequest{URL: "https://example.com", Events: []string{"update"}})
+		require.ErrorIs(t, err, ErrInvalidWebhookEvents)
+	})
+
+	t.Run("foreign webhook not found", func(t *testing.T) {
+		other := userContext("other_id")
+		req := models.WebhookRequest{URL: "https://example.com", Events: []string{models.LinkCreated}}
+
+		_, err := UpdateWebhook(other, store, webhook.ID, req)
+		require.ErrorIs(t, err, ErrNotFound)
+
+		err = DeleteWebhook(other, store, webhook.ID)
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, DeleteWebhook(ctx, store, webhook.ID))
+
+		err := DeleteWebhook(ctx, store, webhook.ID)
+		require.ErrorIs(t, err, data.ErrWebhookNotFound)
+	})
+}
+
+func TestLinkEvents(t *testing.T) {
+	store := data.NewBaseStorage()
+	ctx := userContext("owner_id")
+
+	var (
+		events []models.LinkEvent
+		mu     sync.Mutex
+	)
+	unsubscribe := OnLinkEvent(func(_ context.Context, event models.LinkEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		events = append(events, event)
+	})
+
+	shortURL, err := AddShortURL(ctx, store, "https://ya.ru")
+	require.NoError(t, err)
+
+	_, err = GetURL(context.Background(), store, shortURL)
+	require.NoError(t, err)
+
+	require.NoError(t, DeleteUserURLs(ctx, zap.NewNop(), store, []string{shortURL, "unknown"}))
+
+	unsubscribe()
+	_, err = AddShortURL(ctx, store, "https://ya.ru/other")
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Len(t, events, 3)
+	for i, eventType := range []string{models.LinkCreated, models.LinkClicked, models.LinkDeleted} {
+		assert.Equal(t, eventType, events[i].Type)
+		assert.Equal(t, shortURL, events[i].ShortURL)
+		assert.Equal(t, "https://ya.ru", events[i].OriginalURL)
+		assert.Equal(t, "owner_id", events[i].UserID)
+		assert.False(t, events[i].Time.IsZero())
+	}
+}
+
+func TestLinkEvents_HandlerUnsubscribes(t *testing.T) {
+	store := data.NewBaseStorage()
+	ctx := userContext("owner_id")
+
+	calls := 0
+	var unsubscribe func()
+	unsubscribe = OnLinkEvent(func(context.Context, models.LinkEvent) {
+		calls++
+		unsubscribe()
+	})
+
+	_, err := AddShortURL(ctx, store, "https://ya.ru/unsubscribe/1")
+	require.NoError(t, err)
+	_, err = AddShortURL(ctx, store, "https://ya.ru/unsubscribe/2")
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,131 @@
+// Пакет tracing предназначен для трассировки запросов OpenTelemetry:
+// настройка экспортера спанов и распространения контекста трассировки W3C, а также создание спанов.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/config"
+)
+
+const (
+	serviceName         = "shortener"
+	instrumentationName = "github.com/MihailSergeenkov/shortener"
+	traceFilePerm       = 0o600
+)
+
+// ErrUnknownExporter ошибка неизвестного экспортера трассировки.
+var ErrUnknownExporter = errors.New("unknown trace exporter")
+
+// ShutdownFunc функция, отправляющая накопленные спаны и освобождающая ресурсы экспортера.
+type ShutdownFunc func(ctx context.Context) error
+
+// Setup настраивает глобальный провайдер трассировки по параметрам params.
+// Распространение контекста трассировки W3C (traceparent, baggage) включается и без экспортера.
+func Setup(ctx context.Context, params *config.Settings) (ShutdownFunc, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	exporter, closer, err := newExporter(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if exporter == nil {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(params.TraceRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName))),
+	)
+	otel.SetTracerProvider(tp)
+
+	return func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+		if closer != nil {
+			err = errors.Join(err, closer.Close())
+		}
+		if err != nil {
+			return fmt.Errorf("failed to shutdown tracer provider: %w", err)
+		}
+
+		return nil
+	}, nil
+}
+
+// newExporter создает экспортер спанов, для экспортера в файл возвращается и файл, закрываемый после провайдера.
+func newExporter(ctx context.Context, params *config.Settings) (sdktrace.SpanExporter, io.Closer, error) {
+	switch params.TraceExporter {
+	case config.TraceExporterNone, "":
+		return nil, nil, nil
+	case config.TraceExporterOTLP:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(params.OTLPEndpoint)}
+		if params.OTLPInsecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+
+		exporter, err := otlptracegrpc.New(ctx, opts...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create otlp exporter: %w", err)
+		}
+
+		return exporter, nil, nil
+	case config.TraceExporterStdout:
+		exporter, err := stdouttrace.New()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create stdout exporter: %w", err)
+		}
+
+		return exporter, nil, nil
+	case config.TraceExporterFile:
+		f, err := os.OpenFile(params.TraceFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, traceFilePerm)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open trace file: %w", err)
+		}
+
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			return nil, nil, errors.Join(fmt.Errorf("failed to create file exporter: %w", err), f.Close())
+		}
+
+		return exporter, f, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: %q", ErrUnknownExporter, params.TraceExporter)
+	}
+}
+
+// Start начинает спан name дочерним к спану из ctx.
+// При отключенной трассировке и отсутствии родительского спана контекст возвращается без изменений.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	spanCtx, span := otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+	if !span.IsRecording() && !span.SpanContext().IsValid() {
+		return ctx, span
+	}
+
+	return spanCtx, span
+}
+
+// End завершает спан, отмечая его ошибкой, если *err не nil.
+// Принимает указатель, чтобы в defer учитывалось значение ошибки на момент возврата из функции.
+func End(span trace.Span, err *error) {
+	if err != nil && *err != nil {
+		span.RecordError(*err)
+		span.SetStatus(codes.Error, (*err).Error())
+	}
+
+	span.End()
+}
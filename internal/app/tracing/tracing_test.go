@@ -0,0 +1,83 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/config"
+)
+
+func TestSetup(t *testing.T) {
+	ctx := context.Background()
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	t.Run("file exporter", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "traces.json")
+		shutdown, err := Setup(ctx, &config.Settings{TraceExporter: config.TraceExporterFile, TraceFile: path, TraceRatio: 1})
+		require.NoError(t, err)
+
+		_, span := Start(ctx, "services.GetURL")
+		span.End()
+		require.NoError(t, shutdown(ctx))
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), `"Name":"services.GetURL"`)
+		assert.Contains(t, string(content), `"Value":"shortener"`)
+	})
+
+	t.Run("without exporter", func(t *testing.T) {
+		shutdown, err := Setup(ctx, &config.Settings{TraceExporter: config.TraceExporterNone})
+		require.NoError(t, err)
+		require.NoError(t, shutdown(ctx))
+
+		carrier := propagation.MapCarrier{"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"}
+		ctx := otel.GetTextMapPropagator().Extract(ctx, carrier)
+
+		out := propagation.MapCarrier{}
+		otel.GetTextMapPropagator().Inject(ctx, out)
+		assert.Equal(t, carrier["traceparent"], out["traceparent"])
+	})
+
+	t.Run("unknown exporter", func(t *testing.T) {
+		_, err := Setup(ctx, &config.Settings{TraceExporter: "jaeger"})
+		require.ErrorIs(t, err, ErrUnknownExporter)
+	})
+
+	t.Run("broken trace file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "missing", "traces.json")
+		_, err := Setup(ctx, &config.Settings{TraceExporter: config.TraceExporterFile, TraceFile: path})
+		require.ErrorContains(t, err, "failed to open trace file")
+	})
+}
+
+func TestEnd(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+	someErr := errors.New("some error")
+
+	_, span := tracer.Start(context.Background(), "failed")
+	End(span, &someErr)
+
+	var noErr error
+	_, span = tracer.Start(context.Background(), "succeeded")
+	End(span, &noErr)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "some error", spans[0].Status().Description)
+	assert.Len(t, spans[0].Events(), 1)
+	assert.Empty(t, spans[1].Status().Description)
+	assert.Empty(t, spans[1].Events())
+}
@@ -0,0 +1,92 @@
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+const dialTimeout = 5 * time.Second
+
+// ErrAddressNotAllowed адрес получателя вебхука не является публичным.
+var ErrAddressNotAllowed = errors.New("webhook address is not allowed")
+
+// reservedNets сети, которые не входят в проверки net.IP, но также недоступны из интернета
+// или ведут во внутренние сети: CGNAT, служебные, тестовые и NAT64.
+var reservedNets = parseNets(
+	"0.0.0.0/8", "100.64.0.0/10", "192.0.0.0/24", "198.18.0.0/15", "240.0.0.0/4", "64:ff9b::/96",
+)
+
+// newClient создает HTTP клиент доставки, который не подключается к непубличным адресам.
+// Адрес проверяется при подключении после разрешения имени, поэтому DNS не позволяет обойти проверку.
+// Перенаправления не выполняются и считаются неудачной доставкой, прокси из окружения не используется.
+func newClient() *http.Client {
+	return newClientWithControl(checkPublicAddress)
+}
+
+func newClientWithControl(control func(network, address string, c syscall.RawConn) error) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: dialTimeout,
+		Control: control,
+	}
+
+	return &http.Client{
+		Timeout: requestTimeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   dialTimeout,
+			ResponseHeaderTimeout: requestTimeout,
+			MaxIdleConnsPerHost:   1,
+			IdleConnTimeout:       time.Minute,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// checkPublicAddress запрещает подключение к петлевым, частным, локальным и служебным адресам.
+func checkPublicAddress(_ string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("failed to parse address: %w", err)
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !publicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrAddressNotAllowed, host)
+	}
+
+	return nil
+}
+
+func publicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+
+	for _, n := range reservedNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func parseNets(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+
+	return nets
+}
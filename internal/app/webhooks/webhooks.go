@@ -0,0 +1,437 @@
+// Пакет webhooks предназначен для уведомления внешних систем о событиях ссылок пользователя.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/logger"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+	"github.com/MihailSergeenkov/shortener/internal/app/services"
+)
+
+// Заголовки запроса доставки события.
+const (
+	HeaderWebhookID = "X-Webhook-ID"        // идентификатор вебхука
+	HeaderDelivery  = "X-Webhook-Delivery"  // идентификатор доставки, одинаков для всех попыток
+	HeaderEvent     = "X-Webhook-Event"     // событие: create, delete или click
+	HeaderTimestamp = "X-Webhook-Timestamp" // время попытки в секундах Unix
+	HeaderSignature = "X-Webhook-Signature" // подпись sha256=<hex>, см. Sign
+)
+
+const (
+	signaturePrefix = "sha256="
+	deliveryIDBytes = 16
+	pollPeriod      = time.Second
+	batchSize       = 100
+	maxWorkers      = 8
+	eventQueueSize  = 1024
+	requestTimeout  = 10 * time.Second
+	maxRetryDelay   = 6 * time.Hour
+	maxErrorLength  = 512
+)
+
+var errUnexpectedStatus = errors.New("unexpected response status")
+
+// Причины неудачной доставки, которые видит владелец вебхука. Подробности ошибки пишутся только в журнал,
+// чтобы ответы недоступных получателей не раскрывали устройство сети.
+const (
+	errorNotAllowed = "address is not allowed"
+	errorTimeout    = "request timeout"
+	errorConnection = "connection failed"
+)
+
+// payload тело запроса доставки события.
+type payload struct {
+	ID        string           `json:"id"`
+	Event     string           `json:"event"`
+	Time      time.Time        `json:"time"`
+	ShortLink string           `json:"short_link"`
+	Link      models.LinkEvent `json:"link"`
+}
+
+// Sign возвращает подпись тела body, отправленного в момент timestamp, ключом secret:
+// sha256=<hex HMAC-SHA256 от "<timestamp>.<body>">. Получатель проверяет подпись тем же способом.
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatcher ставит события ссылок в исходящую очередь хранилища и доставляет их вебхукам.
+// События попадают в хранилище через буфер в памяти, поэтому запрос не ждет обращения к хранилищу.
+// Очередь хранится в хранилище, поэтому недоставленные события переживают перезапуск сервиса.
+// Доставки одного вебхука отправляются по очереди, разные вебхуки обслуживаются параллельно,
+// поэтому медленный получатель не задерживает доставку остальным.
+type Dispatcher struct {
+	logger      *zap.Logger
+	store       data.Storager
+	client      *http.Client
+	inFlight    map[string]struct{}
+	workers     chan struct{}
+	events      chan queuedEvent
+	wg          sync.WaitGroup
+	mu          sync.Mutex
+	maxAttempts int
+	retryDelay  time.Duration
+}
+
+// NewDispatcher создает доставщик событий вебхукам. Неудачная доставка повторяется с задержкой retryDelay,
+// удваивающейся с каждой попыткой, после maxAttempts попыток событие переносится в список недоставленных.
+func NewDispatcher(l *zap.Logger, s data.Storager, maxAttempts int, retryDelay time.Duration) *Dispatcher {
+	return &Dispatcher{
+		logger:      l,
+		store:       s,
+		client:      newClient(),
+		inFlight:    map[string]struct{}{},
+		workers:     make(chan struct{}, maxWorkers),
+		events:      make(chan queuedEvent, eventQueueSize),
+		maxAttempts: maxAttempts,
+		retryDelay:  retryDelay,
+	}
+}
+
+// queuedEvent событие, ожидающее постановки в очередь доставки, с журналом запроса, в котором оно возникло.
+type queuedEvent struct {
+	logger *zap.Logger
+	event  models.LinkEvent
+}
+
+// Enqueue передает событие на постановку в очередь доставки вебхукам владельца ссылки, не обращаясь к хранилищу.
+// Подходит как обработчик services.OnLinkEvent: если буфер заполнен, событие пропускается с записью в журнал.
+func (d *Dispatcher) Enqueue(ctx context.Context, event models.LinkEvent) {
+	if event.UserID == "" {
+		return
+	}
+
+	queued := queuedEvent{logger: logger.FromContext(ctx, d.logger), event: event}
+	select {
+	case d.events <- queued:
+	default:
+		queued.logger.Warn("webhook event queue is full, event dropped",
+			zap.String("event", event.Type), zap.String("short_url", event.ShortURL))
+	}
+}
+
+// runQueue ставит переданные события в очередь доставки до отмены ctx,
+// после отмены сохраняет события, оставшиеся в буфере.
+func (d *Dispatcher) runQueue(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), requestTimeout)
+			d.flush(flushCtx)
+			cancel()
+			return
+		case queued := <-d.events:
+			d.enqueue(ctx, queued)
+		}
+	}
+}
+
+// flush ставит в очередь доставки все события из буфера.
+func (d *Dispatcher) flush(ctx context.Context) {
+	for {
+		select {
+		case queued := <-d.events:
+			d.enqueue(ctx, queued)
+		default:
+			return
+		}
+	}
+}
+
+// enqueue ставит событие в очередь доставки вебхукам владельца ссылки, подписанным на событие.
+func (d *Dispatcher) enqueue(ctx context.Context, queued queuedEvent) {
+	event := queued.event
+
+	webhooks, err := d.store.FetchEventWebhooks(ctx, event.UserID, event.Type)
+	if err != nil {
+		queued.logger.Error("failed to fetch webhooks for event", zap.Error(err))
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+
+	deliveries := make([]models.WebhookDelivery, 0, len(webhooks))
+	for _, w := range webhooks {
+		delivery, err := newDelivery(w.ID, event)
+		if err != nil {
+			queued.logger.Error("failed to prepare webhook delivery", zap.Error(err))
+			return
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := d.store.StoreWebhookDeliveries(ctx, deliveries); err != nil {
+		queued.logger.Error("failed to store webhook deliveries", zap.Error(err))
+	}
+}
+
+func newDelivery(webhookID string, event models.LinkEvent) (models.WebhookDelivery, error) {
+	id := make([]byte, deliveryIDBytes)
+	if _, err := rand.Read(id); err != nil {
+		return models.WebhookDelivery{}, fmt.Errorf("generate delivery ID error: %w", err)
+	}
+
+	delivery := models.WebhookDelivery{
+		ID:          hex.EncodeToString(id),
+		WebhookID:   webhookID,
+		Event:       event.Type,
+		NextAttempt: event.Time,
+		CreatedAt:   event.Time,
+	}
+
+	body, err := json.Marshal(payload{
+		ID:        delivery.ID,
+		Event:     event.Type,
+		Time:      event.Time,
+		ShortLink: services.ShortLink(event.ShortURL),
+		Link:      event,
+	})
+	if err != nil {
+		return models.WebhookDelivery{}, fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+	delivery.Payload = body
+
+	return delivery, nil
+}
+
+// Run ставит переданные события в очередь и доставляет события из очереди до отмены ctx,
+// затем ждет завершения начатых доставок.
+// Прерванные остановкой доставки повторяются после запуска, когда истечет их аренда.
+func (d *Dispatcher) Run(ctx context.Context) {
+	queueDone := make(chan struct{})
+	go func() {
+		defer close(queueDone)
+		d.runQueue(ctx)
+	}()
+
+	ticker := time.NewTicker(pollPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			<-queueDone
+			d.wg.Wait()
+			d.logger.Info("webhook dispatcher stopped", zap.Error(ctx.Err()))
+			return
+		case <-ticker.C:
+			d.deliverDue(ctx)
+		}
+	}
+}
+
+// deliverDue запускает отправку доставок, время попытки которых наступило, не дожидаясь ее завершения.
+// Доставки вебхука, который еще обслуживается, и вебхуков сверх maxWorkers ждут следующего опроса.
+func (d *Dispatcher) deliverDue(ctx context.Context) {
+	deliveries, err := d.store.FetchDueWebhookDeliveries(ctx, time.Now(), batchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			d.logger.Error("failed to fetch webhook deliveries", zap.Error(err))
+		}
+		return
+	}
+
+	var order []string
+	groups := map[string][]models.WebhookDelivery{}
+	for _, delivery := range deliveries {
+		if _, ok := groups[delivery.WebhookID]; !ok {
+			order = append(order, delivery.WebhookID)
+		}
+		groups[delivery.WebhookID] = append(groups[delivery.WebhookID], delivery)
+	}
+
+	for _, webhookID := range order {
+		if ctx.Err() != nil || !d.acquire(webhookID) {
+			continue
+		}
+
+		group := d.lease(ctx, groups[webhookID])
+
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			defer d.release(webhookID)
+
+			for _, delivery := range group {
+				if ctx.Err() != nil {
+					return
+				}
+
+				d.attempt(ctx, delivery)
+			}
+		}()
+	}
+}
+
+// acquire занимает обработчик для вебхука, если вебхук еще не обслуживается и есть свободный обработчик.
+func (d *Dispatcher) acquire(webhookID string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.inFlight[webhookID]; ok {
+		return false
+	}
+
+	select {
+	case d.workers <- struct{}{}:
+		d.inFlight[webhookID] = struct{}{}
+		return true
+	default:
+		return false
+	}
+}
+
+func (d *Dispatcher) release(webhookID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.inFlight, webhookID)
+	<-d.workers
+}
+
+// lease переносит попытку доставок на время их отправки, чтобы следующие опросы не выбирали их повторно
+// и не вытесняли из выборки доставки других вебхуков. Доставки, которые не удалось арендовать, пропускаются.
+func (d *Dispatcher) lease(ctx context.Context, group []models.WebhookDelivery) []models.WebhookDelivery {
+	until := time.Now().Add(requestTimeout * time.Duration(len(group)+1))
+
+	leased := make([]models.WebhookDelivery, 0, len(group))
+	for _, delivery := range group {
+		next := delivery
+		next.NextAttempt = until
+		if err := d.store.UpdateWebhookDelivery(ctx, next); err != nil {
+			d.logger.Error("failed to lease webhook delivery", zap.String("delivery_id", delivery.ID), zap.Error(err))
+			continue
+		}
+
+		leased = append(leased, delivery)
+	}
+
+	return leased
+}
+
+// attempt выполняет одну попытку доставки и сохраняет ее результат.
+func (d *Dispatcher) attempt(ctx context.Context, delivery models.WebhookDelivery) {
+	sendErr := d.send(ctx, delivery)
+	if sendErr != nil && ctx.Err() != nil {
+		return
+	}
+
+	if sendErr == nil {
+		if err := d.store.DeleteWebhookDelivery(ctx, delivery.ID); err != nil {
+			d.logger.Error("failed to delete webhook delivery", zap.String("delivery_id", delivery.ID), zap.Error(err))
+		}
+		return
+	}
+
+	delivery.Attempts++
+	delivery.LastError = truncate(publicError(sendErr), maxErrorLength)
+	if delivery.Attempts >= d.maxAttempts {
+		delivery.Dead = true
+	} else {
+		delivery.NextAttempt = time.Now().Add(d.backoff(delivery.Attempts))
+	}
+
+	d.logger.Warn("webhook delivery failed",
+		zap.String("delivery_id", delivery.ID),
+		zap.String("webhook_id", delivery.WebhookID),
+		zap.Int("attempts", delivery.Attempts),
+		zap.Bool("dead", delivery.Dead),
+		zap.Error(sendErr),
+	)
+
+	if err := d.store.UpdateWebhookDelivery(ctx, delivery); err != nil {
+		d.logger.Error("failed to update webhook delivery", zap.String("delivery_id", delivery.ID), zap.Error(err))
+	}
+}
+
+// send отправляет подписанное событие, успешным считается любой ответ 2xx.
+func (d *Dispatcher) send(ctx context.Context, delivery models.WebhookDelivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set(common.ContentTypeHeader, common.JSONContentType)
+	req.Header.Set(HeaderWebhookID, delivery.WebhookID)
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			d.logger.Warn("failed to close webhook response body", zap.Error(err))
+		}
+	}()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: %s", errUnexpectedStatus, resp.Status)
+	}
+
+	return nil
+}
+
+// publicError возвращает причину неудачной доставки для владельца вебхука: код ответа получателя
+// или вид ошибки соединения без подробностей.
+func publicError(err error) string {
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, errUnexpectedStatus):
+		return err.Error()
+	case errors.Is(err, ErrAddressNotAllowed):
+		return errorNotAllowed
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return errorTimeout
+	default:
+		return errorConnection
+	}
+}
+
+// backoff возвращает задержку перед попыткой после attempts неудачных: retryDelay * 2^(attempts-1),
+// но не больше maxRetryDelay.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.retryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxRetryDelay)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	return s[:n]
+}
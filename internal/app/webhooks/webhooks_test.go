@@ -0,0 +1,392 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/common"
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/models"
+)
+
+// receiver тестовый получатель вебхуков, отвечает кодом status и запоминает запросы.
+type receiver struct {
+	server   *httptest.Server
+	requests []*http.Request
+	bodies   [][]byte
+	status   int
+	mu       sync.Mutex
+}
+
+func newReceiver(t *testing.T, status int) *receiver {
+	t.Helper()
+
+	rcv := &receiver{status: status}
+	rcv.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		rcv.mu.Lock()
+		defer rcv.mu.Unlock()
+
+		rcv.requests = append(rcv.requests, r)
+		rcv.bodies = append(rcv.bodies, body)
+		w.WriteHeader(rcv.status)
+	}))
+	t.Cleanup(rcv.server.Close)
+
+	return rcv
+}
+
+func (rcv *receiver) count() int {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+
+	return len(rcv.requests)
+}
+
+func storeWebhook(t *testing.T, s data.Storager, url string, events ...string) models.Webhook {
+	t.Helper()
+
+	webhook := models.Webhook{ID: "hook_1", UserID: "user_1", URL: url, Secret: "secret", Events: events}
+	require.NoError(t, s.StoreWebhook(context.Background(), webhook))
+
+	return webhook
+}
+
+// newDispatcher создает доставщик, которому разрешено подключаться к тестовым получателям на петлевом адресе.
+func newDispatcher(s data.Storager, maxAttempts int, retryDelay time.Duration) *Dispatcher {
+	d := NewDispatcher(zap.NewNop(), s, maxAttempts, retryDelay)
+	d.client = newClientWithControl(nil)
+
+	return d
+}
+
+// deliver ставит переданные события в очередь, отправляет доставки, время попытки которых наступило,
+// и ждет завершения отправки.
+func deliver(ctx context.Context, d *Dispatcher) {
+	d.flush(ctx)
+	d.deliverDue(ctx)
+	d.wg.Wait()
+}
+
+func userContext() context.Context {
+	return context.WithValue(context.Background(), common.KeyUserID, "user_1")
+}
+
+func TestDispatcher(t *testing.T) {
+	ctx := context.Background()
+	event := models.LinkEvent{
+		Time:        time.Now().UTC(),
+		Type:        models.LinkCreated,
+		ShortURL:    "abc",
+		OriginalURL: "https://ya.ru",
+		UserID:      "user_1",
+	}
+
+	t.Run("signed delivery", func(t *testing.T) {
+		rcv := newReceiver(t, http.StatusNoContent)
+		store := data.NewBaseStorage()
+		storeWebhook(t, store, rcv.server.URL, models.LinkCreated)
+		d := newDispatcher(store, 3, time.Minute)
+
+		d.Enqueue(ctx, event)
+		deliver(ctx, d)
+
+		require.Equal(t, 1, rcv.count())
+		req, body := rcv.requests[0], rcv.bodies[0]
+		assert.Equal(t, "hook_1", req.Header.Get(HeaderWebhookID))
+		assert.Equal(t, models.LinkCreated, req.Header.Get(HeaderEvent))
+		assert.Equal(t, Sign("secret", req.Header.Get(HeaderTimestamp), body), req.Header.Get(HeaderSignature))
+
+		var got payload
+		require.NoError(t, json.Unmarshal(body, &got))
+		assert.Equal(t, req.Header.Get(HeaderDelivery), got.ID)
+		assert.Equal(t, "abc", got.Link.ShortURL)
+		assert.Equal(t, "https://ya.ru", got.Link.OriginalURL)
+
+		due, err := store.FetchDueWebhookDeliveries(ctx, time.Now(), 0)
+		require.NoError(t, err)
+		assert.Empty(t, due)
+	})
+
+	t.Run("not subscribed event skipped", func(t *testing.T) {
+		rcv := newReceiver(t, http.StatusOK)
+		store := data.NewBaseStorage()
+		storeWebhook(t, store, rcv.server.URL, models.LinkDeleted)
+		d := newDispatcher(store, 3, time.Minute)
+
+		d.Enqueue(ctx, event)
+		deliver(ctx, d)
+
+		assert.Zero(t, rcv.count())
+	})
+
+	t.Run("retries then dead letter", func(t *testing.T) {
+		rcv := newReceiver(t, http.StatusInternalServerError)
+		store := data.NewBaseStorage()
+		storeWebhook(t, store, rcv.server.URL, models.LinkCreated)
+		d := newDispatcher(store, 2, time.Hour)
+
+		d.Enqueue(ctx, event)
+		deliver(ctx, d)
+
+		due, err := store.FetchDueWebhookDeliveries(ctx, time.Now().Add(59*time.Minute), 0)
+		require.NoError(t, err)
+		assert.Empty(t, due, "retry is delayed")
+
+		due, err = store.FetchDueWebhookDeliveries(ctx, time.Now().Add(time.Hour), 0)
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+		assert.Equal(t, 1, due[0].Attempts)
+		assert.Contains(t, due[0].LastError, "500")
+
+		d.retryDelay = 0
+		due[0].NextAttempt = time.Now()
+		require.NoError(t, store.UpdateWebhookDelivery(ctx, due[0]))
+		deliver(ctx, d)
+
+		assert.Equal(t, 2, rcv.count())
+		dead, err := store.FetchDeadWebhookDeliveries(userContext())
+		require.NoError(t, err)
+		require.Len(t, dead, 1)
+		assert.Equal(t, 2, dead[0].Attempts)
+	})
+
+	t.Run("queue survives restart", func(t *testing.T) {
+		rcv := newReceiver(t, http.StatusOK)
+		path := t.TempDir() + "/short-url-db.json"
+		store, err := data.NewFileStorage(zap.NewNop(), path)
+		require.NoError(t, err)
+		storeWebhook(t, store, rcv.server.URL, models.LinkCreated)
+
+		d := newDispatcher(store, 3, time.Minute)
+		d.Enqueue(ctx, event)
+		d.flush(ctx)
+
+		restarted, err := data.NewFileStorage(zap.NewNop(), path)
+		require.NoError(t, err)
+		deliver(ctx, newDispatcher(restarted, 3, time.Minute))
+
+		assert.Equal(t, 1, rcv.count())
+	})
+}
+
+// blockingStorage хранилище, выборка вебхуков которого ждет закрытия release.
+type blockingStorage struct {
+	data.Storager
+	release chan struct{}
+}
+
+func (s *blockingStorage) FetchEventWebhooks(
+	ctx context.Context,
+	userID string,
+	event string,
+) ([]models.Webhook, error) {
+	<-s.release
+	return s.Storager.FetchEventWebhooks(ctx, userID, event)
+}
+
+func TestDispatcherQueue(t *testing.T) {
+	event := models.LinkEvent{Time: time.Now().UTC(), Type: models.LinkClicked, ShortURL: "abc", UserID: "user_1"}
+
+	t.Run("enqueue does not wait for storage", func(t *testing.T) {
+		store := &blockingStorage{Storager: data.NewBaseStorage(), release: make(chan struct{})}
+		storeWebhook(t, store, "https://example.com/hook", models.LinkClicked)
+		d := newDispatcher(store, 3, time.Minute)
+
+		enqueued := make(chan struct{})
+		go func() {
+			defer close(enqueued)
+			d.Enqueue(context.Background(), event)
+		}()
+
+		select {
+		case <-enqueued:
+		case <-time.After(time.Second):
+			t.Fatal("enqueue waits for storage")
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			d.Run(ctx)
+		}()
+		close(store.release)
+		cancel()
+		<-done
+
+		due, err := store.FetchDueWebhookDeliveries(context.Background(), time.Now().Add(time.Minute), 0)
+		require.NoError(t, err)
+		assert.Len(t, due, 1, "queued event is stored on stop")
+	})
+
+	t.Run("full queue drops events", func(t *testing.T) {
+		store := data.NewBaseStorage()
+		storeWebhook(t, store, "https://example.com/hook", models.LinkClicked)
+		d := newDispatcher(store, 3, time.Minute)
+
+		for range eventQueueSize + 1 {
+			d.Enqueue(context.Background(), event)
+		}
+		d.flush(context.Background())
+
+		due, err := store.FetchDueWebhookDeliveries(context.Background(), time.Now().Add(time.Minute), 0)
+		require.NoError(t, err)
+		assert.Len(t, due, eventQueueSize)
+	})
+}
+
+func TestDispatcherSafety(t *testing.T) {
+	ctx := context.Background()
+	event := models.LinkEvent{Time: time.Now().UTC(), Type: models.LinkCreated, ShortURL: "abc", UserID: "user_1"}
+
+	lastError := func(t *testing.T, store data.Storager) string {
+		t.Helper()
+
+		due, err := store.FetchDueWebhookDeliveries(ctx, time.Now().Add(time.Hour), 0)
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+
+		return due[0].LastError
+	}
+
+	t.Run("private address rejected", func(t *testing.T) {
+		rcv := newReceiver(t, http.StatusOK)
+		store := data.NewBaseStorage()
+		storeWebhook(t, store, rcv.server.URL, models.LinkCreated)
+		d := NewDispatcher(zap.NewNop(), store, 3, time.Minute)
+
+		d.Enqueue(ctx, event)
+		deliver(ctx, d)
+
+		assert.Zero(t, rcv.count())
+		assert.Equal(t, errorNotAllowed, lastError(t, store))
+	})
+
+	t.Run("redirect not followed", func(t *testing.T) {
+		target := newReceiver(t, http.StatusOK)
+		redirect := httptest.NewServer(http.RedirectHandler(target.server.URL, http.StatusFound))
+		defer redirect.Close()
+
+		store := data.NewBaseStorage()
+		storeWebhook(t, store, redirect.URL, models.LinkCreated)
+		d := newDispatcher(store, 3, time.Minute)
+
+		d.Enqueue(ctx, event)
+		deliver(ctx, d)
+
+		assert.Zero(t, target.count())
+		assert.Equal(t, "unexpected response status: 302 Found", lastError(t, store))
+	})
+
+	t.Run("connection error details hidden", func(t *testing.T) {
+		closed := httptest.NewServer(http.NotFoundHandler())
+		closed.Close()
+
+		store := data.NewBaseStorage()
+		storeWebhook(t, store, closed.URL, models.LinkCreated)
+		d := newDispatcher(store, 3, time.Minute)
+
+		d.Enqueue(ctx, event)
+		deliver(ctx, d)
+
+		assert.Equal(t, errorConnection, lastError(t, store))
+	})
+
+	t.Run("slow webhook does not block others", func(t *testing.T) {
+		release := make(chan struct{})
+		slowRequests := make(chan struct{}, 10)
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			slowRequests <- struct{}{}
+			<-release
+		}))
+		defer slow.Close()
+		fast := newReceiver(t, http.StatusOK)
+
+		store := data.NewBaseStorage()
+		webhooks := []models.Webhook{
+			{ID: "slow", UserID: "user_1", URL: slow.URL, Secret: "secret", Events: []string{models.LinkCreated}},
+			{ID: "fast", UserID: "user_2", URL: fast.server.URL, Secret: "secret", Events: []string{models.LinkCreated}},
+		}
+		for _, w := range webhooks {
+			require.NoError(t, store.StoreWebhook(ctx, w))
+		}
+		d := newDispatcher(store, 3, time.Minute)
+
+		d.Enqueue(ctx, event)
+		d.Enqueue(ctx, event)
+		other := event
+		other.UserID = "user_2"
+		d.Enqueue(ctx, other)
+		d.flush(ctx)
+
+		d.deliverDue(ctx)
+		<-slowRequests
+		assert.Eventually(t, func() bool { return fast.count() == 1 }, time.Second, 10*time.Millisecond)
+
+		d.deliverDue(ctx)
+		assert.Empty(t, slowRequests, "leased deliveries are not sent again")
+
+		close(release)
+		d.wg.Wait()
+		assert.Len(t, slowRequests, 1, "deliveries of one webhook are sent one by one")
+	})
+}
+
+func TestPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{ip: "93.184.216.34", want: true},
+		{ip: "2606:2800:220:1:248:1893:25c8:1946", want: true},
+		{ip: "127.0.0.1", want: false},
+		{ip: "::1", want: false},
+		{ip: "10.1.2.3", want: false},
+		{ip: "172.16.0.1", want: false},
+		{ip: "192.168.1.1", want: false},
+		{ip: "169.254.169.254", want: false},
+		{ip: "100.64.0.1", want: false},
+		{ip: "0.0.0.0", want: false},
+		{ip: "fd00::1", want: false},
+		{ip: "fe80::1", want: false},
+		{ip: "::ffff:127.0.0.1", want: false},
+		{ip: "64:ff9b::a9fe:a9fe", want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.ip, func(t *testing.T) {
+			assert.Equal(t, test.want, publicIP(net.ParseIP(test.ip)))
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	d := NewDispatcher(zap.NewNop(), data.NewBaseStorage(), 10, time.Minute)
+
+	assert.Equal(t, time.Minute, d.backoff(1))
+	assert.Equal(t, 2*time.Minute, d.backoff(2))
+	assert.Equal(t, 8*time.Minute, d.backoff(4))
+	assert.Equal(t, maxRetryDelay, d.backoff(20))
+}
+
+func TestSign(t *testing.T) {
+	signature := Sign("secret", "1700000000", []byte(`{"id":"1"}`))
+
+	assert.Equal(t, "sha256=", signature[:7])
+	assert.Equal(t, signature, Sign("secret", "1700000000", []byte(`{"id":"1"}`)))
+	assert.NotEqual(t, signature, Sign("other", "1700000000", []byte(`{"id":"1"}`)))
+	assert.NotEqual(t, signature, Sign("secret", "1700000001", []byte(`{"id":"1"}`)))
+}
@@ -0,0 +1,164 @@
+// Пакет client предоставляет типизированный клиент сервиса сокращения ссылок.
+//
+// Клиент работает поверх транспорта: HTTP (NewHTTPTransport) или gRPC (NewGRPCTransport),
+// можно подключить и собственную реализацию Transport. Временные ошибки повторяются
+// согласно RetryPolicy, общий таймаут вызова задается опцией WithTimeout.
+package client
+
+import (
+	"context"
+	"path"
+	"strings"
+	"time"
+)
+
+// URL модель ссылки пользователя.
+type URL struct {
+	ShortURL    string `json:"short_url"`
+	OriginalURL string `json:"original_url"`
+}
+
+// BatchItem модель ссылки в составе пакетного сокращения.
+type BatchItem struct {
+	CorrelationID string `json:"correlation_id"`
+	OriginalURL   string `json:"original_url"`
+}
+
+// BatchResult модель результата пакетного сокращения для одной ссылки.
+type BatchResult struct {
+	CorrelationID string `json:"correlation_id"`
+	ShortURL      string `json:"short_url"`
+}
+
+// Stats модель статистики сервиса.
+type Stats struct {
+	URLs  int `json:"urls"`
+	Users int `json:"users"`
+}
+
+// Transport интерфейс транспорта, через который клиент обращается к сервису.
+// Ключ короткой ссылки - последний сегмент ее пути.
+type Transport interface {
+	Shorten(ctx context.Context, originalURL string) (string, error)
+	ShortenBatch(ctx context.Context, items []BatchItem) ([]BatchResult, error)
+	Resolve(ctx context.Context, key string) (string, error)
+	ListMyURLs(ctx context.Context) ([]URL, error)
+	Delete(ctx context.Context, keys []string) error
+	Stats(ctx context.Context) (Stats, error)
+}
+
+// Client типизированный клиент сервиса.
+type Client struct {
+	transport Transport
+	retry     RetryPolicy
+	timeout   time.Duration
+}
+
+// New создает клиент поверх транспорта. Учитываются опции WithRetry и WithTimeout.
+func New(transport Transport, opts ...Option) *Client {
+	o := newOptions(opts)
+
+	return &Client{
+		transport: transport,
+		retry:     o.retry,
+		timeout:   o.timeout,
+	}
+}
+
+// Shorten сокращает ссылку. Если ссылка уже сокращена, возвращает существующую короткую ссылку
+// вместе с ошибкой вида ErrConflict.
+func (c *Client) Shorten(ctx context.Context, originalURL string) (string, error) {
+	var shortURL string
+
+	err := c.call(ctx, false, func(ctx context.Context) error {
+		var err error
+		shortURL, err = c.transport.Shorten(ctx, originalURL)
+		return err
+	})
+
+	return shortURL, err
+}
+
+// ShortenBatch сокращает несколько ссылок за один запрос.
+func (c *Client) ShortenBatch(ctx context.Context, items []BatchItem) ([]BatchResult, error) {
+	var results []BatchResult
+
+	err := c.call(ctx, false, func(ctx context.Context) error {
+		var err error
+		results, err = c.transport.ShortenBatch(ctx, items)
+		return err
+	})
+
+	return results, err
+}
+
+// Resolve возвращает оригинальную ссылку по короткой ссылке или ее ключу.
+func (c *Client) Resolve(ctx context.Context, shortURL string) (string, error) {
+	var originalURL string
+
+	err := c.call(ctx, true, func(ctx context.Context) error {
+		var err error
+		originalURL, err = c.transport.Resolve(ctx, Key(shortURL))
+		return err
+	})
+
+	return originalURL, err
+}
+
+// ListMyURLs возвращает ссылки текущего пользователя (или выбранной организации).
+func (c *Client) ListMyURLs(ctx context.Context) ([]URL, error) {
+	var urls []URL
+
+	err := c.call(ctx, true, func(ctx context.Context) error {
+		var err error
+		urls, err = c.transport.ListMyURLs(ctx)
+		return err
+	})
+
+	return urls, err
+}
+
+// Delete удаляет ссылки пользователя по коротким ссылкам или их ключам.
+func (c *Client) Delete(ctx context.Context, shortURLs ...string) error {
+	keys := make([]string, 0, len(shortURLs))
+	for _, u := range shortURLs {
+		keys = append(keys, Key(u))
+	}
+
+	return c.call(ctx, true, func(ctx context.Context) error {
+		return c.transport.Delete(ctx, keys)
+	})
+}
+
+// Stats возвращает статистику сервиса, доступна только из доверенной подсети.
+func (c *Client) Stats(ctx context.Context) (Stats, error) {
+	var stats Stats
+
+	err := c.call(ctx, true, func(ctx context.Context) error {
+		var err error
+		stats, err = c.transport.Stats(ctx)
+		return err
+	})
+
+	return stats, err
+}
+
+// Key возвращает ключ короткой ссылки: последний сегмент пути или саму строку, если это уже ключ.
+func Key(shortURL string) string {
+	if !strings.Contains(shortURL, "/") {
+		return shortURL
+	}
+
+	return path.Base(strings.TrimRight(shortURL, "/"))
+}
+
+// call выполняет запрос с таймаутом на весь вызов, включая повторы.
+func (c *Client) call(ctx context.Context, idempotent bool, fn func(ctx context.Context) error) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	return c.retry.do(ctx, idempotent, fn)
+}
@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/config"
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+	"github.com/MihailSergeenkov/shortener/internal/app/proto"
+	"github.com/MihailSergeenkov/shortener/internal/app/routes"
+)
+
+func setupParams(t *testing.T) {
+	t.Helper()
+
+	params := config.Params
+	t.Cleanup(func() { config.Params = params })
+
+	_, subnet, err := net.ParseCIDR("127.0.0.0/8")
+	require.NoError(t, err)
+
+	config.Params.TrustedSubnet = subnet
+	config.Params.SecretKey = "secret"
+	config.Params.CookiePath = "/"
+	config.Params.CreateRPS = 0
+	config.Params.RedirectRPS = 0
+	config.Params.DailyURLsQuota = 0
+}
+
+func newHTTPServer(t *testing.T, storage data.Storager) *httptest.Server {
+	t.Helper()
+
+	router, err := routes.NewRouter(zap.NewNop(), storage)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	baseURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	config.Params.BaseURL = *baseURL
+
+	return server
+}
+
+func newGRPCConn(t *testing.T, storage data.Storager) *grpc.ClientConn {
+	t.Helper()
+
+	listen := bufconn.Listen(1024 * 1024)
+	s := proto.NewGRPCServer(zap.NewNop(), storage)
+	go func() { _ = s.Serve(listen) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listen.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func TestClient(t *testing.T) {
+	setupParams(t)
+
+	httpTransport, err := NewHTTPTransport(newHTTPServer(t, data.NewBaseStorage()).URL)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		transport Transport
+	}{
+		{name: "http", transport: httpTransport},
+		{name: "grpc", transport: NewGRPCTransport(newGRPCConn(t, data.NewBaseStorage()), WithUserID("some_id"))},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := New(test.transport)
+
+			shortURL, err := c.Shorten(ctx, "https://practicum.yandex.ru/")
+			require.NoError(t, err)
+
+			originalURL, err := c.Resolve(ctx, shortURL)
+			require.NoError(t, err)
+			assert.Equal(t, "https://practicum.yandex.ru/", originalURL)
+
+			results, err := c.ShortenBatch(ctx, []BatchItem{
+				{CorrelationID: "1", OriginalURL: "https://example.com/1"},
+				{CorrelationID: "2", OriginalURL: "https://example.com/2"},
+			})
+			require.NoError(t, err)
+			require.Len(t, results, 2)
+			assert.Equal(t, "1", results[0].CorrelationID)
+
+			urls, err := c.ListMyURLs(ctx)
+			require.NoError(t, err)
+			assert.Len(t, urls, 3)
+
+			require.NoError(t, c.Delete(ctx, shortURL))
+
+			_, err = c.Resolve(ctx, shortURL)
+			assert.ErrorIs(t, err, ErrGone)
+
+			_, err = c.Resolve(ctx, "unknown")
+			assert.ErrorIs(t, err, ErrNotFound)
+
+			stats, err := c.Stats(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, Stats{URLs: 3, Users: 1}, stats)
+		})
+	}
+}
+
+func TestClient_Unauthorized(t *testing.T) {
+	setupParams(t)
+
+	httpTransport, err := NewHTTPTransport(newHTTPServer(t, data.NewBaseStorage()).URL)
+	require.NoError(t, err)
+	grpcTransport := NewGRPCTransport(newGRPCConn(t, data.NewBaseStorage()))
+
+	for _, transport := range []Transport{httpTransport, grpcTransport} {
+		_, err := New(transport).ListMyURLs(context.Background())
+		assert.ErrorIs(t, err, ErrUnauthorized)
+	}
+}
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, "abc", Key("abc"))
+	assert.Equal(t, "abc", Key("http://localhost:8080/abc"))
+	assert.Equal(t, "abc", Key("http://localhost:8080/abc/"))
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Виды ошибок клиента, проверяются через errors.Is.
+var (
+	ErrInvalid      = errors.New("invalid request")     // некорректный запрос
+	ErrUnauthorized = errors.New("unauthorized")        // нет или недействителен токен пользователя
+	ErrForbidden    = errors.New("forbidden")           // недостаточно прав или запрос не из доверенной подсети
+	ErrNotFound     = errors.New("not found")           // ссылка не найдена
+	ErrGone         = errors.New("url deleted")         // ссылка удалена
+	ErrConflict     = errors.New("conflict")            // ссылка уже сокращена
+	ErrRateLimited  = errors.New("rate limit exceeded") // превышен лимит частоты запросов или дневная квота
+	ErrUnavailable  = errors.New("service unavailable") // сервис временно недоступен
+	ErrUnexpected   = errors.New("unexpected response") // неожиданный ответ сервиса
+)
+
+// Error структура ошибки ответа сервиса.
+type Error struct {
+	Kind       error         // вид ошибки, одна из ErrInvalid, ErrNotFound и т.д.
+	Code       string        // код ответа транспорта: HTTP статус или код gRPC
+	Message    string        // сообщение сервиса, если есть
+	RetryAfter time.Duration // время, через которое можно повторить запрос
+}
+
+// Error возвращает описание ошибки.
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%s (%s)", e.Kind, e.Code)
+	}
+
+	return fmt.Sprintf("%s (%s): %s", e.Kind, e.Code, e.Message)
+}
+
+// Unwrap позволяет проверять вид ошибки через errors.Is.
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+func retryAfter(err error) time.Duration {
+	var clientErr *Error
+	if errors.As(err, &clientErr) {
+		return clientErr.RetryAfter
+	}
+
+	return 0
+}
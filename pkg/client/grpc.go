@@ -0,0 +1,184 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/proto"
+)
+
+// Параметры gRPC протокола сервиса.
+const (
+	userIDMetadataKey     = "user_id"
+	orgIDMetadataKey      = "org_id"
+	shortURLMetadataField = "short_url"
+	reasonURLDeleted      = "URL_DELETED"
+)
+
+// GRPCTransport транспорт клиента поверх gRPC сервиса через сгенерированный ShortenerClient.
+type GRPCTransport struct {
+	client proto.ShortenerClient
+	userID string
+	orgID  string
+}
+
+// NewGRPCTransport создает gRPC транспорт поверх соединения conn.
+// Учитываются опции WithUserID и WithOrg.
+func NewGRPCTransport(conn grpc.ClientConnInterface, opts ...Option) *GRPCTransport {
+	o := newOptions(opts)
+
+	return &GRPCTransport{
+		client: proto.NewShortenerClient(conn),
+		userID: o.userID,
+		orgID:  o.orgID,
+	}
+}
+
+// Shorten сокращает ссылку через AddShortURL.
+func (t *GRPCTransport) Shorten(ctx context.Context, originalURL string) (string, error) {
+	resp, err := t.client.AddShortURL(t.outgoing(ctx), &proto.AddShortURLRequest{OriginalUrl: originalURL})
+	if err != nil {
+		return existingShortURL(err), grpcError(ctx, err)
+	}
+
+	return resp.GetShortUrl(), nil
+}
+
+// ShortenBatch сокращает несколько ссылок через AddShortURLs.
+func (t *GRPCTransport) ShortenBatch(ctx context.Context, items []BatchItem) ([]BatchResult, error) {
+	req := &proto.AddShortURLsRequest{Urls: make([]*proto.BatchRequest, 0, len(items))}
+	for _, item := range items {
+		req.Urls = append(req.Urls, &proto.BatchRequest{
+			CorrelationId: item.CorrelationID,
+			OriginalUrl:   item.OriginalURL,
+		})
+	}
+
+	resp, err := t.client.AddShortURLs(t.outgoing(ctx), req)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+
+	results := make([]BatchResult, 0, len(resp.GetUrls()))
+	for _, u := range resp.GetUrls() {
+		results = append(results, BatchResult{CorrelationID: u.GetCorrelationId(), ShortURL: u.GetShortUrl()})
+	}
+
+	return results, nil
+}
+
+// Resolve получает оригинальную ссылку через GetURL.
+func (t *GRPCTransport) Resolve(ctx context.Context, key string) (string, error) {
+	resp, err := t.client.GetURL(t.outgoing(ctx), &proto.GetURLRequest{ShortUrl: key})
+	if err != nil {
+		return "", grpcError(ctx, err)
+	}
+
+	return resp.GetOriginalUrl(), nil
+}
+
+// ListMyURLs получает ссылки пользователя через FetchUserURLs.
+func (t *GRPCTransport) ListMyURLs(ctx context.Context) ([]URL, error) {
+	resp, err := t.client.FetchUserURLs(t.outgoing(ctx), &proto.FetchUserURLsRequest{})
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+
+	urls := make([]URL, 0, len(resp.GetUrls()))
+	for _, u := range resp.GetUrls() {
+		urls = append(urls, URL{ShortURL: u.GetShortUrl(), OriginalURL: u.GetOriginalUrl()})
+	}
+
+	return urls, nil
+}
+
+// Delete удаляет ссылки пользователя через DeleteUserURLs.
+func (t *GRPCTransport) Delete(ctx context.Context, keys []string) error {
+	if _, err := t.client.DeleteUserURLs(t.outgoing(ctx), &proto.DeleteUserURLsRequest{Urls: keys}); err != nil {
+		return grpcError(ctx, err)
+	}
+
+	return nil
+}
+
+// Stats получает статистику через FetchStats.
+func (t *GRPCTransport) Stats(ctx context.Context) (Stats, error) {
+	resp, err := t.client.FetchStats(t.outgoing(ctx), &proto.FetchStatsRequest{})
+	if err != nil {
+		return Stats{}, grpcError(ctx, err)
+	}
+
+	return Stats{URLs: int(resp.GetUrls()), Users: int(resp.GetUsers())}, nil
+}
+
+func (t *GRPCTransport) outgoing(ctx context.Context) context.Context {
+	if t.userID != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, userIDMetadataKey, t.userID)
+	}
+	if t.orgID != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, orgIDMetadataKey, t.orgID)
+	}
+
+	return ctx
+}
+
+// grpcKinds соответствие кодов gRPC видам ошибок клиента.
+var grpcKinds = map[codes.Code]error{
+	codes.InvalidArgument:    ErrInvalid,
+	codes.Unauthenticated:    ErrUnauthorized,
+	codes.PermissionDenied:   ErrForbidden,
+	codes.NotFound:           ErrNotFound,
+	codes.AlreadyExists:      ErrConflict,
+	codes.FailedPrecondition: ErrConflict,
+	codes.ResourceExhausted:  ErrRateLimited,
+	codes.Unavailable:        ErrUnavailable,
+}
+
+// grpcError преобразует gRPC статус в ошибку клиента с учетом подробностей errdetails.
+func grpcError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return fmt.Errorf("%w: %w", ctx.Err(), err)
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return fmt.Errorf("grpc call failed: %w", err)
+	}
+
+	kind, ok := grpcKinds[st.Code()]
+	if !ok {
+		kind = ErrUnexpected
+	}
+
+	clientErr := &Error{Kind: kind, Code: st.Code().String(), Message: st.Message()}
+
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if d.GetReason() == reasonURLDeleted {
+				clientErr.Kind = ErrGone
+			}
+		case *errdetails.RetryInfo:
+			clientErr.RetryAfter = d.GetRetryDelay().AsDuration()
+		}
+	}
+
+	return clientErr
+}
+
+// existingShortURL возвращает существующую короткую ссылку из ошибки конфликта.
+func existingShortURL(err error) string {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info.GetMetadata()[shortURLMetadataField]
+		}
+	}
+
+	return ""
+}
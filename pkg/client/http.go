@@ -0,0 +1,388 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Параметры HTTP протокола сервиса.
+const (
+	authCookieName  = "AUTH_TOKEN"
+	csrfCookieName  = "CSRF_TOKEN"
+	csrfHeaderName  = "X-CSRF-Token"
+	orgHeaderName   = "X-Org-ID"
+	jsonContentType = "application/json"
+	csrfRefreshPath = "/api/user/csrf"
+	maxErrorMessage = 512
+)
+
+var errInvalidBaseURL = errors.New("base url must be absolute")
+
+// HTTPTransport транспорт клиента поверх HTTP API сервиса (/api).
+// Токен пользователя и CSRF токен хранятся в cookie, как у браузера: транспорт сохраняет выданный
+// сервисом токен и отправляет CSRF заголовок в изменяющих запросах.
+type HTTPTransport struct {
+	baseURL *url.URL
+	client  *http.Client
+	headers http.Header
+	orgID   string
+	gzip    bool
+}
+
+// NewHTTPTransport создает HTTP транспорт для сервиса по адресу baseURL.
+// Учитываются опции WithToken, WithOrg, WithHTTPClient, WithHeader и WithGzip.
+func NewHTTPTransport(baseURL string, opts ...Option) (*HTTPTransport, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse base url: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("%w: %s", errInvalidBaseURL, baseURL)
+	}
+
+	o := newOptions(opts)
+
+	client := &http.Client{}
+	if o.httpClient != nil {
+		c := *o.httpClient
+		client = &c
+	}
+
+	if client.Jar == nil {
+		jar, err := cookiejar.New(nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to init cookie jar: %w", err)
+		}
+		client.Jar = jar
+	}
+
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	t := &HTTPTransport{
+		baseURL: u,
+		client:  client,
+		headers: o.headers,
+		orgID:   o.orgID,
+		gzip:    o.gzip,
+	}
+
+	if o.token != "" {
+		t.SetToken(o.token)
+	}
+
+	return t, nil
+}
+
+// Token возвращает текущий JWT пользователя, пустая строка - токен еще не выдан.
+func (t *HTTPTransport) Token() string {
+	return t.cookie(authCookieName)
+}
+
+// SetToken задает JWT пользователя.
+func (t *HTTPTransport) SetToken(token string) {
+	t.client.Jar.SetCookies(t.baseURL, []*http.Cookie{{Name: authCookieName, Value: token, Path: "/"}})
+}
+
+// Shorten сокращает ссылку через POST /api/shorten.
+func (t *HTTPTransport) Shorten(ctx context.Context, originalURL string) (string, error) {
+	resp, err := t.send(ctx, http.MethodPost, "/api/shorten", struct {
+		URL string `json:"url"`
+	}{URL: originalURL}, t.gzip)
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Result string `json:"result"`
+	}
+
+	switch resp.status {
+	case http.StatusCreated:
+		if err := resp.decode(&result); err != nil {
+			return "", err
+		}
+		return result.Result, nil
+	case http.StatusConflict:
+		if err := resp.decode(&result); err != nil {
+			return "", err
+		}
+		return result.Result, resp.error()
+	default:
+		return "", resp.error()
+	}
+}
+
+// ShortenBatch сокращает несколько ссылок через POST /api/shorten/batch.
+func (t *HTTPTransport) ShortenBatch(ctx context.Context, items []BatchItem) ([]BatchResult, error) {
+	resp, err := t.send(ctx, http.MethodPost, "/api/shorten/batch", items, t.gzip)
+	if err != nil {
+		return nil, err
+	}
+	if resp.status != http.StatusCreated {
+		return nil, resp.error()
+	}
+
+	var results []BatchResult
+	if err := resp.decode(&results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// Resolve получает оригинальную ссылку из редиректа GET /{key}.
+func (t *HTTPTransport) Resolve(ctx context.Context, key string) (string, error) {
+	resp, err := t.send(ctx, http.MethodGet, "/"+url.PathEscape(key), nil, false)
+	if err != nil {
+		return "", err
+	}
+	if resp.status != http.StatusTemporaryRedirect {
+		return "", resp.error()
+	}
+
+	return resp.header.Get("Location"), nil
+}
+
+// ListMyURLs получает ссылки пользователя через GET /api/user/urls.
+func (t *HTTPTransport) ListMyURLs(ctx context.Context) ([]URL, error) {
+	resp, err := t.send(ctx, http.MethodGet, "/api/user/urls", nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.status {
+	case http.StatusNoContent:
+		return []URL{}, nil
+	case http.StatusOK:
+		var urls []URL
+		if err := resp.decode(&urls); err != nil {
+			return nil, err
+		}
+		return urls, nil
+	default:
+		return nil, resp.error()
+	}
+}
+
+// Delete удаляет ссылки пользователя через DELETE /api/user/urls.
+func (t *HTTPTransport) Delete(ctx context.Context, keys []string) error {
+	resp, err := t.send(ctx, http.MethodDelete, "/api/user/urls", keys, false)
+	if err != nil {
+		return err
+	}
+	if resp.status != http.StatusAccepted {
+		return resp.error()
+	}
+
+	return nil
+}
+
+// Stats получает статистику через GET /api/internal/stats.
+func (t *HTTPTransport) Stats(ctx context.Context) (Stats, error) {
+	resp, err := t.send(ctx, http.MethodGet, "/api/internal/stats", nil, false)
+	if err != nil {
+		return Stats{}, err
+	}
+	if resp.status != http.StatusOK {
+		return Stats{}, resp.error()
+	}
+
+	var stats Stats
+	if err := resp.decode(&stats); err != nil {
+		return Stats{}, err
+	}
+
+	return stats, nil
+}
+
+// send выполняет запрос. Если изменяющий запрос отклонен без CSRF токена, токен запрашивается
+// безопасным запросом и запрос повторяется один раз.
+func (t *HTTPTransport) send(ctx context.Context, method string, p string, in any, compress bool) (*httpResponse, error) {
+	hadCSRF := t.cookie(csrfCookieName) != ""
+
+	resp, err := t.sendOnce(ctx, method, p, in, compress)
+	if err != nil || resp.status != http.StatusForbidden || isSafeMethod(method) || hadCSRF || t.Token() == "" {
+		return resp, err
+	}
+
+	if _, err := t.sendOnce(ctx, http.MethodGet, csrfRefreshPath, nil, false); err != nil {
+		return nil, err
+	}
+	if t.cookie(csrfCookieName) == "" {
+		return resp, nil
+	}
+
+	return t.sendOnce(ctx, method, p, in, compress)
+}
+
+func (t *HTTPTransport) sendOnce(ctx context.Context, method string, p string, in any, compress bool) (*httpResponse, error) {
+	var body io.Reader = http.NoBody
+	if in != nil {
+		payload, err := encodeBody(in, compress)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, t.baseURL.JoinPath(p).String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	for key, values := range t.headers {
+		req.Header[key] = values
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", jsonContentType)
+	}
+	if compress {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	if t.orgID != "" {
+		req.Header.Set(orgHeaderName, t.orgID)
+	}
+	if csrf := t.cookie(csrfCookieName); csrf != "" && !isSafeMethod(method) {
+		req.Header.Set(csrfHeaderName, csrf)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("request failed: %w", err)
+		}
+		return nil, &Error{Kind: ErrUnavailable, Code: "transport", Message: err.Error()}
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	payload, err := readBody(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &httpResponse{status: resp.StatusCode, header: resp.Header, body: payload}, nil
+}
+
+func (t *HTTPTransport) cookie(name string) string {
+	for _, c := range t.client.Jar.Cookies(t.baseURL) {
+		if c.Name == name {
+			return c.Value
+		}
+	}
+
+	return ""
+}
+
+type httpResponse struct {
+	header http.Header
+	body   []byte
+	status int
+}
+
+func (r *httpResponse) decode(v any) error {
+	if err := json.Unmarshal(r.body, v); err != nil {
+		return &Error{Kind: ErrUnexpected, Code: strconv.Itoa(r.status), Message: err.Error()}
+	}
+
+	return nil
+}
+
+// error преобразует код ответа в ошибку клиента.
+func (r *httpResponse) error() error {
+	kind, ok := httpKinds[r.status]
+	if !ok {
+		kind = ErrUnexpected
+		if r.status >= http.StatusInternalServerError {
+			kind = ErrUnavailable
+		}
+	}
+
+	message := strings.TrimSpace(string(r.body))
+	if len(message) > maxErrorMessage || !strings.HasPrefix(r.header.Get("Content-Type"), "text/") {
+		message = ""
+	}
+
+	var retryAfter time.Duration
+	if seconds, err := strconv.Atoi(r.header.Get("Retry-After")); err == nil {
+		retryAfter = time.Duration(seconds) * time.Second
+	}
+
+	return &Error{Kind: kind, Code: strconv.Itoa(r.status), Message: message, RetryAfter: retryAfter}
+}
+
+// httpKinds соответствие кодов ответа HTTP видам ошибок клиента.
+// Внутренняя ошибка сервиса (500) не считается временной и не повторяется.
+var httpKinds = map[int]error{
+	http.StatusBadRequest:          ErrInvalid,
+	http.StatusUnauthorized:        ErrUnauthorized,
+	http.StatusForbidden:           ErrForbidden,
+	http.StatusNotFound:            ErrNotFound,
+	http.StatusGone:                ErrGone,
+	http.StatusConflict:            ErrConflict,
+	http.StatusTooManyRequests:     ErrRateLimited,
+	http.StatusInternalServerError: ErrUnexpected,
+}
+
+func encodeBody(in any, compress bool) ([]byte, error) {
+	payload, err := json.Marshal(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	if !compress {
+		return payload, nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(payload); err != nil {
+		return nil, fmt.Errorf("failed to compress request: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress request: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// readBody читает тело ответа. Сжатый ответ распаковывает http.Transport, если заголовок Accept-Encoding
+// не задан через WithHeader, иначе ответ распаковывается здесь по заголовку Content-Encoding.
+func readBody(resp *http.Response) ([]byte, error) {
+	var body io.Reader = resp.Body
+	if !resp.Uncompressed && strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		zr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read compressed response: %w", err)
+		}
+		defer func() { _ = zr.Close() }()
+		body = zr
+	}
+
+	payload, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	return payload, nil
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MihailSergeenkov/shortener/internal/app/config"
+	"github.com/MihailSergeenkov/shortener/internal/app/data"
+)
+
+// conflictStorage хранилище, в котором любая оригинальная ссылка уже сокращена.
+type conflictStorage struct {
+	data.Storager
+}
+
+func (s conflictStorage) StoreShortURL(context.Context, string, string) error {
+	return &data.OriginalURLAlreadyExistError{ShortURL: "existing"}
+}
+
+func TestHTTPTransport_Token(t *testing.T) {
+	setupParams(t)
+	config.Params.CSRFProtection = true
+
+	server := newHTTPServer(t, data.NewBaseStorage())
+	ctx := context.Background()
+
+	first, err := NewHTTPTransport(server.URL)
+	require.NoError(t, err)
+	assert.Empty(t, first.Token())
+
+	shortURL, err := New(first).Shorten(ctx, "https://practicum.yandex.ru/")
+	require.NoError(t, err)
+	require.NotEmpty(t, first.Token())
+
+	t.Run("delete with csrf cookie", func(t *testing.T) {
+		assert.NoError(t, New(first).Delete(ctx, shortURL))
+	})
+
+	t.Run("saved token without csrf cookie", func(t *testing.T) {
+		second, err := NewHTTPTransport(server.URL, WithToken(first.Token()))
+		require.NoError(t, err)
+
+		urls, err := New(second).ListMyURLs(ctx)
+		require.NoError(t, err)
+		assert.Len(t, urls, 1)
+
+		third, err := NewHTTPTransport(server.URL, WithToken(first.Token()))
+		require.NoError(t, err)
+		assert.NoError(t, New(third).Delete(ctx, shortURL))
+	})
+}
+
+func TestHTTPTransport_Gzip(t *testing.T) {
+	setupParams(t)
+	ctx := context.Background()
+
+	t.Run("compressed request", func(t *testing.T) {
+		transport, err := NewHTTPTransport(newHTTPServer(t, data.NewBaseStorage()).URL, WithGzip())
+		require.NoError(t, err)
+
+		results, err := New(transport).ShortenBatch(ctx, []BatchItem{{CorrelationID: "1", OriginalURL: "https://example.com/"}})
+		require.NoError(t, err)
+		assert.Len(t, results, 1)
+	})
+
+	t.Run("compressed conflict", func(t *testing.T) {
+		server := newHTTPServer(t, conflictStorage{Storager: data.NewBaseStorage()})
+		transport, err := NewHTTPTransport(server.URL)
+		require.NoError(t, err)
+
+		shortURL, err := New(transport).Shorten(ctx, "https://example.com/")
+		assert.ErrorIs(t, err, ErrConflict)
+		assert.Equal(t, server.URL+"/existing", shortURL)
+	})
+
+	t.Run("explicit accept encoding", func(t *testing.T) {
+		server := newHTTPServer(t, data.NewBaseStorage())
+		transport, err := NewHTTPTransport(server.URL, WithHeader("Accept-Encoding", "gzip"))
+		require.NoError(t, err)
+
+		shortURL, err := New(transport).Shorten(ctx, "https://example.com/")
+		require.NoError(t, err)
+		assert.Contains(t, shortURL, server.URL)
+	})
+}
+
+func TestHTTPTransport_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+		want       error
+	}{
+		{name: "forbidden", status: http.StatusForbidden, want: ErrForbidden},
+		{name: "rate limited", status: http.StatusTooManyRequests, retryAfter: "1", want: ErrRateLimited},
+		{name: "bad gateway", status: http.StatusBadGateway, want: ErrUnavailable},
+		{name: "internal", status: http.StatusInternalServerError, want: ErrUnexpected},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if test.retryAfter != "" {
+					w.Header().Set("Retry-After", test.retryAfter)
+				}
+				w.WriteHeader(test.status)
+			}))
+			defer server.Close()
+
+			transport, err := NewHTTPTransport(server.URL)
+			require.NoError(t, err)
+
+			_, err = transport.Stats(context.Background())
+			assert.ErrorIs(t, err, test.want)
+			if test.retryAfter != "" {
+				assert.Positive(t, retryAfter(err))
+			}
+		})
+	}
+
+	t.Run("relative base url", func(t *testing.T) {
+		_, err := NewHTTPTransport("localhost:8080")
+		assert.Error(t, err)
+	})
+}
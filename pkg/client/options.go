@@ -0,0 +1,90 @@
+package client
+
+import (
+	"net/http"
+	"time"
+)
+
+// Option опция клиента или транспорта. Опции, не относящиеся к транспорту, им игнорируются.
+type Option func(o *options)
+
+type options struct {
+	httpClient *http.Client
+	headers    http.Header
+	retry      RetryPolicy
+	token      string
+	userID     string
+	orgID      string
+	timeout    time.Duration
+	gzip       bool
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		headers: http.Header{},
+		retry:   DefaultRetryPolicy(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithRetry задает политику повторов клиента.
+func WithRetry(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = policy
+	}
+}
+
+// WithTimeout задает таймаут вызова клиента вместе с повторами, если он короче дедлайна контекста.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithOrg выполняет запросы от имени организации.
+func WithOrg(orgID string) Option {
+	return func(o *options) {
+		o.orgID = orgID
+	}
+}
+
+// WithToken задает JWT пользователя для HTTP транспорта (cookie AUTH_TOKEN).
+// Без токена сервис выдаст новый при первом сокращении ссылки, его можно получить через HTTPTransport.Token.
+func WithToken(token string) Option {
+	return func(o *options) {
+		o.token = token
+	}
+}
+
+// WithUserID задает идентификатор пользователя для gRPC транспорта (метаданные user_id).
+func WithUserID(userID string) Option {
+	return func(o *options) {
+		o.userID = userID
+	}
+}
+
+// WithHTTPClient задает HTTP клиент транспорта. Клиент копируется: транспорт подключает свое
+// хранилище cookie, если его нет, и отключает переход по редиректам.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = client
+	}
+}
+
+// WithHeader добавляет заголовок ко всем HTTP запросам, например X-Real-IP для статистики через прокси.
+func WithHeader(key string, value string) Option {
+	return func(o *options) {
+		o.headers.Add(key, value)
+	}
+}
+
+// WithGzip включает сжатие тел запросов на сокращение ссылок для HTTP транспорта.
+func WithGzip() Option {
+	return func(o *options) {
+		o.gzip = true
+	}
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultMaxAttempts = 3
+	defaultBaseDelay   = 100 * time.Millisecond
+	defaultMaxDelay    = 5 * time.Second
+)
+
+// RetryPolicy политика повторов временных ошибок.
+// Превышение лимита частоты повторяется для любых запросов, так как сервис запрос не выполнял.
+// Недоступность сервиса повторяется только для идемпотентных запросов (все, кроме сокращения ссылок).
+// Задержка растет экспоненциально от BaseDelay, но не меньше Retry-After сервиса. Если требуемая
+// задержка больше MaxDelay (например, исчерпана дневная квота), ошибка возвращается сразу.
+type RetryPolicy struct {
+	MaxAttempts int           // число попыток, включая первую; 1 отключает повторы
+	BaseDelay   time.Duration // задержка перед первым повтором
+	MaxDelay    time.Duration // максимальная задержка перед повтором
+}
+
+// DefaultRetryPolicy политика повторов по умолчанию.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: defaultMaxAttempts,
+		BaseDelay:   defaultBaseDelay,
+		MaxDelay:    defaultMaxDelay,
+	}
+}
+
+func (p RetryPolicy) do(ctx context.Context, idempotent bool, fn func(ctx context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil || attempt >= p.MaxAttempts || !retryable(err, idempotent) {
+			return err
+		}
+
+		delay := p.delay(attempt, retryAfter(err))
+		if delay > p.MaxDelay {
+			return err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w: %w", ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
+
+func (p RetryPolicy) delay(attempt int, retryAfter time.Duration) time.Duration {
+	delay := p.BaseDelay << (attempt - 1)
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	return max(delay, retryAfter)
+}
+
+func retryable(err error, idempotent bool) bool {
+	if errors.Is(err, ErrRateLimited) {
+		return true
+	}
+
+	return idempotent && errors.Is(err, ErrUnavailable)
+}
@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyTransport транспорт, возвращающий ошибки из списка, а затем успешные ответы.
+type flakyTransport struct {
+	Transport
+	errs  []error
+	calls int
+}
+
+func (t *flakyTransport) next() error {
+	t.calls++
+	if len(t.errs) == 0 {
+		return nil
+	}
+
+	err := t.errs[0]
+	t.errs = t.errs[1:]
+
+	return err
+}
+
+func (t *flakyTransport) Shorten(context.Context, string) (string, error) {
+	return "key", t.next()
+}
+
+func (t *flakyTransport) Resolve(ctx context.Context, _ string) (string, error) {
+	if err := t.next(); err != nil {
+		return "", err
+	}
+
+	<-ctx.Done()
+
+	return "", ctx.Err()
+}
+
+func (t *flakyTransport) Stats(context.Context) (Stats, error) {
+	return Stats{}, t.next()
+}
+
+func TestRetryPolicy(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+	rateLimited := &Error{Kind: ErrRateLimited}
+	unavailable := &Error{Kind: ErrUnavailable}
+
+	tests := []struct {
+		name      string
+		errs      []error
+		call      func(c *Client) error
+		wantErr   error
+		wantCalls int
+	}{
+		{
+			name:      "rate limited is retried",
+			errs:      []error{rateLimited, rateLimited},
+			call:      func(c *Client) error { _, err := c.Shorten(context.Background(), "u"); return err },
+			wantCalls: 3,
+		},
+		{
+			name:      "attempts exhausted",
+			errs:      []error{rateLimited, rateLimited, rateLimited},
+			call:      func(c *Client) error { _, err := c.Shorten(context.Background(), "u"); return err },
+			wantErr:   ErrRateLimited,
+			wantCalls: 3,
+		},
+		{
+			name:      "unavailable is not retried for shorten",
+			errs:      []error{unavailable},
+			call:      func(c *Client) error { _, err := c.Shorten(context.Background(), "u"); return err },
+			wantErr:   ErrUnavailable,
+			wantCalls: 1,
+		},
+		{
+			name:      "unavailable is retried for stats",
+			errs:      []error{unavailable},
+			call:      func(c *Client) error { _, err := c.Stats(context.Background()); return err },
+			wantCalls: 2,
+		},
+		{
+			name:      "retry after exceeds max delay",
+			errs:      []error{&Error{Kind: ErrRateLimited, RetryAfter: time.Hour}},
+			call:      func(c *Client) error { _, err := c.Stats(context.Background()); return err },
+			wantErr:   ErrRateLimited,
+			wantCalls: 1,
+		},
+		{
+			name:      "not found is not retried",
+			errs:      []error{&Error{Kind: ErrNotFound}},
+			call:      func(c *Client) error { _, err := c.Stats(context.Background()); return err },
+			wantErr:   ErrNotFound,
+			wantCalls: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transport := &flakyTransport{errs: test.errs}
+			err := test.call(New(transport, WithRetry(policy)))
+
+			if test.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, test.wantErr)
+			}
+			assert.Equal(t, test.wantCalls, transport.calls)
+		})
+	}
+}
+
+func TestClient_Timeout(t *testing.T) {
+	c := New(&flakyTransport{}, WithTimeout(10*time.Millisecond))
+
+	_, err := c.Resolve(context.Background(), "key")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}